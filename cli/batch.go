@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// isBatch reports whether the arguments of "gozip extract" name several
+// archives, as "gozip extract *.zip" does, rather than an archive and an
+// entry: there are more than two, or the second is a file on disk with an
+// archive extension, see util.HasArchiveExtension.
+func isBatch(positional []string) bool {
+	switch {
+	case len(positional) < 2:
+		return false
+	case len(positional) > 2:
+		return true
+	}
+	info, err := os.Stat(positional[1])
+	return err == nil && info.Mode().IsRegular() && util.HasArchiveExtension(positional[1])
+}
+
+// batchResult is how the extraction of one archive of a batch went.
+type batchResult struct {
+	zipPath string
+	dest    string
+	count   int
+	err     error
+}
+
+// runBatch extracts each archive into its own folder inside destDir, named
+// after it, going on past the archives that fail, then prints a summary
+// line per archive. Ctrl-C stops the whole batch.
+func (e extraction) runBatch(ctx context.Context, stdout io.Writer, archives []string, destDir string) error {
+	var results []batchResult
+	failed := 0
+	for _, zipPath := range archives {
+		fmt.Fprintf(stdout, "%s:\n", zipPath)
+		r := batchResult{zipPath: zipPath, dest: filepath.Join(destDir, util.ArchiveFolderName(zipPath))}
+		r.count, r.err = e.run(ctx, stdout, zipPath, "", r.dest)
+		results = append(results, r)
+
+		if exitCode(r.err) == ExitInterrupted {
+			printBatchSummary(stdout, results)
+			return failWith(ExitInterrupted, fmt.Errorf("interrupted at %s, %d of %d archives done", zipPath, len(results)-1, len(archives)))
+		}
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(stdout, "  error: %s\n", r.err)
+		}
+	}
+
+	printBatchSummary(stdout, results)
+	if failed > 0 {
+		return failWith(ExitExtract, fmt.Errorf("%d of %d archives failed", failed, len(archives)))
+	}
+	return nil
+}
+
+func printBatchSummary(w io.Writer, results []batchResult) {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(tw, "%s\tfailed\t%s\n", r.zipPath, r.err)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d files\t%s\n", r.zipPath, r.count, r.dest)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runCat implements "gozip cat archive.zip entry/name".
+func runCat(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip cat archive.zip <entry name>")
+		fmt.Fprintln(stdout, "Writes the file, decompressed, to standard output, e.g. to pipe it into jq or less.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("an archive and an entry name are required")
+	}
+
+	_, err := util.CatEntry(fs.Arg(0), fs.Arg(1), stdout)
+	return err
+}
@@ -0,0 +1,154 @@
+// Package cli implements goZip's non-interactive subcommands.
+// Each subcommand parses its own flags and writes its results to the
+// provided writer, so scripts can use goZip without starting the TUI.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/util"
+)
+
+// command describes a single subcommand such as "gozip features".
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+// commands lists every available subcommand, in the order shown by "gozip help".
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "cat", summary: "write a file of an archive to standard output", run: runCat},
+		{name: "comment", summary: "show or replace the comment of an archive", run: runComment},
+		{name: "create", summary: "create a new archive from files and directories", run: runCreate},
+		{name: "diff", summary: "compare the entries of two archives", run: runDiff},
+		{name: "dupes", summary: "find files stored more than once in an archive", run: runDupes},
+		{name: "entropy", summary: "flag files that look encrypted or random without being in a compressed format", run: runEntropy},
+		{name: "extract", summary: "extract an archive, a folder or a file", run: runExtract},
+		{name: "features", summary: "report the formats, methods and optional features of this build", run: runFeatures},
+		{name: "find", summary: "list the archives under a folder, or pick one to browse with --pick", run: runFind},
+		{name: "hash", summary: "print the SHA-256, SHA-1 or MD5 of the files of an archive", run: runHash},
+		{name: "health", summary: "analyse an archive and optionally fix what it finds", run: runHealth},
+		{name: "help", summary: "list the available subcommands", run: runHelp},
+		{name: "info", summary: "summarize an archive: entries, sizes, comment and format", run: runInfo},
+		{name: "merge", summary: "combine several archives into one", run: runMerge},
+		{name: "mount", summary: "expose an archive read-only as a filesystem, through FUSE", run: runMount},
+		{name: "rename", summary: "rename or move a file or folder inside an archive", run: runRename},
+		{name: "replace", summary: "replace the content of a file inside an archive", run: runReplace},
+		{name: "serve", summary: "serve the files of an archive over HTTP, with a browsable index", run: runServe},
+		{name: "stats", summary: "show or manage local, opt-in usage statistics", run: runStats},
+		{name: "sync", summary: "update an archive to mirror a directory", run: runSync},
+		{name: "verify", summary: "check the files of an archive against a SHA256SUMS file", run: runVerify},
+		{name: "verify-sig", summary: "check the detached OpenPGP signature of an archive with gpg", run: runVerifySig},
+	}
+}
+
+// settings are the user's settings, loaded by Run. Flags override them.
+var settings = config.Default()
+
+// Run executes the subcommand named by args[0], after loading the user's
+// settings (see config.Load).
+//
+// Parameters:
+//   - args: command-line arguments without the program name
+//   - stdout: destination for the subcommand's regular output
+//   - stderr: destination for error messages
+//
+// Returns:
+//   - bool: true if args named a subcommand, false if the caller should start the TUI
+//   - int: process exit code, see ExitOK and the other codes; meaningful
+//     only when the first value is true
+func Run(args []string, stdout, stderr io.Writer) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		return false, 0
+	}
+	if _, pick := FindPick(args); pick {
+		return false, 0
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "gozip: %s\n", err)
+		return true, ExitUsage
+	}
+	settings = cfg
+	util.SetDefaultNameEncoding(cfg.Encoding)
+	util.SetMemoryLimit(cfg.MaxMemory)
+	util.SetPreviewLimit(cfg.PreviewLimit)
+
+	util.RecordUsage("command:" + cmd.name)
+	util.Logger().Info("command", "name", cmd.name, "args", args[1:])
+	err = cmd.run(args[1:], stdout)
+	util.FlushUsage()
+
+	if err != nil {
+		util.Logger().Error("command failed", "name", cmd.name, "err", err)
+		fmt.Fprintf(stderr, "gozip %s: %s\n", cmd.name, err)
+	}
+
+	return true, exitCode(err)
+}
+
+// parseInterspersed parses fs from args, allowing flags to appear after
+// positional arguments as in "gozip merge a.zip b.zip -o out.zip".
+// It returns the positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+
+	return command{}, false
+}
+
+func runHelp(args []string, stdout io.Writer) error {
+	fmt.Fprintln(stdout, "usage: gozip <archive.zip>")
+	fmt.Fprintln(stdout, "       gozip --mouse <archive.zip>   browse with the mouse too: click to select, double-click to extract, click a header to sort")
+	fmt.Fprintln(stdout, "       gozip --plain <archive.zip>   browse with a line-oriented prompt instead of the TUI")
+	fmt.Fprintln(stdout, "       gozip --sig <file|URL> <archive.zip>   verify the archive against this detached signature before browsing it")
+	fmt.Fprintln(stdout, "       gozip --date-format relative|rfc3339|<layout> --time-zone utc|local <archive.zip>")
+	fmt.Fprintln(stdout, "                                     show timestamps as \"3 days ago\" or e.g. \"2006-01-02 15:04\", in local time")
+	fmt.Fprintln(stdout, "       gozip <command> [arguments]")
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "-v or --verbose before the command logs what goZip does to gozip.log in")
+	fmt.Fprintln(stdout, "~/.local/state/gozip; -vv logs every file and action too.")
+	fmt.Fprintln(stdout, "--pprof :6060 serves net/http/pprof on that address while goZip runs, and")
+	fmt.Fprintln(stdout, "--trace file writes a runtime trace, for reporting performance problems.")
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "exit codes: 0 ok, 1 usage or other error, 2 archive missing or unreadable,")
+	fmt.Fprintln(stdout, "3 extraction failed, 4 verification failed, 70 goZip crashed, 130 interrupted.")
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(stdout, "  %-10s %s\n", c.name, c.summary)
+	}
+
+	return nil
+}
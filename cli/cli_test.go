@@ -0,0 +1,1089 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/util"
+)
+
+// TestRunUnknownCommand checks that non-subcommand arguments are left to the TUI
+func TestRunUnknownCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no arguments", args: []string{}},
+		{name: "archive name", args: []string{"test.zip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			handled, _ := Run(tt.args, &stdout, &stderr)
+			if handled {
+				t.Errorf("Run(%v) handled = true, want false", tt.args)
+			}
+		})
+	}
+}
+
+// TestRunFeatures checks the text and JSON outputs of the features command
+func TestRunFeatures(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		handled, code := Run([]string{"features"}, &stdout, &stderr)
+		if !handled || code != 0 {
+			t.Fatalf("Run(features) = %v, %d, want true, 0 (stderr: %s)", handled, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "DEFLATE") {
+			t.Errorf("features output missing DEFLATE method:\n%s", stdout.String())
+		}
+		if !strings.Contains(stdout.String(), "  sixel  no ") || !strings.Contains(stdout.String(), "  7z     no ") {
+			t.Errorf("features output does not report sixel and 7z as missing:\n%s", stdout.String())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		Run([]string{"features", "--json"}, &stdout, &stderr)
+
+		var report featureReport
+		if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+			t.Fatalf("features --json produced invalid JSON: %v", err)
+		}
+		if len(report.Formats) == 0 || report.Formats[0] != "zip" {
+			t.Errorf("Formats = %v, want zip first", report.Formats)
+		}
+		for _, name := range []string{"sixel", "7z"} {
+			if enabled, ok := report.Features[name]; !ok || enabled {
+				t.Errorf("Features[%q] = %v, %v, want false, true", name, enabled, ok)
+			}
+		}
+	})
+
+	t.Run("bad flag", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		_, code := Run([]string{"features", "--nope"}, &stdout, &stderr)
+		if code == 0 {
+			t.Error("Run(features --nope) exit code = 0, want non-zero")
+		}
+	})
+}
+
+// TestRunCreateEncrypt checks that --encrypt asks twice and rejects mismatches
+func TestRunCreateEncrypt(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(input, []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	defer func(orig func(string) (string, error)) { readPassword = orig }(readPassword)
+
+	tests := []struct {
+		name      string
+		answers   []string
+		wantCode  int
+		wantAsked int
+	}{
+		{name: "matching", answers: []string{"pw", "pw"}, wantCode: 0, wantAsked: 2},
+		{name: "mismatch", answers: []string{"pw", "other"}, wantCode: 1, wantAsked: 2},
+		{name: "empty", answers: []string{""}, wantCode: 1, wantAsked: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked := 0
+			readPassword = func(string) (string, error) {
+				asked++
+				return tt.answers[asked-1], nil
+			}
+
+			out := filepath.Join(dir, tt.name+".zip")
+			var stdout, stderr bytes.Buffer
+			_, code := Run([]string{"create", "-q", "--encrypt", out, input}, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("Run(create --encrypt) exit code = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if asked != tt.wantAsked {
+				t.Errorf("password asked %d times, want %d", asked, tt.wantAsked)
+			}
+
+			_, err := os.Stat(out)
+			if created := err == nil; created != (tt.wantCode == 0) {
+				t.Errorf("archive created = %v, want %v", created, tt.wantCode == 0)
+			}
+		})
+	}
+}
+
+// TestRunCreatePasswordSources checks where "gozip create --encrypt" takes
+// its password from, and that --keyring saves the one not found there
+func TestRunCreatePasswordSources(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(input, []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\r\nignored\n"), 0600); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	defer func(orig func(string) (string, error)) { readPassword = orig }(readPassword)
+	defer func(orig func(string) (string, error)) { keyringPassword = orig }(keyringPassword)
+	defer func(orig func(string, string) error) { saveKeyringPassword = orig }(saveKeyringPassword)
+
+	tests := []struct {
+		name      string
+		flags     []string
+		env       string
+		saved     string
+		wantCode  int
+		wantAsked bool
+		wantSaved string
+	}{
+		{name: "flag", flags: []string{"--encrypt", "--keyring", "--password", "from-flag"}, env: "from-env", wantSaved: "from-flag"},
+		{name: "file", flags: []string{"--encrypt", "--keyring", "--password-file", passwordFile}, saved: "from-keyring", wantSaved: "from-file"},
+		{name: "env", flags: []string{"--encrypt", "--keyring"}, env: "from-env", wantSaved: "from-env"},
+		{name: "keyring", flags: []string{"--encrypt", "--keyring"}, saved: "from-keyring"},
+		{name: "asked", flags: []string{"--encrypt", "--keyring"}, wantAsked: true, wantSaved: "typed"},
+		{name: "without keyring", flags: []string{"--encrypt"}, saved: "from-keyring", wantAsked: true},
+		{name: "without encrypt", flags: []string{"--password", "pw"}, wantCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PasswordEnv, tt.env)
+			asked := false
+			readPassword = func(string) (string, error) {
+				asked = true
+				return "typed", nil
+			}
+			keyringPassword = func(string) (string, error) { return tt.saved, nil }
+			saved := ""
+			saveKeyringPassword = func(_, password string) error {
+				saved = password
+				return nil
+			}
+
+			out := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".zip")
+			var stdout, stderr bytes.Buffer
+			args := append(append([]string{"create", "-q"}, tt.flags...), out, input)
+			if _, code := Run(args, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("Run(%v) exit code = %d, want %d (stderr: %s)", args, code, tt.wantCode, stderr.String())
+			}
+			if asked != tt.wantAsked {
+				t.Errorf("password asked = %v, want %v", asked, tt.wantAsked)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("password saved in the keyring = %q, want %q", saved, tt.wantSaved)
+			}
+		})
+	}
+}
+
+// TestParseVersion checks the values of "gozip extract --duplicate"
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "last", want: 0},
+		{in: "first", want: 1},
+		{in: "3", want: 3},
+		{in: "0", wantErr: true},
+		{in: "second", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseVersion(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseVersion(%q) = %d, %v, want %d (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestRunExtractSubfolder checks that whole-archive extraction honors the subfolder mode
+func TestRunExtractSubfolder(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+
+	zipPath := filepath.Join(dir, "docs.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	t.Setenv("GOZIP_SUBFOLDER", "")
+	if _, code := Run([]string{"extract", "-d", filepath.Join(dir, "auto"), zipPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "auto", "docs", "a.txt")); err != nil {
+		t.Errorf("auto mode did not extract into docs/: %v", err)
+	}
+
+	t.Setenv("GOZIP_SUBFOLDER", "never")
+	if _, code := Run([]string{"extract", "-d", filepath.Join(dir, "flat"), zipPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "flat", "a.txt")); err != nil {
+		t.Errorf("never mode did not extract in place: %v", err)
+	}
+
+	if _, code := Run([]string{"extract", "--subfolder", "always", "-d", filepath.Join(dir, "flat"), zipPath, "a.txt"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract a.txt) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "flat", "docs")); err == nil {
+		t.Error("extracting a single entry used a subfolder")
+	}
+}
+
+// TestRunExtractVerbose checks that -v prints a line per extracted file
+func TestRunExtractVerbose(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+
+	zipPath := filepath.Join(dir, "docs.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	out := filepath.Join(dir, "out")
+	if _, code := Run([]string{"extract", "-v", "--subfolder", "never", "-d", out, zipPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract -v) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	want := "  extracted: " + filepath.Join(out, "a.txt") + "\n" +
+		"  extracted: " + filepath.Join(out, "b.txt") + "\n" +
+		"extracted 2 files to " + out + "\n"
+	if stdout.String() != want {
+		t.Errorf("Run(extract -v) output = %q, want %q", stdout.String(), want)
+	}
+}
+
+// TestRunExtractList checks that --list extracts only the entries the file names
+func TestRunExtractList(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+
+	zipPath := filepath.Join(dir, "docs.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), filepath.Join(dir, "c.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	list := filepath.Join(dir, "picked.list")
+	if err := os.WriteFile(list, []byte("# picked\na.txt\nc.txt\n"), 0644); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if _, code := Run([]string{"extract", "--list", list, "--subfolder", "never", "-d", out, zipPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract --list) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	for name, want := range map[string]bool{"a.txt": true, "b.txt": false, "c.txt": true} {
+		if _, err := os.Stat(filepath.Join(out, name)); (err == nil) != want {
+			t.Errorf("%s extracted = %v, want %v", name, err == nil, want)
+		}
+	}
+
+	empty := filepath.Join(dir, "empty.list")
+	if err := os.WriteFile(empty, []byte("# nothing yet\n"), 0644); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+	if _, code := Run([]string{"extract", "--list", empty, "-d", out, zipPath}, &stdout, &stderr); code == 0 {
+		t.Error("Run(extract --list) with an empty list succeeded")
+	}
+}
+
+// TestRunInfo checks the summary printed by "gozip info"
+func TestRunInfo(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"info", "--json", "../util/testdata/test.zip"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(info --json) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	var report infoReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("info --json printed invalid JSON: %v", err)
+	}
+	if report.Format != "zip" || report.Entries != 1 || report.Size != 68 {
+		t.Errorf("info --json = %+v, want one 68-byte zip entry", report)
+	}
+
+	if _, code := Run([]string{"info", "../util/testdata/sample.txt"}, &stdout, &stderr); code != ExitArchive {
+		t.Errorf("Run(info sample.txt) exit code = %d, want %d", code, ExitArchive)
+	}
+
+	archive, err := os.ReadFile("../util/testdata/test.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	junk := filepath.Join(t.TempDir(), "junk.zip")
+	content := append([]byte("#!/bin/sh\nexit 0\n"), archive...)
+	if err := os.WriteFile(junk, append(content, "trailer"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if _, code := Run([]string{"info", junk}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(info junk.zip) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "offset:     17 ") || !strings.Contains(out, "trailing:   7 bytes") {
+		t.Errorf("Run(info junk.zip) output = %q, want the offset and the trailing bytes", out)
+	}
+}
+
+// TestRunInfoPackage checks that "gozip info" describes a JAR from its
+// manifest, in text and JSON
+func TestRunInfoPackage(t *testing.T) {
+	dir := t.TempDir()
+	manifest := "Manifest-Version: 1.0\nMain-Class: com.example.Main\nImplementation-Title: demo\nImplementation-Version: 2.0\n"
+	if err := os.MkdirAll(filepath.Join(dir, "META-INF"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "META-INF", "MANIFEST.MF"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jarPath := filepath.Join(dir, "demo.jar")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", jarPath, filepath.Join(dir, "META-INF")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"info", jarPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(info demo.jar) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"package:    JAR demo 2.0\n", "main class: com.example.Main\n", "signer:     none\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Run(info demo.jar) output = %q, want %q", out, want)
+		}
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"info", "--json", jarPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(info --json demo.jar) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	var report infoReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("info --json printed invalid JSON: %v", err)
+	}
+	if report.Package == nil || report.Package.Kind != "JAR" || report.Package.MainClass != "com.example.Main" ||
+		report.Package.Manifest["Implementation-Version"] != "2.0" {
+		t.Errorf("info --json package = %+v, want the JAR's manifest", report.Package)
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"info", "--json", "../util/testdata/test.zip"}, &stdout, &stderr); code != 0 || strings.Contains(stdout.String(), `"package"`) {
+		t.Errorf("Run(info --json test.zip) = %q, want no package", stdout.String())
+	}
+}
+
+// TestRunCat checks that "gozip cat" writes only the file's content
+func TestRunCat(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"a": 1}`), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	zipPath := filepath.Join(dir, "c.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.json")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"cat", zipPath, "a.json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(cat) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if stdout.String() != `{"a": 1}` {
+		t.Errorf("Run(cat) output = %q, want the file's content", stdout.String())
+	}
+
+	stderr.Reset()
+	if _, code := Run([]string{"cat", zipPath, "b.json"}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "b.json") {
+		t.Errorf("Run(cat b.json) exit code = %d, stderr = %q", code, stderr.String())
+	}
+}
+
+// TestRunHash checks the checksums printed and written by "gozip hash"
+func TestRunHash(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	zipPath := filepath.Join(dir, "h.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"hash", "-a", "md5", zipPath, "a.txt"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(hash) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if want := "900150983cd24fb0d6963f7d28e17f72  a.txt\n"; stdout.String() != want {
+		t.Errorf("Run(hash -a md5) output = %q, want %q", stdout.String(), want)
+	}
+
+	sums := filepath.Join(dir, "SHA256SUMS")
+	if _, code := Run([]string{"hash", zipPath, "-o", sums}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(hash -o) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if data, _ := os.ReadFile(sums); !strings.HasPrefix(string(data), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  a.txt") {
+		t.Errorf("SHA256SUMS = %q", data)
+	}
+}
+
+// TestRunVerify checks the report of "gozip verify" and its exit code
+func TestRunVerify(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "abc", "b.txt": "abc"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+	zipPath := filepath.Join(dir, "v.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	sums := filepath.Join(dir, "MD5SUMS")
+	os.WriteFile(sums, []byte("900150983cd24fb0d6963f7d28e17f72  a.txt\n900150983cd24fb0d6963f7d28e17f72  b.txt\n"), 0644)
+	stdout.Reset()
+	if _, code := Run([]string{"verify", zipPath, sums}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(verify) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if stdout.String() != "all 2 files match their md5 checksum\n" {
+		t.Errorf("Run(verify) output = %q", stdout.String())
+	}
+
+	os.WriteFile(sums, []byte("900150983cd24fb0d6963f7d28e17f72  a.txt\n0cc175b9c0f1b6a831c399e269772661  b.txt\n"), 0644)
+	stdout.Reset()
+	if _, code := Run([]string{"verify", zipPath, sums}, &stdout, &stderr); code != ExitVerify || stdout.String() != "b.txt: FAILED\n" {
+		t.Errorf("Run(verify) with a bad checksum = %d, %q", code, stdout.String())
+	}
+}
+
+// TestRunVerifySig checks "gozip verify-sig" with a throwaway key, and its
+// exit code once the archive changed
+func TestRunVerifySig(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	zipPath := filepath.Join(dir, "release.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	if _, code := Run([]string{"verify-sig", zipPath}, &stdout, &stderr); code != ExitUsage {
+		t.Errorf("Run(verify-sig) without a signature exit code = %d, want %d", code, ExitUsage)
+	}
+
+	// The sockets of gpg-agent need a short path, which t.TempDir may not be.
+	home, err := os.MkdirTemp("", "gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	t.Setenv("GNUPGHOME", home)
+	t.Setenv(util.GPGEnv, "")
+	defer exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+	for _, args := range [][]string{
+		{"--quick-gen-key", "Release Bot <bot@example.org>", "ed25519", "sign", "never"},
+		{"--detach-sign", "-o", zipPath + ".sig", zipPath},
+	} {
+		if out, err := exec.Command("gpg", append([]string{"--batch", "--passphrase", ""}, args...)...).CombinedOutput(); err != nil {
+			t.Skipf("gpg %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"verify-sig", zipPath}, &stdout, &stderr); code != 0 || !strings.HasPrefix(stdout.String(), "good signature from Release Bot <bot@example.org>\n") {
+		t.Errorf("Run(verify-sig) = %d, %q (stderr: %s)", code, stdout.String(), stderr.String())
+	}
+
+	if _, code := Run([]string{"comment", "set", zipPath, "changed"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(comment set) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	stdout.Reset()
+	if _, code := Run([]string{"verify-sig", "--sig", zipPath + ".sig", zipPath}, &stdout, &stderr); code != ExitVerify || !strings.HasPrefix(stdout.String(), "bad signature") {
+		t.Errorf("Run(verify-sig) of a changed archive = %d, %q", code, stdout.String())
+	}
+}
+
+// TestRunComment checks setting a comment from the command line and from
+// standard input, and reading it back
+func TestRunComment(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	zipPath := filepath.Join(dir, "c.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	oldInput := commentInput
+	defer func() { commentInput = oldInput }()
+	commentInput = strings.NewReader("from stdin\nsecond line\n")
+
+	for _, tt := range []struct {
+		set  string
+		want string
+	}{
+		{set: "v1.2", want: "v1.2\n"},
+		{set: "-", want: "from stdin\nsecond line\n"},
+		{set: "", want: ""},
+	} {
+		if _, code := Run([]string{"comment", "set", zipPath, tt.set}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Run(comment set %q) exit code = %d (stderr: %s)", tt.set, code, stderr.String())
+		}
+		stdout.Reset()
+		if _, code := Run([]string{"comment", "get", zipPath}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Run(comment get) exit code = %d (stderr: %s)", code, stderr.String())
+		}
+		if stdout.String() != tt.want {
+			t.Errorf("comment after set %q = %q, want %q", tt.set, stdout.String(), tt.want)
+		}
+	}
+
+	if _, code := Run([]string{"comment", "show", zipPath}, &stdout, &stderr); code != 1 {
+		t.Errorf("Run(comment show) exit code = %d, want 1", code)
+	}
+}
+
+// TestRunDupes checks the report printed by "gozip dupes"
+func TestRunDupes(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "same content", "b.txt": "same content", "c.txt": "other"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+
+	zipPath := filepath.Join(dir, "dupes.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), filepath.Join(dir, "c.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"dupes", zipPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(dupes) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "2 copies of 12 B") || !strings.Contains(out, "  a.txt\n  b.txt\n") || !strings.Contains(out, "1 redundant files in 1 groups") {
+		t.Errorf("Run(dupes) output = %q", out)
+	}
+}
+
+// TestRunEntropy checks the report printed by "gozip entropy"
+func TestRunEntropy(t *testing.T) {
+	dir := t.TempDir()
+	random := make([]byte, 8<<10)
+	rand.New(rand.NewSource(1)).Read(random)
+	inputs := map[string][]byte{"secret.txt": random, "notes.txt": bytes.Repeat([]byte("plain text\n"), 1000)}
+	for name, content := range inputs {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+
+	zipPath := filepath.Join(dir, "audit.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "secret.txt"), filepath.Join(dir, "notes.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"entropy", zipPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(entropy) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "secret.txt\n") || strings.Contains(out, "notes.txt") || !strings.Contains(out, "1 of 2 sampled files look encrypted or random") {
+		t.Errorf("Run(entropy) output = %q", out)
+	}
+}
+
+// TestRunExtractSettings checks that the settings file provides defaults the
+// flags override
+func TestRunExtractSettings(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("archived"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	zipPath := filepath.Join(dir, "docs.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("GOZIP_OVERWRITE", "")
+	t.Setenv("GOZIP_DEST_DIR", "")
+	out := filepath.Join(dir, "out")
+	os.MkdirAll(filepath.Join(home, "gozip"), 0755)
+	config := "dest_dir = " + strconv.Quote(out) + "\noverwrite = \"never\"\n"
+	if err := os.WriteFile(filepath.Join(home, "gozip", "config.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, code := Run([]string{"extract", zipPath, "a.txt"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	extracted := filepath.Join(out, "a.txt")
+	if err := os.WriteFile(extracted, []byte("edited"), 0644); err != nil {
+		t.Fatalf("extract did not use dest_dir: %v", err)
+	}
+
+	Run([]string{"extract", zipPath, "a.txt"}, &stdout, &stderr)
+	if data, _ := os.ReadFile(extracted); string(data) != "edited" {
+		t.Errorf("overwrite = never replaced the file: %q", data)
+	}
+	Run([]string{"extract", "--overwrite", "always", zipPath, "a.txt"}, &stdout, &stderr)
+	if data, _ := os.ReadFile(extracted); string(data) != "archived" {
+		t.Errorf("--overwrite always kept the file: %q", data)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, "gozip", "config.toml"), []byte("jobs = -1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	stderr.Reset()
+	if _, code := Run([]string{"info", zipPath}, &stdout, &stderr); code == 0 || !strings.Contains(stderr.String(), "config.toml") {
+		t.Errorf("Run(info) with an invalid settings file = %d, %q, want an error naming the file", code, stderr.String())
+	}
+}
+
+// TestRunExtractHooks checks that the configured hooks run after an
+// extraction, unless --no-hooks is given
+func TestRunExtractHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook in this test needs sh")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("archived"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	zipPath := filepath.Join(dir, "docs.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GOZIP_HOOK", "cat {}")
+	out := filepath.Join(dir, "out")
+
+	stdout.Reset()
+	if _, code := Run([]string{"extract", "-d", out, zipPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), "\narchived\n") {
+		t.Errorf("Run(extract) output = %q, want the hook's output", stdout.String())
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"extract", "--no-hooks", "-d", out, zipPath}, &stdout, &stderr); code != 0 || strings.Contains(stdout.String(), "hook") {
+		t.Errorf("Run(extract --no-hooks) = %d, %q", code, stdout.String())
+	}
+
+	t.Setenv("GOZIP_HOOK", "exit 2")
+	if _, code := Run([]string{"extract", "-d", out, zipPath}, &stdout, &stderr); code != 1 {
+		t.Errorf("Run(extract) with a failing hook exit code = %d, want 1", code)
+	}
+}
+
+// TestRunExtractScanner checks that the configured scanner blocks or reports
+// flagged files, unless --no-scan is given
+func TestRunExtractScanner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the scanner in this test needs sh")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("archived"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	zipPath := filepath.Join(dir, "docs.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GOZIP_SCANNER", "cat >/dev/null; echo 'stdin: Test FOUND'; exit 1")
+	t.Setenv("GOZIP_SCANNER_POLICY", "")
+
+	stdout.Reset()
+	stderr.Reset()
+	blocked := filepath.Join(dir, "blocked")
+	if _, code := Run([]string{"extract", "-d", blocked, zipPath}, &stdout, &stderr); code == 0 || !strings.Contains(stderr.String(), "flagged by the content scanner") {
+		t.Errorf("Run(extract) of a flagged file = %d, %q", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(blocked, "a.txt")); err == nil {
+		t.Error("the flagged file was extracted")
+	}
+
+	t.Setenv("GOZIP_SCANNER_POLICY", "warn")
+	stdout.Reset()
+	warned := filepath.Join(dir, "warned")
+	if _, code := Run([]string{"extract", "-d", warned, zipPath}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "    flagged: a.txt (stdin: Test FOUND)\n") {
+		t.Errorf("Run(extract) with the warn policy = %d, %q", code, stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(warned, "a.txt")); err != nil {
+		t.Errorf("the flagged file was not extracted with the warn policy: %v", err)
+	}
+
+	t.Setenv("GOZIP_SCANNER_POLICY", "")
+	stdout.Reset()
+	if _, code := Run([]string{"extract", "--no-scan", "-d", blocked, zipPath}, &stdout, &stderr); code != 0 || strings.Contains(stdout.String(), "flagged") {
+		t.Errorf("Run(extract --no-scan) = %d, %q", code, stdout.String())
+	}
+}
+
+// TestRunPlain checks that the plain-text browser lists, filters and
+// extracts entries by number
+func TestRunPlain(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+	zipPath := filepath.Join(dir, "p.zip")
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"create", "-q", zipPath, filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	if handled, _ := RunPlain([]string{zipPath}, strings.NewReader(""), &stdout, &stderr); handled {
+		t.Error("RunPlain() without --plain handled the arguments")
+	}
+
+	destDir := t.TempDir()
+	stdout.Reset()
+	input := "list\nfilter B\nlist\nextract 3\ndest " + destDir + "\nextract 2\nfrobnicate\nquit\n"
+	handled, code := RunPlain([]string{"--plain", zipPath}, strings.NewReader(input), &stdout, &stderr)
+	if !handled || code != 0 {
+		t.Fatalf("RunPlain() = %v, %d (stderr: %s)", handled, code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{"1  a.txt, 5 B", "2  b.txt, 5 B", "2 entries", "1 of 2 entries contain \"b\"", "there is no entry 3", "extracted 1 files to " + destDir, "unknown command"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("RunPlain() output lacks %q:\n%s", want, out)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(destDir, "b.txt")); err != nil || string(data) != "b.txt" {
+		t.Errorf("extracted b.txt = %q, %v", data, err)
+	}
+}
+
+// TestVerbosity checks that the verbosity flags are counted and removed,
+// except after a subcommand
+func TestVerbosity(t *testing.T) {
+	tests := []struct {
+		args      []string
+		want      []string
+		verbosity int
+	}{
+		{args: []string{"a.zip"}, want: []string{"a.zip"}},
+		{args: []string{"-v", "a.zip"}, want: []string{"a.zip"}, verbosity: 1},
+		{args: []string{"--mouse", "-vv", "a.zip", "--verbose"}, want: []string{"--mouse", "a.zip"}, verbosity: 3},
+		{args: []string{"-v", "extract", "-v", "a.zip"}, want: []string{"extract", "-v", "a.zip"}, verbosity: 1},
+	}
+
+	for _, tt := range tests {
+		got, verbosity := Verbosity(tt.args)
+		if !slices.Equal(got, tt.want) || verbosity != tt.verbosity {
+			t.Errorf("Verbosity(%v) = %v, %d, want %v, %d", tt.args, got, verbosity, tt.want, tt.verbosity)
+		}
+	}
+}
+
+// TestProfilingFlags checks that --pprof and --trace are taken out of the
+// arguments with their values
+func TestProfilingFlags(t *testing.T) {
+	tests := []struct {
+		args      []string
+		want      []string
+		pprofAddr string
+		traceFile string
+		wantErr   bool
+	}{
+		{args: []string{"a.zip"}, want: []string{"a.zip"}},
+		{args: []string{"--pprof", ":6060", "a.zip"}, want: []string{"a.zip"}, pprofAddr: ":6060"},
+		{args: []string{"--mouse", "--trace=t.out", "--pprof=:0", "a.zip"}, want: []string{"--mouse", "a.zip"}, pprofAddr: ":0", traceFile: "t.out"},
+		{args: []string{"--trace", "t.out", "info", "--pprof", ":6060"}, want: []string{"info", "--pprof", ":6060"}, traceFile: "t.out"},
+		{args: []string{"a.zip", "--pprof"}, wantErr: true},
+		{args: []string{"--trace=", "a.zip"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, pprofAddr, traceFile, err := ProfilingFlags(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ProfilingFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !slices.Equal(got, tt.want) || pprofAddr != tt.pprofAddr || traceFile != tt.traceFile {
+			t.Errorf("ProfilingFlags(%v) = %v, %q, %q, want %v, %q, %q", tt.args, got, pprofAddr, traceFile, tt.want, tt.pprofAddr, tt.traceFile)
+		}
+	}
+}
+
+// TestSignatureFlag checks that --sig is taken out of the arguments before
+// a subcommand and left to it after
+func TestSignatureFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    []string
+		sig     string
+		wantErr bool
+	}{
+		{args: []string{"a.zip"}, want: []string{"a.zip"}},
+		{args: []string{"--sig", "a.zip.asc", "a.zip"}, want: []string{"a.zip"}, sig: "a.zip.asc"},
+		{args: []string{"--mouse", "--sig=https://example.org/a.sig", "a.zip"}, want: []string{"--mouse", "a.zip"}, sig: "https://example.org/a.sig"},
+		{args: []string{"verify-sig", "--sig", "a.sig", "a.zip"}, want: []string{"verify-sig", "--sig", "a.sig", "a.zip"}},
+		{args: []string{"a.zip", "--sig"}, wantErr: true},
+		{args: []string{"--sig=", "a.zip"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, sig, err := SignatureFlag(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SignatureFlag(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !slices.Equal(got, tt.want) || sig != tt.sig {
+			t.Errorf("SignatureFlag(%v) = %v, %q, want %v, %q", tt.args, got, sig, tt.want, tt.sig)
+		}
+	}
+}
+
+// TestDisplayFlags checks that --date-format and --time-zone are taken out
+// of the arguments and override the settings
+func TestDisplayFlags(t *testing.T) {
+	cfg := config.Default()
+	args, err := DisplayFlags([]string{"--date-format", "2006-01-02", "--time-zone=local", "a.zip", "info", "--time-zone", "x"}, &cfg)
+	if err != nil {
+		t.Fatalf("DisplayFlags() unexpected error = %v", err)
+	}
+	if want := []string{"a.zip", "info", "--time-zone", "x"}; !slices.Equal(args, want) {
+		t.Errorf("DisplayFlags() = %v, want %v", args, want)
+	}
+	if cfg.DateFormat != "2006-01-02" || cfg.TimeZone != util.TimeZoneLocal {
+		t.Errorf("DisplayFlags() set %q, %q, want the flags' values", cfg.DateFormat, cfg.TimeZone)
+	}
+
+	for _, bad := range [][]string{{"--date-format", "soon", "a.zip"}, {"a.zip", "--time-zone"}} {
+		cfg := config.Default()
+		if _, err := DisplayFlags(bad, &cfg); err == nil {
+			t.Errorf("DisplayFlags(%v) expected an error", bad)
+		}
+		if cfg.DateFormat != util.DateRFC3339 || cfg.TimeZone != util.TimeZoneUTC {
+			t.Errorf("DisplayFlags(%v) changed the settings to %q, %q", bad, cfg.DateFormat, cfg.TimeZone)
+		}
+	}
+}
+
+// TestExitCodes checks the exit code of each kind of failure
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := "../util/testdata/test.zip"
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"ok", []string{"info", zipPath}, ExitOK},
+		{"usage", []string{"info"}, ExitUsage},
+		{"missing archive", []string{"info", filepath.Join(dir, "missing.zip")}, ExitArchive},
+		{"missing archive to extract", []string{"extract", filepath.Join(dir, "missing.zip")}, ExitArchive},
+		{"missing entry", []string{"extract", "-d", dir, zipPath, "nothing.txt"}, ExitExtract},
+		{"limited rate", []string{"extract", "-d", dir, "--limit-rate", "1M", zipPath}, ExitOK},
+		{"invalid rate", []string{"extract", "-d", dir, "--limit-rate", "fast", zipPath}, ExitUsage},
+		{"case collisions", []string{"extract", "-d", dir, "--case-collisions", "rename", zipPath}, ExitOK},
+		{"invalid case collisions", []string{"extract", "-d", dir, "--case-collisions", "merge", zipPath}, ExitUsage},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if _, code := Run(tt.args, &stdout, &stderr); code != tt.want {
+			t.Errorf("%s: Run(%v) exit code = %d, want %d (stderr: %s)", tt.name, tt.args, code, tt.want, stderr.String())
+		}
+	}
+
+	if code := exitCode(fmt.Errorf("hashing: %w", context.Canceled)); code != ExitInterrupted {
+		t.Errorf("exitCode(canceled) = %d, want %d", code, ExitInterrupted)
+	}
+}
+
+// TestRunExtractBatch checks that several archives are each extracted into
+// their own folder, past the ones that fail, with a summary
+func TestRunExtractBatch(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(input, []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	first, second := filepath.Join(dir, "first.zip"), filepath.Join(dir, "second.zip")
+	for _, zipPath := range []string{first, second} {
+		if _, code := Run([]string{"create", "-q", zipPath, input}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Run(create) exit code = %d (stderr: %s)", code, stderr.String())
+		}
+	}
+	broken := filepath.Join(dir, "broken.zip")
+	if err := os.WriteFile(broken, []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	out := filepath.Join(dir, "out")
+	stdout.Reset()
+	_, code := Run([]string{"extract", first, broken, second, "-d", out}, &stdout, &stderr)
+	if code != ExitExtract {
+		t.Errorf("Run(extract) of a batch with a broken archive exit code = %d, want %d", code, ExitExtract)
+	}
+	for _, name := range []string{"first", "second"} {
+		if _, err := os.Stat(filepath.Join(out, name, "a.txt")); err != nil {
+			t.Errorf("%s.zip not extracted into its folder: %v", name, err)
+		}
+	}
+	if !strings.Contains(stdout.String(), broken+"  failed") || !strings.Contains(stdout.String(), second+"  1 files") {
+		t.Errorf("Run(extract) of a batch summary:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"extract", "-d", out, "--overwrite", "always", first, second}, &stdout, &stderr); code != 0 {
+		t.Errorf("Run(extract) of two archives exit code = %d (stderr: %s)", code, stderr.String())
+	}
+}
+
+// TestRunExtractToTar checks that --to-tar writes the files as a tar stream
+// to standard output, and nothing else
+func TestRunExtractToTar(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	_, code := Run([]string{"extract", "--to-tar", "-", "../util/testdata/test.zip"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run(extract --to-tar -) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	hdr, err := tar.NewReader(&stdout).Next()
+	if err != nil || hdr.Name != "sample.txt" || hdr.Size != 68 {
+		t.Errorf("Run(extract --to-tar -) first entry = %+v, %v, want sample.txt of 68 bytes", hdr, err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.tar")
+	stdout.Reset()
+	if _, code := Run([]string{"extract", "--to-tar", out, "../util/testdata/test.zip", "sample.txt"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(extract --to-tar file) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "wrote 1 files to "+out) {
+		t.Errorf("Run(extract --to-tar file) output = %q", stdout.String())
+	}
+
+	if _, code := Run([]string{"extract", "--to-tar", "-", "../util/testdata/test.zip", "missing"}, io.Discard, io.Discard); code != ExitExtract {
+		t.Errorf("Run(extract --to-tar -) of a missing entry exit code = %d, want %d", code, ExitExtract)
+	}
+}
+
+// TestRunFind checks that "gozip find" lists the archives under a folder
+// by their signature
+func TestRunFind(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile("../util/testdata/test.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string][]byte{"a.zip": data, "sub/b.download": data, "c.zip": []byte("text")} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"find", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Run(find) exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	want := filepath.Join(dir, "a.zip") + "\n" + filepath.Join(dir, "sub", "b.download") + "\n"
+	if stdout.String() != want {
+		t.Errorf("Run(find) output = %q, want %q", stdout.String(), want)
+	}
+
+	if _, code := Run([]string{"find", filepath.Join(dir, "sub", "empty")}, &stdout, &stderr); code == 0 {
+		t.Error("Run(find) of a missing folder succeeded")
+	}
+}
+
+// TestFindPick checks that find --pick is left to the TUI
+func TestFindPick(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+		ok   bool
+	}{
+		{args: []string{"find", "downloads"}},
+		{args: []string{"find", "--pick", "downloads"}, want: "downloads", ok: true},
+		{args: []string{"find", "-i"}, want: ".", ok: true},
+		{args: []string{"info", "--pick"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := FindPick(tt.args)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FindPick(%v) = %q, %v, want %q, %v", tt.args, got, ok, tt.want, tt.ok)
+		}
+		if handled, _ := Run(tt.args, io.Discard, io.Discard); tt.ok && handled {
+			t.Errorf("Run(%v) ran the command instead of leaving it to the TUI", tt.args)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// commentInput is where "gozip comment set archive.zip -" reads the comment.
+var commentInput io.Reader = os.Stdin
+
+// runComment implements "gozip comment get archive.zip" and
+// "gozip comment set archive.zip <text | ->".
+func runComment(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("comment", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip comment get archive.zip")
+		fmt.Fprintln(stdout, "       gozip comment set archive.zip <text | ->")
+		fmt.Fprintln(stdout, "With -, the comment is read from standard input. An empty text removes it.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case fs.NArg() == 2 && fs.Arg(0) == "get":
+		info, err := util.ReadArchiveInfo(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		if info.Comment != "" {
+			fmt.Fprintln(stdout, strings.TrimSuffix(info.Comment, "\n"))
+		}
+		return nil
+
+	case fs.NArg() == 3 && fs.Arg(0) == "set":
+		comment := fs.Arg(2)
+		if comment == "-" {
+			data, err := io.ReadAll(commentInput)
+			if err != nil {
+				return err
+			}
+			comment = strings.TrimSuffix(string(data), "\n")
+		}
+		return util.SetArchiveComment(fs.Arg(1), comment)
+	}
+
+	fs.Usage()
+	return errors.New("expected get or set, an archive and, for set, the comment")
+}
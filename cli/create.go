@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runCreate implements "gozip create out.zip <files/dirs...>".
+func runCreate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	quiet := fs.Bool("q", false, "do not print progress")
+	level := fs.Int("level", -1, "compression level from 0 (store only) to 9 (best); -1 uses the default")
+	encrypt := fs.Bool("encrypt", false, "encrypt files with AES-256; without --password, --password-file or $"+PasswordEnv+" the password is asked twice")
+	passwords := addPasswordFlags(fs)
+	reproducible := fs.Bool("reproducible", false, "sort entries and drop timestamps, ownership and extra fields so the same inputs give the same bytes")
+	splitSize := fs.String("split-size", "", "split the archive into volumes of at most this size, e.g. 100M")
+	store := fs.String("store", strings.Join(util.PrecompressedExtensions, ","), "comma-separated extensions to store without compression (empty to deflate everything)")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip create [flags] out.zip <files/dirs...>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errors.New("an output archive and at least one input are required")
+	}
+
+	opts := util.CreateOptions{Reproducible: *reproducible}
+	switch {
+	case *level == 0:
+		opts.SelectMethod = util.StoreAll
+	case *level > 0 && *level <= 9:
+		opts.Level = *level
+		opts.SelectMethod = util.StoreExtensions(strings.Split(*store, ","))
+	case *level == -1:
+		opts.SelectMethod = util.StoreExtensions(strings.Split(*store, ","))
+	default:
+		return fmt.Errorf("invalid compression level %d", *level)
+	}
+	if *splitSize != "" {
+		size, err := util.ParseSize(*splitSize)
+		if err != nil {
+			return err
+		}
+		opts.SplitSize = size
+		if !*quiet {
+			opts.Volume = func(path string) {
+				fmt.Fprintf(stdout, "wrote volume %s\n", path)
+			}
+		}
+	}
+	if passwords.given() && !*encrypt {
+		return errors.New("a password needs --encrypt")
+	}
+	savePassword := false
+	if *encrypt {
+		password, save, err := passwords.resolve(fs.Arg(0), askNewPassword)
+		if err != nil {
+			return err
+		}
+		opts.Password, savePassword = password, save
+	}
+	if !*quiet {
+		opts.Progress = func(name string, current, total int) {
+			fmt.Fprintf(stdout, "[%*d/%d] adding %s\n", len(fmt.Sprint(total)), current, total, name)
+		}
+	}
+
+	count, err := util.CreateArchive(fs.Arg(0), fs.Args()[1:], opts)
+	if err != nil {
+		return err
+	}
+
+	if !*quiet {
+		fmt.Fprintf(stdout, "created %s with %d entries\n", fs.Arg(0), count)
+	}
+	if savePassword {
+		if err := saveKeyringPassword(fs.Arg(0), opts.Password); err != nil {
+			return fmt.Errorf("the archive was created, but its password was not saved: %w", err)
+		}
+	}
+
+	return nil
+}
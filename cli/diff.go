@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runDiff implements "gozip diff [--all] old.zip new.zip".
+func runDiff(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	all := fs.Bool("all", false, "also list entries that did not change")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip diff [flags] old.zip new.zip")
+		fmt.Fprintln(stdout, "Entries are compared by size and CRC: + added, - removed, ~ changed.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("exactly two archives are required")
+	}
+
+	diffs, err := util.DiffArchives(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[util.DiffStatus]int)
+	for _, d := range diffs {
+		counts[d.Status]++
+
+		switch d.Status {
+		case util.DiffAdded:
+			fmt.Fprintf(stdout, "+ %s\n", d.Name)
+		case util.DiffRemoved:
+			fmt.Fprintf(stdout, "- %s\n", d.Name)
+		case util.DiffChanged:
+			fmt.Fprintf(stdout, "~ %s (size %d -> %d, crc %08x -> %08x)\n", d.Name, d.Old.GetSize(), d.New.GetSize(), d.Old.GetCrc(), d.New.GetCrc())
+		default:
+			if *all {
+				fmt.Fprintf(stdout, "  %s\n", d.Name)
+			}
+		}
+	}
+
+	fmt.Fprintf(stdout, "%d added, %d removed, %d changed, %d unchanged\n",
+		counts[util.DiffAdded], counts[util.DiffRemoved], counts[util.DiffChanged], counts[util.DiffSame])
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/util"
+)
+
+// DisplayFlags takes the --date-format and --time-zone flags out of args,
+// which are the command-line arguments without the program name, and
+// applies them to cfg over the settings file: "--date-format relative" or
+// "--date-format='2006-01-02 15:04'" chooses how the browser shows
+// timestamps, "--time-zone local" shows them in the system's zone instead
+// of UTC. Flags after a subcommand's name are left alone.
+//
+// Parameters:
+//   - args: command-line arguments without the program name
+//   - cfg: the settings to apply the flags to
+//
+// Returns:
+//   - []string: args without the display flags
+//   - error: a flag lacks its value or has an invalid one; cfg is left as
+//     it was
+func DisplayFlags(args []string, cfg *config.Config) ([]string, error) {
+	rest := make([]string, 0, len(args))
+	format, zone := cfg.DateFormat, cfg.TimeZone
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if _, ok := findCommand(arg); ok {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--date-format" && name != "--time-zone" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		var err error
+		if name == "--date-format" {
+			format, err = util.ParseDateFormat(value)
+		} else {
+			zone, err = util.ParseTimeZone(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	cfg.DateFormat, cfg.TimeZone = format, zone
+	return slices.Clip(rest), nil
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runDupes implements "gozip dupes archive.zip".
+func runDupes(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("dupes", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip dupes archive.zip")
+		fmt.Fprintln(stdout, "Files with the same size and CRC-32 are reported as copies of each other.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("exactly one archive is required")
+	}
+
+	report, err := util.FindDuplicateContent(positional[0])
+	if err != nil {
+		return err
+	}
+
+	printDuplicateReport(stdout, report)
+	return nil
+}
+
+func printDuplicateReport(w io.Writer, report util.DuplicateReport) {
+	if len(report.Groups) == 0 {
+		fmt.Fprintln(w, "no duplicated files")
+		return
+	}
+
+	for _, g := range report.Groups {
+		fmt.Fprintf(w, "%d copies of %s (CRC %08x), %s to save:\n", len(g.Entries), util.FormatSize(g.Size), g.CRC32, util.FormatSize(g.Savings()))
+		for _, zf := range g.Entries {
+			fmt.Fprintf(w, "  %s\n", zf.GetName())
+		}
+	}
+
+	fmt.Fprintf(w, "%d redundant files in %d groups: %s could be saved (%s uncompressed)\n",
+		report.Redundant, len(report.Groups), util.FormatSize(report.Savings), util.FormatSize(report.WastedSize))
+}
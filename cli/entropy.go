@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runEntropy implements "gozip entropy archive.zip".
+func runEntropy(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("entropy", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip entropy archive.zip")
+		fmt.Fprintln(stdout, "Files whose first bytes look random, as encrypted data does, although they are")
+		fmt.Fprintln(stdout, "not in a known compressed format, are reported. It is a heuristic for audits.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("exactly one archive is required")
+	}
+
+	report, err := util.FindRandomContent(positional[0])
+	if err != nil {
+		return err
+	}
+
+	printRandomContentReport(stdout, report)
+	return nil
+}
+
+func printRandomContentReport(w io.Writer, report util.RandomContentReport) {
+	for _, e := range report.Entries {
+		how := "compressed"
+		if e.Stored {
+			how = "stored"
+		}
+		fmt.Fprintf(w, "%.2f bits/byte  %-10s %8s  %s\n", e.Entropy, how, util.FormatSize(e.Size), e.Name)
+	}
+
+	if len(report.Entries) == 0 {
+		fmt.Fprintf(w, "no random-looking files among %d sampled (%d skipped)\n", report.Sampled, report.Skipped)
+		return
+	}
+	fmt.Fprintf(w, "%d of %d sampled files look encrypted or random (%d skipped)\n", len(report.Entries), report.Sampled, report.Skipped)
+}
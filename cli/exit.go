@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// Exit codes of goZip's commands, for scripts to branch on.
+const (
+	// ExitOK reports success.
+	ExitOK = 0
+	// ExitUsage reports wrong arguments, and any failure without a code
+	// of its own.
+	ExitUsage = 1
+	// ExitArchive reports an archive that is missing, unreadable or not a
+	// ZIP archive.
+	ExitArchive = 2
+	// ExitExtract reports files that could not be extracted.
+	ExitExtract = 3
+	// ExitVerify reports files whose checksums do not match.
+	ExitVerify = 4
+	// ExitCrash reports that goZip itself failed: it panicked and saved a
+	// crash report, as EX_SOFTWARE in sysexits.h.
+	ExitCrash = 70
+	// ExitInterrupted reports a command stopped by Ctrl-C, as shells do
+	// for SIGINT.
+	ExitInterrupted = 130
+)
+
+// exitError is an error ending goZip with a given exit code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// failWith makes err end goZip with code, unless exitCode finds a more
+// precise one, such as ExitArchive for an archive that cannot be opened.
+func failWith(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code err ends goZip with: ExitInterrupted for
+// Ctrl-C, ExitArchive for an archive that cannot be opened, the code given
+// to failWith, and ExitUsage otherwise.
+func exitCode(err error) int {
+	var e *exitError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, util.ErrCannotOpen):
+		return ExitArchive
+	case errors.As(err, &e):
+		return e.code
+	}
+	return ExitUsage
+}
@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/util"
+)
+
+// runExtract implements "gozip extract [flags] archive.zip [entry]".
+func runExtract(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	destDir := fs.String("d", cmp.Or(settings.DestDir, "."), "destination directory")
+	var opts util.ExtractOptions
+	fs.Var((*stringList)(&opts.Include), "include", "only extract files matching this glob, e.g. '*.go' (repeatable)")
+	fs.Var((*stringList)(&opts.Exclude), "exclude", "skip files matching this glob, e.g. 'vendor/**' (repeatable)")
+	list := fs.String("list", "", "only extract the entries named in this file, one per line, such as a list saved from the browser")
+	fs.BoolVar(&opts.Flatten, "j", false, "junk paths: extract every file into the destination without its folders")
+	fs.BoolVar(&opts.Flatten, "flatten", false, "same as -j")
+	subfolder := fs.String("subfolder", "", "when extracting a whole archive, put it in a folder named after it: auto (if it has several top-level entries), always or never (default $"+util.SubfolderModeEnv+", else auto)")
+	fs.BoolVar(&opts.SkipSpaceCheck, "no-space-check", false, "extract even if the destination seems to lack free space")
+	fs.BoolVar(&opts.RemoveOnFailure, "cleanup", false, "if extraction fails, remove the files and folders it already created")
+	fs.BoolVar(&opts.Resume, "resume", false, "record progress, and skip the files an interrupted --resume run already extracted")
+	fs.IntVar(&opts.Jobs, "jobs", settings.Jobs, "number of files to extract concurrently; helps with many small files")
+	maxMemory := fs.String("max-memory", "", "memory to stay under, e.g. 512M, running fewer jobs if needed (default $"+config.MaxMemoryEnv+" or the settings file, else no limit)")
+	limitRate := fs.String("limit-rate", "", "read at most this many bytes per second, e.g. 10M, to spare slow or shared storage")
+	caseCollisions := fs.String("case-collisions", "abort", "what to do with names differing only in case, like README and readme, when the destination ignores case: abort, rename (readme.1) or ignore")
+	overwrite := fs.String("overwrite", string(settings.Overwrite), "what to do with files that already exist: always replace them, never, or only when the entry is newer")
+	duplicate := fs.String("duplicate", "last", "which version of a name stored several times to extract: first, last or a version number")
+	encoding := fs.String("encoding", "", "code page of names not marked as UTF-8: auto, utf8, cp437, sjis or gbk (default $"+util.NameEncodingEnv+" or the settings file, else auto)")
+	verbose := fs.Bool("v", false, "print each file as it is extracted")
+	dryRun := fs.Bool("dry-run", false, "list what would be written, and what would be overwritten, without writing anything")
+	noScan := fs.Bool("no-scan", false, "do not pipe the files through the scanner of the settings file or $"+config.ScannerEnv+" first")
+	noHooks := fs.Bool("no-hooks", false, "do not run the hook commands of the settings file or $"+config.HookEnv+" afterwards")
+	toTar := fs.String("to-tar", "", "write the files as a tar stream to this file, or to the standard output for -, instead of extracting them")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip extract [flags] archive.zip [file or folder]")
+		fmt.Fprintln(stdout, "       gozip extract [flags] archive.zip archive.zip...")
+		fmt.Fprintln(stdout, "       gozip extract [flags] --to-tar - archive.zip [file or folder] | tar -x")
+		fmt.Fprintln(stdout, "Without a file or folder the whole archive is extracted. Several archives")
+		fmt.Fprintln(stdout, "are each extracted into a folder named after them, going on past failures.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 1 {
+		fs.Usage()
+		return errors.New("an archive is required")
+	}
+
+	if *maxMemory != "" {
+		limit, err := util.ParseSize(*maxMemory)
+		if err != nil {
+			return fmt.Errorf("invalid --max-memory: %w", err)
+		}
+		util.SetMemoryLimit(limit)
+	}
+	if *limitRate != "" {
+		if opts.RateLimit, err = util.ParseSize(*limitRate); err != nil {
+			return fmt.Errorf("invalid --limit-rate: %w", err)
+		}
+	}
+	if !*noScan {
+		opts.Scanner = settings.Scanner
+	}
+	if *list != "" {
+		if opts.Names, err = util.ReadNameList(*list); err != nil {
+			return err
+		}
+		if len(opts.Names) == 0 {
+			return fmt.Errorf("%s lists no entries", *list)
+		}
+	}
+	if opts.Version, err = parseVersion(*duplicate); err != nil {
+		return err
+	}
+	if opts.Overwrite, err = util.ParseOverwritePolicy(*overwrite); err != nil {
+		return err
+	}
+	if opts.CaseCollisions, err = util.ParseCaseCollisionPolicy(*caseCollisions); err != nil {
+		return err
+	}
+	if *encoding != "" {
+		if opts.NameEncoding, err = util.ParseNameEncoding(*encoding); err != nil {
+			return err
+		}
+	}
+
+	e := extraction{opts: opts, verbose: *verbose, dryRun: *dryRun, hooks: !*noHooks}
+
+	// Ctrl-C stops the extraction cleanly: no half-written file is left
+	// behind, and --cleanup and --resume work as after any other failure.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *toTar != "" {
+		if *dryRun || len(positional) > 2 {
+			return errors.New("--to-tar takes a single archive and no --dry-run")
+		}
+		return writeTar(ctx, stdout, positional, *toTar, opts)
+	}
+	if isBatch(positional) {
+		return e.runBatch(ctx, stdout, positional, *destDir)
+	}
+
+	zipPath, target := positional[0], ""
+	if len(positional) == 2 {
+		target = positional[1]
+	}
+
+	if target == "" {
+		dir, err := wholeArchiveDir(zipPath, *destDir, *subfolder)
+		if err != nil {
+			return err
+		}
+		*destDir = dir
+	}
+
+	_, err = e.run(ctx, stdout, zipPath, target, *destDir)
+	return err
+}
+
+// extraction is what "gozip extract" does to each archive, as its flags
+// say.
+type extraction struct {
+	opts    util.ExtractOptions
+	verbose bool
+	dryRun  bool
+	hooks   bool
+}
+
+// run extracts target, or the whole archive for "", from zipPath into
+// destDir, printing what it did to stdout, and returns how many files it
+// extracted; with dryRun it only prints the plan, and returns how many
+// files it would extract.
+func (e extraction) run(ctx context.Context, stdout io.Writer, zipPath, target, destDir string) (int, error) {
+	opts := e.opts
+	if e.dryRun {
+		plan, err := util.PlanExtraction(zipPath, target, destDir, opts)
+		if err != nil {
+			return 0, withCaseHint(err)
+		}
+		printExtractionPlan(stdout, plan)
+		return len(plan.Files), nil
+	}
+
+	// Files renamed because the filesystem cannot store their names, and
+	// those the scanner flags, are always reported.
+	opts.Observer = extractNotes{w: stdout}
+	if e.verbose {
+		opts.Observer = lineObserver{extractNotes{w: stdout}}
+	}
+	var written *util.FileRecorder
+	if e.hooks && !settings.Hooks.IsEmpty() {
+		written = &util.FileRecorder{Next: opts.Observer}
+		opts.Observer = written
+	}
+
+	count, err := util.ExtractWithOptions(ctx, zipPath, target, destDir, opts)
+	if errors.Is(err, context.Canceled) {
+		if opts.Resume {
+			return count, failWith(ExitInterrupted, fmt.Errorf("interrupted after %d files; run the same command again to resume", count))
+		}
+		return count, failWith(ExitInterrupted, fmt.Errorf("interrupted after %d files", count))
+	}
+	if err != nil {
+		return count, failWith(ExitExtract, withCaseHint(err))
+	}
+
+	// Print where the files went in full, so they are easy to find.
+	dest, err := filepath.Abs(destDir)
+	if err != nil {
+		dest = destDir
+	}
+	fmt.Fprintf(stdout, "extracted %d files to %s\n", count, dest)
+	if written == nil {
+		return count, nil
+	}
+	results, err := util.RunExtractHooks(ctx, settings.Hooks, destDir, written.Files)
+	for _, r := range results {
+		fmt.Fprintf(stdout, "hook: %s\n", r.Command)
+		if r.Output != "" {
+			fmt.Fprintln(stdout, strings.TrimSuffix(r.Output, "\n"))
+		}
+	}
+	return count, err
+}
+
+// writeTar implements --to-tar: it writes the files of the archive, or of
+// the file or folder after it in positional, as a tar stream to dest, or
+// to stdout for "-", where nothing else is printed then.
+func writeTar(ctx context.Context, stdout io.Writer, positional []string, dest string, opts util.ExtractOptions) error {
+	zipPath, target := positional[0], ""
+	if len(positional) == 2 {
+		target = positional[1]
+	}
+
+	if dest == "-" {
+		count, err := util.WriteTar(ctx, zipPath, target, stdout, opts)
+		return tarError(err, count)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	count, err := util.WriteTar(ctx, zipPath, target, f, opts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return tarError(err, count)
+	}
+	fmt.Fprintf(stdout, "wrote %d files to %s\n", count, dest)
+	return nil
+}
+
+// tarError gives the failure of --to-tar after count files its exit code.
+func tarError(err error, count int) error {
+	if errors.Is(err, context.Canceled) {
+		return failWith(ExitInterrupted, fmt.Errorf("interrupted after %d files", count))
+	}
+	if err != nil {
+		return failWith(ExitExtract, err)
+	}
+	return nil
+}
+
+// withCaseHint tells how to go past a case collision, when err is one.
+func withCaseHint(err error) error {
+	var collision *util.CaseCollisionError
+	if errors.As(err, &collision) {
+		return fmt.Errorf("%w; run again with --case-collisions rename to keep them all", err)
+	}
+	return err
+}
+
+// parseVersion converts the --duplicate flag into util.ExtractOptions.Version.
+func parseVersion(s string) (int, error) {
+	switch s {
+	case "last":
+		return 0, nil
+	case "first":
+		return 1, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid --duplicate %q (want first, last or a version number)", s)
+	}
+	return n, nil
+}
+
+// wholeArchiveDir returns where a whole archive is extracted: destDir, or a
+// folder inside it named after the archive when the subfolder mode asks for
+// it. An empty flag falls back to the configured default.
+func wholeArchiveDir(zipPath, destDir, flagValue string) (string, error) {
+	mode, err := util.DefaultSubfolderMode()
+	if flagValue != "" {
+		mode, err = util.ParseSubfolderMode(flagValue)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	use, err := util.WantsSubfolder(zipPath, mode)
+	if err != nil || !use {
+		return destDir, err
+	}
+	return filepath.Join(destDir, util.ArchiveFolderName(zipPath)), nil
+}
+
+func printExtractionPlan(w io.Writer, plan util.ExtractionPlan) {
+	for _, f := range plan.Files {
+		action := "write    "
+		if f.Overwrites {
+			action = "overwrite"
+		}
+		fmt.Fprintf(w, "%s %s (%s)\n", action, f.Path, util.FormatSize(f.Size))
+		if f.Renamed != "" {
+			fmt.Fprintf(w, "          renamed from %s: %s\n", f.Name, f.Renamed)
+		}
+	}
+
+	fmt.Fprintf(w, "dry run: %d files, %s, %d would be overwritten\n", len(plan.Files), util.FormatSize(plan.TotalSize), plan.Overwrites)
+	if plan.Kept > 0 {
+		fmt.Fprintf(w, "%d existing files would be kept\n", plan.Kept)
+	}
+	if !plan.Fits() {
+		fmt.Fprintf(w, "warning: only %s free at the destination\n", util.FormatSize(plan.FreeSpace))
+	}
+}
+
+// lineObserver prints a line for every file extracted, or that failed.
+type lineObserver struct {
+	extractNotes
+}
+
+func (o lineObserver) OnEntryDone(name, path string) {
+	fmt.Fprintf(o.w, "  extracted: %s\n", path)
+}
+
+func (o lineObserver) OnError(name string, err error) {
+	fmt.Fprintf(o.w, "     failed: %s\n", name)
+}
+
+// extractNotes prints a line for each file written under another name than
+// its entry's, and for each file the content scanner flags, and ignores the
+// other events.
+type extractNotes struct {
+	util.NopExtractObserver
+	w io.Writer
+}
+
+func (o extractNotes) OnRename(name, path, reason string) {
+	fmt.Fprintf(o.w, "    renamed: %s -> %s (%s)\n", name, path, reason)
+}
+
+func (o extractNotes) OnFlagged(name, report string) {
+	if report == "" {
+		fmt.Fprintf(o.w, "    flagged: %s\n", name)
+		return
+	}
+	fmt.Fprintf(o.w, "    flagged: %s (%s)\n", name, report)
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
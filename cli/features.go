@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// featureReport is the machine-readable form of "gozip features --json".
+type featureReport struct {
+	OS       string          `json:"os"`
+	Arch     string          `json:"arch"`
+	Go       string          `json:"go"`
+	Formats  []string        `json:"formats"`
+	Methods  []string        `json:"methods"`
+	Features map[string]bool `json:"features"`
+}
+
+// runFeatures reports what this particular binary supports, so scripts can
+// probe capabilities before relying on them.
+func runFeatures(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("features", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	features := util.Features()
+
+	if *asJSON {
+		report := featureReport{
+			OS:       runtime.GOOS,
+			Arch:     runtime.GOARCH,
+			Go:       runtime.Version(),
+			Formats:  util.SupportedFormats(),
+			Methods:  util.SupportedMethods(),
+			Features: make(map[string]bool, len(features)),
+		}
+		for _, f := range features {
+			report.Features[f.Name] = f.Enabled
+		}
+
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	fmt.Fprintf(stdout, "platform: %s/%s (%s)\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	fmt.Fprintf(stdout, "formats:  %s\n", strings.Join(util.SupportedFormats(), ", "))
+	fmt.Fprintf(stdout, "methods:  %s\n", strings.Join(util.SupportedMethods(), ", "))
+	fmt.Fprintln(stdout, "features:")
+	for _, f := range features {
+		state := "no"
+		if f.Enabled {
+			state = "yes"
+		}
+		fmt.Fprintf(stdout, "  %-6s %-3s  %s\n", f.Name, state, f.Description)
+	}
+
+	return nil
+}
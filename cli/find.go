@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// pickFlags ask "gozip find" to open the archives found in the TUI rather
+// than print them, see FindPick.
+var pickFlags = []string{"--pick", "-pick", "-i"}
+
+// runFind implements "gozip find [flags] [folder]".
+func runFind(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("find", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	long := fs.Bool("l", false, "print the size and modification time of each archive too")
+	null := fs.Bool("0", false, "end each path with a NUL byte instead of a newline, for xargs -0")
+	fs.Bool("pick", false, "list the archives in the terminal UI and open the one picked (also -i)")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip find [flags] [folder]")
+		fmt.Fprintln(stdout, "Lists the ZIP archives under folder, the current one by default, recognized")
+		fmt.Fprintln(stdout, "by their signature whatever their extension.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return errors.New("at most one folder is required")
+	}
+	root := "."
+	if len(positional) == 1 {
+		root = positional[0]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	end := "\n"
+	if *null {
+		end = "\x00"
+	}
+	count := 0
+	err = util.FindArchives(ctx, root, func(path string, info os.FileInfo) {
+		count++
+		if *long {
+			fmt.Fprintf(stdout, "%10s  %s  ", util.FormatSize(uint64(info.Size())), info.ModTime().Format(time.DateTime))
+		}
+		fmt.Fprint(stdout, path, end)
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("no archives under %s", root)
+	}
+	return nil
+}
+
+// FindPick reports whether args ask "gozip find" to open the archives
+// found in the TUI, as "gozip find --pick ./downloads" does, for the
+// caller to start it instead of running the command; see Run.
+//
+// Parameters:
+//   - args: command-line arguments without the program name
+//
+// Returns:
+//   - string: the folder to search, "." by default
+//   - bool: true if args asked for the picker
+func FindPick(args []string) (string, bool) {
+	if len(args) == 0 || args[0] != "find" {
+		return "", false
+	}
+	rest := slices.DeleteFunc(slices.Clone(args[1:]), func(arg string) bool {
+		return slices.Contains(pickFlags, arg) || arg == "--pick=true"
+	})
+	if len(rest) == len(args)-1 {
+		return "", false
+	}
+
+	root := "."
+	for _, arg := range rest {
+		if arg != "" && arg[0] != '-' {
+			root = arg
+		}
+	}
+	return root, true
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runHash implements "gozip hash [flags] archive.zip [file or folder...]".
+func runHash(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("hash", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	algorithm := fs.String("a", string(settings.Hash), "checksum to compute: sha256, sha1 or md5")
+	output := fs.String("o", "", "write the checksums to this file, e.g. SHA256SUMS, instead of standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip hash [flags] archive.zip [file or folder...]")
+		fmt.Fprintln(stdout, "Prints the checksum of each file, as sha256sum does; without a file or folder, of the whole archive.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 1 {
+		fs.Usage()
+		return errors.New("an archive is required")
+	}
+	alg, err := util.ParseHashAlgorithm(*algorithm)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	hashes, err := util.HashEntries(ctx, positional[0], positional[1:], alg)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return util.WriteSums(stdout, hashes)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := util.WriteSums(f, hashes); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote %d checksums to %s\n", len(hashes), *output)
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runHealth implements "gozip health [--fix ...] archive.zip".
+func runHealth(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fix := fs.String("fix", "", "apply a fix after the analysis: normalize, recompress, vacuum or all")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip health [flags] archive.zip")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("exactly one archive is required")
+	}
+	zipPath := fs.Arg(0)
+
+	report, err := util.CheckHealth(zipPath)
+	if err != nil {
+		return err
+	}
+	printHealthReport(stdout, report)
+
+	var fixes []util.HealthFix
+	switch *fix {
+	case "":
+		return nil
+	case "all":
+		fixes = report.Fixes()
+	case string(util.FixNormalize), string(util.FixRecompress), string(util.FixVacuum):
+		fixes = []util.HealthFix{util.HealthFix(*fix)}
+	default:
+		return fmt.Errorf("unknown fix %q", *fix)
+	}
+
+	for _, f := range fixes {
+		n, err := util.ApplyHealthFix(zipPath, f)
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", f, err)
+		}
+		fmt.Fprintf(stdout, "%s: %d entries rewritten\n", f, n)
+	}
+
+	if len(fixes) > 0 {
+		report, err = util.CheckHealth(zipPath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "score after fixes: %d/100\n", report.Score)
+	}
+
+	return nil
+}
+
+func printHealthReport(w io.Writer, report util.HealthReport) {
+	fmt.Fprintf(w, "score: %d/100 (%d entries, %d bytes, %d wasted)\n", report.Score, report.Entries, report.ArchiveSize, report.WastedBytes)
+
+	for _, issue := range report.Issues {
+		entry := ""
+		if issue.Entry != "" {
+			entry = issue.Entry + ": "
+		}
+		fix := ""
+		if issue.Fix != util.FixNone {
+			fix = fmt.Sprintf(" [fix: %s]", issue.Fix)
+		}
+		fmt.Fprintf(w, "  %-16s %s%s%s\n", issue.Kind, entry, issue.Detail, fix)
+	}
+}
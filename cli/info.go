@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// infoReport is the machine-readable form of "gozip info --json".
+type infoReport struct {
+	Format         string  `json:"format"`
+	Entries        int     `json:"entries"`
+	Size           uint64  `json:"size"`
+	CompressedSize uint64  `json:"compressed_size"`
+	Ratio          float64 `json:"ratio"`
+	Comment        string  `json:"comment"`
+	Zip64          bool    `json:"zip64"`
+	Encrypted      bool    `json:"encrypted"`
+	Prepended      int64   `json:"prepended"`
+	Trailing       int64   `json:"trailing"`
+	// Package is set for JARs and APKs.
+	Package *packageReport `json:"package,omitempty"`
+}
+
+// packageReport is the "package" of infoReport.
+type packageReport struct {
+	Kind        string            `json:"kind"`
+	Manifest    map[string]string `json:"manifest,omitempty"`
+	MainClass   string            `json:"main_class,omitempty"`
+	Package     string            `json:"package,omitempty"`
+	VersionName string            `json:"version_name,omitempty"`
+	VersionCode string            `json:"version_code,omitempty"`
+	MinSDK      string            `json:"min_sdk,omitempty"`
+	TargetSDK   string            `json:"target_sdk,omitempty"`
+	Signers     []signerReport    `json:"signers"`
+}
+
+// signerReport is a signer of packageReport; Subject is empty when its
+// certificate could not be read.
+type signerReport struct {
+	Schemes     []string `json:"schemes"`
+	Subject     string   `json:"subject"`
+	Issuer      string   `json:"issuer,omitempty"`
+	NotAfter    string   `json:"not_after,omitempty"`
+	Fingerprint string   `json:"sha256,omitempty"`
+}
+
+// runInfo implements "gozip info [--json] archive.zip".
+func runInfo(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	asJSON := fs.Bool("json", false, "print the summary as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip info [flags] archive.zip")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("exactly one archive is required")
+	}
+
+	info, err := util.ReadArchiveInfo(positional[0])
+	if err != nil {
+		return err
+	}
+	pkg, isPackage, err := util.ReadPackageInfo(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		report := infoReport{
+			Format:         info.Format,
+			Entries:        info.Entries,
+			Size:           info.Size,
+			CompressedSize: info.CompressedSize,
+			Ratio:          info.Ratio(),
+			Comment:        info.Comment,
+			Zip64:          info.Zip64,
+			Encrypted:      info.Encrypted,
+			Prepended:      info.Prepended,
+			Trailing:       info.Trailing,
+		}
+		if isPackage {
+			report.Package = newPackageReport(pkg)
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	format := info.Format
+	if info.Zip64 {
+		format += " (Zip64)"
+	}
+	fmt.Fprintf(stdout, "format:     %s\n", format)
+	fmt.Fprintf(stdout, "entries:    %d\n", info.Entries)
+	fmt.Fprintf(stdout, "size:       %s\n", util.FormatSize(info.Size))
+	fmt.Fprintf(stdout, "compressed: %s (%.1f%%)\n", util.FormatSize(info.CompressedSize), 100*info.Ratio())
+	fmt.Fprintf(stdout, "encrypted:  %s\n", yesNo(info.Encrypted))
+	if info.Prepended > 0 {
+		fmt.Fprintf(stdout, "offset:     %d (%s of other data before the archive)\n", info.Prepended, util.FormatSize(uint64(info.Prepended)))
+	}
+	if info.Trailing > 0 {
+		fmt.Fprintf(stdout, "trailing:   %d bytes (%s of other data after the archive)\n", info.Trailing, util.FormatSize(uint64(info.Trailing)))
+	}
+	if info.Comment != "" {
+		fmt.Fprintf(stdout, "comment:    %s\n", info.Comment)
+	}
+	if isPackage {
+		printPackage(stdout, pkg)
+	}
+
+	return nil
+}
+
+// newPackageReport converts pkg for "gozip info --json".
+func newPackageReport(pkg util.PackageInfo) *packageReport {
+	report := &packageReport{
+		Kind:        string(pkg.Kind),
+		MainClass:   pkg.MainClass(),
+		Package:     pkg.Package,
+		VersionName: pkg.VersionName,
+		VersionCode: pkg.VersionCode,
+		MinSDK:      pkg.MinSDK,
+		TargetSDK:   pkg.TargetSDK,
+		Signers:     []signerReport{},
+	}
+	if len(pkg.Manifest) > 0 {
+		report.Manifest = make(map[string]string, len(pkg.Manifest))
+		for _, attr := range pkg.Manifest {
+			report.Manifest[attr.Name] = attr.Value
+		}
+	}
+	for _, s := range pkg.Signers {
+		signer := signerReport{Schemes: s.Schemes, Subject: s.Subject, Issuer: s.Issuer, Fingerprint: s.Fingerprint}
+		if !s.NotAfter.IsZero() {
+			signer.NotAfter = s.NotAfter.UTC().Format(time.RFC3339)
+		}
+		report.Signers = append(report.Signers, signer)
+	}
+	return report
+}
+
+// printPackage prints the key attributes of a JAR or APK after the summary
+// of "gozip info".
+func printPackage(w io.Writer, pkg util.PackageInfo) {
+	fmt.Fprintf(w, "package:    %s\n", packageTitle(pkg))
+	if pkg.MinSDK != "" {
+		fmt.Fprintf(w, "min sdk:    %s\n", pkg.MinSDK)
+	}
+	if pkg.TargetSDK != "" {
+		fmt.Fprintf(w, "target sdk: %s\n", pkg.TargetSDK)
+	}
+	if class := pkg.MainClass(); class != "" {
+		fmt.Fprintf(w, "main class: %s\n", class)
+	}
+
+	if len(pkg.Signers) == 0 {
+		fmt.Fprintln(w, "signer:     none")
+	}
+	for _, s := range pkg.Signers {
+		schemes := strings.Join(s.Schemes, ", ")
+		if s.Subject == "" {
+			fmt.Fprintf(w, "signer:     unreadable certificate (%s)\n", schemes)
+			continue
+		}
+		fmt.Fprintf(w, "signer:     %s (%s), valid until %s\n", s.Subject, schemes, s.NotAfter.UTC().Format(time.DateOnly))
+		fmt.Fprintf(w, "            sha256 %s\n", s.Fingerprint)
+	}
+}
+
+// packageTitle names a package: its kind, then the package name and
+// version of an APK, or the title and version of a JAR's manifest.
+func packageTitle(pkg util.PackageInfo) string {
+	parts := []string{string(pkg.Kind)}
+	name, version, code := pkg.Package, pkg.VersionName, pkg.VersionCode
+	if pkg.Kind == util.PackageJAR {
+		name = pkg.Attribute("Implementation-Title")
+		version = pkg.Attribute("Implementation-Version")
+	}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	if version != "" {
+		parts = append(parts, version)
+	}
+	if code != "" {
+		parts = append(parts, "("+code+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// yesNo renders a flag for people.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
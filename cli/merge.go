@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runMerge implements "gozip merge a.zip b.zip... -o combined.zip".
+func runMerge(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	out := fs.String("o", "", "path of the merged archive (required)")
+	conflict := fs.String("conflict", string(util.MergeSkip), "what to do with files found in several archives: skip, overwrite or keep-both")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip merge [flags] a.zip b.zip... -o combined.zip")
+		fs.PrintDefaults()
+	}
+
+	inputs, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if *out == "" || len(inputs) < 2 {
+		fs.Usage()
+		return errors.New("an output archive (-o) and at least two inputs are required")
+	}
+
+	result, err := util.MergeArchives(*out, inputs, util.MergePolicy(*conflict))
+	if err != nil {
+		return err
+	}
+
+	for _, c := range result.Collisions {
+		switch {
+		case c.Result != "":
+			fmt.Fprintf(stdout, "collision: %s from %s kept as %s\n", c.Name, c.Archive, c.Result)
+		case *conflict == string(util.MergeOverwrite):
+			fmt.Fprintf(stdout, "collision: %s overwritten by %s\n", c.Name, c.Archive)
+		default:
+			fmt.Fprintf(stdout, "collision: %s from %s skipped\n", c.Name, c.Archive)
+		}
+	}
+	fmt.Fprintf(stdout, "merged %d archives into %s: %d entries, %d collisions\n", len(inputs), *out, result.Entries, len(result.Collisions))
+
+	return nil
+}
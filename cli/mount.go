@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runMount implements "gozip mount archive.zip mountpoint".
+func runMount(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("mount", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip mount archive.zip mountpoint")
+		fmt.Fprintln(stdout, "Exposes the archive read-only at mountpoint, an empty folder, until Ctrl-C;")
+		fmt.Fprintln(stdout, "needs FUSE (fuse3 on Linux, macFUSE on macOS).")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return errors.New("an archive and a mountpoint are required")
+	}
+	zipPath, mountpoint := positional[0], positional[1]
+
+	// Ctrl-C, or a kill, unmounts before goZip exits: a FUSE filesystem
+	// whose program died stays mounted and broken.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return util.MountArchive(ctx, zipPath, mountpoint, func() {
+		fmt.Fprintf(stdout, "mounted %s at %s; press Ctrl-C to unmount\n", zipPath, mountpoint)
+	})
+}
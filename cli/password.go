@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"golang.org/x/term"
+)
+
+// PasswordEnv names the environment variable giving the password of
+// encrypted archives, for scripts; --password and --password-file
+// override it.
+const PasswordEnv = "GOZIP_PASSWORD"
+
+// readPassword shows prompt and reads a password without echoing it.
+// It is a variable so tests can supply passwords without a terminal.
+var readPassword = promptPassword
+
+// keyringPassword and saveKeyringPassword reach the OS keyring. They are
+// variables so tests do not touch the user's keyring.
+var (
+	keyringPassword     = util.KeyringPassword
+	saveKeyringPassword = util.SaveKeyringPassword
+)
+
+// stdinLines reads passwords piped on standard input, one per line.
+var stdinLines = bufio.NewReader(os.Stdin)
+
+func promptPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		return string(b), err
+	}
+
+	line, err := stdinLines.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.New("no password given on standard input")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// askNewPassword asks for a password twice and returns it when both
+// answers match.
+func askNewPassword() (string, error) {
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", errors.New("the password cannot be empty")
+	}
+
+	again, err := readPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if again != password {
+		return "", errors.New("passwords do not match")
+	}
+
+	return password, nil
+}
+
+// passwordFlags are the flags giving a command the password of an
+// archive without asking for it.
+type passwordFlags struct {
+	password string
+	file     string
+	keyring  bool
+}
+
+// addPasswordFlags defines --password, --password-file and --keyring on
+// fs.
+func addPasswordFlags(fs *flag.FlagSet) *passwordFlags {
+	p := &passwordFlags{}
+	fs.StringVar(&p.password, "password", "", "the password; other users may see it in the process list, prefer --password-file or $"+PasswordEnv)
+	fs.StringVar(&p.file, "password-file", "", "read the password from the first line of this file")
+	fs.BoolVar(&p.keyring, "keyring", false, "use the password saved for the archive in the OS keyring, and save the one given otherwise")
+	return p
+}
+
+// given reports whether a password was passed on the command line.
+func (p *passwordFlags) given() bool {
+	return p.password != "" || p.file != ""
+}
+
+// resolve returns the password of zipPath from --password,
+// --password-file or $GOZIP_PASSWORD, in that order, else with --keyring
+// the one saved for zipPath, else what ask returns.
+//
+// With --keyring, the password found elsewhere than in the keyring is
+// returned with save true: the caller saves it with saveKeyringPassword
+// once the password has been used successfully.
+func (p *passwordFlags) resolve(zipPath string, ask func() (string, error)) (password string, save bool, err error) {
+	switch {
+	case p.password != "":
+		password = p.password
+	case p.file != "":
+		if password, err = readPasswordFile(p.file); err != nil {
+			return "", false, err
+		}
+	case os.Getenv(PasswordEnv) != "":
+		password = os.Getenv(PasswordEnv)
+	case p.keyring:
+		if password, err = keyringPassword(zipPath); err != nil {
+			return "", false, err
+		}
+		if password != "" {
+			return password, false, nil
+		}
+		fallthrough
+	default:
+		if password, err = ask(); err != nil {
+			return "", false, err
+		}
+	}
+	return password, p.keyring, nil
+}
+
+// readPasswordFile returns the first line of the file at path.
+func readPasswordFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" {
+		return "", fmt.Errorf("%s holds no password", path)
+	}
+	return line, nil
+}
@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/core"
+	"github.com/cainlara/gozip/util"
+)
+
+// plainFlag asks for the plain-text browser instead of the TUI.
+const plainFlag = "--plain"
+
+// plainHelp lists the commands of the plain-text browser.
+const plainHelp = `commands:
+  list             list the entries matching the filter, numbered
+  filter [text]    only list the entries whose name contains text; without text, list them all
+  extract N...     extract the entries numbered N, with ranges such as 3-5, or "all"
+  dest [folder]    show or change where extract writes
+  help             show these commands
+  quit             leave; so does the end of the input`
+
+// RunPlain runs the plain-text browser when args hold --plain, as in
+// "gozip --plain archive.zip": instead of the TUI, a line-oriented prompt
+// reads commands to list, filter and extract entries by number, for screen
+// readers and terminals that cannot draw the TUI.
+//
+// Parameters:
+//   - args: command-line arguments without the program name
+//   - stdin: where commands are read from
+//   - stdout: destination for the listings and results
+//   - stderr: destination for error messages
+//
+// Returns:
+//   - bool: true if args asked for the plain-text browser
+//   - int: process exit code, meaningful only when the first value is true
+func RunPlain(args []string, stdin io.Reader, stdout, stderr io.Writer) (bool, int) {
+	i := slices.IndexFunc(args, func(arg string) bool { return arg == plainFlag || arg == "-plain" })
+	if i < 0 {
+		return false, 0
+	}
+	args = slices.Delete(slices.Clone(args), i, i+1)
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "usage: gozip --plain archive.zip")
+		return true, ExitUsage
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "gozip: %s\n", err)
+		return true, ExitUsage
+	}
+	settings = cfg
+	util.SetDefaultNameEncoding(cfg.Encoding)
+	util.SetMemoryLimit(cfg.MaxMemory)
+	util.SetPreviewLimit(cfg.PreviewLimit)
+
+	util.RecordUsage("command:plain")
+	defer util.FlushUsage()
+
+	zipPath := args[0]
+	files, err := util.ListArchive(zipPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "gozip: %s\n", err)
+		return true, exitCode(err)
+	}
+
+	p := newPlainBrowser(zipPath, files, stdout)
+	p.run(stdin)
+	return true, 0
+}
+
+// plainBrowser is the state of the plain-text browser. Entries keep the
+// number of their position in the archive whatever the filter, so a number
+// read in one listing stays valid.
+type plainBrowser struct {
+	zipPath string
+	files   []core.ZippedFile
+	// versions holds which occurrence of its name each entry is, counting
+	// from 1; counts holds how many entries share each name.
+	versions []int
+	counts   map[string]int
+	filter   string
+	destDir  string
+	out      io.Writer
+}
+
+func newPlainBrowser(zipPath string, files []core.ZippedFile, out io.Writer) *plainBrowser {
+	p := &plainBrowser{
+		zipPath:  zipPath,
+		files:    files,
+		versions: make([]int, len(files)),
+		counts:   make(map[string]int),
+		destDir:  cmp.Or(settings.DestDir, "."),
+		out:      out,
+	}
+	for i, zf := range files {
+		p.counts[zf.GetName()]++
+		p.versions[i] = p.counts[zf.GetName()]
+	}
+	return p
+}
+
+// run reads commands from in until quit or the end of the input.
+func (p *plainBrowser) run(in io.Reader) {
+	var size uint64
+	for _, zf := range p.files {
+		size += zf.GetSize()
+	}
+	fmt.Fprintf(p.out, "%s: %d entries, %s. Type help for the commands.\n", filepath.Base(p.zipPath), len(p.files), util.FormatSize(size))
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(p.out, "gozip> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(p.out)
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		name, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+
+		var err error
+		switch strings.ToLower(name) {
+		case "":
+		case "list", "ls", "l":
+			p.list()
+		case "filter", "f":
+			p.setFilter(rest)
+		case "extract", "x":
+			err = p.extract(strings.Fields(rest))
+		case "dest", "d":
+			if rest != "" {
+				p.destDir = rest
+			}
+			fmt.Fprintf(p.out, "extract writes to %s\n", p.destDir)
+		case "help", "h", "?":
+			fmt.Fprintln(p.out, plainHelp)
+		case "quit", "q", "exit":
+			return
+		default:
+			err = fmt.Errorf("unknown command %q; type help for the commands", name)
+		}
+		if err != nil {
+			fmt.Fprintf(p.out, "error: %s\n", err)
+		}
+	}
+}
+
+// list prints the entries matching the filter, one per line, after their
+// number.
+func (p *plainBrowser) list() {
+	shown := 0
+	for i, zf := range p.files {
+		if !p.matches(zf) {
+			continue
+		}
+		shown++
+		fmt.Fprintf(p.out, "%d  %s\n", i+1, p.describe(i))
+	}
+
+	if p.filter == "" {
+		fmt.Fprintf(p.out, "%d entries\n", shown)
+		return
+	}
+	fmt.Fprintf(p.out, "%d of %d entries contain %q\n", shown, len(p.files), p.filter)
+}
+
+// describe renders the i-th entry on one line, in words rather than
+// symbols so screen readers say it well.
+func (p *plainBrowser) describe(i int) string {
+	zf := p.files[i]
+	parts := []string{zf.GetName()}
+	if count := p.counts[zf.GetName()]; count > 1 {
+		parts[0] += fmt.Sprintf(" (version %d of %d)", p.versions[i], count)
+	}
+	if zf.IsDir() {
+		parts = append(parts, "folder")
+	} else {
+		parts = append(parts, util.FormatSize(zf.GetSize()))
+	}
+	if zf.IsEncrypted() {
+		parts = append(parts, "encrypted")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (p *plainBrowser) matches(zf core.ZippedFile) bool {
+	return strings.Contains(strings.ToLower(zf.GetName()), p.filter)
+}
+
+// setFilter changes the filter and tells how many entries it keeps.
+func (p *plainBrowser) setFilter(text string) {
+	p.filter = strings.ToLower(text)
+	if p.filter == "" {
+		fmt.Fprintf(p.out, "filter cleared, %d entries\n", len(p.files))
+		return
+	}
+
+	shown := 0
+	for _, zf := range p.files {
+		if p.matches(zf) {
+			shown++
+		}
+	}
+	fmt.Fprintf(p.out, "%d of %d entries contain %q; type list to see them\n", shown, len(p.files), p.filter)
+}
+
+// extract extracts the entries numbered in args, or the whole archive for
+// "all", into destDir.
+func (p *plainBrowser) extract(args []string) error {
+	if len(args) == 0 {
+		return errors.New("give the numbers of the entries to extract, or all")
+	}
+
+	// Ctrl-C stops the extraction, not goZip.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	opts := util.ExtractOptions{Jobs: settings.Jobs, Overwrite: settings.Overwrite, Scanner: settings.Scanner}
+
+	if len(args) == 1 && strings.EqualFold(args[0], "all") {
+		dest, err := wholeArchiveDir(p.zipPath, p.destDir, "")
+		if err != nil {
+			return err
+		}
+		count, err := util.ExtractWithOptions(ctx, p.zipPath, "", dest, opts)
+		if err != nil {
+			return err
+		}
+		p.printExtracted(count, dest)
+		return nil
+	}
+
+	numbers, err := parseEntryNumbers(args, len(p.files))
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, n := range numbers {
+		zf := p.files[n-1]
+		opts.Version = 0
+		if p.counts[zf.GetName()] > 1 {
+			opts.Version = p.versions[n-1]
+		}
+		count, err := util.ExtractWithOptions(ctx, p.zipPath, zf.GetName(), p.destDir, opts)
+		total += count
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", n, err)
+		}
+	}
+	p.printExtracted(total, p.destDir)
+	return nil
+}
+
+func (p *plainBrowser) printExtracted(count int, dest string) {
+	if abs, err := filepath.Abs(dest); err == nil {
+		dest = abs
+	}
+	fmt.Fprintf(p.out, "extracted %d files to %s\n", count, dest)
+}
+
+// parseEntryNumbers reads entry numbers and ranges such as "3-5", from 1
+// to last, in the order given and without repeats.
+func parseEntryNumbers(args []string, last int) ([]int, error) {
+	var numbers []int
+	for _, arg := range args {
+		from, to, isRange := strings.Cut(arg, "-")
+		if !isRange {
+			to = from
+		}
+		first, err1 := strconv.Atoi(from)
+		end, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || first < 1 || end < first {
+			return nil, fmt.Errorf("invalid entry number %q", arg)
+		}
+		if end > last {
+			return nil, fmt.Errorf("there is no entry %d, the last one is %d", end, last)
+		}
+		for n := first; n <= end; n++ {
+			if !slices.Contains(numbers, n) {
+				numbers = append(numbers, n)
+			}
+		}
+	}
+	return numbers, nil
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProfilingFlags takes the --pprof and --trace flags out of args, which are
+// the command-line arguments without the program name, as Verbosity does
+// for -v: "--pprof :6060" or "--pprof=:6060" gives the address to serve
+// net/http/pprof on, "--trace file" the file to write a runtime trace to.
+// Flags after a subcommand's name are left alone.
+//
+// Parameters:
+//   - args: command-line arguments without the program name
+//
+// Returns:
+//   - []string: args without the profiling flags
+//   - string: address for util.StartProfiling, "" without --pprof
+//   - string: trace file for util.StartProfiling, "" without --trace
+//   - error: a flag lacks its value
+func ProfilingFlags(args []string) ([]string, string, string, error) {
+	rest := make([]string, 0, len(args))
+	var pprofAddr, traceFile string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if _, ok := findCommand(arg); ok {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		var target *string
+		switch name {
+		case "--pprof", "-pprof":
+			target = &pprofAddr
+		case "--trace", "-trace":
+			target = &traceFile
+		default:
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, "", "", fmt.Errorf("%s needs a value, as in %s", name, profilingExample(name))
+			}
+			i++
+			value = args[i]
+		}
+		if value == "" {
+			return nil, "", "", fmt.Errorf("%s needs a value, as in %s", name, profilingExample(name))
+		}
+		*target = value
+	}
+	return slices.Clip(rest), pprofAddr, traceFile, nil
+}
+
+func profilingExample(flag string) string {
+	if strings.HasSuffix(flag, "pprof") {
+		return "--pprof :6060"
+	}
+	return "--trace gozip.trace"
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runRename implements "gozip rename archive.zip old/name new/name".
+func runRename(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip rename archive.zip <old name> <new name>")
+		fmt.Fprintln(stdout, "Renaming a folder moves everything inside it.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return errors.New("an archive, the current name and the new name are required")
+	}
+
+	count, err := util.RenameEntry(fs.Arg(0), fs.Arg(1), fs.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "renamed %d entries\n", count)
+	return nil
+}
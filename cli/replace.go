@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runReplace implements "gozip replace archive.zip entry/name file".
+func runReplace(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("replace", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip replace archive.zip <entry name> <file>")
+		fmt.Fprintln(stdout, "The entry keeps its name; its content is taken from file.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return errors.New("an archive, an entry name and a file are required")
+	}
+
+	if err := util.ReplaceEntry(fs.Arg(0), fs.Arg(1), fs.Arg(2)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "replaced %s\n", fs.Arg(1))
+	return nil
+}
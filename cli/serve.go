@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runServe implements "gozip serve [--addr :8080] archive.zip".
+func runServe(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	addr := fs.String("addr", ":8080", "address to listen on; :8080 is every interface, localhost:8080 this computer only")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip serve [flags] archive.zip")
+		fmt.Fprintln(stdout, "Serves the files of the archive over HTTP, read-only, until Ctrl-C.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("exactly one archive is required")
+	}
+	zipPath := positional[0]
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return util.ServeArchive(ctx, zipPath, *addr, func(addr string) {
+		// An address without a host listens everywhere; name this host.
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil && (host == "" || host == "::" || host == "0.0.0.0") {
+			if name, err := os.Hostname(); err == nil {
+				addr = net.JoinHostPort(name, port)
+			}
+		}
+		fmt.Fprintf(stdout, "serving %s on http://%s/; press Ctrl-C to stop\n", zipPath, addr)
+	})
+}
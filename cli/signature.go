@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// SignatureFlag takes the --sig flag out of args, which are the
+// command-line arguments without the program name, as ProfilingFlags does
+// for --pprof: "--sig file" or "--sig=https://host/archive.zip.asc" gives
+// the detached signature the browser verifies the archive against before
+// opening it, instead of the one next to the archive. Flags after a
+// subcommand's name are left alone, such as those of "gozip verify-sig".
+//
+// Parameters:
+//   - args: command-line arguments without the program name
+//
+// Returns:
+//   - []string: args without --sig
+//   - string: the signature, a file or an http(s) URL; "" without --sig
+//   - error: the flag lacks its value
+func SignatureFlag(args []string) ([]string, string, error) {
+	rest := make([]string, 0, len(args))
+	var sig string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if _, ok := findCommand(arg); ok {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--sig" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, "", errors.New("--sig needs a value, as in --sig archive.zip.asc")
+			}
+			i++
+			value = args[i]
+		}
+		if value == "" {
+			return nil, "", errors.New("--sig needs a value, as in --sig archive.zip.asc")
+		}
+		sig = value
+	}
+	return slices.Clip(rest), sig, nil
+}
+
+// runVerifySig implements "gozip verify-sig [--sig file|URL] archive.zip".
+func runVerifySig(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify-sig", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	sig := fs.String("sig", "", "the detached signature, a file or an http(s) URL (default archive.zip.asc or archive.zip.sig)")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip verify-sig [--sig file|URL] archive.zip")
+		fmt.Fprintln(stdout, "Checks a detached OpenPGP signature of the archive with gpg ($"+util.GPGEnv+" to use another program),")
+		fmt.Fprintln(stdout, "against your keyring.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("exactly one archive is required")
+	}
+	zipPath := positional[0]
+
+	signature := cmp.Or(*sig, util.FindSignature(zipPath))
+	if signature == "" {
+		return fmt.Errorf("no %s.asc or %s.sig next to the archive; give one with --sig", zipPath, zipPath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := util.VerifySignature(ctx, zipPath, signature)
+	if err != nil {
+		return err
+	}
+	printSignature(stdout, result)
+	if !result.Valid() {
+		return failWith(ExitVerify, fmt.Errorf("%s signature in %s", result.Status, signature))
+	}
+	return nil
+}
+
+func printSignature(w io.Writer, r util.SignatureResult) {
+	if r.Signer != "" {
+		fmt.Fprintf(w, "%s signature from %s\n", r.Status, r.Signer)
+	} else {
+		fmt.Fprintf(w, "%s signature\n", r.Status)
+	}
+	if r.KeyID != "" {
+		fmt.Fprintf(w, "  key:         %s\n", r.KeyID)
+	}
+	if r.Fingerprint != "" {
+		fmt.Fprintf(w, "  fingerprint: %s\n", r.Fingerprint)
+	}
+	if r.Trust != "" {
+		fmt.Fprintf(w, "  trust:       %s\n", r.Trust)
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runStats implements "gozip stats [enable|disable|reset]". Statistics are
+// opt-in and stored locally only; goZip never sends them anywhere.
+func runStats(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip stats [enable|disable|reset]")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "":
+		return printStats(stdout)
+	case "enable":
+		if err := util.EnableUsageStats(); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "local usage statistics enabled")
+		return nil
+	case "disable":
+		if err := util.DisableUsageStats(); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "local usage statistics disabled and deleted")
+		return nil
+	case "reset":
+		if err := util.DisableUsageStats(); err != nil {
+			return err
+		}
+		if err := util.EnableUsageStats(); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "local usage statistics cleared")
+		return nil
+	default:
+		fs.Usage()
+		return errors.New("unknown stats action: " + fs.Arg(0))
+	}
+}
+
+func printStats(stdout io.Writer) error {
+	stats, counts, enabled, err := util.UsageReport()
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		fmt.Fprintln(stdout, "local usage statistics are disabled; run 'gozip stats enable' to start recording")
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "usage recorded since %s\n", stats.Since.Local().Format("2006-01-02"))
+	if len(counts) == 0 {
+		fmt.Fprintln(stdout, "nothing recorded yet")
+		return nil
+	}
+
+	// Group by the event kind ("command", "action", "key") keeping the
+	// most-used-first order within each group.
+	groups := map[string][]util.UsageCount{}
+	var order []string
+	for _, c := range counts {
+		kind, _, _ := strings.Cut(c.Event, ":")
+		if _, ok := groups[kind]; !ok {
+			order = append(order, kind)
+		}
+		groups[kind] = append(groups[kind], c)
+	}
+
+	for _, kind := range order {
+		fmt.Fprintf(stdout, "\n%ss:\n", kind)
+		for _, c := range groups[kind] {
+			_, name, _ := strings.Cut(c.Event, ":")
+			fmt.Fprintf(stdout, "  %6d  %s\n", c.Count, name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runSync implements "gozip sync [--hash] [--delete] dir/ out.zip".
+func runSync(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	hash := fs.Bool("hash", false, "detect changes by content (CRC-32) instead of size and modification time")
+	del := fs.Bool("delete", false, "remove entries whose file no longer exists in the directory")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip sync [flags] dir/ out.zip")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return errors.New("a directory and an archive are required")
+	}
+
+	result, err := util.SyncDirectory(positional[0], positional[1], util.SyncOptions{CompareHash: *hash, Delete: *del})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range result.Added {
+		fmt.Fprintf(stdout, "+ %s\n", name)
+	}
+	for _, name := range result.Updated {
+		fmt.Fprintf(stdout, "~ %s\n", name)
+	}
+	for _, name := range result.Deleted {
+		fmt.Fprintf(stdout, "- %s\n", name)
+	}
+	fmt.Fprintf(stdout, "%d added, %d updated, %d deleted, %d unchanged\n",
+		len(result.Added), len(result.Updated), len(result.Deleted), result.Unchanged)
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package cli
+
+import "slices"
+
+// Verbosity takes the -v, -vv and --verbose flags out of args, which are
+// the command-line arguments without the program name, and counts them:
+// each -v and --verbose adds one, -vv two. Flags after a subcommand's name
+// are the subcommand's own, as in "gozip extract -v", and are left alone.
+//
+// Parameters:
+//   - args: command-line arguments without the program name
+//
+// Returns:
+//   - []string: args without the verbosity flags
+//   - int: verbosity for util.OpenLog, 0 without flags
+func Verbosity(args []string) ([]string, int) {
+	rest := make([]string, 0, len(args))
+	verbosity := 0
+	for i, arg := range args {
+		if _, ok := findCommand(arg); ok {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		switch arg {
+		case "-v", "--verbose", "-verbose":
+			verbosity++
+		case "-vv":
+			verbosity += 2
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return slices.Clip(rest), verbosity
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// runVerify implements "gozip verify [-v] archive.zip SHA256SUMS".
+func runVerify(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	verbose := fs.Bool("v", false, "also print the files that passed")
+	fs.Usage = func() {
+		fmt.Fprintln(stdout, "usage: gozip verify [-v] archive.zip <sums file>")
+		fmt.Fprintln(stdout, "Checks the files against a SHA256SUMS, SHA1SUMS or MD5SUMS file, on disk or inside the archive.")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return errors.New("an archive and a sums file are required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := util.VerifySums(ctx, positional[0], positional[1])
+	if err != nil {
+		return err
+	}
+
+	if *verbose {
+		for _, name := range report.OK {
+			fmt.Fprintf(stdout, "%s: OK\n", name)
+		}
+	}
+	for _, name := range report.Mismatched {
+		fmt.Fprintf(stdout, "%s: FAILED\n", name)
+	}
+	for _, name := range report.Missing {
+		fmt.Fprintf(stdout, "%s: MISSING\n", name)
+	}
+
+	total := len(report.OK) + len(report.Mismatched) + len(report.Missing)
+	if !report.Passed() {
+		return failWith(ExitVerify, fmt.Errorf("%d of %d files failed, %d missing", len(report.Mismatched), total, len(report.Missing)))
+	}
+	fmt.Fprintf(stdout, "all %d files match their %s checksum\n", total, report.Algorithm)
+	return nil
+}
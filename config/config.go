@@ -0,0 +1,538 @@
+// Package config loads goZip's settings from the settings file,
+// ~/.config/gozip/config.toml or config.yaml, and from environment
+// variables, which win over the file. Command-line flags in turn win over
+// both; the CLI and the UI apply them on top of the Config loaded here.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/cainlara/gozip/util"
+	"gopkg.in/yaml.v3"
+)
+
+// fileNames are the settings files looked for in util.ConfigDir, in order;
+// the first one found is read.
+var fileNames = []string{"config.toml", "config.yaml", "config.yml"}
+
+// Environment variables overriding the settings file. The name encoding
+// uses util.NameEncodingEnv.
+const (
+	DestDirEnv      = "GOZIP_DEST_DIR"
+	OverwriteEnv    = "GOZIP_OVERWRITE"
+	ThemeEnv        = "GOZIP_THEME"
+	HumanSizesEnv   = "GOZIP_HUMAN_SIZES"
+	ColumnsEnv      = "GOZIP_COLUMNS"
+	JobsEnv         = "GOZIP_JOBS"
+	WatchEnv        = "GOZIP_WATCH"
+	HookEnv         = "GOZIP_HOOK"
+	HashEnv         = "GOZIP_HASH"
+	ParanoidEnv     = "GOZIP_PARANOID"
+	MouseEnv        = "GOZIP_MOUSE"
+	LanguageEnv     = "GOZIP_LANG"
+	MaxMemoryEnv    = "GOZIP_MAX_MEMORY"
+	PreviewLimitEnv = "GOZIP_PREVIEW_LIMIT"
+	DateFormatEnv   = "GOZIP_DATE_FORMAT"
+	TimeZoneEnv     = "GOZIP_TIME_ZONE"
+	OrderEnv        = "GOZIP_ORDER"
+	ScannerEnv      = "GOZIP_SCANNER"
+	ScanPolicyEnv   = "GOZIP_SCANNER_POLICY"
+)
+
+// Columns are the optional columns of the archive browser, in their default
+// order. The name column is always shown first.
+var Columns = []string{"folder", "size", "modified", "crc"}
+
+// Orders the browser lists the entries in until sorted by a column.
+const (
+	// OrderArchive lists them as the archive stores them.
+	OrderArchive = "archive"
+	// OrderPath lists them sorted by path, folder by folder.
+	OrderPath = "path"
+)
+
+// Config holds goZip's settings.
+type Config struct {
+	// DestDir is where extractions go when no destination is given; empty
+	// means the current directory.
+	DestDir string
+	// Overwrite decides what extraction does with existing files.
+	Overwrite util.OverwritePolicy
+	// Theme names the color scheme of the UI.
+	Theme string
+	// HumanSizes shows sizes as "1.2 MB" instead of bytes in the browser.
+	HumanSizes bool
+	// Columns lists the browser's columns after the name, among Columns.
+	Columns []string
+	// DateFormat is how the browser and the inspector show timestamps.
+	DateFormat util.DateFormat
+	// TimeZone is the zone they are shown in.
+	TimeZone util.TimeZone
+	// Order is OrderArchive or OrderPath.
+	Order string
+	// Jobs is the number of files extracted concurrently.
+	Jobs int
+	// MaxMemory is the memory goZip aims to stay under, in bytes, see
+	// util.SetMemoryLimit; 0 means no limit.
+	MaxMemory int64
+	// PreviewLimit is the largest entry, in bytes, read whole to be shown
+	// or compared, see util.SetPreviewLimit.
+	PreviewLimit int64
+	// Encoding is the code page of names not marked as UTF-8.
+	Encoding util.NameEncoding
+	// Keys remaps the browser's actions to keys, see ui.Configure.
+	Keys map[string][]string
+	// Watch reloads the browser when the archive changes on disk.
+	Watch bool
+	// Hooks are the commands run after each extraction.
+	Hooks util.ExtractHooks
+	// Scanner is the command every file is piped through before it is
+	// extracted, and what happens to those it flags.
+	Scanner util.ContentScanner
+	// Hash is the checksum computed for the entries.
+	Hash util.HashAlgorithm
+	// Paranoid cross-checks every entry's local header against the central
+	// directory when an archive is opened, see util.CheckHeaders.
+	Paranoid bool
+	// Mouse lets the browser be used with the mouse: clicking rows,
+	// double-clicking to extract, clicking headers to sort and scrolling.
+	Mouse bool
+	// Language is the language of the UI; LanguageAuto follows the
+	// locale, see util.SystemLanguage.
+	Language util.Language
+}
+
+// Default returns the settings used when nothing is configured.
+func Default() Config {
+	return Config{
+		Overwrite:    util.OverwriteAlways,
+		Columns:      slices.Clone(Columns),
+		DateFormat:   util.DateRFC3339,
+		TimeZone:     util.TimeZoneUTC,
+		Order:        OrderArchive,
+		Jobs:         1,
+		PreviewLimit: util.DefaultPreviewLimit,
+		Encoding:     util.NameEncodingAuto,
+		Hash:         util.HashSHA256,
+		Scanner:      util.ContentScanner{Policy: util.ScanBlock},
+		Language:     util.LanguageAuto,
+	}
+}
+
+// fileConfig is the layout of the settings file. Pointers tell unset
+// settings from zero values.
+type fileConfig struct {
+	DestDir    *string  `toml:"dest_dir" yaml:"dest_dir"`
+	Overwrite  *string  `toml:"overwrite" yaml:"overwrite"`
+	Theme      *string  `toml:"theme" yaml:"theme"`
+	HumanSizes *bool    `toml:"human_sizes" yaml:"human_sizes"`
+	Columns    []string `toml:"columns" yaml:"columns"`
+	DateFormat *string  `toml:"date_format" yaml:"date_format"`
+	TimeZone   *string  `toml:"time_zone" yaml:"time_zone"`
+	Order      *string  `toml:"order" yaml:"order"`
+	Jobs       *int     `toml:"jobs" yaml:"jobs"`
+	// MaxMemory and PreviewLimit are byte counts, or strings such as "512M".
+	MaxMemory    any               `toml:"max_memory" yaml:"max_memory"`
+	PreviewLimit any               `toml:"preview_limit" yaml:"preview_limit"`
+	Encoding     *string           `toml:"encoding" yaml:"encoding"`
+	Keys         map[string]any    `toml:"keys" yaml:"keys"`
+	Watch        *bool             `toml:"watch" yaml:"watch"`
+	Hook         *string           `toml:"hook" yaml:"hook"`
+	Hooks        map[string]string `toml:"hooks" yaml:"hooks"`
+	Scanner      *string           `toml:"scanner" yaml:"scanner"`
+	ScanPolicy   *string           `toml:"scanner_policy" yaml:"scanner_policy"`
+	Hash         *string           `toml:"hash" yaml:"hash"`
+	Paranoid     *bool             `toml:"paranoid" yaml:"paranoid"`
+	Mouse        *bool             `toml:"mouse" yaml:"mouse"`
+	Language     *string           `toml:"language" yaml:"language"`
+}
+
+// Path returns the settings file in use: the first of config.toml,
+// config.yaml and config.yml found in util.ConfigDir, or config.toml when
+// there is none yet.
+func Path() (string, error) {
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range fileNames {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return filepath.Join(dir, fileNames[0]), nil
+}
+
+// Load reads the settings file, if any, then the environment variables.
+//
+// Returns:
+//   - Config: Default with the configured settings applied
+//   - error: an unreadable settings file or an invalid setting, naming the
+//     file or variable it comes from
+func Load() (Config, error) {
+	cfg := Default()
+
+	p, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+	file, err := readFile(p)
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %w", p, err)
+	}
+	if err := cfg.applyFile(file); err != nil {
+		return cfg, fmt.Errorf("%s: %w", p, err)
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// readFile decodes the settings file at p according to its extension. A
+// missing file reads as empty.
+func readFile(p string) (fileConfig, error) {
+	var file fileConfig
+
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return file, err
+	}
+
+	if filepath.Ext(p) == ".toml" {
+		err = toml.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	return file, err
+}
+
+// applyFile validates the settings of the file and copies them into c.
+func (c *Config) applyFile(file fileConfig) error {
+	if file.DestDir != nil {
+		c.DestDir = expandHome(*file.DestDir)
+	}
+	if file.Overwrite != nil {
+		if err := c.setOverwrite(*file.Overwrite); err != nil {
+			return err
+		}
+	}
+	if file.Theme != nil {
+		c.Theme = *file.Theme
+	}
+	if file.HumanSizes != nil {
+		c.HumanSizes = *file.HumanSizes
+	}
+	if file.Columns != nil {
+		if err := c.setColumns(file.Columns); err != nil {
+			return err
+		}
+	}
+	if file.DateFormat != nil {
+		if err := c.setDateFormat(*file.DateFormat); err != nil {
+			return err
+		}
+	}
+	if file.TimeZone != nil {
+		if err := c.setTimeZone(*file.TimeZone); err != nil {
+			return err
+		}
+	}
+	if file.Order != nil {
+		if err := c.setOrder(*file.Order); err != nil {
+			return err
+		}
+	}
+	if file.Jobs != nil {
+		if err := c.setJobs(*file.Jobs); err != nil {
+			return err
+		}
+	}
+	if file.MaxMemory != nil {
+		if err := setSize(&c.MaxMemory, file.MaxMemory, 0); err != nil {
+			return fmt.Errorf("max_memory: %w", err)
+		}
+	}
+	if file.PreviewLimit != nil {
+		if err := setSize(&c.PreviewLimit, file.PreviewLimit, 1); err != nil {
+			return fmt.Errorf("preview_limit: %w", err)
+		}
+	}
+	if file.Encoding != nil {
+		if err := c.setEncoding(*file.Encoding); err != nil {
+			return err
+		}
+	}
+
+	if file.Watch != nil {
+		c.Watch = *file.Watch
+	}
+	if file.Hook != nil {
+		c.Hooks.Command = *file.Hook
+	}
+	if file.Hooks != nil {
+		c.Hooks.ByPattern = file.Hooks
+	}
+	if file.Scanner != nil {
+		c.Scanner.Command = *file.Scanner
+	}
+	if file.ScanPolicy != nil {
+		if err := c.setScanPolicy(*file.ScanPolicy); err != nil {
+			return err
+		}
+	}
+	if file.Hash != nil {
+		if err := c.setHash(*file.Hash); err != nil {
+			return err
+		}
+	}
+	if file.Paranoid != nil {
+		c.Paranoid = *file.Paranoid
+	}
+	if file.Mouse != nil {
+		c.Mouse = *file.Mouse
+	}
+	if file.Language != nil {
+		if err := c.setLanguage(*file.Language); err != nil {
+			return err
+		}
+	}
+
+	if file.Keys != nil {
+		c.Keys = make(map[string][]string, len(file.Keys))
+	}
+	for action, value := range file.Keys {
+		keys, err := keyList(value)
+		if err != nil {
+			return fmt.Errorf("keys.%s: %w", action, err)
+		}
+		c.Keys[action] = keys
+	}
+
+	return nil
+}
+
+// applyEnv copies the settings of the environment variables that are set
+// into c.
+func (c *Config) applyEnv() error {
+	set := func(name string, apply func(string) error) error {
+		value := os.Getenv(name)
+		if value == "" {
+			return nil
+		}
+		if err := apply(value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+
+	return errors.Join(
+		set(DestDirEnv, func(v string) error { c.DestDir = expandHome(v); return nil }),
+		set(OverwriteEnv, c.setOverwrite),
+		set(ThemeEnv, func(v string) error { c.Theme = v; return nil }),
+		set(HumanSizesEnv, func(v string) error { return setBool(&c.HumanSizes, v) }),
+		set(ColumnsEnv, func(v string) error { return c.setColumns(strings.Split(v, ",")) }),
+		set(DateFormatEnv, c.setDateFormat),
+		set(TimeZoneEnv, c.setTimeZone),
+		set(OrderEnv, c.setOrder),
+		set(JobsEnv, func(v string) error {
+			jobs, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid number of jobs %q", v)
+			}
+			return c.setJobs(jobs)
+		}),
+		set(MaxMemoryEnv, func(v string) error { return setSize(&c.MaxMemory, v, 0) }),
+		set(PreviewLimitEnv, func(v string) error { return setSize(&c.PreviewLimit, v, 1) }),
+		set(util.NameEncodingEnv, c.setEncoding),
+		set(WatchEnv, func(v string) error { return setBool(&c.Watch, v) }),
+		set(HookEnv, func(v string) error { c.Hooks.Command = v; return nil }),
+		set(ScannerEnv, func(v string) error { c.Scanner.Command = v; return nil }),
+		set(ScanPolicyEnv, c.setScanPolicy),
+		set(HashEnv, c.setHash),
+		set(ParanoidEnv, func(v string) error { return setBool(&c.Paranoid, v) }),
+		set(MouseEnv, func(v string) error { return setBool(&c.Mouse, v) }),
+		set(LanguageEnv, c.setLanguage),
+	)
+}
+
+// expandHome replaces a leading "~/" with the user's home directory, as a
+// shell would.
+func expandHome(p string) string {
+	rest, ok := strings.CutPrefix(p, "~/")
+	if !ok {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, rest)
+}
+
+// setBool parses the value of a boolean variable into b.
+func setBool(b *bool, v string) error {
+	value, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid value %q (want true or false)", v)
+	}
+	*b = value
+	return nil
+}
+
+// setSize parses a byte count given as a number or as a string such as
+// "512M", see util.ParseSize, into n; it must be at least least.
+func setSize(n *int64, value any, least int64) error {
+	var size int64
+	switch v := value.(type) {
+	case int64:
+		size = v
+	case int:
+		size = int64(v)
+	case string:
+		var err error
+		if size, err = util.ParseSize(v); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid size %v (want a number of bytes, or one such as \"512M\")", value)
+	}
+	if size < least {
+		return fmt.Errorf("invalid size %d", size)
+	}
+	*n = size
+	return nil
+}
+
+func (c *Config) setOverwrite(s string) error {
+	policy, err := util.ParseOverwritePolicy(s)
+	if err != nil {
+		return err
+	}
+	c.Overwrite = policy
+	return nil
+}
+
+func (c *Config) setEncoding(s string) error {
+	enc, err := util.ParseNameEncoding(s)
+	if err != nil {
+		return err
+	}
+	c.Encoding = enc
+	return nil
+}
+
+func (c *Config) setDateFormat(s string) error {
+	format, err := util.ParseDateFormat(s)
+	if err != nil {
+		return err
+	}
+	c.DateFormat = format
+	return nil
+}
+
+func (c *Config) setTimeZone(s string) error {
+	zone, err := util.ParseTimeZone(s)
+	if err != nil {
+		return err
+	}
+	c.TimeZone = zone
+	return nil
+}
+
+func (c *Config) setOrder(s string) error {
+	switch order := strings.ToLower(strings.TrimSpace(s)); order {
+	case OrderArchive, OrderPath:
+		c.Order = order
+		return nil
+	default:
+		return fmt.Errorf("invalid order %q (want archive or path)", s)
+	}
+}
+
+func (c *Config) setScanPolicy(s string) error {
+	policy, err := util.ParseScanPolicy(s)
+	if err != nil {
+		return err
+	}
+	c.Scanner.Policy = policy
+	return nil
+}
+
+func (c *Config) setHash(s string) error {
+	alg, err := util.ParseHashAlgorithm(s)
+	if err != nil {
+		return err
+	}
+	c.Hash = alg
+	return nil
+}
+
+func (c *Config) setLanguage(s string) error {
+	lang, err := util.ParseLanguage(s)
+	if err != nil {
+		return err
+	}
+	c.Language = lang
+	return nil
+}
+
+func (c *Config) setJobs(jobs int) error {
+	if jobs < 1 {
+		return fmt.Errorf("invalid number of jobs %d", jobs)
+	}
+	c.Jobs = jobs
+	return nil
+}
+
+// setColumns validates column names, ignoring case, blanks and "name",
+// which is always shown.
+func (c *Config) setColumns(names []string) error {
+	columns := []string{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "" || name == "name":
+		case !slices.Contains(Columns, name):
+			return fmt.Errorf("unknown column %q (want %s)", name, strings.Join(Columns, ", "))
+		case !slices.Contains(columns, name):
+			columns = append(columns, name)
+		}
+	}
+	c.Columns = columns
+	return nil
+}
+
+// keyList reads the keys of an action, given as a single key or a list.
+func keyList(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		keys := make([]string, 0, len(v))
+		for _, key := range v {
+			s, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v is not a string", key)
+			}
+			keys = append(keys, s)
+		}
+		return keys, nil
+	default:
+		return nil, errors.New("want a key or a list of keys")
+	}
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// writeConfig writes a settings file into a fresh configuration directory
+// and clears the variables that would override it
+func writeConfig(t *testing.T, name, content string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	for _, env := range []string{DestDirEnv, OverwriteEnv, ThemeEnv, HumanSizesEnv, ColumnsEnv, DateFormatEnv, TimeZoneEnv, OrderEnv, JobsEnv, MaxMemoryEnv, PreviewLimitEnv, util.NameEncodingEnv, WatchEnv, HookEnv, ScannerEnv, ScanPolicyEnv, HashEnv, ParanoidEnv, MouseEnv, LanguageEnv} {
+		t.Setenv(env, "")
+	}
+
+	if name == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(home, "gozip"), 0o755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "gozip", name), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+// TestLoadDefaults checks the settings without a file or variables
+func TestLoadDefaults(t *testing.T) {
+	writeConfig(t, "", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load() = %+v, want the defaults %+v", cfg, Default())
+	}
+}
+
+// TestLoadFiles checks that TOML and YAML files hold the same settings
+func TestLoadFiles(t *testing.T) {
+	want := Config{
+		DestDir:      "/tmp/out",
+		Overwrite:    util.OverwriteNewer,
+		Theme:        "light",
+		HumanSizes:   true,
+		Columns:      []string{"size", "modified"},
+		DateFormat:   "2006-01-02 15:04",
+		TimeZone:     util.TimeZoneLocal,
+		Order:        OrderPath,
+		Jobs:         4,
+		MaxMemory:    512 << 20,
+		PreviewLimit: 64 << 10,
+		Encoding:     util.NameEncodingShiftJIS,
+		Keys:         map[string][]string{"quit": {"q", "Ctrl+Q"}, "filter": {"/"}},
+		Watch:        true,
+		Hooks:        util.ExtractHooks{Command: "notify-send {dest}", ByPattern: map[string]string{"*.deb": "dpkg -I {}"}},
+		Scanner:      util.ContentScanner{Command: "clamscan --no-summary -", Policy: util.ScanWarn},
+		Hash:         util.HashSHA1,
+		Paranoid:     true,
+		Mouse:        true,
+		Language:     util.LanguageSpanish,
+	}
+
+	files := map[string]string{
+		"config.toml": `dest_dir = "/tmp/out"
+overwrite = "newer"
+theme = "light"
+human_sizes = true
+columns = ["name", "Size", "modified"]
+date_format = "2006-01-02 15:04"
+time_zone = "Local"
+order = "path"
+jobs = 4
+max_memory = "512M"
+preview_limit = 65536
+encoding = "shift-jis"
+watch = true
+hook = "notify-send {dest}"
+scanner = "clamscan --no-summary -"
+scanner_policy = "warn"
+hash = "SHA-1"
+paranoid = true
+mouse = true
+language = "es"
+
+[hooks]
+"*.deb" = "dpkg -I {}"
+
+[keys]
+quit = ["q", "Ctrl+Q"]
+filter = "/"
+`,
+		"config.yaml": `dest_dir: /tmp/out
+overwrite: newer
+theme: light
+human_sizes: true
+columns: [name, Size, modified]
+date_format: "2006-01-02 15:04"
+time_zone: local
+order: Path
+jobs: 4
+max_memory: 512M
+preview_limit: 65536
+encoding: shift-jis
+watch: true
+hook: notify-send {dest}
+scanner: clamscan --no-summary -
+scanner_policy: Warn
+hash: SHA-1
+paranoid: true
+mouse: true
+language: es
+hooks:
+  "*.deb": dpkg -I {}
+keys:
+  quit: [q, Ctrl+Q]
+  filter: /
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			writeConfig(t, name, content)
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("Load() = %+v, want %+v", cfg, want)
+			}
+		})
+	}
+}
+
+// TestLoadEnvironment checks that variables override the file
+func TestLoadEnvironment(t *testing.T) {
+	writeConfig(t, "config.toml", "jobs = 4\noverwrite = \"newer\"\n")
+	t.Setenv(JobsEnv, "8")
+	t.Setenv(ColumnsEnv, "crc, folder")
+	t.Setenv(HumanSizesEnv, "yes")
+
+	if _, err := Load(); err == nil {
+		t.Errorf("Load() with %s=yes expected an error", HumanSizesEnv)
+	}
+
+	t.Setenv(HumanSizesEnv, "1")
+	t.Setenv(WatchEnv, "true")
+	t.Setenv(HookEnv, "ls {}")
+	t.Setenv(ParanoidEnv, "1")
+	t.Setenv(MouseEnv, "true")
+	t.Setenv(LanguageEnv, "es_ES.UTF-8")
+	t.Setenv(MaxMemoryEnv, "1G")
+	t.Setenv(DateFormatEnv, "Relative")
+	t.Setenv(ScannerEnv, "clamdscan -")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+	if cfg.Jobs != 8 || cfg.Overwrite != util.OverwriteNewer || !cfg.HumanSizes || !cfg.Watch || !cfg.Paranoid || !cfg.Mouse || cfg.Language != util.LanguageSpanish || cfg.Hooks.Command != "ls {}" || cfg.MaxMemory != 1<<30 || !reflect.DeepEqual(cfg.Columns, []string{"crc", "folder"}) {
+		t.Errorf("Load() = %+v, want jobs and columns from the environment", cfg)
+	}
+	if cfg.Scanner != (util.ContentScanner{Command: "clamdscan -", Policy: util.ScanBlock}) {
+		t.Errorf("Load() scanner = %+v, want clamdscan blocking", cfg.Scanner)
+	}
+	if cfg.DateFormat != util.DateRelative || cfg.TimeZone != util.TimeZoneUTC {
+		t.Errorf("Load() date format and zone = %q, %q, want relative dates in UTC", cfg.DateFormat, cfg.TimeZone)
+	}
+}
+
+// TestLoadInvalid checks that invalid settings are reported
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"syntax":    "jobs = \n",
+		"overwrite": "overwrite = \"sometimes\"\n",
+		"column":    "columns = [\"owner\"]\n",
+		"jobs":      "jobs = 0\n",
+		"date":      "date_format = \"short\"\n",
+		"zone":      "time_zone = \"Mars/Olympus\"\n",
+		"order":     "order = \"random\"\n",
+		"memory":    "max_memory = \"lots\"\n",
+		"preview":   "preview_limit = 0\n",
+		"encoding":  "encoding = \"latin1\"\n",
+		"hash":      "hash = \"crc32\"\n",
+		"scanner":   "scanner_policy = \"quarantine\"\n",
+		"language":  "language = \"klingon\"\n",
+		"key type":  "[keys]\nquit = 1\n",
+		"key list":  "[keys]\nquit = [\"q\", 2]\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			writeConfig(t, "config.toml", content)
+			if _, err := Load(); err == nil {
+				t.Errorf("Load(%q) expected an error", content)
+			}
+		})
+	}
+}
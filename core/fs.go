@@ -0,0 +1,40 @@
+package core
+
+import (
+	"archive/zip"
+	"io/fs"
+)
+
+// archiveFS is the fs.FS returned by ArchiveFS. It is archive/zip's own
+// view of the archive, which already synthesizes the folders that only
+// appear as part of entry names.
+type archiveFS struct {
+	*zip.ReadCloser
+}
+
+// ArchiveFS opens the ZIP archive at zipPath as a read-only fs.FS, so its
+// contents can be used with the standard io/fs tooling: fs.WalkDir,
+// fs.ReadFile, http.FS, template.ParseFS and so on.
+//
+// Entries are decompressed with the decompressors registered in
+// archive/zip. Besides STORE and DEFLATE these include every method goZip
+// supports (BZIP2, LZMA, XZ and, unless built with nozstd, ZSTD) in any
+// program that imports the util package, as goZip itself does.
+//
+// The returned FS also implements io.Closer; close it once done to release
+// the archive.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - fs.FS: the archive's contents, rooted at the archive's top level
+//   - error: any error encountered while opening the archive
+func ArchiveFS(zipPath string) (fs.FS, error) {
+	rc, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return archiveFS{rc}, nil
+}
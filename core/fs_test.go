@@ -0,0 +1,99 @@
+package core_test
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/cainlara/gozip/core"
+	// util registra los descompresores BZIP2, LZMA, XZ y ZSTD.
+	_ "github.com/cainlara/gozip/util"
+)
+
+// TestArchiveFS verifica que ArchiveFS exponga el contenido del archivo,
+// incluidas las entradas comprimidas con métodos ajenos a archive/zip
+func TestArchiveFS(t *testing.T) {
+	want, err := os.ReadFile("../util/testdata/sample.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, zipPath := range []string{"../util/testdata/bzip2.zip", "../util/testdata/lzma.zip"} {
+		t.Run(zipPath, func(t *testing.T) {
+			fsys, err := core.ArchiveFS(zipPath)
+			if err != nil {
+				t.Fatalf("ArchiveFS() error = %v", err)
+			}
+			defer fsys.(io.Closer).Close()
+
+			got, err := fs.ReadFile(fsys, "sample.txt")
+			if err != nil {
+				t.Fatalf("fs.ReadFile() error = %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("fs.ReadFile() = %q, want %q", got, want)
+			}
+
+			if err := fstest.TestFS(fsys, "sample.txt"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestArchiveFSWalk verifica que fs.WalkDir recorra también las carpetas
+// que solo aparecen en los nombres de las entradas
+func TestArchiveFSWalk(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "walk.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(out)
+	for _, name := range []string{"docs/guide/intro.md", "readme.txt"} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(name))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+
+	fsys, err := core.ArchiveFS(zipPath)
+	if err != nil {
+		t.Fatalf("ArchiveFS() error = %v", err)
+	}
+	defer fsys.(io.Closer).Close()
+
+	var paths []string
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fs.WalkDir() error = %v", err)
+	}
+
+	want := []string{".", "docs", "docs/guide", "docs/guide/intro.md", "readme.txt"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("fs.WalkDir() visited %v, want %v", paths, want)
+	}
+}
+
+// TestArchiveFSNotZip verifica que un archivo que no es ZIP se rechace
+func TestArchiveFSNotZip(t *testing.T) {
+	if _, err := core.ArchiveFS("../util/testdata/sample.txt"); err == nil {
+		t.Error("ArchiveFS() expected an error for a non-ZIP file")
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+// ArchiveInfo describes an archive as a whole: what it holds and how it is
+// stored. Entry counts and sizes are accumulated with Add, so the summary
+// of an archive being listed progressively stays current.
+type ArchiveInfo struct {
+	// Format is the archive format, such as "zip".
+	Format string
+	// Entries counts every entry, files and folders alike.
+	Entries int
+	// Size and CompressedSize are the total uncompressed and compressed
+	// sizes of the entries, in bytes.
+	Size           uint64
+	CompressedSize uint64
+	// Comment is the archive comment.
+	Comment string
+	// Zip64 is set when the archive uses the Zip64 extensions, needed past
+	// 65535 entries or 4 GiB.
+	Zip64 bool
+	// Encrypted is set when at least one entry needs a password.
+	Encrypted bool
+	// Prepended is the number of bytes before the archive proper, such as
+	// a self-extractor stub or a file the archive was appended to.
+	Prepended int64
+	// Trailing is the number of bytes after the archive, such as a file
+	// concatenated to it.
+	Trailing int64
+}
+
+// Add counts an entry of the archive.
+func (ai *ArchiveInfo) Add(zf ZippedFile) {
+	ai.Entries++
+	ai.Size += zf.GetSize()
+	ai.CompressedSize += zf.GetCompressedSize()
+	if zf.IsEncrypted() {
+		ai.Encrypted = true
+	}
+}
+
+// HasJunk reports whether the archive is surrounded by data that is not
+// part of it, which is skipped when reading it.
+func (ai ArchiveInfo) HasJunk() bool {
+	return ai.Prepended > 0 || ai.Trailing > 0
+}
+
+// Ratio returns the compressed size as a fraction of the uncompressed size,
+// e.g. 0.25 when compression saved three quarters. It is 0 for an archive
+// without data.
+func (ai ArchiveInfo) Ratio() float64 {
+	if ai.Size == 0 {
+		return 0
+	}
+	return float64(ai.CompressedSize) / float64(ai.Size)
+}
@@ -1,7 +1,12 @@
 // Package core provides the fundamental data structures for representing
-// files within a ZIP archive.
+// files within a ZIP archive, and an io/fs view of an archive's contents.
 package core
 
+import (
+	"io/fs"
+	"time"
+)
+
 // ZippedFile represents a file or directory within a ZIP archive.
 // It contains all metadata associated with the compressed file, including
 // name, size, compression method, modification date, and CRC.
@@ -13,6 +18,47 @@ type ZippedFile struct {
 	method     string
 	modified   string
 	crc        uint32
+
+	modTime      time.Time
+	accessed     time.Time
+	created      time.Time
+	mode         fs.FileMode
+	uid          int
+	gid          int
+	comment      string
+	encrypted    bool
+	headerOffset int64
+	flags        uint16
+	storedName   string
+}
+
+// Metadata holds the details of an entry beyond those given to
+// NewZippedFile, as recorded in the archive's central directory.
+type Metadata struct {
+	// Modified is the modification time; it replaces the one parsed from
+	// the modified date given to NewZippedFile.
+	Modified time.Time
+	// Accessed and Created are the last access and creation times, when
+	// the archive records them (NTFS and extended timestamp extra fields).
+	Accessed time.Time
+	Created  time.Time
+	// Mode holds the permission and type bits, as zip.FileHeader.Mode.
+	Mode fs.FileMode
+	// UID and GID are the owner recorded by Unix tools, or -1 if unknown.
+	UID int
+	GID int
+	// Comment is the entry comment.
+	Comment string
+	// Encrypted is set when the entry needs a password to be read.
+	Encrypted bool
+	// HeaderOffset is the position of the entry's local header in the file.
+	HeaderOffset int64
+	// Flags are the general purpose bit flags of the central directory.
+	Flags uint16
+	// StoredName is the name as the archive stores it, decoded to UTF-8;
+	// it differs from the listed one when folders are separated by
+	// backslashes.
+	StoredName string
 }
 
 // NewZippedFile creates a new ZippedFile instance with the provided parameters.
@@ -26,7 +72,12 @@ type ZippedFile struct {
 //   - method: compression method used (e.g., "STORE", "DEFLATE")
 //   - modified: modification date in RFC3339 format
 //   - crc: CRC32 value of the file
+//
+// The modification time returned by GetModified is parsed from modified; the
+// owner is unknown and the other metadata empty until set with WithMetadata.
 func NewZippedFile(fileName string, dir bool, size uint64, compressed uint64, method string, modified string, crc uint32) ZippedFile {
+	modTime, _ := time.Parse(time.RFC3339, modified)
+
 	return ZippedFile{
 		fileName:   fileName,
 		dir:        dir,
@@ -35,14 +86,43 @@ func NewZippedFile(fileName string, dir bool, size uint64, compressed uint64, me
 		method:     method,
 		modified:   modified,
 		crc:        crc,
+		modTime:    modTime,
+		uid:        -1,
+		gid:        -1,
 	}
 }
 
+// WithMetadata returns a copy of the ZippedFile carrying the given metadata.
+func (zf ZippedFile) WithMetadata(m Metadata) ZippedFile {
+	zf.modTime = m.Modified
+	zf.accessed = m.Accessed
+	zf.created = m.Created
+	zf.mode = m.Mode
+	zf.uid = m.UID
+	zf.gid = m.GID
+	zf.comment = m.Comment
+	zf.encrypted = m.Encrypted
+	zf.headerOffset = m.HeaderOffset
+	zf.flags = m.Flags
+	zf.storedName = m.StoredName
+	return zf
+}
+
 // GetName returns the name of the file or directory within the ZIP.
 func (zf ZippedFile) GetName() string {
 	return zf.fileName
 }
 
+// GetStoredName returns the name as the archive stores it, which may
+// separate folders with backslashes; it is GetName unless set with
+// WithMetadata.
+func (zf ZippedFile) GetStoredName() string {
+	if zf.storedName == "" {
+		return zf.fileName
+	}
+	return zf.storedName
+}
+
 // IsDir returns true if the ZippedFile represents a directory, false if it's a file.
 func (zf ZippedFile) IsDir() bool {
 	return zf.dir
@@ -77,3 +157,61 @@ func (zf ZippedFile) GetModifiedDate() string {
 func (zf ZippedFile) GetCrc() uint32 {
 	return zf.crc
 }
+
+// GetModified returns the modification time of the file, or the zero time if
+// it is not available. Unlike GetModifiedDate it can be compared directly,
+// e.g. to sort entries by date.
+func (zf ZippedFile) GetModified() time.Time {
+	return zf.modTime
+}
+
+// GetAccessed returns the last access time of the file, or the zero time if
+// the archive does not record it.
+func (zf ZippedFile) GetAccessed() time.Time {
+	return zf.accessed
+}
+
+// GetCreated returns the creation time of the file, or the zero time if the
+// archive does not record it.
+func (zf ZippedFile) GetCreated() time.Time {
+	return zf.created
+}
+
+// GetMode returns the permission and type bits of the file. It is 0 when the
+// archive was created by a tool that records neither.
+func (zf ZippedFile) GetMode() fs.FileMode {
+	return zf.mode
+}
+
+// GetUID returns the user ID of the file's owner, or -1 if unknown.
+func (zf ZippedFile) GetUID() int {
+	return zf.uid
+}
+
+// GetGID returns the group ID of the file's owner, or -1 if unknown.
+func (zf ZippedFile) GetGID() int {
+	return zf.gid
+}
+
+// GetComment returns the comment attached to the entry, if any.
+func (zf ZippedFile) GetComment() string {
+	return zf.comment
+}
+
+// IsEncrypted returns true if the file needs a password to be read.
+func (zf ZippedFile) IsEncrypted() bool {
+	return zf.encrypted
+}
+
+// GetHeaderOffset returns the position of the entry's local header in the
+// archive file, which tools such as hex viewers can jump to.
+func (zf ZippedFile) GetHeaderOffset() int64 {
+	return zf.headerOffset
+}
+
+// GetFlags returns the general purpose bit flags of the entry, which tell
+// among others whether it is encrypted, has a data descriptor or a UTF-8
+// name.
+func (zf ZippedFile) GetFlags() uint16 {
+	return zf.flags
+}
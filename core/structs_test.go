@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 // TestNewZippedFile verifica que el constructor NewZippedFile
 // inicialice correctamente todos los campos de la estructura ZippedFile
@@ -197,3 +200,82 @@ func TestZippedFileEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// TestZippedFileMetadata verifica los valores por defecto de los metadatos
+// extendidos y que WithMetadata los reemplace sin tocar el resto
+func TestZippedFileMetadata(t *testing.T) {
+	zf := NewZippedFile("bin/tool", false, 100, 50, "DEFLATE", "2024-01-15T10:30:00Z", 42)
+
+	if want := time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC); !zf.GetModified().Equal(want) {
+		t.Errorf("GetModified() = %v, want %v", zf.GetModified(), want)
+	}
+	if zf.GetUID() != -1 || zf.GetGID() != -1 {
+		t.Errorf("owner = %d:%d, want unknown (-1:-1)", zf.GetUID(), zf.GetGID())
+	}
+	if zf.GetMode() != 0 || zf.GetComment() != "" || zf.IsEncrypted() || zf.GetHeaderOffset() != 0 || zf.GetFlags() != 0 {
+		t.Errorf("unexpected default metadata: %+v", zf)
+	}
+
+	if undated := NewZippedFile("x", false, 0, 0, "STORE", "-", 0); !undated.GetModified().IsZero() {
+		t.Errorf("GetModified() = %v, want the zero time for \"-\"", undated.GetModified())
+	}
+
+	modified := time.Date(2025, time.March, 1, 8, 0, 0, 0, time.UTC)
+	accessed := modified.Add(time.Hour)
+	if !zf.GetAccessed().IsZero() || !zf.GetCreated().IsZero() {
+		t.Errorf("GetAccessed(), GetCreated() = %v, %v, want the zero time", zf.GetAccessed(), zf.GetCreated())
+	}
+
+	extended := zf.WithMetadata(Metadata{
+		Modified:     modified,
+		Accessed:     accessed,
+		Created:      modified,
+		Mode:         0755,
+		UID:          1000,
+		GID:          100,
+		Comment:      "release build",
+		Encrypted:    true,
+		HeaderOffset: 4096,
+		Flags:        0x0809,
+	})
+
+	if !extended.GetModified().Equal(modified) || !extended.GetAccessed().Equal(accessed) ||
+		!extended.GetCreated().Equal(modified) || extended.GetMode() != 0755 ||
+		extended.GetUID() != 1000 || extended.GetGID() != 100 || extended.GetComment() != "release build" ||
+		!extended.IsEncrypted() || extended.GetHeaderOffset() != 4096 || extended.GetFlags() != 0x0809 {
+		t.Errorf("WithMetadata() = %+v", extended)
+	}
+	if extended.GetName() != "bin/tool" || extended.GetCrc() != 42 || extended.GetModifiedDate() != "2024-01-15T10:30:00Z" {
+		t.Errorf("WithMetadata() changed the basic fields: %+v", extended)
+	}
+	if zf.GetUID() != -1 {
+		t.Error("WithMetadata() modified the original ZippedFile")
+	}
+}
+
+// TestArchiveInfo verifica la acumulación de entradas y el cálculo del ratio
+func TestArchiveInfo(t *testing.T) {
+	var info ArchiveInfo
+	if info.Ratio() != 0 {
+		t.Errorf("Ratio() of an empty archive = %v, want 0", info.Ratio())
+	}
+
+	info.Add(NewZippedFile("docs/", true, 0, 0, "STORE", "-", 0))
+	info.Add(NewZippedFile("docs/a.txt", false, 300, 100, "DEFLATE", "-", 1))
+	info.Add(NewZippedFile("docs/b.txt", false, 100, 100, "STORE", "-", 2))
+
+	if info.Entries != 3 || info.Size != 400 || info.CompressedSize != 200 {
+		t.Errorf("info = %+v, want 3 entries, 400 bytes, 200 compressed", info)
+	}
+	if info.Ratio() != 0.5 {
+		t.Errorf("Ratio() = %v, want 0.5", info.Ratio())
+	}
+	if info.Encrypted {
+		t.Error("Encrypted set without encrypted entries")
+	}
+
+	info.Add(NewZippedFile("secret", false, 1, 1, "AES", "-", 3).WithMetadata(Metadata{Encrypted: true}))
+	if !info.Encrypted {
+		t.Error("Encrypted not set after adding an encrypted entry")
+	}
+}
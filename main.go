@@ -1,21 +1,138 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"slices"
 
+	"github.com/cainlara/gozip/cli"
+	"github.com/cainlara/gozip/config"
 	"github.com/cainlara/gozip/ui"
 	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
 )
 
 func main() {
-	fileName, zipPath, content, err := util.GetFileToExtract()
+	args, verbosity := cli.Verbosity(os.Args[1:])
+	os.Args = append(os.Args[:1], args...)
+	closeLog, err := util.OpenLog(verbosity)
 	if err != nil {
-		log.Panic(err)
+		log.Printf("gozip: cannot write the log: %s", err)
+		closeLog = func() error { return nil }
 	}
+	util.Logger().Info("starting", "args", args)
 
-	root := ui.BuildUI(fileName, zipPath, content)
+	args, pprofAddr, traceFile, err := cli.ProfilingFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gozip: %s\n", err)
+		closeLog()
+		os.Exit(1)
+	}
+	os.Args = append(os.Args[:1], args...)
+	profiler, err := util.StartProfiling(pprofAddr, traceFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gozip: %s\n", err)
+		closeLog()
+		os.Exit(1)
+	}
+	if profiler.Addr() != "" {
+		fmt.Fprintf(os.Stderr, "gozip: serving pprof on http://%s/debug/pprof/\n", profiler.Addr())
+	}
+	// shutdown finishes the trace before the log it may report errors to.
+	shutdown := func() {
+		if err := profiler.Stop(); err != nil {
+			util.Logger().Error("cannot stop profiling", "err", err)
+		}
+		closeLog()
+	}
+	// fail reports err and ends goZip with code.
+	fail := func(code int, err error) {
+		fmt.Fprintf(os.Stderr, "gozip: %s\n", err)
+		shutdown()
+		os.Exit(code)
+	}
 
-	if err := root.EnableMouse(false).Run(); err != nil {
-		log.Panic(err)
+	if handled, code := cli.Run(os.Args[1:], os.Stdout, os.Stderr); handled {
+		shutdown()
+		os.Exit(code)
+	}
+	if handled, code := cli.RunPlain(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); handled {
+		shutdown()
+		os.Exit(code)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail(cli.ExitUsage, err)
+	}
+	util.SetDefaultNameEncoding(cfg.Encoding)
+	util.SetMemoryLimit(cfg.MaxMemory)
+	util.SetPreviewLimit(cfg.PreviewLimit)
+	// --mouse turns the mouse on whatever the settings say.
+	if i := slices.Index(os.Args, "--mouse"); i > 0 {
+		os.Args = slices.Delete(os.Args, i, i+1)
+		cfg.Mouse = true
+	}
+	args, err = cli.DisplayFlags(os.Args[1:], &cfg)
+	if err != nil {
+		fail(cli.ExitUsage, err)
+	}
+	os.Args = append(os.Args[:1], args...)
+	args, sig, err := cli.SignatureFlag(os.Args[1:])
+	if _, find := cli.FindPick(args); err == nil && sig != "" && (len(args) == 0 || find) {
+		err = errors.New("--sig needs an archive, as in gozip --sig archive.zip.asc archive.zip")
+	}
+	if err != nil {
+		fail(cli.ExitUsage, err)
+	}
+	os.Args = append(os.Args[:1], args...)
+	if err := ui.Configure(cfg); err != nil {
+		fail(cli.ExitUsage, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var root *tview.Application
+	if len(os.Args) == 1 {
+		// Without an archive, let the user pick one.
+		wd, err := os.Getwd()
+		if err != nil {
+			fail(cli.ExitUsage, err)
+		}
+		root = ui.BuildPickerUI(ctx, wd)
+	} else if dir, ok := cli.FindPick(os.Args[1:]); ok {
+		root = ui.BuildFindUI(ctx, dir)
+	} else {
+		fileName, zipPath, err := util.GetArchiveArgument()
+		if err != nil {
+			fail(cli.ExitUsage, err)
+		}
+
+		stream, err := util.OpenArchiveStream(zipPath)
+		if err != nil {
+			fail(cli.ExitArchive, err)
+		}
+		if sig != "" {
+			ui.SetSignature(zipPath, sig)
+		}
+		root = ui.BuildStreamingUI(ctx, fileName, zipPath, stream)
+	}
+
+	err = ui.Run(root.EnableMouse(cfg.Mouse))
+	cancel()
+	util.FlushUsage()
+	ui.SaveSession()
+	util.Logger().Info("exiting", "err", err)
+	shutdown()
+	var crash *ui.CrashError
+	if errors.As(err, &crash) {
+		fmt.Fprintln(os.Stderr, crash)
+		os.Exit(cli.ExitCrash)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gozip: %s\n", err)
+		os.Exit(cli.ExitUsage)
 	}
 }
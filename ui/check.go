@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// testEntry decompresses the selected file in the background, without
+// writing it anywhere, and checks its CRC-32. The outcome shows in the
+// status bar and as a badge next to the name, see nameCell.
+func testEntry(app *tview.Application, table *tview.Table, status *statusBar, zipPath string, entries *entryTable) {
+	row, _ := table.GetSelection()
+	zf, ok := entries.entryAt(row)
+	if !ok || zf.IsDir() {
+		return
+	}
+	version, count := entries.versionAt(row)
+	if count < 2 {
+		version = 0
+	}
+
+	util.RecordUsage("action:test-entry")
+	name := zf.GetName()
+	status.setMessage(fmt.Sprintf(palette.warning+tr("Testing %s...")+"[-]", tview.Escape(name)))
+
+	goSafe(app, func() {
+		n, err := util.CheckEntry(context.Background(), zipPath, name, version)
+		app.QueueUpdateDraw(func() {
+			if err != nil && !errors.Is(err, zip.ErrChecksum) {
+				status.showError(err)
+				return
+			}
+			if entries.tested == nil {
+				entries.tested = make(map[int64]bool)
+			}
+			entries.tested[zf.GetHeaderOffset()] = err == nil
+			if err != nil {
+				status.showError(err)
+				return
+			}
+			status.setMessage(fmt.Sprintf(palette.success+tr("%s is intact: %s match CRC-32 %08x")+"[-]", tview.Escape(name), util.FormatSize(uint64(n)), zf.GetCrc()))
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// promptCompareEntry asks for a file on disk and compares the selected entry
+// with it. The path is prefilled with where extracting the entry puts it.
+func promptCompareEntry(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, zipPath string) {
+	row, _ := table.GetSelection()
+	if row < 1 {
+		return
+	}
+
+	nameCell := table.GetCell(row, 0)
+	if nameCell == nil {
+		return
+	}
+	entry, ok := cellEntry(nameCell)
+	if !ok || entry.IsDir() {
+		return
+	}
+	entryName := entry.GetName()
+
+	showPrompt(app, "Compare "+entryName, "With file: ", filepath.FromSlash(entryName), func(diskPath string, ok bool) {
+		if ok && diskPath != "" {
+			util.RecordUsage("action:compare-entry")
+			result, err := util.CompareEntry(zipPath, entryName, diskPath)
+			if err == nil {
+				showEntryComparison(app, layout, table, entryName, diskPath, result)
+				return
+			}
+			status.showError(err)
+		}
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+	})
+}
+
+// showEntryComparison shows the outcome of a comparison full screen, with
+// the unified diff colored for text files.
+func showEntryComparison(app *tview.Application, layout *tview.Flex, table *tview.Table, entryName, diskPath string, result util.EntryComparison) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("%s vs %s", entryName, diskPath))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Archive: %d bytes   Disk: %d bytes\n\n", result.EntrySize, result.FileSize)
+
+	switch {
+	case result.Identical:
+		b.WriteString(palette.success + "The contents are identical.[-]\n")
+	case result.Text:
+		for _, line := range strings.SplitAfter(result.Diff, "\n") {
+			color := ""
+			switch {
+			case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+				color = "::b"
+			case strings.HasPrefix(line, "@@"):
+				color = "teal"
+			case strings.HasPrefix(line, "-"):
+				color = "red"
+			case strings.HasPrefix(line, "+"):
+				color = "green"
+			}
+			if color == "" {
+				b.WriteString(tview.Escape(line))
+			} else {
+				fmt.Fprintf(&b, "[%s]%s[-:-:-]", color, tview.Escape(line))
+			}
+		}
+	default:
+		fmt.Fprintf(&b, palette.failure+"The contents differ[-] starting at byte %d.\n", result.FirstDifference)
+		if result.Note != "" {
+			fmt.Fprintf(&b, palette.muted+"(compared byte by byte: %s)[-]\n", result.Note)
+		}
+	}
+	b.WriteString("\n" + palette.muted + "Esc close[-]")
+	view.SetText(b.String())
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// crash is a panic caught by goSafe, for Run to report.
+type crash struct {
+	value any
+	stack []byte
+}
+
+// backgroundCrash holds the first panic of the goroutines started with
+// goSafe.
+var backgroundCrash atomic.Pointer[crash]
+
+// goSafe runs f in a new goroutine. A panic there would end goZip with the
+// terminal still in raw mode; instead it stops app, which restores the
+// terminal, and Run reports it.
+func goSafe(app *tview.Application, f func()) {
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				backgroundCrash.CompareAndSwap(nil, &crash{value: p, stack: debug.Stack()})
+				app.Stop()
+			}
+		}()
+		f()
+	}()
+}
+
+// CrashError is the error Run returns when goZip panicked.
+type CrashError struct {
+	// Value is the value goZip panicked with.
+	Value any
+	// Report is the path of the crash report, "" if it could not be
+	// written, see ReportErr.
+	Report    string
+	ReportErr error
+}
+
+func (e *CrashError) Error() string {
+	if e.ReportErr != nil {
+		return fmt.Sprintf("goZip crashed: %v (the crash report could not be saved: %s)", e.Value, e.ReportErr)
+	}
+	return fmt.Sprintf("goZip crashed: %v\nA crash report was saved to %s; please attach it when reporting the problem.", e.Value, e.Report)
+}
+
+// Run runs app until it stops, like app.Run, but survives a panic in the
+// browser or in its background work: the terminal is restored first, then
+// the panic is written to a crash report, see util.WriteCrashReport. The
+// scratch folder of a tour still running is removed.
+//
+// Parameters:
+//   - app: the application from BuildUI or BuildStreamingUI
+//
+// Returns:
+//   - error: from app.Run, or a *CrashError after a panic
+func Run(app *tview.Application) (err error) {
+	defer removeTourDir()
+	defer func() {
+		// app.Run has restored the terminal before panicking again.
+		if p := recover(); p != nil {
+			err = newCrashError(p, debug.Stack())
+		}
+	}()
+
+	err = app.Run()
+	if c := backgroundCrash.Load(); c != nil {
+		return newCrashError(c.value, c.stack)
+	}
+	return err
+}
+
+func newCrashError(value any, stack []byte) *CrashError {
+	report, err := util.WriteCrashReport(value, stack)
+	return &CrashError{Value: value, Report: report, ReportErr: err}
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+)
+
+// relativeUnits are the counts RelativeTime gives, in the singular and the
+// plural, for texts such as "3 days ago".
+var relativeUnits = map[string][2]string{
+	"minute": {"%d minute", "%d minutes"},
+	"hour":   {"%d hour", "%d hours"},
+	"day":    {"%d day", "%d days"},
+	"month":  {"%d month", "%d months"},
+	"year":   {"%d year", "%d years"},
+}
+
+// entryDate renders a timestamp for the browser's table, in the configured
+// format and zone; "-" when the archive did not record it.
+func entryDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if settings.DateFormat == util.DateRelative {
+		return relativeDate(t, time.Now())
+	}
+	return t.In(settings.TimeZone.Location()).Format(settings.DateFormat.Layout())
+}
+
+// entryTime renders a timestamp for the inspector: as the table does, but
+// with the precision the archive recorded it with, down to the 100 ns of
+// NTFS times, when the format is RFC 3339, and with the date itself next
+// to a relative one.
+func entryTime(t time.Time) string {
+	if t.IsZero() {
+		return "not recorded"
+	}
+	precise := t.In(settings.TimeZone.Location()).Format(time.RFC3339Nano)
+	switch settings.DateFormat {
+	case "", util.DateRFC3339:
+		return precise
+	case util.DateRelative:
+		return relativeDate(t, time.Now()) + " (" + precise + ")"
+	}
+	return entryDate(t)
+}
+
+// relativeDate renders t as seen from now, e.g. "3 days ago".
+func relativeDate(t, now time.Time) string {
+	n, unit := util.RelativeTime(t, now)
+	if unit == "second" {
+		return tr("just now")
+	}
+
+	forms := relativeUnits[unit]
+	count := fmt.Sprintf(tr(forms[0]), 1)
+	if n < 0 {
+		n = -n
+	}
+	if n != 1 {
+		count = fmt.Sprintf(tr(forms[1]), n)
+	}
+	if t.After(now) {
+		return fmt.Sprintf(tr("in %s"), count)
+	}
+	return fmt.Sprintf(tr("%s ago"), count)
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// diffColor returns the color of the rows of a diff status in the current
+// theme.
+func diffColor(status util.DiffStatus) tcell.Color {
+	switch status {
+	case util.DiffAdded:
+		return currentTheme.success
+	case util.DiffRemoved:
+		return currentTheme.failure
+	case util.DiffChanged:
+		return currentTheme.warning
+	default:
+		return currentTheme.PrimaryTextColor
+	}
+}
+
+// promptDiff asks for another archive and compares the current one with it.
+func promptDiff(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath string) {
+	showPrompt(app, "Compare "+fileName+" with", "Archive: ", "", func(otherPath string, ok bool) {
+		if ok && otherPath != "" {
+			util.RecordUsage("action:diff")
+			err := showDiff(app, layout, table, zipPath, otherPath)
+			if err == nil {
+				return
+			}
+			status.showError(err)
+		}
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+	})
+}
+
+// showDiff shows oldPath and newPath side by side, one row per entry name:
+// added rows are green, removed ones red and changed ones yellow in the
+// default theme. 's' toggles the unchanged rows and Esc returns to layout.
+func showDiff(app *tview.Application, layout *tview.Flex, table *tview.Table, oldPath, newPath string) error {
+	diffs, err := util.DiffArchives(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+
+	view := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selection)
+	view.SetBorder(true)
+
+	headers := []string{filepath.Base(oldPath), "SIZE", "CRC", "│", filepath.Base(newPath), "SIZE", "CRC"}
+	hideSame := false
+
+	populate := func() {
+		view.Clear()
+		for c, h := range headers {
+			view.SetCell(0, c, tview.NewTableCell("[::b]"+tview.Escape(h)).SetSelectable(false))
+		}
+
+		counts := make(map[util.DiffStatus]int)
+		row := 1
+		for _, d := range diffs {
+			counts[d.Status]++
+			if hideSame && d.Status == util.DiffSame {
+				continue
+			}
+
+			cells := []string{"", "", "", "│", "", "", ""}
+			if d.Status != util.DiffAdded {
+				cells[0] = d.Name
+				cells[1] = strconv.FormatUint(d.Old.GetSize(), 10)
+				cells[2] = fmt.Sprintf("%08x", d.Old.GetCrc())
+			}
+			if d.Status != util.DiffRemoved {
+				cells[4] = d.Name
+				cells[5] = strconv.FormatUint(d.New.GetSize(), 10)
+				cells[6] = fmt.Sprintf("%08x", d.New.GetCrc())
+			}
+
+			for c, text := range cells {
+				view.SetCell(row, c, tview.NewTableCell(text).SetTextColor(diffColor(d.Status)))
+			}
+			row++
+		}
+
+		view.SetTitle(fmt.Sprintf(" "+palette.success+"+%d added[-] "+palette.failure+"-%d removed[-] "+palette.warning+"~%d changed[-] • s toggle unchanged • Esc close ",
+			counts[util.DiffAdded], counts[util.DiffRemoved], counts[util.DiffChanged]))
+		if row > 1 {
+			view.Select(1, 0)
+		}
+	}
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case ev.Key() == tcell.KeyEscape, ev.Key() == tcell.KeyRune && ev.Rune() == 'q':
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		case ev.Key() == tcell.KeyRune && ev.Rune() == 's':
+			hideSame = !hideSame
+			populate()
+			return nil
+		}
+		return ev
+	})
+
+	populate()
+	app.SetRoot(view, true)
+
+	return nil
+}
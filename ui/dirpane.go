@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// dirEntry is a folder or a file listed by a dirPane.
+type dirEntry struct {
+	name     string
+	dir      bool
+	size     int64
+	modified time.Time
+}
+
+// dirPane is a table listing a folder of the disk, folders first, with a
+// ".." row to go up. It is the file picker and the disk side of the two
+// panes mode.
+type dirPane struct {
+	*tview.Table
+
+	dir string
+	// archivesOnly leaves out the files that are not ZIP archives.
+	archivesOnly bool
+}
+
+// newDirPane builds a pane for dir; call list to fill it.
+func newDirPane(dir string, archivesOnly bool) *dirPane {
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selection)
+	table.SetBorder(true).SetTitleAlign(tview.AlignCenter)
+
+	return &dirPane{Table: table, dir: dir, archivesOnly: archivesOnly}
+}
+
+// list shows the folder at path, selecting the entry called selected or
+// else the first one, and returns the entries listed. The pane is left as
+// it was if the folder cannot be read.
+func (p *dirPane) list(path, selected string) ([]dirEntry, error) {
+	entries, err := listDir(path, p.archivesOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	p.dir = path
+	p.Clear().SetOffset(0, 0)
+	p.SetTitle(tview.Escape(path))
+	for column, title := range []string{"NAME", "SIZE", "MODIFIED ON"} {
+		p.SetCell(0, column, tview.NewTableCell("[::b]"+title).SetSelectable(false).SetAlign(tview.AlignCenter))
+	}
+
+	rows := entries
+	// The first entry is selected by default, rather than "..".
+	selectedRow := 1
+	if parent := filepath.Dir(path); parent != path {
+		rows = append([]dirEntry{{name: "..", dir: true}}, entries...)
+		selectedRow = min(2, len(rows))
+	}
+
+	for i, e := range rows {
+		row := i + 1
+		name, size, modified := tview.Escape(e.name), util.FormatSize(uint64(e.size)), e.modified.Format(time.DateTime)
+		if e.dir {
+			name, size = name+"/", ""
+		}
+		if e.name == ".." {
+			modified = ""
+		}
+		p.SetCell(row, 0, tview.NewTableCell(name).SetReference(e).SetExpansion(1))
+		p.SetCell(row, 1, tview.NewTableCell(size).SetAlign(tview.AlignRight))
+		p.SetCell(row, 2, tview.NewTableCell(modified))
+		if e.name == selected {
+			selectedRow = row
+		}
+	}
+	p.Select(selectedRow, 0)
+
+	return entries, nil
+}
+
+// reload lists the folder again, keeping the selection on the entry called
+// selected, or else on the selected entry.
+func (p *dirPane) reload(selected string) error {
+	if selected == "" {
+		if e, ok := p.selection(); ok {
+			selected = e.name
+		}
+	}
+	_, err := p.list(p.dir, selected)
+	return err
+}
+
+// selection returns the selected entry, if any.
+func (p *dirPane) selection() (dirEntry, bool) {
+	row, _ := p.GetSelection()
+	e, ok := p.GetCell(row, 0).GetReference().(dirEntry)
+	return e, ok
+}
+
+// navigate handles the keys moving between folders: Enter on a folder opens
+// it and Backspace or Left goes to the parent one. It returns the listing of
+// the folder opened, and ev when it is not such a key.
+func (p *dirPane) navigate(ev *tcell.EventKey) ([]dirEntry, *tcell.EventKey, error) {
+	switch ev.Key() {
+	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyLeft:
+		entries, err := p.list(filepath.Dir(p.dir), filepath.Base(p.dir))
+		return entries, nil, err
+	case tcell.KeyEnter:
+		e, ok := p.selection()
+		if !ok || !e.dir {
+			return nil, ev, nil
+		}
+		if e.name == ".." {
+			entries, err := p.list(filepath.Dir(p.dir), filepath.Base(p.dir))
+			return entries, nil, err
+		}
+		entries, err := p.list(filepath.Join(p.dir, e.name), "")
+		return entries, nil, err
+	}
+	return nil, ev, nil
+}
+
+// listDir returns the folders and files of dir, folders first, each group
+// sorted by name. Hidden entries, whose name starts with a dot, are left
+// out, and so are files other than ZIP archives, recognized by their
+// extension or signature, when archivesOnly is set.
+func listDir(dir string, archivesOnly bool) ([]dirEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var entries []dirEntry
+	for _, de := range dirEntries {
+		if strings.HasPrefix(de.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, de.Name())
+		// Stat follows symbolic links, so links to folders list as folders.
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if !info.IsDir() {
+			if !info.Mode().IsRegular() {
+				continue
+			}
+			if archivesOnly && !util.HasArchiveExtension(de.Name()) {
+				if isZip, err := util.IsZipFile(path); err != nil || !isZip {
+					continue
+				}
+			}
+		}
+		entries = append(entries, dirEntry{name: de.Name(), dir: info.IsDir(), size: info.Size(), modified: info.ModTime()})
+	}
+
+	slices.SortFunc(entries, func(a, b dirEntry) int {
+		if a.dir != b.dir {
+			if a.dir {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(strings.ToLower(a.name), strings.ToLower(b.name))
+	})
+	return entries, nil
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showDuplicateContent lists the files stored more than once in the archive
+// full screen, with the space removing the copies would save. Esc or q goes
+// back to the browser.
+func showDuplicateContent(app *tview.Application, layout *tview.Flex, table *tview.Table, fileName, zipPath string) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("Duplicated files in %s", fileName))
+
+	report, err := util.FindDuplicateContent(zipPath)
+	if err != nil {
+		view.SetText(fmt.Sprintf(palette.failure+"Error: %s[-]\n\n"+palette.muted+"Esc close[-]", tview.Escape(err.Error())))
+	} else {
+		view.SetText(formatDuplicateReport(report))
+	}
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
+
+// formatDuplicateReport renders the groups of identical files for the
+// duplicates view.
+func formatDuplicateReport(report util.DuplicateReport) string {
+	var b strings.Builder
+
+	if len(report.Groups) == 0 {
+		b.WriteString(palette.success + "No file is stored more than once.[-]\n\n")
+	} else {
+		fmt.Fprintf(&b, "[::b]%d redundant files in %d groups: %s could be saved[::-] (%s uncompressed)\n\n",
+			report.Redundant, len(report.Groups), util.FormatSize(report.Savings), util.FormatSize(report.WastedSize))
+
+		for _, g := range report.Groups {
+			fmt.Fprintf(&b, palette.highlight+"%d copies of %s[-] "+palette.muted+"(CRC %08x, %s to save)[-]\n", len(g.Entries), util.FormatSize(g.Size), g.CRC32, util.FormatSize(g.Savings()))
+			for _, zf := range g.Entries {
+				fmt.Fprintf(&b, "  %s\n", tview.Escape(zf.GetName()))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(palette.muted + "Esc close[-]")
+
+	return b.String()
+}
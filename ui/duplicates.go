@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+
+	"github.com/rivo/tview"
+)
+
+// chooseVersion asks which version of a name stored count times to extract:
+// the selected one, the first or the last. extract is called with the
+// chosen version, counting from 1, unless the user cancels.
+func chooseVersion(app *tview.Application, layout *tview.Flex, table *tview.Table, name string, version, count int, extract func(version int)) {
+	thisOne := fmt.Sprintf(tr("This one (%d)"), version)
+	first, last := tr("First"), tr("Last")
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf(tr("'%s' is stored %d times in the archive, usually because it was updated by appending.\n\nWhich version do you want to extract?"), name, count)).
+		AddButtons([]string{thisOne, first, last, tr("Cancel")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case thisOne:
+				extract(version)
+			case first:
+				extract(1)
+			case last:
+				extract(count)
+			}
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+		})
+
+	app.SetRoot(modal, true)
+}
+
+// confirmCaseRename lists the names of an extraction that differ only in
+// case, which the destination cannot keep apart, and asks whether to write
+// the later ones under new names such as "readme.1". rename is called, with
+// the browser back in place, if the user agrees.
+func confirmCaseRename(app *tview.Application, layout *tview.Flex, table *tview.Table, collision *util.CaseCollisionError, rename func()) {
+	const shown = 5
+	var groups []string
+	for i, names := range collision.Collisions {
+		if i == shown {
+			groups = append(groups, fmt.Sprintf(tr("and %d more"), len(collision.Collisions)-shown))
+			break
+		}
+		groups = append(groups, strings.Join(names, " / "))
+	}
+
+	renameLabel := tr("Rename")
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf(tr("These names differ only in case, and the destination would keep only one of each:\n\n%s\n\nWrite the later ones as name.1, name.2...?"), strings.Join(groups, "\n"))).
+		AddButtons([]string{renameLabel, tr("Cancel")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			if buttonLabel == renameLabel {
+				rename()
+			}
+		})
+
+	app.SetRoot(modal, true)
+}
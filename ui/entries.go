@@ -0,0 +1,570 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/core"
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// entryHeaders are the column titles of the archive browser. The name is
+// always shown; settings.Columns picks the others.
+var entryHeaders = []string{"NAME", "IS FOLDER", "SIZE", "MODIFIED ON", "CRC"}
+
+// columnFields maps the column names of config.Columns to their position in
+// entryHeaders and in a row.
+var columnFields = map[string]int{"folder": 1, "size": 2, "modified": 3, "crc": 4}
+
+// ownerHeaders are the titles of the optional permission and ownership
+// columns, shown after entryHeaders. They are not searched by the filter.
+var ownerHeaders = []string{"MODE", "UID", "GID"}
+
+// dropOrder lists the fields hidden, in order, when the table is too narrow
+// for every column: the CRC and the date first, the size last.
+var dropOrder = []int{4, 3, 7, 6, 5, 1, 2}
+
+// minNameWidth is the width the name column keeps before other columns are
+// hidden; longer names are cut with an ellipsis.
+const minNameWidth = 24
+
+// entryTable is the tview.TableContent behind the archive browser. It keeps
+// the text of every entry in memory and the positions of those passing the
+// current filter, and only builds cells for the rows tview actually draws,
+// so archives with hundreds of thousands of entries open instantly.
+//
+// Row 0 is the header; row i > 0 is the (i-1)-th visible entry.
+//
+// Filtering runs against index, the lowercased text of each row built once
+// when the row is added, so a keystroke costs one substring search per
+// entry. A filter that extends the previous one only searches the entries
+// still visible.
+type entryTable struct {
+	tview.TableContentReadOnly
+
+	files   []core.ZippedFile
+	rows    [][]string
+	index   []string
+	visible []int
+	filter  string
+
+	// versions holds, for each row, which occurrence of its name it is,
+	// counting from 1; counts holds how many entries share each name.
+	// Archives updated by appending store a name several times.
+	versions       []int
+	counts         map[string]int
+	duplicateNames int
+
+	// fields are the positions, in a row, of the columns shown before the
+	// owner columns: the name and the configured ones.
+	fields []int
+
+	// showOwner adds the ownerHeaders columns.
+	showOwner bool
+
+	// marked flags the rows marked for a later action; markedCount and
+	// markedSize add them up.
+	marked      []bool
+	markedCount int
+	markedSize  uint64
+
+	// headerIssues describes, by header offset, the entries whose local
+	// header disagrees with the central directory; set in paranoid mode,
+	// see checkHeaders.
+	headerIssues map[int64]string
+
+	// tested records, by header offset, whether the entries tested with
+	// testEntry matched their CRC-32.
+	tested map[int64]bool
+
+	// table is the view showing the entries, whose width decides which
+	// columns fit, see columns; widths holds the widest text of each field,
+	// header included.
+	table  *tview.Table
+	widths []int
+	layout tableLayout
+
+	// nameOffset is the number of cells the name column is scrolled
+	// by, see scrollNames; basenames shows names without their folder and
+	// storedNames shows them as stored, see core.ZippedFile.GetStoredName.
+	nameOffset  int
+	basenames   bool
+	storedNames bool
+
+	// rawNumbers shows sizes in bytes and CRCs in decimal rather than as
+	// configured and in hex, see setRawNumbers.
+	rawNumbers bool
+
+	// order is the order visible lists the entries in, see cycleSort;
+	// unsorted is the one it goes back to, archive or path order, see
+	// togglePathOrder.
+	order    sortOrder
+	unsorted sortOrder
+}
+
+// tableLayout is the columns that fit in a width, computed by fit and
+// kept until the width, the owner columns or the widths change.
+type tableLayout struct {
+	width     int
+	showOwner bool
+	entries   int
+	order     sortOrder
+	columns   []int
+	// nameWidth is the room left for the names, 0 when unlimited.
+	nameWidth int
+}
+
+// indexSeparator joins the columns of a row in its index entry. It cannot be
+// typed in the filter, so a match never spans two columns.
+const indexSeparator = "\x00"
+
+// newEntryTable renders the columns of every entry once.
+func newEntryTable(content []core.ZippedFile) *entryTable {
+	t := &entryTable{
+		files:    make([]core.ZippedFile, 0, len(content)),
+		rows:     make([][]string, 0, len(content)),
+		index:    make([]string, 0, len(content)),
+		versions: make([]int, 0, len(content)),
+		marked:   make([]bool, 0, len(content)),
+		counts:   make(map[string]int, len(content)),
+		fields:   []int{0},
+		widths:   make([]int, len(entryHeaders)+len(ownerHeaders)),
+	}
+	t.measureHeaders()
+	for _, column := range settings.Columns {
+		t.fields = append(t.fields, columnFields[column])
+	}
+	t.unsorted.byPath = settings.Order == config.OrderPath
+	t.order = t.unsorted
+	t.appendEntries(content)
+	return t
+}
+
+// appendEntries adds entries after the existing ones, showing those that
+// pass the current filter. It lets the browser fill up while a large
+// archive is still being read.
+func (t *entryTable) appendEntries(content []core.ZippedFile) {
+	for _, zf := range content {
+		row := t.entryRow(zf)
+		t.files = append(t.files, zf)
+		t.counts[zf.GetName()]++
+		if t.counts[zf.GetName()] == 2 {
+			t.duplicateNames++
+		}
+		t.versions = append(t.versions, t.counts[zf.GetName()])
+		t.rows = append(t.rows, row)
+		t.marked = append(t.marked, false)
+		t.measure(row)
+		t.index = append(t.index, t.indexOf(row))
+
+		if t.matches(len(t.rows) - 1) {
+			t.visible = append(t.visible, len(t.rows)-1)
+		}
+	}
+	if t.order.sorted() {
+		t.resort()
+	}
+}
+
+// entryRow renders the columns of an entry: the entryHeaders, then the
+// ownerHeaders.
+func (t *entryTable) entryRow(zf core.ZippedFile) []string {
+	size := strconv.FormatUint(zf.GetSize(), 10)
+	crc := fmt.Sprintf("%08x", zf.GetCrc())
+	if t.rawNumbers {
+		crc = strconv.FormatUint(uint64(zf.GetCrc()), 10)
+	} else if settings.HumanSizes {
+		size = util.FormatSize(zf.GetSize())
+	}
+	return []string{
+		zf.GetName(),
+		strconv.FormatBool(zf.IsDir()),
+		size,
+		entryDate(zf.GetModified()),
+		crc,
+		zf.GetMode().String(),
+		ownerID(zf.GetUID()),
+		ownerID(zf.GetGID())}
+}
+
+// measureHeaders makes the widths those of the column titles.
+func (t *entryTable) measureHeaders() {
+	for i, header := range append(entryHeaders[:len(entryHeaders):len(entryHeaders)], ownerHeaders...) {
+		t.widths[i] = displayWidth(tr(header))
+	}
+}
+
+// measure widens the widths to fit row.
+func (t *entryTable) measure(row []string) {
+	t.widths[0] = max(t.widths[0], displayWidth(printableName(row[0])))
+	for j, v := range row[1:] {
+		t.widths[j+1] = max(t.widths[j+1], len(v))
+	}
+}
+
+// indexOf returns the index entry of row, see matches.
+func (t *entryTable) indexOf(row []string) string {
+	shown := make([]string, len(t.fields))
+	for j, field := range t.fields {
+		shown[j] = row[field]
+	}
+	return strings.ToLower(strings.Join(shown, indexSeparator))
+}
+
+// setRawNumbers shows sizes in bytes and CRCs in decimal, as some tools
+// print them, or back in the configured size format and in hex. Every row
+// is rendered again, and filtered again since the filter searches the text
+// shown.
+func (t *entryTable) setRawNumbers(raw bool) {
+	t.rawNumbers = raw
+	t.measureHeaders()
+	for i, zf := range t.files {
+		t.rows[i] = t.entryRow(zf)
+		t.index[i] = t.indexOf(t.rows[i])
+		t.measure(t.rows[i])
+	}
+	t.layout = tableLayout{}
+
+	filter := t.filter
+	t.filter = ""
+	t.setFilter(filter)
+}
+
+// setFilter keeps the entries having filterText, case-insensitively, in any
+// of the name and configured columns, in the current order. An empty filter
+// keeps every entry.
+func (t *entryTable) setFilter(filterText string) {
+	filter := strings.ToLower(filterText)
+	narrowing := t.filter != "" && strings.Contains(filter, t.filter)
+	t.filter = filter
+
+	if narrowing {
+		// Whatever contains the new filter contains the previous one too.
+		kept := t.visible[:0]
+		for _, i := range t.visible {
+			if t.matches(i) {
+				kept = append(kept, i)
+			}
+		}
+		t.visible = kept
+		return
+	}
+
+	t.visible = t.visible[:0]
+	for i := range t.rows {
+		if t.matches(i) {
+			t.visible = append(t.visible, i)
+		}
+	}
+	if t.order.sorted() {
+		t.sortVisible()
+	}
+}
+
+// matches reports whether the i-th row passes the current filter.
+func (t *entryTable) matches(i int) bool {
+	return t.filter == "" || strings.Contains(t.index[i], t.filter)
+}
+
+// entryAt returns the entry shown at a table row, if any.
+func (t *entryTable) entryAt(row int) (core.ZippedFile, bool) {
+	if row < 1 || row > len(t.visible) {
+		return core.ZippedFile{}, false
+	}
+	return t.files[t.visible[row-1]], true
+}
+
+// rowOf returns the table row of the first visible entry called name.
+func (t *entryTable) rowOf(name string) (int, bool) {
+	for row, i := range t.visible {
+		if t.files[i].GetName() == name {
+			return row + 1, true
+		}
+	}
+	return 0, false
+}
+
+// toggleMark marks the entry at a table row, or unmarks it if it was
+// marked. It reports whether there was an entry at row.
+func (t *entryTable) toggleMark(row int) bool {
+	if row < 1 || row > len(t.visible) {
+		return false
+	}
+	i := t.visible[row-1]
+	t.setMark(i, !t.marked[i])
+	return true
+}
+
+// setMark marks or unmarks the i-th entry, keeping the totals.
+func (t *entryTable) setMark(i int, marked bool) {
+	if t.marked[i] == marked {
+		return
+	}
+	t.marked[i] = marked
+	if marked {
+		t.markedCount++
+		t.markedSize += t.files[i].GetSize()
+	} else {
+		t.markedCount--
+		t.markedSize -= t.files[i].GetSize()
+	}
+}
+
+// markVisible marks every entry passing the filter.
+func (t *entryTable) markVisible() {
+	for _, i := range t.visible {
+		t.setMark(i, true)
+	}
+}
+
+// invertVisible marks the entries passing the filter that are not marked
+// and unmarks those that are.
+func (t *entryTable) invertVisible() {
+	for _, i := range t.visible {
+		t.setMark(i, !t.marked[i])
+	}
+}
+
+// markMatching marks every entry whose name matches, filtered out or not,
+// and returns how many of them were not marked yet.
+func (t *entryTable) markMatching(match func(string) bool) int {
+	marked := 0
+	for i, zf := range t.files {
+		if !t.marked[i] && match(zf.GetName()) {
+			t.setMark(i, true)
+			marked++
+		}
+	}
+	return marked
+}
+
+// markNames marks every entry with one of names, filtered out or not, and
+// returns how many of them were not marked yet and how many of names no
+// entry has.
+func (t *entryTable) markNames(names []string) (marked, missing int) {
+	listed := make(map[string]bool, len(names))
+	for _, name := range names {
+		listed[name] = true
+	}
+	marked = t.markMatching(func(name string) bool { return listed[name] })
+	for name := range listed {
+		if t.counts[name] == 0 {
+			missing++
+		}
+	}
+	return marked, missing
+}
+
+// clearMarks unmarks every entry, filtered out or not.
+func (t *entryTable) clearMarks() {
+	for i := range t.marked {
+		t.setMark(i, false)
+	}
+}
+
+// versionAt returns which occurrence of its name the entry at a table row
+// is, and how many entries share that name.
+func (t *entryTable) versionAt(row int) (version, count int) {
+	if row < 1 || row > len(t.visible) {
+		return 0, 0
+	}
+	i := t.visible[row-1]
+	return t.versions[i], t.counts[t.files[i].GetName()]
+}
+
+// columns returns the positions, in a row, of the columns currently shown:
+// those that fit in the table, see fit.
+func (t *entryTable) columns() []int {
+	return t.fit().columns
+}
+
+// fit works out which columns fit in the table. Columns are hidden in
+// dropOrder until the name gets minNameWidth, or less when no name is that
+// long; the name column takes the rest. Before the table is drawn, every
+// column is shown.
+func (t *entryTable) fit() tableLayout {
+	width := 0
+	if t.table != nil {
+		_, _, width, _ = t.table.GetInnerRect()
+	}
+	if t.layout.columns != nil && t.layout.width == width && t.layout.showOwner == t.showOwner && t.layout.entries == len(t.rows) && t.layout.order == t.order {
+		return t.layout
+	}
+
+	columns := t.fields
+	if t.showOwner {
+		columns = append(t.fields[:len(t.fields):len(t.fields)], len(entryHeaders), len(entryHeaders)+1, len(entryHeaders)+2)
+	}
+	// Columns are one cell apart, and the sorted one is wider by its
+	// arrow.
+	others := func(columns []int) int {
+		n := 0
+		for _, field := range columns[1:] {
+			n += t.widths[field] + 1
+			if t.order.sorts(field) {
+				n += displayWidth(t.order.indicator())
+			}
+		}
+		return n
+	}
+
+	layout := tableLayout{width: width, showOwner: t.showOwner, entries: len(t.rows), order: t.order, columns: columns}
+	if width > 0 {
+		need := min(t.widths[0], minNameWidth)
+		for _, field := range dropOrder {
+			if need+others(layout.columns) <= width {
+				break
+			}
+			layout.columns = slices.DeleteFunc(slices.Clone(layout.columns), func(f int) bool { return f == field })
+		}
+		layout.nameWidth = max(width-others(layout.columns), 1)
+	}
+	t.layout = layout
+	return layout
+}
+
+// truncatedName returns the name of the entry at a table row when it is
+// too long for the name column, escaped for display.
+func (t *entryTable) truncatedName(row int) (string, bool) {
+	if row < 1 || row > len(t.visible) {
+		return "", false
+	}
+	nameWidth := t.fit().nameWidth
+	if nameWidth == 0 || !strings.HasSuffix(t.nameCell(t.visible[row-1]).Text, "…") {
+		return "", false
+	}
+	return tview.Escape(printableName(t.files[t.visible[row-1]].GetName())), true
+}
+
+// headerOf returns the title of the column showing a field of the rows.
+func headerOf(field int) string {
+	if field < len(entryHeaders) {
+		return entryHeaders[field]
+	}
+	return ownerHeaders[field-len(entryHeaders)]
+}
+
+// headers returns the titles of the columns currently shown, the sorted
+// one with an arrow.
+func (t *entryTable) headers() []string {
+	var headers []string
+	for _, field := range t.columns() {
+		header := tr(headerOf(field))
+		if t.order.sorts(field) {
+			header += t.order.indicator()
+		}
+		headers = append(headers, header)
+	}
+	return headers
+}
+
+// GetCell returns the cell at the given position, built on demand.
+func (t *entryTable) GetCell(row, column int) *tview.TableCell {
+	columns := t.columns()
+	if column < 0 || column >= len(columns) {
+		return nil
+	}
+
+	if row == 0 {
+		field := columns[column]
+		return tview.NewTableCell(fmt.Sprintf("[::b]%s", t.headers()[column])).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter).
+			SetClickedFunc(func() bool {
+				util.RecordUsage("action:sort")
+				t.cycleSort(field)
+				return true
+			})
+	}
+
+	if row < 0 || row > len(t.visible) {
+		return nil
+	}
+	if column == 0 {
+		return t.nameCell(t.visible[row-1])
+	}
+	return tview.NewTableCell(t.rows[t.visible[row-1]][columns[column]])
+}
+
+// nameCell builds the name cell of the i-th entry. Marked entries start
+// with a "*", encrypted ones with a lock, those with inconsistent headers
+// with a warning sign, tested ones with a check or a cross, and names
+// stored several times get a "(2 of 3)" badge; the cell's reference holds
+// the entry, see cellEntry. Names too long for the table end in an
+// ellipsis, and see displayName for scrolling and basenames.
+func (t *entryTable) nameCell(i int) *tview.TableCell {
+	var prefix, suffix string
+	if t.marked[i] {
+		prefix += palette.highlight + "* [-]"
+	}
+	if intact, ok := t.tested[t.files[i].GetHeaderOffset()]; ok {
+		if intact {
+			prefix += palette.success + "✓[-] "
+		} else {
+			prefix += palette.failure + "✗[-] "
+		}
+	}
+	if _, ok := t.headerIssues[t.files[i].GetHeaderOffset()]; ok {
+		prefix += palette.failure + "⚠[-] "
+	}
+	if t.files[i].IsEncrypted() {
+		prefix += palette.warning + "🔒[-] "
+	}
+	if count := t.counts[t.rows[i][0]]; count > 1 {
+		suffix = fmt.Sprintf(" "+palette.highlight+"(%d of %d)[-]", t.versions[i], count)
+	}
+
+	name := t.displayName(i)
+	if nameWidth := t.layout.nameWidth; nameWidth > 0 {
+		name = truncateWidth(name, max(nameWidth-tview.TaggedStringWidth(prefix+suffix), 1))
+	}
+	return tview.NewTableCell(prefix + tview.Escape(name) + suffix).SetReference(t.files[i]).SetMaxWidth(t.layout.nameWidth)
+}
+
+// cellEntry returns the entry behind a name cell.
+func cellEntry(cell *tview.TableCell) (core.ZippedFile, bool) {
+	zf, ok := cell.GetReference().(core.ZippedFile)
+	return zf, ok
+}
+
+// cellEntryName returns the entry name shown in a name cell, without its
+// duplicate badge.
+func cellEntryName(cell *tview.TableCell) string {
+	if zf, ok := cellEntry(cell); ok {
+		return zf.GetName()
+	}
+	return cell.Text
+}
+
+// GetRowCount returns the number of visible entries plus the header.
+func (t *entryTable) GetRowCount() int {
+	return len(t.visible) + 1
+}
+
+// GetColumnCount returns the number of columns.
+func (t *entryTable) GetColumnCount() int {
+	return len(t.columns())
+}
+
+// ownerID renders a user or group ID, or "-" when the archive has none.
+func ownerID(id int) string {
+	if id < 0 {
+		return "-"
+	}
+	return strconv.Itoa(id)
+}
+
+// markedNames returns the names of the marked entries, in archive order.
+func (t *entryTable) markedNames() []string {
+	var names []string
+	for i, marked := range t.marked {
+		if marked {
+			names = append(names, t.files[i].GetName())
+		}
+	}
+	return names
+}
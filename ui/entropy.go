@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showRandomContent lists full screen the files of the archive whose
+// content looks encrypted or random without being in a known compressed
+// format, see util.FindRandomContent. Esc or q goes back to the browser.
+func showRandomContent(app *tview.Application, layout *tview.Flex, table *tview.Table, fileName, zipPath string) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("Random-looking files in %s", fileName))
+
+	report, err := util.FindRandomContent(zipPath)
+	if err != nil {
+		view.SetText(fmt.Sprintf(palette.failure+"Error: %s[-]\n\n"+palette.muted+"Esc close[-]", tview.Escape(err.Error())))
+	} else {
+		view.SetText(formatRandomContentReport(report))
+	}
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
+
+// formatRandomContentReport renders the suspicious files for the entropy
+// view.
+func formatRandomContentReport(report util.RandomContentReport) string {
+	var b strings.Builder
+
+	if len(report.Entries) == 0 {
+		fmt.Fprintf(&b, palette.success+"No random-looking file among the %d sampled.[-]\n\n", report.Sampled)
+	} else {
+		fmt.Fprintf(&b, "[::b]%d of %d sampled files look encrypted or random[::-] without being in a known compressed format.\n\n",
+			len(report.Entries), report.Sampled)
+
+		for _, e := range report.Entries {
+			how := "compressed"
+			if e.Stored {
+				how = "stored"
+			}
+			fmt.Fprintf(&b, palette.warning+"%.2f bits/byte[-] "+palette.muted+"%-10s %9s[-]  %s\n", e.Entropy, how, util.FormatSize(e.Size), tview.Escape(e.Name))
+		}
+		b.WriteString("\n" + palette.muted + "This is a heuristic: formats it does not know may be compressed by design.[-]\n")
+	}
+	if report.Skipped > 0 {
+		fmt.Fprintf(&b, palette.muted+"%d files skipped: too small, encrypted by the archive or unreadable.[-]\n", report.Skipped)
+	}
+
+	b.WriteString("\n" + palette.muted + "Esc close[-]")
+
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// errorMessage renders err for the status bar, followed by a hint on what
+// to do next for the error kinds the user can act on.
+func errorMessage(err error) string {
+	var hint string
+	switch {
+	case errors.Is(err, util.ErrPathTraversal):
+		hint = " - " + tr("press h, then n to normalize the names")
+	case errors.Is(err, util.ErrEncrypted):
+		hint = " - " + tr("encrypted entries cannot be extracted yet")
+	case errors.Is(err, util.ErrNotZip):
+		hint = " - " + tr("the file may be damaged or not an archive")
+	}
+
+	return fmt.Sprintf(palette.failure+tr("Error: %s")+"%s[-]", tview.Escape(err.Error()), hint)
+}
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// failedEntry is a file an extraction could not write, and why.
+type failedEntry struct {
+	name string
+	err  error
+}
+
+// extractionLog records what an extraction wrote, adding up its size, and
+// the files it failed on.
+type extractionLog struct {
+	util.NopExtractObserver
+	sizes  map[string]uint64
+	total  uint64
+	done   []string
+	failed []failedEntry
+	// written are the files extracted, for the hooks.
+	written []util.ExtractedFile
+	// renamed counts the files written under another name than their
+	// entry's, because the filesystem cannot store it.
+	renamed int
+	// flagged counts the files the content scanner flagged, written or
+	// not as its policy says.
+	flagged int
+}
+
+func (l *extractionLog) OnEntryStart(name string, size uint64) {
+	l.sizes[name] = size
+}
+
+func (l *extractionLog) OnEntryDone(name, path string) {
+	l.total += l.sizes[name]
+	l.done = append(l.done, name)
+	l.written = append(l.written, util.ExtractedFile{Name: name, Path: path})
+}
+
+func (l *extractionLog) OnError(name string, err error) {
+	l.failed = append(l.failed, failedEntry{name: name, err: err})
+}
+
+func (l *extractionLog) OnRename(name, path, reason string) {
+	l.renamed++
+}
+
+func (l *extractionLog) OnFlagged(name, report string) {
+	l.flagged++
+}
+
+// failedNames returns the names of the files the extraction failed on.
+func (l *extractionLog) failedNames() []string {
+	names := make([]string, len(l.failed))
+	for i, f := range l.failed {
+		names[i] = f.name
+	}
+	return names
+}
+
+// showExtractionFailures lists full screen the files an extraction into
+// destDir failed on, with the reason, and those it wrote. 'r' runs retry on
+// the failed files, 'y' copies the errors to the clipboard and Esc or q
+// goes back.
+func showExtractionFailures(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, result *extractionLog, destDir string, retry func()) {
+	slices.Sort(result.done)
+	slices.SortFunc(result.failed, func(a, b failedEntry) int { return cmp.Compare(a.name, b.name) })
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	view.SetBorder(true).SetTitle("Extraction failed")
+	view.SetText(formatExtractionFailures(result, destDir))
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		if ev.Key() != tcell.KeyRune {
+			return ev
+		}
+
+		switch ev.Rune() {
+		case 'r':
+			util.RecordUsage("action:extract-retry")
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			retry()
+		case 'y':
+			util.RecordUsage("action:copy-errors")
+			copyToClipboard(app, extractionErrors(result))
+			status.setMessage(fmt.Sprintf(palette.success+"Copied %d errors[-]", len(result.failed)))
+		default:
+			return ev
+		}
+		return nil
+	})
+
+	app.SetRoot(view, true)
+}
+
+// formatExtractionFailures renders an extraction's result for the failures
+// view.
+func formatExtractionFailures(result *extractionLog, destDir string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s%d of %d files failed[-] to extract into %s.\n\n",
+		palette.failure, len(result.failed), len(result.failed)+len(result.done), tview.Escape(destDir))
+
+	b.WriteString("[::b]Failed[::-]\n")
+	for _, f := range result.failed {
+		fmt.Fprintf(&b, "  %s\n    %s%s[-]\n", tview.Escape(f.name), palette.muted, tview.Escape(f.err.Error()))
+	}
+
+	b.WriteString("\n[::b]Extracted[::-]\n")
+	if len(result.done) == 0 {
+		b.WriteString("  " + palette.muted + "none[-]\n")
+	}
+	for _, name := range result.done {
+		fmt.Fprintf(&b, "  %s\n", tview.Escape(name))
+	}
+
+	b.WriteString("\n" + palette.muted + "r retry the failed files • y copy the errors • Esc close[-]")
+
+	return b.String()
+}
+
+// extractionErrors renders the failures of an extraction as plain text, one
+// file per line.
+func extractionErrors(result *extractionLog) string {
+	var b strings.Builder
+	for _, f := range result.failed {
+		fmt.Fprintf(&b, "%s: %v\n", f.name, f.err)
+	}
+	return b.String()
+}
+
+// copyToClipboard asks the terminal to put text on the system clipboard,
+// with the OSC 52 escape sequence, once the screen is next drawn. Terminals
+// that do not support it ignore it.
+func copyToClipboard(app *tview.Application, text string) {
+	app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		screen.SetClipboard([]byte(text))
+		app.SetAfterDrawFunc(nil)
+	})
+}
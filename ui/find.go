@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// BuildFindUI builds the interface of "gozip find --pick": the ZIP archives
+// under root, listed as the search finds them, see util.FindArchives.
+// Enter opens the selected one in the archive browser.
+//
+// Parameters:
+//   - ctx: stops the search and abandons reading the archive opened;
+//     cancel it when the application stops, as for BuildStreamingUI
+//   - root: the folder to search
+//
+// Returns:
+//   - *tview.Application: configured tview application ready to run
+func BuildFindUI(ctx context.Context, root string) *tview.Application {
+	app := tview.NewApplication()
+
+	layout := showFound(ctx, app, root)
+
+	if !tutorialSeen() {
+		offerTutorial(app, layout)
+	}
+
+	return app
+}
+
+// showFound makes the list of the archives under root the root of app,
+// filling it up in the background, and returns its layout.
+func showFound(ctx context.Context, app *tview.Application, root string) *tview.Flex {
+	header := tview.NewTextView().SetDynamicColors(true)
+	header.SetBackgroundColor(currentTheme.header)
+	header.SetText("[::b]goZip! " + palette.muted + "• Enter open • q exit[-]")
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selection)
+	table.SetBorder(true).SetTitle(tview.Escape(" Archives in " + root + " ")).SetTitleAlign(tview.AlignCenter)
+
+	line := tview.NewTextView().SetDynamicColors(true)
+	notes := newNotifier(app, line)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(header, 1, 0, false).
+		AddItem(table, 0, 1, true).
+		AddItem(line, 1, 0, false)
+
+	search, stopSearch := context.WithCancel(ctx)
+
+	table.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case ev.Key() == tcell.KeyEnter:
+			row, _ := table.GetSelection()
+			path, ok := table.GetCell(row, 0).GetReference().(string)
+			if !ok {
+				return nil
+			}
+			util.RecordUsage("action:find-open")
+			stream, err := util.OpenArchiveStream(path)
+			if err != nil {
+				notes.notify(errorMessage(err), errorTimeout)
+				return nil
+			}
+			openVerified(app, path, func() {
+				stopSearch()
+				showStreamingBrowser(ctx, app, filepath.Base(path), path, stream)
+			}, func() {
+				stream.Close()
+				app.SetRoot(layout, true)
+			})
+		case ev.Key() == tcell.KeyRune && ev.Rune() == 'q':
+			stopSearch()
+			app.Stop()
+		default:
+			return ev
+		}
+		return nil
+	})
+
+	notes.setHint(palette.muted + "Searching...[-]")
+	goSafe(app, func() {
+		found := 0
+		err := util.FindArchives(search, root, func(path string, info fs.FileInfo) {
+			found++
+			count := found
+			app.QueueUpdateDraw(func() {
+				addFound(table, root, path, info)
+				notes.setHint(fmt.Sprintf(palette.muted+"Searching... %d found[-]", count))
+			})
+		})
+		if search.Err() != nil {
+			return
+		}
+
+		app.QueueUpdateDraw(func() {
+			switch {
+			case err != nil:
+				notes.setHint("")
+				notes.notify(errorMessage(err), errorTimeout)
+			case found == 0:
+				notes.setHint(palette.muted + "No archives under " + tview.Escape(root) + "[-]")
+			case found == 1:
+				notes.setHint(palette.muted + "1 archive found[-]")
+			default:
+				notes.setHint(fmt.Sprintf(palette.muted+"%d archives found[-]", found))
+			}
+		})
+	})
+
+	app.SetRoot(layout, true)
+	return layout
+}
+
+// addFound adds an archive found at path to table, as its path inside root,
+// size and modification time.
+func addFound(table *tview.Table, root, path string, info fs.FileInfo) {
+	name := path
+	if rel, err := filepath.Rel(root, path); err == nil {
+		name = rel
+	}
+
+	row := table.GetRowCount()
+	table.SetCell(row, 0, tview.NewTableCell(tview.Escape(name)).SetReference(path).SetExpansion(1))
+	table.SetCell(row, 1, tview.NewTableCell(util.FormatSize(uint64(info.Size()))).SetAlign(tview.AlignRight))
+	table.SetCell(row, 2, tview.NewTableCell(palette.muted+info.ModTime().Local().Format(time.DateTime)+"[-]"))
+	if row == 0 {
+		table.Select(0, 0)
+	}
+}
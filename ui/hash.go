@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showEntryHashes computes the checksums of the marked entries, or of the
+// selected one, with every file inside folders, and lists them full screen.
+// w writes the checksums of the whole archive to a sums file. Esc or q
+// goes back to the browser and stops what is being computed.
+func showEntryHashes(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath string, entries *entryTable) {
+	names := entries.markedNames()
+	if len(names) == 0 {
+		row, _ := table.GetSelection()
+		entry, ok := cellEntry(table.GetCell(row, 0))
+		if !ok {
+			return
+		}
+		names = []string{entry.GetName()}
+	}
+
+	alg := settings.Hash
+	ctx, cancel := context.WithCancel(context.Background())
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("%s checksums in %s", strings.ToUpper(string(alg)), fileName))
+	view.SetText(palette.muted + "Computing...[-]")
+
+	goSafe(app, func() {
+		hashes, err := util.HashEntries(ctx, zipPath, names, alg)
+		app.QueueUpdateDraw(func() {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			view.SetText(formatEntryHashes(hashes, alg, err))
+		})
+	})
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q'):
+			cancel()
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		case ev.Key() == tcell.KeyRune && ev.Rune() == 'w':
+			cancel()
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			exportChecksums(app, status, zipPath, alg)
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
+
+// formatEntryHashes renders checksums for the checksums view, in the
+// format of sha256sum.
+func formatEntryHashes(hashes []util.EntryHash, alg util.HashAlgorithm, err error) string {
+	var b strings.Builder
+
+	if err != nil {
+		fmt.Fprintf(&b, palette.failure+"Error: %s[-]\n", tview.Escape(err.Error()))
+	}
+	for _, h := range hashes {
+		fmt.Fprintf(&b, "%s  %s\n", h.Sum, tview.Escape(h.Name))
+	}
+	fmt.Fprintf(&b, "\n"+palette.muted+"Esc close • w write %s for the whole archive[-]", alg.SumsFileName())
+
+	return b.String()
+}
+
+// exportChecksums writes the checksums of every file of the archive, in the
+// background, to a sums file such as SHA256SUMS in the destination folder,
+// refusing to replace an existing one.
+func exportChecksums(app *tview.Application, status *statusBar, zipPath string, alg util.HashAlgorithm) {
+	dir := settings.DestDir
+	if dir == "" {
+		dir = "."
+	}
+	p, err := filepath.Abs(filepath.Join(dir, alg.SumsFileName()))
+	if err != nil {
+		status.showError(err)
+		return
+	}
+
+	util.RecordUsage("action:export-checksums")
+	status.setMessage(palette.muted + "Computing the checksums...[-]")
+	goSafe(app, func() {
+		hashes, err := util.HashEntries(context.Background(), zipPath, nil, alg)
+		if err == nil {
+			err = writeSumsFile(p, hashes)
+		}
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				status.showError(err)
+				return
+			}
+			status.setMessage(fmt.Sprintf(palette.success+"Wrote %d checksums to %s[-]", len(hashes), p))
+		})
+	})
+}
+
+// writeSumsFile creates the sums file p, which must not exist yet.
+func writeSumsFile(p string, hashes []util.EntryHash) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("'%s' already exists", p)
+	}
+	if err != nil {
+		return err
+	}
+	if err := util.WriteSums(f, hashes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// checkHeaders cross-checks the local headers of the archive against its
+// central directory in the background, in paranoid mode, and flags the
+// inconsistent entries of the browser with a warning badge, see nameCell.
+func checkHeaders(app *tview.Application, zipPath string, entries *entryTable, status *statusBar) {
+	goSafe(app, func() {
+		mismatches, err := util.CheckHeaders(zipPath)
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				status.showError(fmt.Errorf("could not check the headers: %w", err))
+				return
+			}
+			if len(mismatches) == 0 {
+				return
+			}
+
+			entries.headerIssues = make(map[int64]string, len(mismatches))
+			for _, m := range mismatches {
+				entries.headerIssues[m.HeaderOffset] = m.Detail()
+			}
+			status.setMessage(fmt.Sprintf(palette.failure+"%d entries have inconsistent headers; %s shows what differs[-]", len(mismatches), keyOf(actionProperties)))
+		})
+	})
+}
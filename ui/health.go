@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// healthFixKeys maps the one-key fixes offered by the health view.
+var healthFixKeys = map[rune]util.HealthFix{
+	'n': util.FixNormalize,
+	'r': util.FixRecompress,
+	'v': util.FixVacuum,
+}
+
+// showHealthReport analyses the archive and shows the findings full screen,
+// letting the user apply the recommended fixes with a single key. When a fix
+// rewrote the archive the browser is rebuilt on close, otherwise the previous
+// layout is restored.
+func showHealthReport(app *tview.Application, layout *tview.Flex, table *tview.Table, fileName, zipPath string) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("Health of %s", fileName))
+
+	changed := false
+	status := ""
+
+	refresh := func() {
+		report, err := util.CheckHealth(zipPath)
+		if err != nil {
+			view.SetText(fmt.Sprintf(palette.failure+"Error: %s[-]\n\n"+palette.muted+"Esc close[-]", tview.Escape(err.Error())))
+			return
+		}
+		view.SetText(formatHealthReport(report, status))
+	}
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			if changed {
+				if err := reloadBrowser(app, fileName, zipPath, ""); err == nil {
+					return nil
+				}
+			}
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+
+		if ev.Key() != tcell.KeyRune {
+			return ev
+		}
+
+		var fixes []util.HealthFix
+		if ev.Rune() == 'a' {
+			if report, err := util.CheckHealth(zipPath); err == nil {
+				fixes = report.Fixes()
+			}
+		} else if fix, ok := healthFixKeys[ev.Rune()]; ok {
+			fixes = []util.HealthFix{fix}
+		} else {
+			return ev
+		}
+
+		var applied []string
+		failed := false
+		for _, fix := range fixes {
+			util.RecordUsage("action:health-" + string(fix))
+			n, err := util.ApplyHealthFix(zipPath, fix)
+			if err != nil {
+				status = fmt.Sprintf(palette.failure+"%s failed: %s[-]", fix, tview.Escape(err.Error()))
+				failed = true
+				break
+			}
+			changed = true
+			applied = append(applied, fmt.Sprintf("%s (%d entries)", fix, n))
+		}
+		if len(applied) > 0 && !failed {
+			status = palette.success + "Applied " + strings.Join(applied, ", ") + "[-]"
+		}
+
+		refresh()
+		return nil
+	})
+
+	refresh()
+	app.SetRoot(view, true)
+}
+
+// formatHealthReport renders a report for the health view.
+func formatHealthReport(report util.HealthReport, status string) string {
+	var b strings.Builder
+
+	color := "green"
+	switch {
+	case report.Score < 50:
+		color = "red"
+	case report.Score < 90:
+		color = "yellow"
+	}
+
+	fmt.Fprintf(&b, "[::b]Score: [%s]%d/100[-][::-]  %d entries, %d bytes, %d wasted\n\n", color, report.Score, report.Entries, report.ArchiveSize, report.WastedBytes)
+
+	if len(report.Issues) == 0 {
+		b.WriteString("No issues found.\n")
+	}
+	for _, issue := range report.Issues {
+		entry := ""
+		if issue.Entry != "" {
+			entry = tview.Escape(issue.Entry) + ": "
+		}
+		fmt.Fprintf(&b, "  "+palette.highlight+"%-16s[-] %s%s\n", issue.Kind, entry, tview.Escape(issue.Detail))
+	}
+
+	b.WriteString("\n")
+	if status != "" {
+		b.WriteString(status + "\n\n")
+	}
+
+	hints := []string{}
+	for _, fix := range report.Fixes() {
+		switch fix {
+		case util.FixNormalize:
+			hints = append(hints, "n normalize")
+		case util.FixRecompress:
+			hints = append(hints, "r recompress")
+		case util.FixVacuum:
+			hints = append(hints, "v vacuum")
+		}
+	}
+	if len(hints) > 1 {
+		hints = append(hints, "a apply all")
+	}
+	hints = append(hints, "Esc close")
+
+	b.WriteString(palette.muted + strings.Join(hints, " • ") + "[-]")
+
+	return b.String()
+}
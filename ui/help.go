@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showKeyHelp draws a scrollable list of every key binding over the browser.
+// The list is built from browserKeys, so it always matches the handlers.
+// Esc, q or ? closes it.
+func showKeyHelp(app *tview.Application, layout *tview.Flex, table *tview.Table) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	view.SetBorder(true).SetTitle(tr("Keys"))
+	view.SetText(formatKeyHelp())
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && (ev.Rune() == 'q' || ev.Rune() == '?')) {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		return ev
+	})
+
+	// The window takes the middle three quarters of the screen, leaving
+	// the browser visible around it.
+	window := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(view, 0, 6, true).
+			AddItem(nil, 0, 1, false), 0, 6, true).
+		AddItem(nil, 0, 1, false)
+
+	pages := tview.NewPages().
+		AddPage("browser", layout, true, true).
+		AddPage("help", window, true, true)
+
+	app.SetRoot(pages, true)
+}
+
+// formatKeyHelp renders the keymap for the help screen: navigation first,
+// then the actions in browserKeys order.
+func formatKeyHelp() string {
+	var b strings.Builder
+
+	b.WriteString("[::b]" + tr("Navigation") + "[::-]\n")
+	for _, binding := range navigationKeys {
+		writeKeyHelp(&b, binding)
+	}
+
+	b.WriteString("\n[::b]" + tr("Actions") + "[::-]\n")
+	for _, binding := range browserKeys {
+		writeKeyHelp(&b, binding)
+	}
+
+	b.WriteString("\n" + palette.muted + tr("Keys marked * also work during the tutorial. Up/Down scroll, Esc close") + "[-]")
+
+	return b.String()
+}
+
+// writeKeyHelp writes one line of the help screen, with the action's name
+// as config.toml spells it.
+func writeKeyHelp(b *strings.Builder, binding keyBinding) {
+	mark := " "
+	if binding.action != "" && binding.scope != scopeBrowser {
+		mark = "*"
+	}
+	keys := tview.Escape(strings.Join(binding.keys, ", "))
+	if len(binding.keys) == 0 {
+		keys = tr("(unbound)")
+	}
+	fmt.Fprintf(b, " "+palette.highlight+"%-16s[-]%s "+palette.muted+"%-14s[-] %s\n", keys, mark, binding.action, tr(binding.help))
+}
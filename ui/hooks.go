@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// runExtractHooks runs the configured hooks on the files an extraction
+// wrote, in the background so a slow command does not freeze the browser.
+// Each command and its output go to the message log.
+func runExtractHooks(app *tview.Application, status *statusBar, destDir string, files []util.ExtractedFile) {
+	if settings.Hooks.IsEmpty() || len(files) == 0 {
+		return
+	}
+
+	goSafe(app, func() {
+		results, err := util.RunExtractHooks(context.Background(), settings.Hooks, destDir, files)
+		app.QueueUpdateDraw(func() {
+			for _, r := range results {
+				text := palette.muted + "Hook: " + tview.Escape(r.Command) + "[-]"
+				if output := strings.TrimSpace(r.Output); output != "" {
+					text += "\n" + tview.Escape(output)
+				}
+				status.setMessage(text)
+			}
+			if err != nil {
+				status.showError(err)
+				return
+			}
+			status.setMessage(fmt.Sprintf(palette.success+"Ran %d hooks; l shows their output[-]", len(results)))
+		})
+	})
+}
@@ -0,0 +1,22 @@
+package ui
+
+import "github.com/cainlara/gozip/util"
+
+// language is the language of the UI, set by Configure.
+var language = util.LanguageEnglish
+
+// catalogs holds the translations of the UI's strings by language. They
+// are keyed by the English text, which is what the code is written in, so
+// English needs no catalog and a string missing from one stays in English.
+var catalogs = map[util.Language]map[string]string{
+	util.LanguageSpanish: spanishMessages,
+}
+
+// tr translates msg, a text or a fmt format of the UI written in English,
+// into the configured language.
+func tr(msg string) string {
+	if translated, ok := catalogs[language][msg]; ok {
+		return translated
+	}
+	return msg
+}
@@ -0,0 +1,215 @@
+package ui
+
+// spanishMessages translates the UI into Spanish. Formats keep the verbs of
+// the English text, in the same order.
+var spanishMessages = map[string]string{
+	// Column titles.
+	"NAME":        "NOMBRE",
+	"IS FOLDER":   "CARPETA",
+	"SIZE":        "TAMAÑO",
+	"MODIFIED ON": "MODIFICADO",
+	"CRC":         "CRC",
+	"MODE":        "MODO",
+	"UID":         "UID",
+	"GID":         "GID",
+
+	// Header hints.
+	"select":        "elegir",
+	"extract":       "extraer",
+	"extract all":   "extraer todo",
+	"filter":        "filtrar",
+	"mark":          "marcar",
+	"rename/move":   "renombrar/mover",
+	"replace":       "reemplazar",
+	"delete":        "borrar",
+	"health":        "salud",
+	"duplicates":    "duplicados",
+	"sizes":         "tamaños",
+	"info":          "info",
+	"properties":    "propiedades",
+	"owner columns": "columnas de dueño",
+	"compare":       "comparar",
+	"diff file":     "comparar archivo",
+	"two panes":     "dos paneles",
+	"help":          "ayuda",
+	"exit":          "salir",
+
+	// Help of the keys.
+	"select the previous or next entry": "elegir la entrada anterior o la siguiente",
+	"move one page up or down":          "subir o bajar una página",
+	"select the first or last entry":    "elegir la primera o la última entrada",
+	"jump to the next entry starting with the letters typed; hold Alt for letters bound to an action": "saltar a la siguiente entrada que empieza con las letras tecleadas; con Alt para las letras asignadas a una acción",
+	"extract the selected file, or the selected folder with its contents":                             "extraer el archivo elegido, o la carpeta elegida con su contenido",
+	"extract the whole archive":                                                                                         "extraer el archivo comprimido entero",
+	"filter the entries by name; Enter keeps the filter, Esc clears it":                                                 "filtrar las entradas por nombre; Enter conserva el filtro, Esc lo borra",
+	"mark or unmark the selected entry and move to the next one":                                                        "marcar o desmarcar la entrada elegida y pasar a la siguiente",
+	"mark every entry the filter shows":                                                                                 "marcar todas las entradas que muestra el filtro",
+	"mark the entries the filter shows that are not marked, and unmark those that are":                                  "marcar las entradas que muestra el filtro que no están marcadas, y desmarcar las que sí",
+	"mark every entry matching a glob, or a /regular expression/, shown or not":                                         "marcar todas las entradas que coinciden con un glob, o una /expresión regular/, se muestren o no",
+	"unmark every entry, shown or not":                                                                                  "desmarcar todas las entradas, se muestren o no",
+	"save the names of the marked entries to a file, one per line":                                                      "guardar los nombres de las entradas marcadas en un archivo, uno por línea",
+	"mark the entries named in a file, one per line, such as a saved list":                                              "marcar las entradas nombradas en un archivo, una por línea, como una lista guardada",
+	"extract the marked entries":                                                                                        "extraer las entradas marcadas",
+	"rename or move the selected entry":                                                                                 "renombrar o mover la entrada elegida",
+	"replace the selected file with a file from disk":                                                                   "reemplazar el archivo elegido por un archivo del disco",
+	"delete the selected entry, after confirmation":                                                                     "borrar la entrada elegida, tras confirmar",
+	"check the archive for problems and fix them":                                                                       "buscar problemas en el archivo comprimido y corregirlos",
+	"find files that look encrypted or random without being in a compressed format":                                     "encontrar archivos que parecen cifrados o aleatorios sin estar en un formato comprimido",
+	"find files stored more than once":                                                                                  "encontrar archivos guardados más de una vez",
+	"show the largest files and the totals per extension, method and folder":                                            "mostrar los archivos más grandes y los totales por extensión, método y carpeta",
+	"show the archive summary and edit its comment; Tab shows the manifest of a JAR or APK":                             "mostrar el resumen del archivo comprimido y editar su comentario; Tab muestra el manifiesto de un JAR o APK",
+	"show everything recorded about the selected entry; r there dumps its raw headers":                                  "mostrar todo lo registrado sobre la entrada elegida; r allí vuelca sus cabeceras en crudo",
+	"show or hide the mode, UID and GID columns":                                                                        "mostrar u ocultar las columnas de modo, UID y GID",
+	"compare the archive with another one":                                                                              "comparar el archivo comprimido con otro",
+	"compare the selected file with a file on disk":                                                                     "comparar el archivo elegido con un archivo del disco",
+	"show the checksums of the selected entry or the marked ones, and export them for the whole archive":                "mostrar las sumas de comprobación de la entrada elegida o de las marcadas, y exportarlas para todo el archivo comprimido",
+	"test the selected file: decompress it without writing anything and check its CRC-32":                               "probar el archivo elegido: descomprimirlo sin escribir nada y comprobar su CRC-32",
+	"show the recent messages, such as extraction results and errors":                                                   "mostrar los mensajes recientes, como resultados de extracciones y errores",
+	"open the selected file in the pager, an editor or the default application; edits can be saved back":                "abrir el archivo elegido en el paginador, un editor o la aplicación predeterminada; los cambios pueden guardarse de vuelta",
+	"open the folder the last extraction wrote into in the file manager":                                                "abrir en el gestor de archivos la carpeta donde escribió la última extracción",
+	"copy the content of the selected file, a small text file, to the clipboard":                                        "copiar al portapapeles el contenido del archivo elegido, un archivo de texto pequeño",
+	"copy the name of the selected entry, its path inside the archive, to the clipboard":                                "copiar al portapapeles el nombre de la entrada elegida, su ruta dentro del archivo comprimido",
+	"copy the path Enter would extract the selected entry to":                                                           "copiar la ruta donde Enter extraería la entrada elegida",
+	"read the archive again, keeping the filter and the selected entry":                                                 "volver a leer el archivo comprimido, conservando el filtro y la entrada elegida",
+	"scroll the names back to their start":                                                                              "desplazar los nombres de vuelta a su comienzo",
+	"scroll the names to see the end of long ones":                                                                      "desplazar los nombres para ver el final de los largos",
+	"show names without their folder, which the title shows for the selected entry, or in full":                         "mostrar los nombres sin su carpeta, que el título muestra para la entrada elegida, o completos",
+	"show the names as the archive stores them, with the backslashes old Windows tools wrote for slashes, or as listed": "mostrar los nombres como los guarda el archivo comprimido, con las barras invertidas que escribían viejas herramientas de Windows en lugar de barras, o como se listan",
+	"list the entries sorted by path, folder by folder, or in the order the archive stores them":                        "listar las entradas ordenadas por ruta, carpeta por carpeta, o en el orden en que las guarda el archivo comprimido",
+	"show sizes in bytes and CRCs as decimal numbers, or back in hex and the configured size format":                    "mostrar los tamaños en bytes y los CRC como números decimales, o de nuevo en hexadecimal y con el formato de tamaño configurado",
+	"show or hide a folder of the disk next to the archive":                                                             "mostrar u ocultar una carpeta del disco junto al archivo comprimido",
+	"move between the archive and the disk pane":                                                                        "pasar del archivo comprimido al panel del disco y viceversa",
+	"copy to the other pane: extract the selected entry into the disk pane's folder, or add the file or folder selected on disk to the archive, in the folder of the selected entry": "copiar al otro panel: extraer la entrada elegida en la carpeta del panel del disco, o añadir al archivo comprimido el archivo o la carpeta elegidos en el disco, en la carpeta de la entrada elegida",
+	"open the command palette: type part of any action's name or description, Enter runs it":                                                                                         "abrir la paleta de comandos: teclear parte del nombre o la descripción de cualquier acción; Enter la ejecuta",
+	"show this help":     "mostrar esta ayuda",
+	"leave the tutorial": "salir del tutorial",
+	"quit goZip":         "cerrar goZip",
+
+	// Help screen and command palette.
+	"Keys":       "Teclas",
+	"Navigation": "Navegación",
+	"Actions":    "Acciones",
+	"Keys marked * also work during the tutorial. Up/Down scroll, Esc close": "Las teclas marcadas con * también funcionan durante el tutorial. Arriba/Abajo desplazan, Esc cierra",
+	"(unbound)": "(sin asignar)",
+	"Commands":  "Comandos",
+	"Up/Down pick • Enter run • Esc close": "Arriba/Abajo eligen • Enter ejecuta • Esc cierra",
+	"sort by %s": "ordenar por %s",
+	"sort the entries by %s; again to reverse the order, a third time to go back to archive order": "ordenar las entradas por %s; otra vez para invertir el orden, una tercera para volver al orden del archivo comprimido",
+	"archive order": "orden del archivo comprimido",
+	"list the entries in the order the archive stores them": "listar las entradas en el orden en que las guarda el archivo comprimido",
+
+	// Status bar and messages.
+	"%d entries":                                "%d entradas",
+	"1 entry":                                   "1 entrada",
+	"%d match":                                  "%d coinciden",
+	"%d marked, %s":                             "%d marcadas, %s",
+	"%s, %s compressed (%.1f%%)":                "%s, %s comprimido (%.1f%%)",
+	"encrypted entries":                         "entradas cifradas",
+	"1 duplicate name":                          "1 nombre duplicado",
+	"%d duplicate names":                        "%d nombres duplicados",
+	"comment: %s":                               "comentario: %s",
+	"Loading %d of %d entries...":               "Cargando %d de %d entradas...",
+	"Error after %d of %d entries: %s":          "Error tras %d de %d entradas: %s",
+	"Error: %s":                                 "Error: %s",
+	"press h, then n to normalize the names":    "pulsa h, luego n para normalizar los nombres",
+	"encrypted entries cannot be extracted yet": "las entradas cifradas aún no pueden extraerse",
+	"the file may be damaged or not an archive": "el archivo puede estar dañado o no ser un archivo comprimido",
+	"Ignored data around the archive: see %s":   "Se ignoraron datos alrededor del archivo: ver %s",
+	"Messages":                                  "Mensajes",
+	"No messages yet.":                          "Todavía no hay mensajes.",
+	"Esc close":                                 "Esc cierra",
+	"Filter: ":                                  "Filtro: ",
+	"Reloaded":                                  "Recargado",
+	"Sorted by path":                            "Ordenado por ruta",
+	"In archive order":                          "En el orden del archivo comprimido",
+	"Testing %s...":                             "Probando %s...",
+	"%s is intact: %s match CRC-32 %08x":        "%s está intacto: %s coinciden con el CRC-32 %08x",
+	"%d of %d files failed to extract":          "%d de %d archivos no se pudieron extraer",
+	"%s opens the folder":                       "%s abre la carpeta",
+	"%d renamed to names this system accepts":   "%d renombrados con nombres que este sistema acepta",
+	"%d flagged by the scanner":                 "%d señalados por el analizador",
+	"Extracted folder: %d files, %s":            "Carpeta extraída: %d archivos, %s",
+	"Extracted %d files, %s":                    "%d archivos extraídos, %s",
+	"Marked %d entries from %s":                 "%d entradas marcadas desde %s",
+	"Marked %d entries; %d names not found":     "%d entradas marcadas; %d nombres no encontrados",
+	"Signed by %s":                              "Firmado por %s",
+	"The signature is %s; %s shows it":          "La firma es %s; %s la muestra",
+	"Saved %d names to %s":                      "%d nombres guardados en %s",
+	"Marked %d entries matching %s":             "%d entradas marcadas que coinciden con %s",
+	"Nothing is marked; %s marks an entry":      "No hay nada marcado; %s marca una entrada",
+	"Kept the existing %s":                      "Se conservó el %s existente",
+	"Extracted: %s":                             "Extraído: %s",
+	"Deleted %d entries":                        "%d entradas borradas",
+	"Renamed %d entries":                        "%d entradas renombradas",
+	"Replaced %s":                               "%s reemplazado",
+	"Added %d entries":                          "%d entradas añadidas",
+
+	// Relative dates.
+	"just now":   "ahora mismo",
+	"%d minute":  "%d minuto",
+	"%d minutes": "%d minutos",
+	"%d hour":    "%d hora",
+	"%d hours":   "%d horas",
+	"%d day":     "%d día",
+	"%d days":    "%d días",
+	"%d month":   "%d mes",
+	"%d months":  "%d meses",
+	"%d year":    "%d año",
+	"%d years":   "%d años",
+	"%s ago":     "hace %s",
+	"in %s":      "dentro de %s",
+
+	// Signature statuses.
+	"good":        "buena",
+	"bad":         "mala",
+	"expired":     "caducada",
+	"revoked":     "revocada",
+	"unknown key": "de clave desconocida",
+
+	// Dialogs.
+	"Yes":           "Sí",
+	"No":            "No",
+	"Cancel":        "Cancelar",
+	"Flatten":       "Aplanar",
+	"Patterns":      "Patrones",
+	"Preview":       "Vista previa",
+	"Here":          "Aquí",
+	"Into %s":       "En %s",
+	"First":         "Primera",
+	"Last":          "Última",
+	"Delete":        "Borrar",
+	"Add":           "Añadir",
+	"This one (%d)": "Esta (%d)",
+	"Rename":        "Renombrar",
+	"and %d more":   "y %d más",
+	"These names differ only in case, and the destination would keep only one of each:\n\n%s\n\nWrite the later ones as name.1, name.2...?": "Estos nombres solo difieren en mayúsculas y minúsculas, y el destino conservaría solo uno de cada grupo:\n\n%s\n\n¿Escribir los siguientes como nombre.1, nombre.2...?",
+	"Extract folder '%s' and all its contents?\n\nThis will extract all files within this folder recursively.":                              "¿Extraer la carpeta '%s' y todo su contenido?\n\nSe extraerán todos los archivos de esta carpeta, recursivamente.",
+	"Extract %s":                        "Extraer %s",
+	"Patterns (!excludes): ":            "Patrones (!exclusiones): ",
+	"Mark by pattern":                   "Marcar por patrón",
+	"Save the marked names":             "Guardar los nombres marcados",
+	"To file: ":                         "En el archivo: ",
+	"Mark the entries in a list":        "Marcar las entradas de una lista",
+	"From file: ":                       "Desde el archivo: ",
+	"Glob or /regexp/: ":                "Glob o /regexp/: ",
+	"the current directory":             "el directorio actual",
+	"Extract everything in %s into %s?": "¿Extraer todo el contenido de %s en %s?",
+	"Extract everything in %s into a new folder '%s/' in %s?\n\nThis keeps its top-level entries together instead of spreading them over %s.": "¿Extraer todo el contenido de %s en una carpeta nueva '%s/' en %s?\n\nAsí sus entradas de primer nivel quedan juntas en vez de repartirse por %s.",
+	"Warning: %s to write but only %s free. Free some space first.":                                                                           "Atención: hay que escribir %s pero solo quedan %s libres. Libera espacio primero.",
+	"Total size: %s.": "Tamaño total: %s.",
+	"The signature of %s is %s (%s).\n\nIt may have been modified since it was signed. Open it anyway?": "La firma de %s es %s (%s).\n\nPuede haberse modificado después de firmarse. ¿Abrirlo de todos modos?",
+	"The signature of %s could not be checked:\n\n%s\n\nOpen it anyway?":                                "No se pudo comprobar la firma de %s:\n\n%s\n\n¿Abrirlo de todos modos?",
+	"Open anyway":                     "Abrir de todos modos",
+	"Checking the signature of %s...": "Comprobando la firma de %s...",
+	"Esc cancel":                      "Esc cancelar",
+	"Delete '%s' from %s?\n\nThe archive will be rewritten without it.":                               "¿Borrar '%s' de %s?\n\nEl archivo comprimido se reescribirá sin él.",
+	"Delete folder '%s' and all its contents from %s?\n\nThe archive will be rewritten without them.": "¿Borrar la carpeta '%s' y todo su contenido de %s?\n\nEl archivo comprimido se reescribirá sin ellos.",
+	"Rename / move": "Renombrar / mover",
+	"New name: ":    "Nombre nuevo: ",
+	"Replace %s":    "Reemplazar %s",
+	"With file: ":   "Con el archivo: ",
+	"'%s' is stored %d times in the archive, usually because it was updated by appending.\n\nWhich version do you want to extract?": "'%s' está guardado %d veces en el archivo comprimido, normalmente porque se actualizó añadiendo al final.\n\n¿Qué versión quieres extraer?",
+	"folder '%s' and all its contents": "la carpeta '%s' y todo su contenido",
+	"%s in %s":                         "%s en %s",
+	"Add %s to %s?\n\nThe archive will be rewritten with it. Files with the same name are replaced.": "¿Añadir %s a %s?\n\nEl archivo comprimido se reescribirá con ello. Los archivos con el mismo nombre se reemplazan.",
+}
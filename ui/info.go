@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/core"
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showArchiveInfo shows the summary of the archive full screen, with its
+// whole comment, which distributions often use for release notes or
+// signatures. 'e' edits the comment; when it was changed the browser is
+// rebuilt on close, otherwise the previous layout is restored. For JARs and
+// APKs, Tab switches to a second tab with the manifest and the signers, see
+// formatPackageInfo.
+func showArchiveInfo(app *tview.Application, layout *tview.Flex, table *tview.Table, fileName, zipPath string) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("About %s", fileName))
+
+	changed := false
+	status := ""
+	var comment string
+	isPackage, packageTab := false, false
+
+	refresh := func() {
+		info, err := util.ReadArchiveInfo(zipPath)
+		if err != nil {
+			view.SetText(fmt.Sprintf(palette.failure+"Error: %s[-]\n\n"+palette.muted+"Esc close[-]", tview.Escape(err.Error())))
+			return
+		}
+		comment = info.Comment
+
+		pkg, ok, pkgErr := util.ReadPackageInfo(zipPath)
+		isPackage = ok
+		if !packageTab || !isPackage {
+			packageTab = false
+			view.SetTitle(fmt.Sprintf("About %s", fileName))
+			text := formatArchiveInfo(info, formatSignature(zipPath), status)
+			if isPackage {
+				text += palette.muted + " • Tab " + strings.ToLower(string(pkg.Kind)) + "[-]"
+			}
+			view.SetText(text)
+			return
+		}
+
+		view.SetTitle(fmt.Sprintf("%s %s", pkg.Kind, fileName))
+		if pkgErr != nil {
+			view.SetText(fmt.Sprintf(palette.failure+"Error: %s[-]\n\n", tview.Escape(pkgErr.Error())) + palette.muted + "Tab archive • Esc close[-]")
+			return
+		}
+		view.SetText(formatPackageInfo(pkg) + "\n" + palette.muted + "Tab archive • Esc close[-]")
+	}
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			if changed {
+				if err := reloadBrowser(app, fileName, zipPath, ""); err == nil {
+					return nil
+				}
+			}
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+
+		if ev.Key() == tcell.KeyTab && isPackage {
+			packageTab = !packageTab
+			refresh()
+			view.ScrollToBeginning()
+			return nil
+		}
+
+		if ev.Key() == tcell.KeyRune && ev.Rune() == 'e' && !packageTab {
+			editComment(app, fileName, comment, func(text string, ok bool) {
+				if ok && text != comment {
+					util.RecordUsage("action:comment")
+					if err := util.SetArchiveComment(zipPath, text); err != nil {
+						status = fmt.Sprintf(palette.failure+"Saving the comment failed: %s[-]", tview.Escape(err.Error()))
+					} else {
+						changed = true
+						status = palette.success + "Comment saved[-]"
+					}
+				}
+				refresh()
+				app.SetRoot(view, true)
+			})
+			return nil
+		}
+
+		return ev
+	})
+
+	refresh()
+	app.SetRoot(view, true)
+}
+
+// editComment lets the user edit a multi-line comment; Ctrl+S saves it and
+// Esc cancels.
+func editComment(app *tview.Application, fileName, comment string, done func(text string, ok bool)) {
+	area := tview.NewTextArea().SetText(comment, true)
+	area.SetBorder(true).
+		SetTitle(fmt.Sprintf("Comment of %s - Ctrl+S save, Esc cancel", fileName)).
+		SetTitleAlign(tview.AlignCenter)
+
+	area.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch ev.Key() {
+		case tcell.KeyCtrlS:
+			done(area.GetText(), true)
+			return nil
+		case tcell.KeyEscape:
+			done("", false)
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(area, true)
+	app.SetFocus(area)
+}
+
+// formatArchiveInfo renders the summary of an archive for the info view,
+// with signature as formatSignature renders it.
+func formatArchiveInfo(info core.ArchiveInfo, signature, status string) string {
+	var b strings.Builder
+
+	format := info.Format
+	if info.Zip64 {
+		format += " (Zip64)"
+	}
+	encrypted := "no"
+	if info.Encrypted {
+		encrypted = "yes"
+	}
+
+	fmt.Fprintf(&b, "[::b]Format:[::-]     %s\n", format)
+	fmt.Fprintf(&b, "[::b]Entries:[::-]    %d\n", info.Entries)
+	fmt.Fprintf(&b, "[::b]Size:[::-]       %s\n", util.FormatSize(info.Size))
+	fmt.Fprintf(&b, "[::b]Compressed:[::-] %s (%.1f%%)\n", util.FormatSize(info.CompressedSize), 100*info.Ratio())
+	fmt.Fprintf(&b, "[::b]Encrypted:[::-]  %s\n", encrypted)
+	if info.Prepended > 0 {
+		fmt.Fprintf(&b, "[::b]Offset:[::-]     "+palette.warning+"%d (%s of other data before the archive)[-]\n", info.Prepended, util.FormatSize(uint64(info.Prepended)))
+	}
+	if info.Trailing > 0 {
+		fmt.Fprintf(&b, "[::b]Trailing:[::-]   "+palette.warning+"%d bytes (%s of other data after the archive)[-]\n", info.Trailing, util.FormatSize(uint64(info.Trailing)))
+	}
+	fmt.Fprintf(&b, "[::b]Signature:[::-]  %s\n", signature)
+	b.WriteString("\n")
+
+	if info.Comment == "" {
+		b.WriteString(palette.muted + "No comment.[-]\n")
+	} else {
+		b.WriteString("[::b]Comment:[::-]\n")
+		b.WriteString(tview.Escape(info.Comment))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if status != "" {
+		b.WriteString(status + "\n\n")
+	}
+	b.WriteString(palette.muted + "e edit comment • Esc close[-]")
+
+	return b.String()
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/core"
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showEntryProperties shows everything the archive records about the
+// selected entry full screen: sizes, method, time, Unix permissions and
+// owner, encryption and flags, comment and where its header starts. r
+// switches to the raw headers, see formatRawHeader. Esc or q goes back.
+func showEntryProperties(app *tview.Application, layout *tview.Flex, table *tview.Table, entries *entryTable, zipPath string) {
+	row, _ := table.GetSelection()
+	zf, ok := entries.entryAt(row)
+	if !ok {
+		return
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	title := fmt.Sprintf("Properties of %s", tview.Escape(zf.GetName()))
+	properties := formatEntryProperties(zf, entries.headerIssues[zf.GetHeaderOffset()])
+	view.SetBorder(true).SetTitle(title)
+	view.SetText(properties)
+
+	raw := false
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		if ev.Key() == tcell.KeyRune && ev.Rune() == 'r' {
+			raw = !raw
+			if !raw {
+				view.SetTitle(title)
+				view.SetText(properties).ScrollToBeginning()
+				return nil
+			}
+			util.RecordUsage("action:raw-headers")
+			var text string
+			if h, err := util.ReadRawHeader(zipPath, zf.GetHeaderOffset()); err != nil {
+				text = palette.failure + tview.Escape("Cannot read the headers: "+err.Error()) + "[-]\n\n" + palette.muted + "Esc close • r properties[-]"
+			} else {
+				text = formatRawHeader(h)
+			}
+			view.SetTitle(fmt.Sprintf("Raw headers of %s", tview.Escape(zf.GetName())))
+			view.SetText(text).ScrollToBeginning()
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
+
+// formatEntryProperties renders an entry for the properties view;
+// headerIssue, when not empty, tells how its local header disagrees with
+// the central directory.
+func formatEntryProperties(zf core.ZippedFile, headerIssue string) string {
+	var b strings.Builder
+
+	kind := "file"
+	if zf.IsDir() {
+		kind = "folder"
+	}
+	encrypted := "no"
+	if zf.IsEncrypted() {
+		encrypted = "yes, so goZip cannot extract or open it yet"
+	}
+	flags := "none"
+	if names := util.DescribeFlags(zf.GetFlags()); len(names) > 0 {
+		flags = strings.Join(names, ", ")
+	}
+
+	fmt.Fprintf(&b, "[::b]Name:[::-]       %s\n", tview.Escape(zf.GetName()))
+	fmt.Fprintf(&b, "[::b]Kind:[::-]       %s\n", kind)
+	fmt.Fprintf(&b, "[::b]Size:[::-]       %s (%d bytes)\n", util.FormatSize(zf.GetSize()), zf.GetSize())
+	fmt.Fprintf(&b, "[::b]Compressed:[::-] %s, %s\n", util.FormatSize(zf.GetCompressedSize()), zf.GetMethod())
+	fmt.Fprintf(&b, "[::b]Modified:[::-]   %s\n", entryTime(zf.GetModified()))
+	if !zf.GetAccessed().IsZero() {
+		fmt.Fprintf(&b, "[::b]Accessed:[::-]   %s\n", entryTime(zf.GetAccessed()))
+	}
+	if !zf.GetCreated().IsZero() {
+		fmt.Fprintf(&b, "[::b]Created:[::-]    %s\n", entryTime(zf.GetCreated()))
+	}
+	fmt.Fprintf(&b, "[::b]Mode:[::-]       %s\n", zf.GetMode())
+	if zf.GetUID() < 0 {
+		b.WriteString("[::b]Owner:[::-]      not recorded\n")
+	} else {
+		fmt.Fprintf(&b, "[::b]Owner:[::-]      uid %d, gid %d\n", zf.GetUID(), zf.GetGID())
+	}
+	fmt.Fprintf(&b, "[::b]CRC-32:[::-]     %08x\n", zf.GetCrc())
+	fmt.Fprintf(&b, "[::b]Encrypted:[::-]  %s\n", encrypted)
+	fmt.Fprintf(&b, "[::b]Flags:[::-]      0x%04x: %s\n", zf.GetFlags(), flags)
+	fmt.Fprintf(&b, "[::b]Offset:[::-]     %d\n", zf.GetHeaderOffset())
+	if headerIssue != "" {
+		fmt.Fprintf(&b, "[::b]Headers:[::-]    "+palette.failure+"%s[-]\n", headerIssue)
+	}
+	b.WriteString("\n")
+
+	if zf.GetComment() != "" {
+		b.WriteString("[::b]Comment:[::-]\n")
+		b.WriteString(tview.Escape(zf.GetComment()))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(palette.muted + "Esc close • r raw headers[-]")
+
+	return b.String()
+}
+
+// formatRawHeader renders the headers of an entry as stored, for the raw
+// view of the properties: versions, the fixed part of the local header and
+// both extra fields, block by block, in hex.
+func formatRawHeader(h util.RawHeader) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[::b]Offset:[::-]          %d (0x%x)\n", h.HeaderOffset, h.HeaderOffset)
+	fmt.Fprintf(&b, "[::b]Version made by:[::-] %d (%s)\n", h.VersionMadeBy, util.DescribeVersionMadeBy(h.VersionMadeBy))
+	fmt.Fprintf(&b, "[::b]Version needed:[::-]  %d (%s)", h.VersionNeeded, util.DescribeVersion(h.VersionNeeded))
+	if h.LocalVersionNeeded != h.VersionNeeded {
+		fmt.Fprintf(&b, palette.warning+", %d in the local header[-]", h.LocalVersionNeeded)
+	}
+	b.WriteString("\n\n[::b]Local header:[::-]\n")
+	b.WriteString(tview.Escape(hex.Dump(h.Local)))
+
+	for _, extra := range []struct {
+		where string
+		data  []byte
+	}{{"central directory", h.CentralExtra}, {"local header", h.LocalExtra}} {
+		fmt.Fprintf(&b, "\n[::b]Extra field, %s:[::-] %d bytes\n", extra.where, len(extra.data))
+		fields, rest := util.SplitExtra(extra.data)
+		for _, f := range fields {
+			fmt.Fprintf(&b, "0x%04x %s, %d bytes\n", f.ID, f.Name(), len(f.Data))
+			b.WriteString(tview.Escape(hex.Dump(f.Data)))
+		}
+		if len(rest) > 0 {
+			fmt.Fprintf(&b, palette.warning+"%d bytes left over[-]\n", len(rest))
+			b.WriteString(tview.Escape(hex.Dump(rest)))
+		}
+	}
+
+	b.WriteString("\n" + palette.muted + "Esc close • r properties[-]")
+
+	return b.String()
+}
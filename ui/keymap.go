@@ -0,0 +1,332 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// browserAction names something the archive browser does on a key press.
+type browserAction string
+
+const (
+	actionExtract       browserAction = "extract"
+	actionExtractAll    browserAction = "extract-all"
+	actionFilter        browserAction = "filter"
+	actionMark          browserAction = "mark"
+	actionMarkAll       browserAction = "mark-all"
+	actionInvertMarks   browserAction = "invert-marks"
+	actionMarkPattern   browserAction = "mark-pattern"
+	actionClearMarks    browserAction = "clear-marks"
+	actionExtractMarked browserAction = "extract-marked"
+	actionSaveMarks     browserAction = "save-marks"
+	actionLoadMarks     browserAction = "load-marks"
+	actionRename        browserAction = "rename"
+	actionReplace       browserAction = "replace"
+	actionDelete        browserAction = "delete"
+	actionHealth        browserAction = "health"
+	actionDuplicates    browserAction = "duplicates"
+	actionEntropy       browserAction = "entropy"
+	actionSizes         browserAction = "sizes"
+	actionInfo          browserAction = "info"
+	actionProperties    browserAction = "properties"
+	actionOwnerColumns  browserAction = "owner-columns"
+	actionDiff          browserAction = "diff"
+	actionCompareEntry  browserAction = "compare-entry"
+	actionHash          browserAction = "hash"
+	actionTestEntry     browserAction = "test-entry"
+	actionMessages      browserAction = "messages"
+	actionOpenWith      browserAction = "open-with"
+	actionOpenFolder    browserAction = "open-folder"
+	actionReload        browserAction = "reload"
+	actionPalette       browserAction = "palette"
+	actionScrollLeft    browserAction = "scroll-left"
+	actionScrollRight   browserAction = "scroll-right"
+	actionBasenames     browserAction = "basenames"
+	actionStoredNames   browserAction = "stored-names"
+	actionRawNumbers    browserAction = "raw-numbers"
+	actionPathOrder     browserAction = "path-order"
+	actionTwoPanes      browserAction = "two-panes"
+	actionSwitchPane    browserAction = "switch-pane"
+	actionCopy          browserAction = "copy"
+	actionCopyContent   browserAction = "copy-content"
+	actionYankPath      browserAction = "yank-path"
+	actionYankDest      browserAction = "yank-destination"
+	actionHelp          browserAction = "help"
+	actionQuit          browserAction = "quit"
+	actionEndTour       browserAction = "end-tour"
+)
+
+// keyScope tells when a binding is active.
+type keyScope int
+
+const (
+	// scopeBrowser bindings work outside the tutorial only.
+	scopeBrowser keyScope = iota
+	// scopeAlways bindings work during the tutorial too.
+	scopeAlways
+	// scopeTour bindings work during the tutorial only.
+	scopeTour
+)
+
+// keyBinding ties an action to the keys that trigger it.
+type keyBinding struct {
+	action browserAction
+	// keys are key names as keyName returns them: "Enter", "F2", "Ctrl+C",
+	// or a single character. Letters match in either case, unless the
+	// other case is bound to an action of its own.
+	keys []string
+	// hint is the short label of the header line; bindings without one
+	// are only listed by the help.
+	hint string
+	// help describes the binding on the help screen.
+	help  string
+	scope keyScope
+}
+
+// defaultKeys is the keymap of the archive browser before the settings file
+// remaps it.
+var defaultKeys = []keyBinding{
+	{actionExtract, []string{"Enter"}, "extract", "extract the selected file, or the selected folder with its contents", scopeAlways},
+	{actionExtractAll, []string{"x"}, "extract all", "extract the whole archive", scopeBrowser},
+	{actionFilter, []string{"f"}, "filter", "filter the entries by name; Enter keeps the filter, Esc clears it", scopeAlways},
+	{actionMark, []string{"Space"}, "mark", "mark or unmark the selected entry and move to the next one", scopeBrowser},
+	{actionMarkAll, []string{"Ctrl+A"}, "", "mark every entry the filter shows", scopeBrowser},
+	{actionInvertMarks, []string{"*"}, "", "mark the entries the filter shows that are not marked, and unmark those that are", scopeBrowser},
+	{actionMarkPattern, []string{"+"}, "", "mark every entry matching a glob, or a /regular expression/, shown or not", scopeBrowser},
+	{actionClearMarks, []string{"-"}, "", "unmark every entry, shown or not", scopeBrowser},
+	{actionExtractMarked, []string{"X"}, "", "extract the marked entries", scopeBrowser},
+	{actionSaveMarks, []string{"S"}, "", "save the names of the marked entries to a file, one per line", scopeBrowser},
+	{actionLoadMarks, []string{"L"}, "", "mark the entries named in a file, one per line, such as a saved list", scopeBrowser},
+	{actionRename, []string{"m", "F2"}, "rename/move", "rename or move the selected entry", scopeBrowser},
+	{actionReplace, []string{"u"}, "replace", "replace the selected file with a file from disk", scopeBrowser},
+	{actionDelete, []string{"d", "Delete"}, "delete", "delete the selected entry, after confirmation", scopeBrowser},
+	{actionHealth, []string{"h"}, "health", "check the archive for problems and fix them", scopeBrowser},
+	{actionDuplicates, []string{"w"}, "duplicates", "find files stored more than once", scopeBrowser},
+	{actionEntropy, []string{"E"}, "", "find files that look encrypted or random without being in a compressed format", scopeBrowser},
+	{actionSizes, []string{"s"}, "sizes", "show the largest files and the totals per extension, method and folder", scopeBrowser},
+	{actionInfo, []string{"i"}, "info", "show the archive summary and edit its comment; Tab shows the manifest of a JAR or APK", scopeBrowser},
+	{actionProperties, []string{"p"}, "properties", "show everything recorded about the selected entry; r there dumps its raw headers", scopeBrowser},
+	{actionOwnerColumns, []string{"o"}, "owner columns", "show or hide the mode, UID and GID columns", scopeBrowser},
+	{actionDiff, []string{"c"}, "compare", "compare the archive with another one", scopeBrowser},
+	{actionCompareEntry, []string{"="}, "diff file", "compare the selected file with a file on disk", scopeBrowser},
+	{actionHash, []string{"#"}, "", "show the checksums of the selected entry or the marked ones, and export them for the whole archive", scopeBrowser},
+	{actionTestEntry, []string{"T"}, "", "test the selected file: decompress it without writing anything and check its CRC-32", scopeBrowser},
+	{actionMessages, []string{"l"}, "", "show the recent messages, such as extraction results and errors", scopeBrowser},
+	{actionOpenWith, []string{"v"}, "", "open the selected file in the pager, an editor or the default application; edits can be saved back", scopeBrowser},
+	{actionOpenFolder, []string{"e"}, "", "open the folder the last extraction wrote into in the file manager", scopeAlways},
+	{actionCopyContent, []string{"Ctrl+Y"}, "", "copy the content of the selected file, a small text file, to the clipboard", scopeBrowser},
+	{actionYankPath, []string{"y"}, "", "copy the name of the selected entry, its path inside the archive, to the clipboard", scopeBrowser},
+	{actionYankDest, []string{"Y"}, "", "copy the path Enter would extract the selected entry to", scopeBrowser},
+	{actionReload, []string{"r"}, "", "read the archive again, keeping the filter and the selected entry", scopeBrowser},
+	{actionScrollLeft, []string{"Left"}, "", "scroll the names back to their start", scopeBrowser},
+	{actionScrollRight, []string{"Right"}, "", "scroll the names to see the end of long ones", scopeBrowser},
+	{actionBasenames, []string{"b"}, "", "show names without their folder, which the title shows for the selected entry, or in full", scopeBrowser},
+	{actionStoredNames, []string{"\\"}, "", "show the names as the archive stores them, with the backslashes old Windows tools wrote for slashes, or as listed", scopeBrowser},
+	{actionPathOrder, []string{"a"}, "", "list the entries sorted by path, folder by folder, or in the order the archive stores them", scopeBrowser},
+	{actionRawNumbers, []string{"n"}, "", "show sizes in bytes and CRCs as decimal numbers, or back in hex and the configured size format", scopeBrowser},
+	{actionTwoPanes, []string{"t"}, "two panes", "show or hide a folder of the disk next to the archive", scopeBrowser},
+	{actionSwitchPane, []string{"Tab"}, "", "move between the archive and the disk pane", scopeBrowser},
+	{actionCopy, []string{"F5"}, "", "copy to the other pane: extract the selected entry into the disk pane's folder, or add the file or folder selected on disk to the archive, in the folder of the selected entry", scopeBrowser},
+	{actionPalette, []string{":"}, "", "open the command palette: type part of any action's name or description, Enter runs it", scopeBrowser},
+	{actionHelp, []string{"?"}, "help", "show this help", scopeAlways},
+	{actionEndTour, []string{"Esc"}, "", "leave the tutorial", scopeTour},
+	{actionQuit, []string{"q", "Ctrl+C"}, "exit", "quit goZip", scopeAlways},
+}
+
+// browserKeys is the keymap in use. The input handler, the header line and
+// the help screen are all built from it, in this order.
+var browserKeys = defaultKeys
+
+// navigationKeys are handled by the table itself. They are listed by the
+// help screen only.
+var navigationKeys = []keyBinding{
+	{keys: []string{"Up", "Down", "k", "j"}, hint: "select", help: "select the previous or next entry"},
+	{keys: []string{"PgUp", "PgDn"}, help: "move one page up or down"},
+	{keys: []string{"Home", "End", "g", "G"}, help: "select the first or last entry"},
+	{keys: []string{"letters"}, help: "jump to the next entry starting with the letters typed; hold Alt for letters bound to an action"},
+}
+
+// keyName returns the name an event is bound by: the character typed for
+// plain keys, or "Space", prefixed by "Alt+" or "Ctrl+" when held, and
+// tcell's name otherwise, with "Ctrl-C" spelled "Ctrl+C" whether or not the
+// terminal reports the modifier.
+func keyName(ev *tcell.EventKey) string {
+	if ev.Key() != tcell.KeyRune {
+		return strings.Replace(ev.Name(), "Ctrl-", "Ctrl+", 1)
+	}
+
+	var prefix string
+	for _, m := range []struct {
+		mask tcell.ModMask
+		name string
+	}{{tcell.ModCtrl, "Ctrl+"}, {tcell.ModAlt, "Alt+"}, {tcell.ModMeta, "Meta+"}} {
+		if ev.Modifiers()&m.mask != 0 {
+			prefix += m.name
+		}
+	}
+	if ev.Rune() == ' ' {
+		return prefix + "Space"
+	}
+	return prefix + string(ev.Rune())
+}
+
+// namedKeys maps the lower-cased name of every special key to the spelling
+// keyName uses, so the settings file can write "enter" or "ctrl+q".
+var namedKeys = func() map[string]string {
+	names := make(map[string]string, len(tcell.KeyNames))
+	for _, name := range tcell.KeyNames {
+		name = strings.Replace(name, "Ctrl-", "Ctrl+", 1)
+		names[strings.ToLower(name)] = name
+	}
+	names["space"] = "Space"
+	return names
+}()
+
+// parseKey validates a key name from the settings file and returns it as
+// keyName spells it: a single character, possibly after "Ctrl+", "Alt+" or
+// "Meta+", or the name of a special key such as "F2", "PgDn", "Space" or
+// "Ctrl+Q".
+func parseKey(s string) (string, error) {
+	if s == " " {
+		return "Space", nil
+	}
+	if utf8.RuneCountInString(s) == 1 {
+		return s, nil
+	}
+	if name, ok := namedKeys[strings.ToLower(s)]; ok {
+		return name, nil
+	}
+
+	var prefix string
+	rest := s
+	for {
+		mod, key, ok := strings.Cut(rest, "+")
+		if !ok || key == "" {
+			break
+		}
+		switch strings.ToLower(mod) {
+		case "ctrl":
+			prefix += "Ctrl+"
+		case "alt":
+			prefix += "Alt+"
+		case "meta":
+			prefix += "Meta+"
+		case "shift":
+			prefix += "Shift+"
+		default:
+			return "", fmt.Errorf("invalid key %q", s)
+		}
+		rest = key
+	}
+
+	if utf8.RuneCountInString(rest) == 1 && !strings.Contains(prefix, "Shift+") {
+		return prefix + rest, nil
+	}
+	if name, ok := namedKeys[strings.ToLower(rest)]; ok && prefix != "" {
+		return prefix + name, nil
+	}
+	return "", fmt.Errorf("invalid key %q", s)
+}
+
+// remapKeys returns bindings with the keys of the actions in config
+// replaced. A key given to an action is taken from whichever action had it
+// before, so every key triggers a single action.
+func remapKeys(bindings []keyBinding, config map[string][]string) ([]keyBinding, error) {
+	remapped := slices.Clone(bindings)
+	taken := make(map[string]browserAction)
+
+	for _, action := range slices.Sorted(maps.Keys(config)) {
+		keys := config[action]
+		i := slices.IndexFunc(remapped, func(b keyBinding) bool { return string(b.action) == action })
+		if i < 0 {
+			return nil, fmt.Errorf("keys.%s: unknown action", action)
+		}
+
+		parsed := make([]string, 0, len(keys))
+		for _, key := range keys {
+			name, err := parseKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("keys.%s: %w", action, err)
+			}
+			if other, ok := taken[name]; ok && other != remapped[i].action {
+				return nil, fmt.Errorf("keys.%s: %s is also bound to %s", action, name, other)
+			}
+			taken[name] = remapped[i].action
+			parsed = append(parsed, name)
+		}
+		remapped[i].keys = parsed
+	}
+
+	for i, b := range remapped {
+		if _, ok := config[string(b.action)]; ok {
+			continue
+		}
+		remapped[i].keys = slices.DeleteFunc(slices.Clone(b.keys), func(key string) bool {
+			_, ok := taken[key]
+			return ok
+		})
+	}
+
+	return remapped, nil
+}
+
+// lookupKey returns the binding of bindings an event triggers. A key bound
+// as typed wins over a letter bound in the other case.
+func lookupKey(bindings []keyBinding, ev *tcell.EventKey) (keyBinding, bool) {
+	name := keyName(ev)
+	for _, match := range []func(key string) bool{
+		func(key string) bool { return key == name },
+		func(key string) bool { return len(key) == 1 && strings.EqualFold(key, name) },
+	} {
+		for _, b := range bindings {
+			if slices.ContainsFunc(b.keys, match) {
+				return b, true
+			}
+		}
+	}
+	return keyBinding{}, false
+}
+
+// keyOf returns the first key bound to action, "" if it has none.
+func keyOf(action browserAction) string {
+	i := slices.IndexFunc(browserKeys, func(b keyBinding) bool { return b.action == action })
+	if i < 0 || len(browserKeys[i].keys) == 0 {
+		return ""
+	}
+	return browserKeys[i].keys[0]
+}
+
+// active reports whether the binding works in or out of the tutorial.
+func (b keyBinding) active(inTour bool) bool {
+	switch b.scope {
+	case scopeAlways:
+		return true
+	case scopeTour:
+		return inTour
+	default:
+		return !inTour
+	}
+}
+
+// headerText builds the header line from the bindings with a hint.
+func headerText() string {
+	var b strings.Builder
+	b.WriteString("[::b]goZip! " + palette.muted)
+	for _, binding := range append(navigationKeys[:1:1], browserKeys...) {
+		if binding.hint == "" || len(binding.keys) == 0 {
+			continue
+		}
+		key := binding.keys[0]
+		if binding.action == "" {
+			key = strings.Join(binding.keys[:2], "/")
+		}
+		key = strings.ReplaceAll(key, "[", "[[]")
+		b.WriteString("• " + key + " " + tr(binding.hint) + " ")
+	}
+	return strings.TrimSpace(b.String()) + palette.muted
+}
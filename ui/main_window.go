@@ -4,10 +4,15 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/cainlara/gozip/core"
 	"github.com/cainlara/gozip/util"
@@ -21,10 +26,37 @@ import (
 //   - A header with the title and keyboard shortcuts
 //   - An interactive table displaying the ZIP file contents
 //   - Filtering functionality activated with the 'f' key
+//   - Marking entries with Space
+//   - An archive health report with one-key fixes on the 'h' key
+//   - Comparing the archive with another one side by side with 'c'
+//   - Comparing the selected file with a file on disk with '='
 //   - File extraction with the Enter key
-//   - Navigation with arrow keys
+//   - Extracting the whole archive with 'x', into a folder named after it
+//     when it has several top-level entries (see util.SubfolderMode)
+//   - Renaming or moving the selected entry with 'm' or F2
+//   - Replacing the selected file with one from disk with 'u'
+//   - Deleting the selected entry with 'd' or Delete, after confirmation
+//   - Showing the archive's summary and comment, and editing it, with 'i'
+//   - A help screen listing every key binding with '?'
+//   - The log of recent notifications with 'l'
+//   - Opening the selected file in the pager, an editor or the default
+//     application with 'v'
+//   - Reading the archive again with 'r', keeping the filter and the
+//     selection, or whenever it changes on disk with the watch setting
+//   - A folder of the disk next to the archive with 't', Tab moving
+//     between them and F5 copying the selection from one to the other
+//   - Navigation with arrow keys, Page Up/Down and Home/End, and jumping
+//     to an entry by typing the start of its name
 //   - Exit with 'q' or Ctrl+C
 //
+// A status bar below the table summarizes the archive (entries, sizes and
+// flags), counts the entries matching the filter and the marked ones, and
+// shows the result of each action for a few seconds.
+//
+// When the archive is signed, see SetSignature, the signature is verified
+// before the browser opens; a signature that does not verify must be
+// confirmed, and declining it stops the application.
+//
 // Parameters:
 //   - fileName: name of the ZIP file to display in the title
 //   - zipPath: full path to the ZIP file for extraction
@@ -40,12 +72,135 @@ import (
 func BuildUI(fileName string, zipPath string, content []core.ZippedFile) *tview.Application {
 	app := tview.NewApplication()
 
+	openVerified(app, zipPath, func() {
+		entries := newEntryTable(content)
+		layout, table, status := buildBrowser(app, fileName, zipPath, entries, nil)
+		info := core.ArchiveInfo{Format: "zip"}
+		for _, zf := range content {
+			info.Add(zf)
+		}
+		status.setSummary(archiveSummary(info, entries.duplicateNames))
+		restoreBrowser(zipPath, table, entries, status)
+		app.SetRoot(layout, true)
+		if settings.Watch {
+			goSafe(app, func() { watchArchive(context.Background(), app, fileName, zipPath) })
+		}
+
+		if !tutorialSeen() {
+			offerTutorial(app, layout)
+		}
+	}, app.Stop)
+
+	return app
+}
+
+// BuildStreamingUI is BuildUI for an archive still being read: the browser
+// opens at once and fills up as stream returns entries, with a
+// "Loading N of M entries" line, replaced by the summary of the archive once
+// every entry is listed. The stream is
+// closed once read, or once ctx is done; cancel ctx when the application
+// stops so the rest of the archive is not read for nothing.
+//
+// Parameters:
+//   - ctx: abandons reading the stream once done
+//   - fileName: name of the ZIP file to display in the title
+//   - zipPath: full path to the ZIP file for extraction
+//   - stream: the archive's entries, from util.OpenArchiveStream
+//
+// Returns:
+//   - *tview.Application: configured tview application ready to run
+func BuildStreamingUI(ctx context.Context, fileName string, zipPath string, stream *util.ArchiveStream) *tview.Application {
+	app := tview.NewApplication()
+
+	openVerified(app, zipPath, func() {
+		layout := showStreamingBrowser(ctx, app, fileName, zipPath, stream)
+
+		if !tutorialSeen() {
+			offerTutorial(app, layout)
+		}
+	}, func() {
+		stream.Close()
+		app.Stop()
+	})
+
+	return app
+}
+
+// showStreamingBrowser makes the browser of an archive still being read the
+// root of app, filling it up from stream in the background, and returns its
+// layout.
+func showStreamingBrowser(ctx context.Context, app *tview.Application, fileName, zipPath string, stream *util.ArchiveStream) *tview.Flex {
+	entries := newEntryTable(nil)
+	layout, table, status := buildBrowser(app, fileName, zipPath, entries, nil)
+	app.SetRoot(layout, true)
+
+	goSafe(app, func() { streamEntries(ctx, app, zipPath, stream, entries, table, status) })
+	if settings.Watch {
+		goSafe(app, func() { watchArchive(ctx, app, fileName, zipPath) })
+	}
+
+	return layout
+}
+
+// streamBatchSize is the number of entries added to the browser at a time
+// while an archive is being read.
+const streamBatchSize = 5000
+
+// streamEntries reads the stream in batches and adds them to entries from
+// the UI goroutine. The status bar shows the progress, or the error that
+// stopped the listing, and the summary of the archive once every entry is
+// listed, when the cursor and filter of the previous session are restored
+// unless the user already moved. It stops silently once ctx is done.
+func streamEntries(ctx context.Context, app *tview.Application, zipPath string, stream *util.ArchiveStream, entries *entryTable, table *tview.Table, status *statusBar) {
+	defer stream.Close()
+
+	for ctx.Err() == nil {
+		batch, err := stream.Next(streamBatchSize)
+		loaded, total, info := stream.Loaded(), stream.Total(), stream.Info()
+
+		app.QueueUpdateDraw(func() {
+			entries.appendEntries(batch)
+			if row, _ := table.GetSelection(); row < 1 && len(entries.visible) > 0 {
+				table.Select(1, 0)
+			}
+			// A table drawn while shorter than the screen follows its end;
+			// keep the view where the user left it instead.
+			table.SetOffset(table.GetOffset())
+
+			switch {
+			case errors.Is(err, io.EOF):
+				status.setSummary(archiveSummary(info, entries.duplicateNames))
+				if row, _ := table.GetSelection(); row <= 1 && entries.filter == "" {
+					restoreBrowser(zipPath, table, entries, status)
+				}
+			case err != nil:
+				status.setSummary(fmt.Sprintf(palette.failure+tr("Error after %d of %d entries: %s")+"[-]", loaded, total, tview.Escape(err.Error())))
+			default:
+				status.setSummary(fmt.Sprintf(palette.warning+tr("Loading %d of %d entries...")+"[-]", loaded, total))
+			}
+		})
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// buildBrowser builds the header, table, status bar and filter footer for
+// one archive and returns the layout holding them together with the table
+// and the status bar. The disk pane is shown next to the table if it was
+// open in the previous browser, see twoPanes, and outside the tutorial the
+// browser becomes shownBrowser and, in paranoid mode, the headers of the
+// archive are checked, see checkHeaders.
+// When tour is not nil the browser runs in tutorial mode: the tour bar is shown
+// and extractions go to the tour's scratch directory.
+func buildBrowser(app *tview.Application, fileName string, zipPath string, entries *entryTable, tour *tutorial) (*tview.Flex, *tview.Table, *statusBar) {
 	header := buildHeader()
 
 	filterInput := tview.NewInputField().
-		SetLabel("Filter: ").
+		SetLabel(tr("Filter: ")).
 		SetFieldWidth(0).
-		SetFieldBackgroundColor(tcell.ColorBlack)
+		SetFieldBackgroundColor(currentTheme.field)
 
 	footer := tview.NewFlex().
 		AddItem(filterInput, 0, 1, true)
@@ -54,11 +209,58 @@ func BuildUI(fileName string, zipPath string, content []core.ZippedFile) *tview.
 		SetDirection(tview.FlexRow).
 		AddItem(header, 1, 0, false)
 
-	table := buildContentTable(fileName, zipPath, footer, filterInput, layout, app, content)
+	if tour != nil {
+		layout.AddItem(tour.view, 3, 0, false)
+	}
 
-	layout.AddItem(table, 0, 1, true)
+	status := newStatusBar(app, entries)
+	panes := newTwoPanes(app, layout, status, fileName, zipPath)
+	table := buildContentTable(fileName, zipPath, footer, filterInput, layout, app, entries, status, panes, tour)
+	if tour == nil {
+		panes.setTable(table)
+		trackBrowser(zipPath, panes, entries)
+		if settings.Paranoid {
+			checkHeaders(app, zipPath, entries, status)
+		}
+	} else {
+		panes.AddItem(table, 0, 1, true)
+	}
+
+	layout.AddItem(panes, 0, 1, true).
+		AddItem(status, 1, 0, false)
+
+	return layout, table, status
+}
+
+// reloadBrowser lists the archive again and replaces the current view.
+// It is used after the archive has been rewritten on disk; a non-empty
+// message is shown in the status bar.
+func reloadBrowser(app *tview.Application, fileName string, zipPath string, message string) error {
+	return reloadBrowserView(app, fileName, zipPath, browserView{}, message)
+}
+
+// reloadBrowserView is reloadBrowser putting the filter and the selection
+// of view back. The signature of the archive is verified again, see
+// checkSignature.
+func reloadBrowserView(app *tview.Application, fileName, zipPath string, view browserView, message string) error {
+	stamp, _ := stampOf(zipPath)
+	content, err := util.ListArchive(zipPath)
+	if err != nil {
+		return err
+	}
+	watched.set(stamp)
+
+	entries := newEntryTable(content)
+	layout, table, status := buildBrowser(app, fileName, zipPath, entries, nil)
+	view.restore(table, entries, status)
+	status.setMessage(message)
+	if info, err := util.ReadArchiveInfo(zipPath); err == nil {
+		status.setSummary(archiveSummary(info, entries.duplicateNames))
+	}
+	app.SetRoot(layout, true)
+	checkSignature(app, zipPath, status)
 
-	return app.SetRoot(layout, true)
+	return nil
 }
 
 func buildHeader() *tview.TextView {
@@ -66,81 +268,41 @@ func buildHeader() *tview.TextView {
 		SetTextAlign(tview.AlignLeft).
 		SetDynamicColors(true)
 
-	header.SetText("[::b]goZip! [gray]• Up/Down select • Enter extract • f filter • q exit[gray]")
-	header.SetBackgroundColor(tcell.ColorReset)
+	header.SetText(headerText())
+	header.SetBackgroundColor(currentTheme.header)
 
 	return header
 }
 
-func buildContentTable(fileName string, zipPath string, filterFooter *tview.Flex, filterInput *tview.InputField, layout *tview.Flex, app *tview.Application, content []core.ZippedFile) *tview.Table {
+func buildContentTable(fileName string, zipPath string, filterFooter *tview.Flex, filterInput *tview.InputField, layout *tview.Flex, app *tview.Application, entries *entryTable, status *statusBar, panes *twoPanes, tour *tutorial) *tview.Table {
 	table := tview.NewTable().
 		SetBorders(false).
 		SetFixed(1, 0).
-		SetSelectable(true, false)
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selection)
 
 	table.
 		SetBorder(true).
 		SetTitle(fileName).
 		SetTitleAlign(tview.AlignCenter)
 
-	allRows := make([][]string, 0, len(content))
-
-	for _, zf := range content {
-		row := []string{
-			zf.GetName(),
-			strconv.FormatBool(zf.IsDir()),
-			strconv.FormatUint(zf.GetSize(), 10),
-			zf.GetModifiedDate(),
-			strconv.FormatUint(uint64(zf.GetCrc()), 10)}
-		allRows = append(allRows, row)
-	}
-
-	headers := []string{"NAME", "IS FOLDER", "SIZE", "MODIFIED ON", "CRC"}
+	table.SetContent(entries)
+	entries.table = table
 
 	populateTable := func(filterText string) {
-		table.Clear()
-
-		for c, h := range headers {
-			cell := tview.NewTableCell(fmt.Sprintf("[::b]%s", h)).
-				SetSelectable(false).
-				SetAlign(tview.AlignCenter)
-			table.SetCell(0, c, cell)
-		}
-
-		rowIndex := 1
-		filterLower := strings.ToLower(filterText)
-		for _, row := range allRows {
-			matches := filterText == ""
-			if !matches {
-				for _, val := range row {
-					if strings.Contains(strings.ToLower(val), filterLower) {
-						matches = true
-						break
-					}
-				}
-			}
-
-			if matches {
-				for c, val := range row {
-					table.SetCell(rowIndex, c, tview.NewTableCell(val))
-				}
-				rowIndex++
-			}
-		}
-
-		if rowIndex > 1 {
+		entries.setFilter(filterText)
+		status.refresh()
+		table.SetOffset(0, 0)
+		if len(entries.visible) > 0 {
 			table.Select(1, 0)
 		}
 	}
 
-	populateTable("")
-
 	table.Select(1, 0)
 
 	filterMode := false
 
-	var lastExtractedRow int = -1
-	var extractionMessage string = ""
+	var jump typeAhead
 
 	filterInput.SetChangedFunc(func(text string) {
 		populateTable(text)
@@ -152,107 +314,590 @@ func buildContentTable(fileName string, zipPath string, filterFooter *tview.Flex
 			if key == tcell.KeyEscape {
 				filterInput.SetText("")
 				populateTable("")
+			} else if filterInput.GetText() != "" {
+				tour.notify(tourFiltered)
 			}
 			layout.RemoveItem(filterFooter)
 			app.SetFocus(table)
 		}
 	})
 
-	table.SetSelectionChangedFunc(func(row, column int) {
-		if lastExtractedRow != -1 && row != lastExtractedRow {
-			table.SetTitle(fileName)
-			lastExtractedRow = -1
-			extractionMessage = ""
+	// describeSelection shows the full name of the entry at row when it
+	// is cut, and its folder in the title in basenames mode.
+	describeSelection := func(row int) {
+		hint := ""
+		if name, ok := entries.truncatedName(row); ok {
+			hint = palette.muted + name + "[-]"
+		}
+		status.setHint(hint)
+
+		title := tview.Escape(fileName)
+		if zf, ok := entries.entryAt(row); ok && entries.basenames {
+			_, _, width, _ := table.GetInnerRect()
+			title = breadcrumb(fileName, zf.GetName(), width-2)
 		}
+		table.SetTitle(title)
+	}
+
+	table.SetSelectionChangedFunc(func(row, column int) {
+		describeSelection(row)
+		tour.notify(tourSelected)
 	})
 
-	table.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
-		switch ev.Key() {
-		case tcell.KeyCtrlC:
+	// extractSelection extracts the selected entry: a file right away, or
+	// after choosing which of the entries sharing its name; a folder after
+	// confirming.
+	extractSelection := func() {
+		row, _ := table.GetSelection()
+		if row < 1 {
+			return
+		}
+
+		fileNameCell := table.GetCell(row, 0)
+		if fileNameCell == nil {
+			return
+		}
+		entry, ok := cellEntry(fileNameCell)
+		if !ok {
+			return
+		}
+		targetName := entry.GetName()
+
+		if entry.IsDir() {
+			util.RecordUsage("action:extract-folder")
+			showConfirmationModal(app, layout, table, status, zipPath, targetName, tour)
+			return
+		}
+
+		if version, count := entries.versionAt(row); count > 1 {
+			chooseVersion(app, layout, table, targetName, version, count, func(v int) {
+				util.RecordUsage("action:extract-version")
+				extractItem(app, layout, table, status, zipPath, targetName, tour.destDir(), util.ExtractOptions{Version: v}, false)
+			})
+			return
+		}
+
+		util.RecordUsage("action:extract-file")
+		if extractItem(app, layout, table, status, zipPath, targetName, tour.destDir(), util.ExtractOptions{}, false) {
+			tour.notify(tourExtractedFile)
+		}
+	}
+
+	// runAction does what an action of the keymap does, whether its key
+	// was pressed or it was picked from the command palette.
+	var runAction func(action browserAction)
+	runAction = func(action browserAction) {
+		switch action {
+		case actionQuit:
+			util.RecordUsage("action:quit")
 			app.Stop()
-			return nil
-		case tcell.KeyEnter:
+		case actionEndTour:
+			tour.finish()
+		case actionHelp:
+			util.RecordUsage("action:help")
+			showKeyHelp(app, layout, table)
+		case actionPalette:
+			util.RecordUsage("action:palette")
+			showPalette(app, layout, table, browserCommands(entries, runAction))
+		case actionMessages:
+			util.RecordUsage("action:messages")
+			showMessageLog(app, layout, table)
+		case actionExtract:
+			extractSelection()
+		case actionFilter:
+			if filterMode {
+				return
+			}
+			util.RecordUsage("action:filter")
+			filterMode = true
+			filterInput.SetText("")
+			layout.AddItem(filterFooter, 1, 0, true)
+			app.SetFocus(filterInput)
+		case actionMark:
 			row, _ := table.GetSelection()
-			if row < 1 {
-				return nil
+			if entries.toggleMark(row) {
+				util.RecordUsage("action:mark")
+				status.refresh()
+				if row < len(entries.visible) {
+					table.Select(row+1, 0)
+				}
 			}
-
-			fileNameCell := table.GetCell(row, 0)
-			isDirCell := table.GetCell(row, 1)
-			if fileNameCell == nil || isDirCell == nil {
-				return nil
+		case actionMarkAll:
+			util.RecordUsage("action:mark-all")
+			entries.markVisible()
+			status.refresh()
+		case actionInvertMarks:
+			util.RecordUsage("action:invert-marks")
+			entries.invertVisible()
+			status.refresh()
+		case actionMarkPattern:
+			showPrompt(app, tr("Mark by pattern"), tr("Glob or /regexp/: "), "", func(text string, ok bool) {
+				app.SetRoot(layout, true)
+				app.SetFocus(table)
+				if !ok {
+					return
+				}
+				match, err := util.ParseNamePattern(text)
+				if err != nil {
+					status.showError(err)
+					return
+				}
+				util.RecordUsage("action:mark-pattern")
+				status.setMessage(fmt.Sprintf(tr("Marked %d entries matching %s"), entries.markMatching(match), strings.TrimSpace(text)))
+				status.refresh()
+			})
+		case actionClearMarks:
+			entries.clearMarks()
+			status.refresh()
+		case actionExtractMarked:
+			names := entries.markedNames()
+			if len(names) == 0 {
+				status.setMessage(fmt.Sprintf(palette.warning+tr("Nothing is marked; %s marks an entry")+"[-]", keyOf(actionMark)))
+				break
+			}
+			util.RecordUsage("action:extract-marked")
+			extractItem(app, layout, table, status, zipPath, "", tour.destDir(), util.ExtractOptions{Names: names}, true)
+		case actionSaveMarks:
+			promptSaveMarks(app, layout, table, status, entries, zipPath, tour.destDir())
+		case actionLoadMarks:
+			promptLoadMarks(app, layout, table, status, entries, zipPath, tour.destDir())
+		case actionExtractAll:
+			confirmExtractAll(app, layout, table, status, fileName, zipPath)
+		case actionDelete:
+			confirmDelete(app, layout, table, status, fileName, zipPath)
+		case actionRename:
+			promptRename(app, layout, table, status, fileName, zipPath)
+		case actionReplace:
+			promptReplace(app, layout, table, status, fileName, zipPath)
+		case actionHealth:
+			util.RecordUsage("action:health")
+			showHealthReport(app, layout, table, fileName, zipPath)
+		case actionInfo:
+			util.RecordUsage("action:info")
+			showArchiveInfo(app, layout, table, fileName, zipPath)
+		case actionDiff:
+			promptDiff(app, layout, table, status, fileName, zipPath)
+		case actionCompareEntry:
+			promptCompareEntry(app, layout, table, status, zipPath)
+		case actionHash:
+			util.RecordUsage("action:hash")
+			showEntryHashes(app, layout, table, status, fileName, zipPath, entries)
+		case actionTestEntry:
+			testEntry(app, table, status, zipPath, entries)
+		case actionSizes:
+			util.RecordUsage("action:sizes")
+			showSizeBreakdown(app, layout, table, fileName, entries)
+		case actionDuplicates:
+			util.RecordUsage("action:dupes")
+			showDuplicateContent(app, layout, table, fileName, zipPath)
+		case actionEntropy:
+			util.RecordUsage("action:entropy")
+			showRandomContent(app, layout, table, fileName, zipPath)
+		case actionProperties:
+			util.RecordUsage("action:properties")
+			showEntryProperties(app, layout, table, entries, zipPath)
+		case actionOpenWith:
+			promptOpenWith(app, layout, table, status, fileName, zipPath)
+		case actionOpenFolder:
+			openExtractedDir(status)
+		case actionCopyContent:
+			copyEntryText(app, table, status, zipPath)
+		case actionYankPath:
+			yankEntryPath(app, table, status)
+		case actionYankDest:
+			yankDestPath(app, table, status, tour.destDir())
+		case actionScrollLeft:
+			entries.scrollNames(-nameScrollStep)
+		case actionScrollRight:
+			util.RecordUsage("action:scroll-names")
+			entries.scrollNames(nameScrollStep)
+		case actionBasenames:
+			util.RecordUsage("action:basenames")
+			entries.basenames = !entries.basenames
+			row, _ := table.GetSelection()
+			describeSelection(row)
+		case actionStoredNames:
+			util.RecordUsage("action:stored-names")
+			entries.storedNames = !entries.storedNames
+		case actionPathOrder:
+			util.RecordUsage("action:path-order")
+			if entries.togglePathOrder() {
+				status.setMessage(palette.muted + tr("Sorted by path") + "[-]")
+			} else {
+				status.setMessage(palette.muted + tr("In archive order") + "[-]")
+			}
+		case actionRawNumbers:
+			util.RecordUsage("action:raw-numbers")
+			entries.setRawNumbers(!entries.rawNumbers)
+			status.refresh()
+		case actionReload:
+			util.RecordUsage("action:reload")
+			if err := reloadBrowserView(app, fileName, zipPath, currentView(table, entries), palette.success+tr("Reloaded")+"[-]"); err != nil {
+				status.showError(err)
 			}
+		case actionTwoPanes:
+			util.RecordUsage("action:two-panes")
+			panes.toggle()
+		case actionSwitchPane:
+			panes.switchFocus()
+		case actionCopy:
+			panes.copyToDisk()
+		case actionOwnerColumns:
+			util.RecordUsage("action:owner-columns")
+			entries.showOwner = !entries.showOwner
+		}
+	}
 
-			targetName := fileNameCell.Text
-			isDir := isDirCell.Text == "true"
+	table.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		util.RecordUsage("key:" + ev.Name())
 
-			if isDir {
-				showConfirmationModal(app, layout, table, zipPath, targetName, &lastExtractedRow, &extractionMessage)
-			} else {
-				extractItem(table, zipPath, targetName, false, row, &lastExtractedRow, &extractionMessage)
+		binding, ok := lookupKey(browserKeys, ev)
+		if prefix, jumping := jump.typed(ev, ok, time.Now()); jumping {
+			// A new jump starts past the selection, so typing the same
+			// letter again goes to the next entry starting with it.
+			row, _ := table.GetSelection()
+			if utf8.RuneCountInString(prefix) == 1 {
+				util.RecordUsage("action:jump")
+				row++
+			}
+			if row, found := entries.rowWithPrefix(prefix, row); found {
+				table.Select(row, 0)
 			}
 			return nil
 		}
-		if ev.Key() == tcell.KeyRune {
-			switch ev.Rune() {
-			case 'q', 'Q':
-				app.Stop()
-				return nil
-			case 'f', 'F':
-				if !filterMode {
-					filterMode = true
-					filterInput.SetText("")
-					layout.AddItem(filterFooter, 1, 0, true)
-					app.SetFocus(filterInput)
-					return nil
-				}
-			}
+		if !ok || !binding.active(tour != nil) {
+			return ev
 		}
 
-		return ev
+		runAction(binding.action)
+
+		return nil
+	})
+
+	// With settings.Mouse on, a click selects a row, a double
+	// click extracts it and a click on a header sorts by that column.
+	table.SetMouseCapture(func(action tview.MouseAction, ev *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action != tview.MouseLeftDoubleClick {
+			return action, ev
+		}
+		row, _ := table.CellAt(ev.Position())
+		if _, ok := entries.entryAt(row); !ok {
+			return action, ev
+		}
+		util.RecordUsage("action:double-click")
+		table.Select(row, 0)
+		extractSelection()
+		return tview.MouseConsumed, nil
 	})
 
 	return table
 }
 
 // showConfirmationModal displays a modal dialog asking for confirmation before extracting a folder.
-func showConfirmationModal(app *tview.Application, layout *tview.Flex, table *tview.Table, zipPath, folderName string, lastExtractedRow *int, extractionMessage *string) {
+// The Preview button lists the files that would be written before deciding, and
+// Patterns asks for include/exclude globs limiting which files are extracted.
+// Flatten extracts every file directly into the destination, without folders.
+func showConfirmationModal(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, zipPath, folderName string, tour *tutorial) {
+	yes, flatten, patterns, preview := tr("Yes"), tr("Flatten"), tr("Patterns"), tr("Preview")
 	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Extract folder '%s' and all its contents?\n\nThis will extract all files within this folder recursively.", folderName)).
-		AddButtons([]string{"Yes", "No"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Yes" {
-				row, _ := table.GetSelection()
-				extractItem(table, zipPath, folderName, true, row, lastExtractedRow, extractionMessage)
+		SetText(fmt.Sprintf(tr("Extract folder '%s' and all its contents?\n\nThis will extract all files within this folder recursively.")+"%s", folderName, spaceNote(zipPath, folderName, tour.destDir()))).
+		AddButtons([]string{yes, flatten, patterns, preview, tr("No")})
+
+	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		if buttonLabel == preview {
+			util.RecordUsage("action:extract-preview")
+			showExtractionPreview(app, zipPath, folderName, tour.destDir(), func() {
+				app.SetRoot(modal, true)
+			})
+			return
+		}
+
+		if buttonLabel == patterns {
+			showPrompt(app, fmt.Sprintf(tr("Extract %s"), folderName), tr("Patterns (!excludes): "), "", func(text string, ok bool) {
+				app.SetRoot(layout, true)
+				app.SetFocus(table)
+				if ok {
+					util.RecordUsage("action:extract-patterns")
+					extractItem(app, layout, table, status, zipPath, folderName, tour.destDir(), parsePatterns(text), true)
+				}
+			})
+			return
+		}
+
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+		if buttonLabel == yes || buttonLabel == flatten {
+			opts := util.ExtractOptions{Flatten: buttonLabel == flatten}
+			if opts.Flatten {
+				util.RecordUsage("action:extract-flatten")
+			}
+			if extractItem(app, layout, table, status, zipPath, folderName, tour.destDir(), opts, true) {
+				tour.notify(tourExtractedFolder)
 			}
+		}
+	})
+
+	app.SetRoot(modal, true)
+}
+
+// confirmExtractAll asks before extracting the whole archive into the
+// configured destination, by default the current directory. When the configured util.SubfolderMode asks for it, extracting
+// into a new folder named after the archive is offered first, so archives
+// with many top-level entries do not litter the directory.
+func confirmExtractAll(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath string) {
+	mode, err := util.DefaultSubfolderMode()
+	var useFolder bool
+	if err == nil {
+		useFolder, err = util.WantsSubfolder(zipPath, mode)
+	}
+	if err != nil {
+		status.showError(err)
+		return
+	}
+
+	folder := util.ArchiveFolderName(zipPath)
+	intoFolder := fmt.Sprintf(tr("Into %s"), folder+"/")
+	here := tr("Here")
+	where := tr("the current directory")
+	if settings.DestDir != "" {
+		where = "'" + settings.DestDir + "'"
+	}
+	text := fmt.Sprintf(tr("Extract everything in %s into %s?")+"%s", fileName, where, spaceNote(zipPath, "", settings.DestDir))
+	buttons := []string{here, intoFolder, tr("Cancel")}
+	if useFolder {
+		text = fmt.Sprintf(tr("Extract everything in %s into a new folder '%s/' in %s?\n\nThis keeps its top-level entries together instead of spreading them over %s.")+"%s", fileName, folder, where, where, spaceNote(zipPath, "", settings.DestDir))
+		buttons = []string{intoFolder, here, tr("Cancel")}
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			app.SetRoot(layout, true)
 			app.SetFocus(table)
+			if buttonLabel == here || buttonLabel == intoFolder {
+				util.RecordUsage("action:extract-all")
+				destDir := settings.DestDir
+				if buttonLabel == intoFolder {
+					destDir = filepath.Join(destDir, folder)
+				}
+				extractItem(app, layout, table, status, zipPath, "", destDir, util.ExtractOptions{}, true)
+			}
 		})
 
 	app.SetRoot(modal, true)
 }
 
-// extractItem performs the actual extraction and updates the table title with status.
-func extractItem(table *tview.Table, zipPath, targetName string, isFolder bool, row int, lastExtractedRow *int, extractionMessage *string) {
-	destDir, err := os.Getwd()
+// spaceNote tells how much an extraction would write, for confirmation
+// modals, and warns when the destination lacks the space for it, in which
+// case the extraction is refused. It is empty if the archive cannot be read.
+func spaceNote(zipPath, targetName, destDir string) string {
+	plan, err := util.PlanExtraction(zipPath, targetName, destDir, extractOptions(util.ExtractOptions{}))
 	if err != nil {
-		table.SetTitle(fmt.Sprintf("[red]Error: %s[-]", err.Error()))
+		return ""
+	}
+
+	if !plan.Fits() {
+		return "\n\n" + fmt.Sprintf(tr("Warning: %s to write but only %s free. Free some space first."), util.FormatSize(plan.TotalSize), util.FormatSize(plan.FreeSpace))
+	}
+	return "\n\n" + fmt.Sprintf(tr("Total size: %s."), util.FormatSize(plan.TotalSize))
+}
+
+// confirmDelete asks for confirmation before removing the selected entry,
+// or folder with everything inside it, from the archive.
+func confirmDelete(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath string) {
+	row, _ := table.GetSelection()
+	if row < 1 {
 		return
 	}
 
-	count, err := util.ExtractFile(zipPath, targetName, destDir)
-	if err != nil {
-		table.SetTitle(fmt.Sprintf("[red]Error: %s[-]", err.Error()))
-		*lastExtractedRow = -1
-		*extractionMessage = ""
-	} else {
-		*lastExtractedRow = row
+	nameCell := table.GetCell(row, 0)
+	if nameCell == nil {
+		return
+	}
+	entry, ok := cellEntry(nameCell)
+	if !ok {
+		return
+	}
+	targetName := entry.GetName()
+
+	text := fmt.Sprintf(tr("Delete '%s' from %s?\n\nThe archive will be rewritten without it."), targetName, fileName)
+	if entry.IsDir() {
+		text = fmt.Sprintf(tr("Delete folder '%s' and all its contents from %s?\n\nThe archive will be rewritten without them."), targetName, fileName)
+	}
 
-		if isFolder {
-			*extractionMessage = fmt.Sprintf("[green]Extracted folder: %d files[-]", count)
-		} else {
-			*extractionMessage = fmt.Sprintf("[green]Extracted: %s[-]", targetName)
+	deleteLabel := tr("Delete")
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{deleteLabel, tr("Cancel")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == deleteLabel {
+				util.RecordUsage("action:delete")
+				count, err := util.DeleteEntry(zipPath, targetName)
+				if err == nil {
+					err = reloadBrowser(app, fileName, zipPath, fmt.Sprintf(palette.success+tr("Deleted %d entries")+"[-]", count))
+				}
+				if err == nil {
+					return
+				}
+				status.showError(err)
+			}
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+		})
+
+	app.SetRoot(modal, true)
+}
+
+// promptRename asks for a new name or path for the selected entry and
+// rewrites the archive with it. Folders are moved with their contents.
+func promptRename(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath string) {
+	row, _ := table.GetSelection()
+	if row < 1 {
+		return
+	}
+
+	nameCell := table.GetCell(row, 0)
+	if nameCell == nil {
+		return
+	}
+	oldName := cellEntryName(nameCell)
+
+	showPrompt(app, tr("Rename / move"), tr("New name: "), oldName, func(newName string, ok bool) {
+		if ok && newName != oldName {
+			util.RecordUsage("action:rename")
+			count, err := util.RenameEntry(zipPath, oldName, newName)
+			if err == nil {
+				err = reloadBrowser(app, fileName, zipPath, fmt.Sprintf(palette.success+tr("Renamed %d entries")+"[-]", count))
+			}
+			if err == nil {
+				return
+			}
+			status.showError(err)
 		}
-		table.SetTitle(*extractionMessage)
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+	})
+}
+
+// promptReplace asks for a file on disk and replaces the selected entry's
+// content with it. The path is prefilled with where extracting the entry
+// would have put it, the usual place for an edited copy.
+func promptReplace(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath string) {
+	row, _ := table.GetSelection()
+	if row < 1 {
+		return
+	}
+
+	nameCell := table.GetCell(row, 0)
+	if nameCell == nil {
+		return
 	}
+	entryName := cellEntryName(nameCell)
+
+	showPrompt(app, fmt.Sprintf(tr("Replace %s"), entryName), tr("With file: "), filepath.FromSlash(entryName), func(srcPath string, ok bool) {
+		if ok && srcPath != "" {
+			util.RecordUsage("action:replace")
+			err := util.ReplaceEntry(zipPath, entryName, srcPath)
+			if err == nil {
+				err = reloadBrowser(app, fileName, zipPath, fmt.Sprintf(palette.success+tr("Replaced %s")+"[-]", entryName))
+			}
+			if err == nil {
+				return
+			}
+			status.showError(err)
+		}
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+	})
+}
+
+// parsePatterns turns space-separated globs into extraction options: plain
+// patterns are includes and patterns starting with '!' are excludes, so
+// "*.go !vendor/**" extracts Go files outside vendor/.
+func parsePatterns(text string) util.ExtractOptions {
+	var opts util.ExtractOptions
+	for _, p := range strings.Fields(text) {
+		if exclude, ok := strings.CutPrefix(p, "!"); ok {
+			if exclude != "" {
+				opts.Exclude = append(opts.Exclude, exclude)
+			}
+			continue
+		}
+		opts.Include = append(opts.Include, p)
+	}
+	return opts
+}
+
+// extractItem performs the actual extraction and shows its result in the status bar.
+// An empty destDir means the current working directory. The configured jobs,
+// overwrite policy and content scanner apply unless opts sets them. Folders are extracted past the
+// files that fail, which are then listed full screen with a way to retry them,
+// see showExtractionFailures; callers restore layout before extracting so the
+// list stays up. It reports whether the extraction succeeded.
+func extractItem(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, zipPath, targetName, destDir string, opts util.ExtractOptions, isFolder bool) bool {
+	if destDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			status.showError(err)
+			return false
+		}
+		destDir = wd
+	}
+
+	result := &extractionLog{sizes: make(map[string]uint64)}
+	opts = extractOptions(opts)
+	opts.Observer = result
+	opts.ContinueOnError = isFolder
+
+	count, err := util.ExtractWithOptions(context.Background(), zipPath, targetName, destDir, opts)
+	if err != nil && len(result.failed) > 0 {
+		status.showError(fmt.Errorf(tr("%d of %d files failed to extract"), len(result.failed), len(result.failed)+len(result.done)))
+		showExtractionFailures(app, layout, table, status, result, destDir, func() {
+			retry := opts
+			retry.Names = result.failedNames()
+			extractItem(app, layout, table, status, zipPath, targetName, destDir, retry, isFolder)
+		})
+		return false
+	}
+	var collision *util.CaseCollisionError
+	if errors.As(err, &collision) {
+		confirmCaseRename(app, layout, table, collision, func() {
+			renamed := opts
+			renamed.CaseCollisions = util.CaseCollisionRename
+			extractItem(app, layout, table, status, zipPath, targetName, destDir, renamed, isFolder)
+		})
+		return false
+	}
+	if err != nil {
+		status.showError(err)
+		return false
+	}
+
+	if abs, err := filepath.Abs(destDir); err == nil {
+		extractedDir = abs
+	}
+	var hint string
+	if key := keyOf(actionOpenFolder); key != "" {
+		hint = palette.muted + " • " + fmt.Sprintf(tr("%s opens the folder"), tview.Escape(key)) + "[-]"
+	}
+	if result.renamed > 0 {
+		hint = palette.warning + " • " + fmt.Sprintf(tr("%d renamed to names this system accepts"), result.renamed) + "[-]" + hint
+	}
+	if result.flagged > 0 {
+		hint = palette.failure + " • " + fmt.Sprintf(tr("%d flagged by the scanner"), result.flagged) + "[-]" + hint
+	}
+
+	if targetName == "" && len(opts.Names) > 0 {
+		status.setMessage(fmt.Sprintf(palette.success+tr("Extracted %d files, %s")+"[-]%s", count, util.FormatSize(result.total), hint))
+	} else if isFolder {
+		status.setMessage(fmt.Sprintf(palette.success+tr("Extracted folder: %d files, %s")+"[-]%s", count, util.FormatSize(result.total), hint))
+	} else if count == 0 {
+		status.setMessage(fmt.Sprintf(palette.warning+tr("Kept the existing %s")+"[-]", targetName))
+	} else {
+		status.setMessage(fmt.Sprintf(palette.success+tr("Extracted: %s")+"[-]%s", targetName, hint))
+	}
+	runExtractHooks(app, status, destDir, result.written)
+
+	return true
 }
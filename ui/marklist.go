@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// markListPath is where a selection list for the archive is saved and
+// looked for by default: next to what is extracted, named after the
+// archive, e.g. backup.list for backup.zip.
+func markListPath(zipPath, destDir string) string {
+	base := filepath.Base(zipPath)
+	return filepath.Join(destDir, strings.TrimSuffix(base, filepath.Ext(base))+".list")
+}
+
+// promptSaveMarks asks for a file and writes the names of the marked
+// entries to it, one per line, for promptLoadMarks or
+// "gozip extract --list" to use later.
+func promptSaveMarks(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, entries *entryTable, zipPath, destDir string) {
+	names := entries.markedNames()
+	if len(names) == 0 {
+		status.setMessage(fmt.Sprintf(palette.warning+tr("Nothing is marked; %s marks an entry")+"[-]", keyOf(actionMark)))
+		return
+	}
+
+	showPrompt(app, tr("Save the marked names"), tr("To file: "), markListPath(zipPath, destDir), func(path string, ok bool) {
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+		if !ok || path == "" {
+			return
+		}
+		util.RecordUsage("action:save-marks")
+		if err := util.WriteNameList(path, names); err != nil {
+			status.showError(err)
+			return
+		}
+		status.setMessage(fmt.Sprintf(palette.success+tr("Saved %d names to %s")+"[-]", len(names), path))
+	})
+}
+
+// promptLoadMarks asks for a list of names, as promptSaveMarks writes them,
+// and marks the entries it names, filtered out or not. Marks already set
+// are kept.
+func promptLoadMarks(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, entries *entryTable, zipPath, destDir string) {
+	showPrompt(app, tr("Mark the entries in a list"), tr("From file: "), markListPath(zipPath, destDir), func(path string, ok bool) {
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+		if !ok || path == "" {
+			return
+		}
+		names, err := util.ReadNameList(path)
+		if err != nil {
+			status.showError(err)
+			return
+		}
+
+		util.RecordUsage("action:load-marks")
+		marked, missing := entries.markNames(names)
+		status.refresh()
+		if missing > 0 {
+			status.setMessage(fmt.Sprintf(palette.warning+tr("Marked %d entries; %d names not found")+"[-]", marked, missing))
+			return
+		}
+		status.setMessage(fmt.Sprintf(tr("Marked %d entries from %s"), marked, path))
+	})
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"path"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// nameScrollStep is the number of cells Left and Right scroll the names by.
+const nameScrollStep = 8
+
+// scrollNames scrolls the name column by delta cells, right when positive,
+// stopping once the longest name ends in view.
+func (t *entryTable) scrollNames(delta int) {
+	limit := t.widths[0] - 1
+	if nameWidth := t.fit().nameWidth; nameWidth > 0 {
+		// The ellipsis shown in front takes a cell.
+		limit = t.widths[0] - nameWidth + 1
+	}
+	t.nameOffset = min(max(t.nameOffset+delta, 0), max(limit, 0))
+}
+
+// displayName is the name of the i-th entry as the name column shows it,
+// printable but not escaped: as stored in storedNames mode, without its
+// folder in basenames mode, and with its first cells cut while the column
+// is scrolled.
+func (t *entryTable) displayName(i int) string {
+	name := printableName(t.rows[i][0])
+	if t.storedNames {
+		name = printableName(t.files[i].GetStoredName())
+	}
+	if t.basenames {
+		name = baseName(name)
+	}
+	if t.nameOffset > 0 {
+		name = "…" + cutLeft(name, t.nameOffset)
+	}
+	return name
+}
+
+// baseName returns the last element of an entry name, keeping the slash of
+// folders.
+func baseName(name string) string {
+	base := path.Base(name)
+	if strings.HasSuffix(name, "/") {
+		base += "/"
+	}
+	return base
+}
+
+// breadcrumb is the title of the browser in basenames mode: the archive
+// followed by the folders of the selected entry. When longer than width,
+// the outer folders give way to an ellipsis.
+func breadcrumb(fileName, name string, width int) string {
+	var folders []string
+	if dir := path.Dir(strings.TrimSuffix(name, "/")); dir != "." {
+		folders = strings.Split(dir, "/")
+	}
+
+	crumbs := append([]string{fileName}, folders...)
+	for i := 1; i < len(crumbs) && width > 0 && displayWidth(strings.Join(crumbs, " › ")) > width; i++ {
+		crumbs = append([]string{fileName, "…"}, folders[i:]...)
+	}
+	for i, crumb := range crumbs {
+		crumbs[i] = tview.Escape(printableName(crumb))
+	}
+	return strings.Join(crumbs, " › ")
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// How long notifications stay on screen. Errors stay longer, as they
+// usually need reading twice.
+const (
+	messageTimeout = 5 * time.Second
+	errorTimeout   = 15 * time.Second
+)
+
+// messageLogSize is the number of notifications kept for the message log.
+const messageLogSize = 100
+
+// loggedMessage is a notification as the message log lists it.
+type loggedMessage struct {
+	at   time.Time
+	text string
+}
+
+// messageLog holds the last notifications of the session, oldest first. It
+// outlives the browsers, which are rebuilt whenever the archive changes.
+var messageLog []loggedMessage
+
+// notifier shows transient notifications in a view, each one replacing the
+// previous one and clearing itself after a while, and logs them. Between
+// notifications the view shows the hint, if any.
+type notifier struct {
+	app  *tview.Application
+	view *tview.TextView
+	// shown counts the notifications, so a timer only clears its own.
+	shown int
+	// active tells whether a notification is on screen.
+	active bool
+	hint   string
+}
+
+// newNotifier shows the notifications of app in view.
+func newNotifier(app *tview.Application, view *tview.TextView) *notifier {
+	return &notifier{app: app, view: view}
+}
+
+// notify shows text, with color tags, for timeout. An empty text clears
+// the current notification.
+func (n *notifier) notify(text string, timeout time.Duration) {
+	n.shown++
+	n.active = text != ""
+	if text == "" {
+		n.view.SetText(n.hint)
+		return
+	}
+	n.view.SetText(text)
+
+	messageLog = append(messageLog, loggedMessage{at: time.Now(), text: text})
+	if len(messageLog) > messageLogSize {
+		messageLog = messageLog[len(messageLog)-messageLogSize:]
+	}
+
+	id := n.shown
+	time.AfterFunc(timeout, func() {
+		n.app.QueueUpdateDraw(func() {
+			if n.shown == id {
+				n.active = false
+				n.view.SetText(n.hint)
+			}
+		})
+	})
+}
+
+// setHint shows text, with color tags, whenever no notification is on
+// screen, without logging it; "" removes the hint.
+func (n *notifier) setHint(text string) {
+	n.hint = text
+	if !n.active {
+		n.view.SetText(text)
+	}
+}
+
+// showMessageLog lists the notifications of the session full screen, the
+// latest last. Esc or q goes back.
+func showMessageLog(app *tview.Application, layout *tview.Flex, table *tview.Table) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	view.SetBorder(true).SetTitle(tr("Messages"))
+	view.SetText(formatMessageLog(messageLog))
+	view.ScrollToEnd()
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
+
+// formatMessageLog renders messages for the message log view.
+func formatMessageLog(messages []loggedMessage) string {
+	var b strings.Builder
+
+	if len(messages) == 0 {
+		b.WriteString(palette.muted + tr("No messages yet.") + "[-]\n")
+	}
+	for _, m := range messages {
+		fmt.Fprintf(&b, "%s%s[-] %s\n", palette.muted, m.at.Format(time.TimeOnly), m.text)
+	}
+	b.WriteString("\n" + palette.muted + tr("Esc close") + "[-]")
+
+	return b.String()
+}
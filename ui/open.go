@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// viewerButtons are the choices of the open with dialog.
+var viewerButtons = map[string]util.Viewer{
+	"Pager":       util.ViewerPager,
+	"Editor":      util.ViewerEditor,
+	"Default app": util.ViewerDefault,
+}
+
+// promptOpenWith asks which program should open the selected file, then
+// extracts it to a temporary folder and opens it there. The pager and the
+// editor take over the terminal until they exit; if the file was edited,
+// saving it back into the archive is offered.
+func promptOpenWith(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath string) {
+	row, _ := table.GetSelection()
+	entry, ok := cellEntry(table.GetCell(row, 0))
+	if !ok {
+		return
+	}
+	if entry.IsDir() {
+		status.showError(errors.New("only files can be opened, not folders"))
+		return
+	}
+	name := entry.GetName()
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Open '%s' with", name)).
+		AddButtons([]string{"Pager", "Editor", "Default app", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			viewer, ok := viewerButtons[buttonLabel]
+			if !ok {
+				return
+			}
+			util.RecordUsage("action:open-" + string(viewer))
+			if err := openEntry(app, layout, table, status, fileName, zipPath, name, viewer); err != nil {
+				status.showError(err)
+			}
+		})
+
+	app.SetRoot(modal, true)
+}
+
+// openEntry extracts the file name to a temporary folder and opens it with
+// viewer. The default application runs on its own, so its copy is left for
+// the system to clean up; the others are waited for and their copy removed.
+func openEntry(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath, name string, viewer util.Viewer) error {
+	tmpPath, err := util.ExtractToTemp(zipPath, name)
+	if err != nil {
+		return err
+	}
+	args, err := util.OpenCommand(viewer, tmpPath)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(tmpPath))
+		return err
+	}
+
+	if viewer == util.ViewerDefault {
+		if err := startProgram(args); err != nil {
+			return err
+		}
+		status.setMessage(fmt.Sprintf(palette.success+"Opened %s[-]", name))
+		return nil
+	}
+
+	before, _ := stampOf(tmpPath)
+	app.Suspend(func() {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		err = cmd.Run()
+	})
+	if err != nil {
+		os.RemoveAll(filepath.Dir(tmpPath))
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	if after, _ := stampOf(tmpPath); viewer == util.ViewerEditor && after != before {
+		confirmSaveEdit(app, layout, table, status, fileName, zipPath, name, tmpPath)
+		return nil
+	}
+	os.RemoveAll(filepath.Dir(tmpPath))
+	return nil
+}
+
+// confirmSaveEdit offers to replace the entry name with its copy edited at
+// tmpPath, which is removed either way.
+func confirmSaveEdit(app *tview.Application, layout *tview.Flex, table *tview.Table, status *statusBar, fileName, zipPath, name, tmpPath string) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("'%s' was changed.\n\nSave the changes into %s?", name, fileName)).
+		AddButtons([]string{"Save", "Discard"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			defer os.RemoveAll(filepath.Dir(tmpPath))
+			if buttonLabel == "Save" {
+				util.RecordUsage("action:open-save")
+				view := browserView{selected: name}
+				err := util.ReplaceEntry(zipPath, name, tmpPath)
+				if err == nil {
+					err = reloadBrowserView(app, fileName, zipPath, view, fmt.Sprintf(palette.success+"Saved %s[-]", name))
+				}
+				if err == nil {
+					return
+				}
+				status.showError(err)
+			}
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+		})
+
+	app.SetRoot(modal, true)
+}
+
+// extractedDir is the folder the last successful extraction wrote into,
+// which openExtractedDir shows.
+var extractedDir string
+
+// openExtractedDir opens the folder of the last extraction in the file
+// manager.
+func openExtractedDir(status *statusBar) {
+	if extractedDir == "" {
+		status.showError(errors.New("nothing was extracted yet"))
+		return
+	}
+
+	util.RecordUsage("action:open-folder")
+	args, err := util.OpenCommand(util.ViewerDefault, extractedDir)
+	if err == nil {
+		err = startProgram(args)
+	}
+	if err != nil {
+		status.showError(err)
+		return
+	}
+	status.setMessage(fmt.Sprintf(palette.success+"Opened %s[-]", extractedDir))
+}
+
+// startProgram runs a desktop program on its own, without waiting for it.
+func startProgram(args []string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// copyEntryText puts the content of the selected file on the clipboard,
+// for small text files only.
+func copyEntryText(app *tview.Application, table *tview.Table, status *statusBar, zipPath string) {
+	row, _ := table.GetSelection()
+	entry, ok := cellEntry(table.GetCell(row, 0))
+	if !ok {
+		return
+	}
+	if entry.IsDir() {
+		status.showError(errors.New("only files can be copied, not folders"))
+		return
+	}
+
+	util.RecordUsage("action:copy-content")
+	text, err := util.ReadTextEntry(zipPath, entry.GetName())
+	if err != nil {
+		status.showError(err)
+		return
+	}
+	copyToClipboard(app, text)
+	status.setMessage(fmt.Sprintf(palette.success+"Copied the content of %s (%s)[-]", entry.GetName(), util.FormatSize(uint64(len(text)))))
+}
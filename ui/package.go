@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// formatPackageInfo renders the package tab of the info view, for JARs and
+// APKs: the key attributes, the signers and the whole main section of the
+// manifest.
+func formatPackageInfo(pkg util.PackageInfo) string {
+	var b strings.Builder
+
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "[::b]%-12s[::-] %s\n", label+":", tview.Escape(value))
+		}
+	}
+	version := pkg.VersionName
+	if pkg.VersionCode != "" {
+		version = strings.TrimSpace(version + " (" + pkg.VersionCode + ")")
+	}
+	row("Kind", string(pkg.Kind))
+	row("Package", pkg.Package)
+	row("Title", pkg.Attribute("Implementation-Title"))
+	row("Version", version)
+	row("Version", pkg.Attribute("Implementation-Version"))
+	row("Min SDK", pkg.MinSDK)
+	row("Target SDK", pkg.TargetSDK)
+	row("Main class", pkg.MainClass())
+
+	b.WriteString("\n[::b]Signers:[::-]\n")
+	if len(pkg.Signers) == 0 {
+		b.WriteString(palette.warning + "  not signed[-]\n")
+	}
+	for _, s := range pkg.Signers {
+		schemes := strings.Join(s.Schemes, ", ")
+		if s.Subject == "" {
+			fmt.Fprintf(&b, "  "+palette.failure+"unreadable certificate[-] (%s)\n", schemes)
+			continue
+		}
+		until := s.NotAfter.UTC().Format(time.DateOnly)
+		if s.NotAfter.Before(time.Now()) {
+			until = palette.warning + "expired " + until + "[-]"
+		} else {
+			until = "valid until " + until
+		}
+		fmt.Fprintf(&b, "  %s (%s)\n", tview.Escape(s.Subject), schemes)
+		fmt.Fprintf(&b, "    "+palette.muted+"issued by %s, [-]%s\n", tview.Escape(s.Issuer), until)
+		fmt.Fprintf(&b, "    "+palette.muted+"sha256 %s[-]\n", s.Fingerprint)
+	}
+
+	if len(pkg.Manifest) > 0 {
+		b.WriteString("\n[::b]Manifest:[::-]\n")
+		for _, attr := range pkg.Manifest {
+			fmt.Fprintf(&b, "  %s: %s\n", tview.Escape(attr.Name), tview.Escape(attr.Value))
+		}
+	}
+
+	return b.String()
+}
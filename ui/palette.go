@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// paletteCommand is a command of the command palette.
+type paletteCommand struct {
+	name string
+	help string
+	// keys are the keys doing the same from the browser, "" for none.
+	keys string
+	run  func()
+}
+
+// browserCommands lists the commands of the palette: every action of the
+// browser's keymap, bound to a key or not, then sorting by each column
+// shown and back in archive order.
+func browserCommands(entries *entryTable, run func(browserAction)) []paletteCommand {
+	var commands []paletteCommand
+	for _, binding := range browserKeys {
+		if binding.action == actionPalette || !binding.active(false) {
+			continue
+		}
+		commands = append(commands, paletteCommand{
+			name: string(binding.action),
+			help: tr(binding.help),
+			keys: strings.Join(binding.keys, ", "),
+			run:  func() { run(binding.action) },
+		})
+	}
+	for _, field := range entries.columns() {
+		title := strings.ToLower(tr(headerOf(field)))
+		commands = append(commands, paletteCommand{
+			name: fmt.Sprintf(tr("sort by %s"), title),
+			help: fmt.Sprintf(tr("sort the entries by %s; again to reverse the order, a third time to go back to archive order"), title),
+			run:  func() { entries.cycleSort(field) },
+		})
+	}
+	commands = append(commands, paletteCommand{
+		name: tr("archive order"),
+		help: tr("list the entries in the order the archive stores them"),
+		run:  func() { entries.setOrder(sortOrder{}) },
+	})
+	return commands
+}
+
+// matchCommands returns the commands whose name and help match query, best
+// matches first, see fuzzyScore; all of them, in order, for an empty query.
+func matchCommands(commands []paletteCommand, query string) []paletteCommand {
+	type match struct {
+		command paletteCommand
+		score   int
+	}
+	var matches []match
+	for _, c := range commands {
+		// The name counts more than the help.
+		score, ok := fuzzyScore(query, c.name)
+		if ok {
+			score += 100
+		} else if score, ok = fuzzyScore(query, c.name+" "+c.help); !ok {
+			continue
+		}
+		matches = append(matches, match{c, score})
+	}
+	slices.SortStableFunc(matches, func(a, b match) int { return cmp.Compare(b.score, a.score) })
+
+	result := make([]paletteCommand, len(matches))
+	for i, m := range matches {
+		result[i] = m.command
+	}
+	return result
+}
+
+// fuzzyScore reports whether the letters of query appear in text in the
+// same order, ignoring case and spaces, as "xal" does in "extract-all",
+// and how well: letters following each other and letters starting a word
+// score higher.
+func fuzzyScore(query, text string) (int, bool) {
+	runes := []rune(strings.ToLower(text))
+	score, next := 0, 0
+	for _, q := range strings.ToLower(query) {
+		if unicode.IsSpace(q) {
+			continue
+		}
+		i := slices.Index(runes[next:], q)
+		if i < 0 {
+			return 0, false
+		}
+		i += next
+
+		score++
+		if i == next && next > 0 {
+			score += 2
+		}
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			score += 3
+		}
+		next = i + 1
+	}
+	return score, true
+}
+
+// showPalette draws the command palette over the browser: typing narrows
+// the commands down, Up and Down pick one, Enter runs it and Esc closes the
+// palette.
+func showPalette(app *tview.Application, layout *tview.Flex, table *tview.Table, commands []paletteCommand) {
+	input := tview.NewInputField().
+		SetLabel("> ").
+		SetFieldWidth(0).
+		SetFieldBackgroundColor(currentTheme.field)
+	list := tview.NewTable().
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selection)
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(palette.muted + tr("Up/Down pick • Enter run • Esc close") + "[-]")
+
+	var shown []paletteCommand
+	fill := func(query string) {
+		shown = matchCommands(commands, query)
+		list.Clear()
+		for i, c := range shown {
+			list.SetCell(i, 0, tview.NewTableCell(palette.highlight+tview.Escape(c.name)+"[-]"))
+			list.SetCell(i, 1, tview.NewTableCell(palette.muted+tview.Escape(c.keys)+"[-]"))
+			list.SetCell(i, 2, tview.NewTableCell(tview.Escape(c.help)).SetExpansion(1))
+		}
+		list.ScrollToBeginning()
+		list.Select(0, 0)
+	}
+	fill("")
+
+	closePalette := func() {
+		app.SetRoot(layout, true)
+		app.SetFocus(table)
+	}
+
+	input.SetChangedFunc(fill)
+	input.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch ev.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			list.InputHandler()(ev, nil)
+			return nil
+		case tcell.KeyEscape:
+			closePalette()
+			return nil
+		case tcell.KeyEnter:
+			row, _ := list.GetSelection()
+			if row < 0 || row >= len(shown) {
+				return nil
+			}
+			closePalette()
+			shown[row].run()
+			return nil
+		}
+		return ev
+	})
+
+	frame := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false).
+		AddItem(hint, 1, 0, false)
+	frame.SetBorder(true).SetTitle(tr("Commands"))
+
+	// Like the help, the palette takes the middle of the screen.
+	window := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(frame, 0, 6, true).
+			AddItem(nil, 0, 1, false), 0, 6, true).
+		AddItem(nil, 0, 1, false)
+
+	pages := tview.NewPages().
+		AddPage("browser", layout, true, true).
+		AddPage("palette", window, true, true)
+
+	app.SetRoot(pages, true)
+	app.SetFocus(input)
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// diskPaneDir is the folder the disk pane lists, "" while it is hidden. It
+// outlives the browsers, which are rebuilt whenever the archive changes, so
+// the pane stays open after adding files.
+var diskPaneDir string
+
+// twoPanes holds the archive table of a browser and, in two panes mode, a
+// pane listing a folder of the disk next to it. Copying from one pane to
+// the other extracts the selected entry into that folder, or adds the file
+// or folder selected on disk to the archive.
+type twoPanes struct {
+	*tview.Flex
+
+	app      *tview.Application
+	layout   *tview.Flex
+	table    *tview.Table
+	status   *statusBar
+	fileName string
+	zipPath  string
+	// disk is nil while the disk pane is hidden.
+	disk *dirPane
+}
+
+// newTwoPanes builds the panes of a browser; the archive table is added
+// once built, with setTable.
+func newTwoPanes(app *tview.Application, layout *tview.Flex, status *statusBar, fileName, zipPath string) *twoPanes {
+	return &twoPanes{Flex: tview.NewFlex(), app: app, layout: layout, status: status, fileName: fileName, zipPath: zipPath}
+}
+
+// setTable adds the archive table, and the disk pane if it was open in the
+// previous browser.
+func (p *twoPanes) setTable(table *tview.Table) {
+	p.table = table
+	p.AddItem(table, 0, 1, true)
+	if diskPaneDir != "" {
+		if err := p.showDisk(diskPaneDir); err != nil {
+			diskPaneDir = ""
+		}
+	}
+}
+
+// toggle shows the disk pane, listing the folder last extracted into from
+// it, the configured destination or the current directory, and moves to
+// it, or hides it.
+func (p *twoPanes) toggle() {
+	if p.disk != nil {
+		p.RemoveItem(p.disk)
+		p.disk = nil
+		diskPaneDir = ""
+		p.app.SetFocus(p.table)
+		return
+	}
+
+	// The folder last extracted into may be gone since.
+	var err error
+	for _, dir := range []string{lastDestDir(), settings.DestDir, "."} {
+		if dir == "" {
+			continue
+		}
+		if dir, err = filepath.Abs(dir); err != nil {
+			continue
+		}
+		if err = p.showDisk(dir); err == nil {
+			p.app.SetFocus(p.disk)
+			return
+		}
+	}
+	p.status.showError(err)
+}
+
+// switchFocus moves between the archive table and the disk pane.
+func (p *twoPanes) switchFocus() {
+	switch {
+	case p.disk == nil:
+	case p.table.HasFocus():
+		p.app.SetFocus(p.disk)
+	default:
+		p.app.SetFocus(p.table)
+	}
+}
+
+// showDisk adds the disk pane, listing dir.
+func (p *twoPanes) showDisk(dir string) error {
+	disk := newDirPane(dir, false)
+	if _, err := disk.list(dir, ""); err != nil {
+		return err
+	}
+
+	disk.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		_, ev, err := disk.navigate(ev)
+		if ev == nil {
+			if err != nil {
+				p.status.showError(err)
+			}
+			diskPaneDir = disk.dir
+			return nil
+		}
+
+		binding, ok := lookupKey(browserKeys, ev)
+		if !ok {
+			return ev
+		}
+		switch binding.action {
+		case actionSwitchPane:
+			p.switchFocus()
+		case actionTwoPanes:
+			p.toggle()
+		case actionCopy:
+			p.confirmAdd()
+		case actionHelp:
+			showKeyHelp(p.app, p.layout, p.table)
+		case actionQuit:
+			util.RecordUsage("action:quit")
+			p.app.Stop()
+		default:
+			return ev
+		}
+		return nil
+	})
+
+	p.disk = disk
+	diskPaneDir = dir
+	p.AddItem(disk, 0, 1, false)
+	return nil
+}
+
+// copyToDisk extracts the entry selected in the archive, with everything
+// inside when it is a folder, into the disk pane's folder.
+func (p *twoPanes) copyToDisk() {
+	if p.disk == nil {
+		return
+	}
+	row, _ := p.table.GetSelection()
+	entry, ok := cellEntry(p.table.GetCell(row, 0))
+	if !ok {
+		return
+	}
+
+	util.RecordUsage("action:copy-to-disk")
+	name := entry.GetName()
+	session.destDir = p.disk.dir
+	extractItem(p.app, p.layout, p.table, p.status, p.zipPath, name, p.disk.dir, util.ExtractOptions{}, entry.IsDir())
+
+	// Select what the extraction created in the pane's folder.
+	top, _, _ := strings.Cut(name, "/")
+	if err := p.disk.reload(top); err != nil {
+		p.status.showError(err)
+	}
+}
+
+// confirmAdd asks before adding the file or folder selected in the disk
+// pane to the archive, in the folder of the entry selected there.
+func (p *twoPanes) confirmAdd() {
+	e, ok := p.disk.selection()
+	if !ok || e.name == ".." {
+		return
+	}
+	srcPath := filepath.Join(p.disk.dir, e.name)
+	folder := p.archiveFolder()
+
+	what := "'" + e.name + "'"
+	if e.dir {
+		what = fmt.Sprintf(tr("folder '%s' and all its contents"), e.name+"/")
+	}
+	where := p.fileName
+	if folder != "" {
+		where = fmt.Sprintf(tr("%s in %s"), folder, p.fileName)
+	}
+
+	add := tr("Add")
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf(tr("Add %s to %s?\n\nThe archive will be rewritten with it. Files with the same name are replaced."), what, where)).
+		AddButtons([]string{add, tr("Cancel")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == add {
+				util.RecordUsage("action:copy-to-archive")
+				count, err := util.AddToArchive(p.zipPath, srcPath, folder)
+				if err == nil {
+					err = reloadBrowser(p.app, p.fileName, p.zipPath, fmt.Sprintf(palette.success+tr("Added %d entries")+"[-]", count))
+				}
+				if err == nil {
+					return
+				}
+				p.status.showError(err)
+			}
+			p.app.SetRoot(p.layout, true)
+			p.app.SetFocus(p.disk)
+		})
+
+	p.app.SetRoot(modal, true)
+}
+
+// archiveFolder returns the folder of the entry selected in the archive:
+// the entry itself when it is a folder, "" for the root.
+func (p *twoPanes) archiveFolder() string {
+	row, _ := p.table.GetSelection()
+	entry, ok := cellEntry(p.table.GetCell(row, 0))
+	if !ok {
+		return ""
+	}
+	if entry.IsDir() {
+		return entry.GetName()
+	}
+	dir := path.Dir(entry.GetName())
+	if dir == "." {
+		return ""
+	}
+	return dir + "/"
+}
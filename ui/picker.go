@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// BuildPickerUI builds the interface shown when goZip starts without an
+// archive: a file browser listing the folders and ZIP archives, recognized by
+// their extension or signature, of dir, below the archives opened lately.
+// Enter opens a folder, or an archive in the archive browser, Backspace goes
+// to the parent folder and Tab moves between the recent archives and the
+// folder.
+//
+// Parameters:
+//   - ctx: abandons reading the archive opened; cancel it when the
+//     application stops, as for BuildStreamingUI
+//   - dir: the folder listed first
+//
+// Returns:
+//   - *tview.Application: configured tview application ready to run
+func BuildPickerUI(ctx context.Context, dir string) *tview.Application {
+	app := tview.NewApplication()
+
+	layout := showPicker(ctx, app, dir)
+
+	if !tutorialSeen() {
+		offerTutorial(app, layout)
+	}
+
+	return app
+}
+
+// recentShown is the number of recent archives the picker lists at most.
+const recentShown = 8
+
+// showPicker makes the file picker, listing dir, the root of app and returns
+// its layout.
+func showPicker(ctx context.Context, app *tview.Application, dir string) *tview.Flex {
+	header := tview.NewTextView().SetDynamicColors(true)
+	header.SetBackgroundColor(currentTheme.header)
+
+	pane := newDirPane(dir, true)
+
+	line := tview.NewTextView().SetDynamicColors(true)
+	notes := newNotifier(app, line)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(header, 1, 0, false)
+
+	// open shows the archive at path in the archive browser, once its
+	// signature is verified, see openVerified.
+	open := func(path string) {
+		util.RecordUsage("action:picker-open")
+		stream, err := util.OpenArchiveStream(path)
+		if err != nil {
+			notes.notify(errorMessage(err), errorTimeout)
+			return
+		}
+		openVerified(app, path, func() {
+			showStreamingBrowser(ctx, app, filepath.Base(path), path, stream)
+		}, func() {
+			stream.Close()
+			app.SetRoot(layout, true)
+		})
+	}
+
+	hints := "• Enter open • Backspace parent folder • q exit"
+	recent := recentArchives()
+	var recentTable *tview.Table
+	if len(recent) > 0 {
+		hints = "• Enter open • Backspace parent folder • Tab recent/folder • q exit"
+		recentTable = buildRecentTable(recent)
+		layout.AddItem(recentTable, len(recent)+2, 0, true)
+	}
+	header.SetText("[::b]goZip! " + palette.muted + hints + "[-]")
+	layout.AddItem(pane, 0, 1, recentTable == nil).
+		AddItem(line, 1, 0, false)
+
+	// keys handles the keys both lists share.
+	keys := func(ev *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case ev.Key() == tcell.KeyTab && recentTable != nil:
+			if pane.HasFocus() {
+				app.SetFocus(recentTable)
+			} else {
+				app.SetFocus(pane)
+			}
+		case ev.Key() == tcell.KeyRune && ev.Rune() == 'q':
+			app.Stop()
+		default:
+			return ev
+		}
+		return nil
+	}
+
+	if recentTable != nil {
+		recentTable.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+			if ev.Key() == tcell.KeyEnter {
+				row, _ := recentTable.GetSelection()
+				if path, ok := recentTable.GetCell(row, 0).GetReference().(string); ok {
+					open(path)
+				}
+				return nil
+			}
+			return keys(ev)
+		})
+	}
+
+	// listed tells when a folder holds no archive.
+	listed := func(entries []dirEntry, err error) {
+		switch {
+		case err != nil:
+			notes.notify(errorMessage(err), errorTimeout)
+		case slices.ContainsFunc(entries, func(e dirEntry) bool { return !e.dir }):
+			notes.notify("", 0)
+		default:
+			notes.notify(palette.muted+"No archives in this folder[-]", messageTimeout)
+		}
+	}
+
+	pane.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		entries, ev, err := pane.navigate(ev)
+		if ev == nil {
+			listed(entries, err)
+			return nil
+		}
+
+		if ev.Key() == tcell.KeyEnter {
+			if e, ok := pane.selection(); ok {
+				open(filepath.Join(pane.dir, e.name))
+			}
+			return nil
+		}
+		return keys(ev)
+	})
+
+	listed(pane.list(dir, ""))
+	app.SetRoot(layout, true)
+
+	return layout
+}
+
+// recentArchives returns the archives opened lately that are still there,
+// the latest first, at most recentShown.
+func recentArchives() []util.ArchiveState {
+	var recent []util.ArchiveState
+	for _, a := range previousSession().Recent {
+		if info, err := os.Stat(a.Path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		recent = append(recent, a)
+		if len(recent) == recentShown {
+			break
+		}
+	}
+	return recent
+}
+
+// buildRecentTable lists the recent archives: name, folder and when each
+// was last opened.
+func buildRecentTable(recent []util.ArchiveState) *tview.Table {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selection)
+	table.SetBorder(true).SetTitle("Recent").SetTitleAlign(tview.AlignCenter)
+
+	for row, a := range recent {
+		table.SetCell(row, 0, tview.NewTableCell(tview.Escape(filepath.Base(a.Path))).SetReference(a.Path))
+		table.SetCell(row, 1, tview.NewTableCell(palette.muted+tview.Escape(filepath.Dir(a.Path))+"[-]").SetExpansion(1))
+		table.SetCell(row, 2, tview.NewTableCell(a.OpenedAt.Local().Format(time.DateTime)))
+	}
+	return table
+}
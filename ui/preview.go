@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showExtractionPreview lists the files extracting targetName would write,
+// flagging the ones that would be overwritten, without touching the disk.
+// back is called when the preview is closed.
+func showExtractionPreview(app *tview.Application, zipPath, targetName, destDir string, back func()) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("Extraction preview: %s", targetName))
+
+	dest := destDir
+	if dest == "" {
+		dest = "."
+	}
+
+	plan, err := util.PlanExtraction(zipPath, targetName, destDir, extractOptions(util.ExtractOptions{}))
+	if err != nil {
+		view.SetText(fmt.Sprintf(palette.failure+"Error: %s[-]\n\n"+palette.muted+"Esc back[-]", tview.Escape(err.Error())))
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "[::b]%d files, %s to write into %s[::-]", len(plan.Files), util.FormatSize(plan.TotalSize), tview.Escape(dest))
+		if plan.Overwrites > 0 {
+			fmt.Fprintf(&b, " "+palette.warning+"(%d overwrite existing files)[-]", plan.Overwrites)
+		}
+		if plan.Kept > 0 {
+			fmt.Fprintf(&b, " "+palette.muted+"(%d existing files kept)[-]", plan.Kept)
+		}
+		if !plan.Fits() {
+			fmt.Fprintf(&b, " "+palette.failure+"(only %s free)[-]", util.FormatSize(plan.FreeSpace))
+		}
+		b.WriteString("\n\n")
+
+		for _, f := range plan.Files {
+			if f.Overwrites {
+				fmt.Fprintf(&b, palette.warning+"overwrite[-] %s "+palette.muted+"(%s)[-]\n", tview.Escape(f.Path), util.FormatSize(f.Size))
+			} else {
+				fmt.Fprintf(&b, palette.success+"new[-]       %s "+palette.muted+"(%s)[-]\n", tview.Escape(f.Path), util.FormatSize(f.Size))
+			}
+		}
+		b.WriteString("\n" + palette.muted + "Esc back[-]")
+		view.SetText(b.String())
+	}
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			back()
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showPrompt displays a centered single-line input over the whole screen.
+// done receives the entered text and true on Enter, or "" and false on Esc;
+// it is responsible for restoring the previous root.
+func showPrompt(app *tview.Application, title, label, initial string, done func(text string, ok bool)) {
+	input := tview.NewInputField().
+		SetLabel(label).
+		SetText(initial).
+		SetFieldWidth(0).
+		SetFieldBackgroundColor(currentTheme.field)
+
+	input.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			done(input.GetText(), true)
+		case tcell.KeyEscape:
+			done("", false)
+		}
+	})
+
+	// Center the box: flexible padding around a fixed-height, 2/3-width row.
+	row := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(input, 0, 4, true).
+		AddItem(nil, 0, 1, false)
+
+	frame := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(row, 3, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	app.SetRoot(frame, true)
+	app.SetFocus(input)
+}
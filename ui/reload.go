@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rivo/tview"
+)
+
+// watchSettle is how long a watched archive must go without changes
+// before it is reloaded.
+const watchSettle = time.Second
+
+// browserView is where the user stands in a browser: the filter applied,
+// the entry selected and the order of the entries.
+type browserView struct {
+	filter   string
+	selected string
+	order    sortOrder
+	unsorted sortOrder
+}
+
+// currentView returns the view of a browser.
+func currentView(table *tview.Table, entries *entryTable) browserView {
+	view := browserView{filter: entries.filter, order: entries.order, unsorted: entries.unsorted}
+	row, _ := table.GetSelection()
+	if entry, ok := entries.entryAt(row); ok {
+		view.selected = entry.GetName()
+	}
+	return view
+}
+
+// restore applies the view to a browser. The selection stays on the first
+// entry when the one selected is gone or filtered out. The zero view leaves
+// the browser as it was built.
+func (v browserView) restore(table *tview.Table, entries *entryTable, status *statusBar) {
+	if v == (browserView{}) {
+		return
+	}
+	if v.order != entries.order {
+		entries.order, entries.unsorted = v.order, v.unsorted
+		entries.sortVisible()
+	}
+	if v.filter != "" {
+		entries.setFilter(v.filter)
+		status.refresh()
+		table.SetOffset(0, 0)
+		table.Select(1, 0)
+	}
+	if row, found := entries.rowOf(v.selected); found {
+		table.Select(row, 0)
+	}
+}
+
+// shownBrowser is the browser of the archive the user opened, outside the
+// tutorial. The browser is rebuilt whenever the archive changes; this is
+// the latest one.
+var shownBrowser struct {
+	panes   *twoPanes
+	entries *entryTable
+}
+
+// archiveStamp tells whether an archive changed on disk since it was read.
+type archiveStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stampOf returns the stamp of the archive at path as it is now.
+func stampOf(path string) (archiveStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return archiveStamp{}, err
+	}
+	return archiveStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// stampLock holds the stamp of the archive when the browser last read it,
+// so the watch leaves alone the changes goZip made itself and reloaded.
+type stampLock struct {
+	sync.Mutex
+	stamp archiveStamp
+}
+
+var watched stampLock
+
+func (l *stampLock) set(stamp archiveStamp) {
+	l.Lock()
+	defer l.Unlock()
+	l.stamp = stamp
+}
+
+func (l *stampLock) get() archiveStamp {
+	l.Lock()
+	defer l.Unlock()
+	return l.stamp
+}
+
+// watchArchive reloads the browser of zipPath, keeping its view, whenever
+// the archive changes on disk, until ctx is done. The folder of the archive
+// is watched rather than the file, so an archive replaced by renaming a new
+// one over it, as most tools save, is still followed. A change is only
+// reloaded once the archive got no events for watchSettle, so an archive
+// still being written is not read half way, and while the browser has the
+// focus, so dialogs and full screen views are not closed under the user.
+func watchArchive(ctx context.Context, app *tview.Application, fileName, zipPath string) {
+	if stamp, err := stampOf(zipPath); err == nil {
+		watched.set(stamp)
+	}
+
+	abs, err := filepath.Abs(zipPath)
+	if err == nil {
+		err = watchFolder(ctx, abs, func() { reloadChanged(app, fileName, zipPath) })
+	}
+	if err != nil {
+		app.QueueUpdateDraw(func() {
+			if panes := shownBrowser.panes; panes != nil {
+				panes.status.showError(fmt.Errorf("cannot watch the archive: %w", err))
+			}
+		})
+	}
+}
+
+// watchFolder calls changed whenever the file at path, which must be
+// absolute, was created, written, renamed or removed and then got no more
+// events for watchSettle, until ctx is done.
+func watchFolder(ctx context.Context, path string, changed func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	settle := time.NewTimer(watchSettle)
+	settle.Stop()
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) == path && !ev.Has(fsnotify.Chmod) {
+				settle.Reset(watchSettle)
+			}
+		case <-settle.C:
+			changed()
+		}
+	}
+}
+
+// reloadChanged reloads the browser of zipPath when the archive differs
+// from the one it shows. It runs outside the UI goroutine.
+func reloadChanged(app *tview.Application, fileName, zipPath string) {
+	// The archive may be missing while another program replaces it.
+	stamp, err := stampOf(zipPath)
+	if err != nil || stamp == watched.get() {
+		return
+	}
+
+	app.QueueUpdateDraw(func() {
+		panes := shownBrowser.panes
+		if panes == nil || !panes.HasFocus() || watched.get() == stamp {
+			return
+		}
+		view := currentView(panes.table, shownBrowser.entries)
+		if err := reloadBrowserView(app, fileName, zipPath, view, palette.warning+"Changed on disk, reloaded[-]"); err != nil {
+			// Wait for the next change rather than failing on every event.
+			watched.set(stamp)
+			panes.status.showError(err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// previousSession is the state saved by the previous runs, read once.
+var previousSession = sync.OnceValue(func() util.Session {
+	s, _ := util.LoadSession()
+	return s
+})
+
+// session is the state of this run that SaveSession keeps for the next
+// ones: the archive opened and the folder last extracted into from the disk
+// pane. Where the cursor and the filter were left is read from shownBrowser.
+var session struct {
+	zipPath  string
+	openedAt time.Time
+	destDir  string
+}
+
+// SaveSession records the archive browsed, with the entry selected and the
+// filter active, among the recent archives, and the folder last extracted
+// into from the disk pane, so the next run can restore them. Call it once
+// the application stopped.
+//
+// Returns:
+//   - error: any error writing the state file
+func SaveSession() error {
+	if session.zipPath == "" && session.destDir == "" {
+		return nil
+	}
+
+	return util.UpdateSession(func(s *util.Session) {
+		if session.destDir != "" {
+			s.LastDestDir = session.destDir
+		}
+		if session.zipPath == "" {
+			return
+		}
+
+		view := currentView(shownBrowser.panes.table, shownBrowser.entries)
+		s.Remember(util.ArchiveState{Path: session.zipPath, OpenedAt: session.openedAt, Selected: view.selected, Filter: view.filter})
+	})
+}
+
+// trackBrowser makes the browser of zipPath the one shown, whose view
+// SaveSession saves and the watch keeps.
+func trackBrowser(zipPath string, panes *twoPanes, entries *entryTable) {
+	abs, err := filepath.Abs(zipPath)
+	if err != nil {
+		return
+	}
+	if abs != session.zipPath {
+		session.zipPath, session.openedAt = abs, time.Now().UTC()
+	}
+	shownBrowser.panes, shownBrowser.entries = panes, entries
+}
+
+// restoreBrowser applies the filter active and selects the entry selected
+// when zipPath was last left, once its entries are listed.
+func restoreBrowser(zipPath string, table *tview.Table, entries *entryTable, status *statusBar) {
+	abs, err := filepath.Abs(zipPath)
+	if err != nil {
+		return
+	}
+	s := previousSession()
+	state, ok := s.Archive(abs)
+	if !ok {
+		return
+	}
+
+	browserView{filter: state.Filter, selected: state.Selected}.restore(table, entries, status)
+	if state.Filter != "" {
+		status.setMessage(fmt.Sprintf(palette.muted+"Restored the filter %q[-]", tview.Escape(state.Filter)))
+	}
+}
+
+// lastDestDir returns the folder last extracted into from the disk pane,
+// in this run or a previous one, "" if none.
+func lastDestDir() string {
+	if session.destDir != "" {
+		return session.destDir
+	}
+	return previousSession().LastDestDir
+}
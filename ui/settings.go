@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/util"
+)
+
+// settings are the options the UI runs with, set by Configure.
+var settings = config.Default()
+
+// Configure applies the user's settings to the UI: the browser's columns and
+// size format, whether it watches the archive for changes, where and how
+// extractions write files and the hooks run after them, the color theme,
+// the keymap, whose actions are named as the help screen lists them, and
+// the language. A NO_COLOR environment variable turns colors off whatever
+// the theme. Call it before BuildUI or BuildStreamingUI.
+//
+// Parameters:
+//   - cfg: the settings, usually from config.Load
+//
+// Returns:
+//   - error: an unknown theme, or an unknown action or key name in cfg.Keys;
+//     the UI is left as it was
+func Configure(cfg config.Config) error {
+	t, err := findTheme(cfg.Theme)
+	if err != nil {
+		return err
+	}
+	keys, err := remapKeys(defaultKeys, cfg.Keys)
+	if err != nil {
+		return fmt.Errorf("invalid key binding: %w", err)
+	}
+
+	applyTheme(t)
+	browserKeys = keys
+	language = cfg.Language.Resolve()
+	settings = cfg
+	return nil
+}
+
+// extractOptions completes opts with the configured concurrency, overwrite
+// policy and content scanner.
+func extractOptions(opts util.ExtractOptions) util.ExtractOptions {
+	if opts.Jobs == 0 {
+		opts.Jobs = settings.Jobs
+	}
+	if opts.Overwrite == "" {
+		opts.Overwrite = settings.Overwrite
+	}
+	if opts.Scanner.IsEmpty() {
+		opts.Scanner = settings.Scanner
+	}
+	return opts
+}
@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// signatureCheck is the outcome of verifying the signature of an archive,
+// see openVerified and checkSignature.
+type signatureCheck struct {
+	result util.SignatureResult
+	err    error
+}
+
+// trusted reports whether the archive opens without asking: the signature
+// is valid, or only expired.
+func (c signatureCheck) trusted() bool {
+	return c.err == nil && (c.result.Valid() || c.result.Status == util.SignatureExpired)
+}
+
+// signatures holds the signature checks done since goZip started, by
+// archive path. It is only used from the UI goroutine.
+var signatures = map[string]signatureCheck{}
+
+// givenSignatures are the signatures given with SetSignature, by absolute
+// archive path.
+var givenSignatures = map[string]string{}
+
+// SetSignature makes the browser verify the archive at zipPath against
+// signature, as "gozip --sig" asks, instead of the signature next to it.
+//
+// Parameters:
+//   - zipPath: path to the ZIP file
+//   - signature: the detached signature, a file or an http(s) URL
+func SetSignature(zipPath, signature string) {
+	if abs, err := filepath.Abs(zipPath); err == nil {
+		givenSignatures[abs] = signature
+	}
+}
+
+// signatureOf returns the signature to verify the archive at zipPath
+// against: the one given with SetSignature, or archive.zip.asc or .sig next
+// to it; "" when there is none.
+func signatureOf(zipPath string) string {
+	if abs, err := filepath.Abs(zipPath); err == nil {
+		if sig, ok := givenSignatures[abs]; ok {
+			return sig
+		}
+	}
+	return util.FindSignature(zipPath)
+}
+
+// openVerified calls open, which shows the browser of zipPath, once the
+// signature of the archive is verified, see signatureOf; right away when it
+// has none. Meanwhile a message tells what goZip waits for, and Esc gives
+// up. A signature that is bad, revoked, made by an unknown key or that
+// cannot be checked is a warning the user must confirm before the archive
+// opens; cancel runs when they do not, or give up waiting.
+func openVerified(app *tview.Application, zipPath string, open, cancel func()) {
+	sig := signatureOf(zipPath)
+	if sig == "" {
+		open()
+		return
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	waiting := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf("\n"+tr("Checking the signature of %s...")+"\n\n"+palette.muted+tr("Esc cancel")+"[-]", tview.Escape(filepath.Base(zipPath))))
+	waiting.SetBorder(true)
+	waiting.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape {
+			stop()
+			cancel()
+			return nil
+		}
+		return ev
+	})
+	app.SetRoot(waiting, true)
+
+	goSafe(app, func() {
+		result, err := util.VerifySignature(ctx, zipPath, sig)
+		app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			stop()
+
+			check := signatureCheck{result: result, err: err}
+			signatures[zipPath] = check
+			if !check.trusted() {
+				confirmSignature(app, zipPath, check, open, cancel)
+				return
+			}
+			open()
+			if panes := shownBrowser.panes; panes != nil {
+				reportSignature(panes.status, check)
+			}
+		})
+	})
+}
+
+// confirmSignature asks whether to open the archive at zipPath although
+// its signature does not verify, then calls open or cancel.
+func confirmSignature(app *tview.Application, zipPath string, check signatureCheck, open, cancel func()) {
+	name := filepath.Base(zipPath)
+	var text string
+	if check.err != nil {
+		text = fmt.Sprintf(tr("The signature of %s could not be checked:\n\n%s\n\nOpen it anyway?"), name, check.err)
+	} else {
+		who := check.result.Signer
+		if who == "" {
+			who = "key " + check.result.KeyID
+		}
+		text = fmt.Sprintf(tr("The signature of %s is %s (%s).\n\nIt may have been modified since it was signed. Open it anyway?"), name, tr(string(check.result.Status)), who)
+	}
+
+	openLabel := tr("Open anyway")
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{tr("Cancel"), openLabel}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel != openLabel {
+				cancel()
+				return
+			}
+			open()
+			if panes := shownBrowser.panes; panes != nil {
+				reportSignature(panes.status, check)
+			}
+		})
+	modal.SetBackgroundColor(currentTheme.failure)
+
+	app.SetRoot(modal, true)
+}
+
+// checkSignature verifies again in the background the signature of an
+// archive already open, after it was rewritten or reloaded, and tells the
+// outcome in the status bar; the info view shows the signer.
+func checkSignature(app *tview.Application, zipPath string, status *statusBar) {
+	sig := signatureOf(zipPath)
+	if sig == "" {
+		return
+	}
+	delete(signatures, zipPath)
+
+	goSafe(app, func() {
+		result, err := util.VerifySignature(context.Background(), zipPath, sig)
+		app.QueueUpdateDraw(func() {
+			check := signatureCheck{result: result, err: err}
+			signatures[zipPath] = check
+			reportSignature(status, check)
+		})
+	})
+}
+
+// reportSignature tells the outcome of a signature check in the status bar.
+func reportSignature(status *statusBar, check signatureCheck) {
+	switch {
+	case check.err != nil:
+		status.showError(fmt.Errorf("could not check the signature: %w", check.err))
+	case check.result.Valid():
+		status.setMessage(fmt.Sprintf(palette.success+tr("Signed by %s")+"[-]", tview.Escape(check.result.Signer)))
+	default:
+		status.setMessage(fmt.Sprintf(palette.failure+tr("The signature is %s; %s shows it")+"[-]", tr(string(check.result.Status)), keyOf(actionInfo)))
+	}
+}
+
+// formatSignature renders what is known about the signature of the archive
+// for the info view.
+func formatSignature(zipPath string) string {
+	if signatureOf(zipPath) == "" {
+		return palette.muted + "none found[-]"
+	}
+	check, ok := signatures[zipPath]
+	switch {
+	case !ok:
+		return palette.muted + "checking...[-]"
+	case check.err != nil:
+		return palette.failure + tview.Escape(check.err.Error()) + "[-]"
+	}
+
+	r := check.result
+	color := palette.failure
+	if r.Valid() {
+		color = palette.success
+	}
+	text := color + string(r.Status) + "[-]"
+	if r.Signer != "" {
+		text += " from " + tview.Escape(r.Signer)
+	}
+	if r.Fingerprint != "" {
+		text += "\n            " + palette.muted + "fingerprint " + r.Fingerprint + "[-]"
+	} else if r.KeyID != "" {
+		text += "\n            " + palette.muted + "key " + r.KeyID + "[-]"
+	}
+	if r.Trust != "" {
+		text += "\n            " + palette.muted + "trust " + r.Trust + "[-]"
+	}
+	return text
+}
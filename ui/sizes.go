@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// largestFilesShown is the number of files in the largest-files list.
+const largestFilesShown = 20
+
+// showSizeBreakdown shows full screen where the size of the archive goes:
+// its largest files and the totals per extension, compression method and
+// top-level folder. It covers the entries listed so far. Esc or q goes back.
+func showSizeBreakdown(app *tview.Application, layout *tview.Flex, table *tview.Table, fileName string, entries *entryTable) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(fmt.Sprintf("Sizes in %s", fileName))
+	view.SetText(formatSizeBreakdown(util.BreakDownSizes(entries.files, largestFilesShown)))
+
+	view.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		if ev.Key() == tcell.KeyEscape || (ev.Key() == tcell.KeyRune && ev.Rune() == 'q') {
+			app.SetRoot(layout, true)
+			app.SetFocus(table)
+			return nil
+		}
+		return ev
+	})
+
+	app.SetRoot(view, true)
+}
+
+// formatSizeBreakdown renders the sections of the sizes view.
+func formatSizeBreakdown(breakdown util.SizeBreakdown) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[::b]Largest files[::-]\n")
+	fmt.Fprintf(&b, palette.muted+"%10s %10s  %s[-]\n", "SIZE", "STORED", "NAME")
+	for _, zf := range breakdown.Largest {
+		fmt.Fprintf(&b, "%10s %10s  %s\n", util.FormatSize(zf.GetSize()), util.FormatSize(zf.GetCompressedSize()), tview.Escape(zf.GetName()))
+	}
+	if len(breakdown.Largest) == 0 {
+		b.WriteString(palette.muted + "No files.[-]\n")
+	}
+
+	writeBuckets(&b, "By extension", breakdown.Extensions)
+	writeBuckets(&b, "By compression method", breakdown.Methods)
+	writeBuckets(&b, "By top-level folder", breakdown.Folders)
+
+	b.WriteString("\n" + palette.muted + "Esc close[-]")
+
+	return b.String()
+}
+
+// writeBuckets renders one section of grouped totals.
+func writeBuckets(b *strings.Builder, title string, buckets []util.SizeBucket) {
+	fmt.Fprintf(b, "\n[::b]%s[::-]\n", title)
+	fmt.Fprintf(b, palette.muted+"%10s %10s %7s  %s[-]\n", "SIZE", "STORED", "FILES", "NAME")
+	for _, bucket := range buckets {
+		fmt.Fprintf(b, "%10s %10s %7d  %s\n", util.FormatSize(bucket.Size), util.FormatSize(bucket.CompressedSize), bucket.Files, tview.Escape(bucket.Name))
+	}
+}
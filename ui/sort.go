@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// sortOrder is the order the browser lists its entries in: by the field of
+// a row, see entryHeaders and ownerHeaders, by path, see comparePaths, or,
+// the zero value, in archive order. Entries that compare equal keep their
+// archive order.
+type sortOrder struct {
+	byField    bool
+	field      int
+	descending bool
+	byPath     bool
+}
+
+// sorted reports whether the order is other than the archive's.
+func (o sortOrder) sorted() bool {
+	return o.byField || o.byPath
+}
+
+// sorts reports whether the entries are sorted by field.
+func (o sortOrder) sorts(field int) bool {
+	return o.byField && o.field == field
+}
+
+// indicator is the arrow shown after the header of the sorted column.
+func (o sortOrder) indicator() string {
+	if o.descending {
+		return " ▼"
+	}
+	return " ▲"
+}
+
+// cycleSort sorts the entries by field, ascending the first time, then
+// descending, then back in archive or path order, whichever the entries
+// are listed in unsorted, keeping the selected entry selected.
+func (t *entryTable) cycleSort(field int) {
+	switch {
+	case !t.order.sorts(field):
+		t.setOrder(sortOrder{byField: true, field: field})
+	case !t.order.descending:
+		t.setOrder(sortOrder{byField: true, field: field, descending: true})
+	default:
+		t.setOrder(t.unsorted)
+	}
+}
+
+// togglePathOrder lists the entries by path, when no column sorts them,
+// or back in archive order, and does so at once. It reports whether they
+// are now listed by path.
+func (t *entryTable) togglePathOrder() bool {
+	t.unsorted.byPath = !t.unsorted.byPath
+	t.setOrder(t.unsorted)
+	return t.unsorted.byPath
+}
+
+// setOrder lists the entries in order, keeping the selected entry
+// selected.
+func (t *entryTable) setOrder(order sortOrder) {
+	t.order = order
+	t.resort()
+}
+
+// resort puts the visible entries in the current order, keeping the
+// selected entry selected.
+func (t *entryTable) resort() {
+	selected := -1
+	if t.table != nil {
+		row, _ := t.table.GetSelection()
+		if row >= 1 && row <= len(t.visible) {
+			selected = t.visible[row-1]
+		}
+	}
+
+	t.sortVisible()
+
+	if selected >= 0 {
+		t.table.Select(slices.Index(t.visible, selected)+1, 0)
+	}
+}
+
+// sortVisible sorts the visible entries in the current order.
+func (t *entryTable) sortVisible() {
+	switch {
+	case t.order.byPath:
+		slices.SortFunc(t.visible, func(a, b int) int {
+			return cmp.Or(comparePaths(t.files[a].GetName(), t.files[b].GetName()), cmp.Compare(a, b))
+		})
+		return
+	case !t.order.byField:
+		slices.Sort(t.visible)
+		return
+	}
+	slices.SortFunc(t.visible, func(a, b int) int {
+		c := t.compare(a, b, t.order.field)
+		if t.order.descending {
+			c = -c
+		}
+		return cmp.Or(c, cmp.Compare(a, b))
+	})
+}
+
+// compare orders the a-th and b-th entries by a field: names as strings,
+// folders before files, and sizes, dates, checksums, modes and owners by
+// value.
+func (t *entryTable) compare(a, b, field int) int {
+	x, y := t.files[a], t.files[b]
+	switch field {
+	case 0:
+		return strings.Compare(x.GetName(), y.GetName())
+	case 1:
+		return -compareBool(x.IsDir(), y.IsDir())
+	case 2:
+		return cmp.Compare(x.GetSize(), y.GetSize())
+	case 3:
+		return x.GetModified().Compare(y.GetModified())
+	case 4:
+		return cmp.Compare(x.GetCrc(), y.GetCrc())
+	case 5:
+		return cmp.Compare(x.GetMode(), y.GetMode())
+	case 6:
+		return cmp.Compare(x.GetUID(), y.GetUID())
+	case 7:
+		return cmp.Compare(x.GetGID(), y.GetGID())
+	}
+	return 0
+}
+
+// comparePaths orders names folder by folder, so that the entries of a
+// folder follow it instead of mixing with names it is a prefix of:
+// "a/", "a/b", "a-b", "a.txt".
+func comparePaths(x, y string) int {
+	for {
+		xs, xrest, xmore := strings.Cut(x, "/")
+		ys, yrest, ymore := strings.Cut(y, "/")
+		if c := strings.Compare(xs, ys); c != 0 {
+			return c
+		}
+		if !xmore || !ymore {
+			return compareBool(xmore, ymore)
+		}
+		x, y = xrest, yrest
+	}
+}
+
+// compareBool orders false before true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// statusBar is the line below the browser's table. Its left part counts the
+// entries: the archive's summary, or its loading progress, then how many
+// entries pass the filter and how many are marked. Notifications, such as
+// the result of the last action, take the rest of the line for a few
+// seconds.
+type statusBar struct {
+	*tview.Flex
+
+	counts  *tview.TextView
+	notes   *notifier
+	entries *entryTable
+	summary string
+}
+
+// newStatusBar builds the status bar of entries, in app.
+func newStatusBar(app *tview.Application, entries *entryTable) *statusBar {
+	message := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignRight)
+	s := &statusBar{
+		Flex:    tview.NewFlex(),
+		counts:  tview.NewTextView().SetDynamicColors(true),
+		notes:   newNotifier(app, message),
+		entries: entries,
+	}
+	s.AddItem(s.counts, 0, 1, false).
+		AddItem(message, 0, 1, false)
+
+	s.refresh()
+	return s
+}
+
+// setSummary replaces the summary of the archive, see archiveSummary.
+func (s *statusBar) setSummary(text string) {
+	s.summary = text
+	s.refresh()
+}
+
+// setMessage notifies the result of the last action; "" clears it.
+func (s *statusBar) setMessage(text string) {
+	s.notes.notify(text, messageTimeout)
+}
+
+// showError notifies err, see errorMessage.
+func (s *statusBar) showError(err error) {
+	util.Logger().Error("action failed", "err", err)
+	s.notes.notify(errorMessage(err), errorTimeout)
+}
+
+// setHint shows text, such as the full name of the selected entry, while
+// no notification is shown; "" removes it.
+func (s *statusBar) setHint(text string) {
+	s.notes.setHint(text)
+}
+
+// refresh renders the counts again, after the filter or the marks changed.
+// The counts keep the width they need and the message gets the rest.
+func (s *statusBar) refresh() {
+	text := s.summary
+	if text == "" {
+		text = palette.muted + fmt.Sprintf(tr("%d entries"), len(s.entries.rows)) + "[-]"
+	}
+	if s.entries.filter != "" {
+		text += palette.muted + fmt.Sprintf(" • "+tr("%d match"), len(s.entries.visible)) + "[-]"
+	}
+	if s.entries.markedCount > 0 {
+		text += palette.highlight + fmt.Sprintf(" • "+tr("%d marked, %s"), s.entries.markedCount, util.FormatSize(s.entries.markedSize)) + "[-]"
+	}
+
+	s.counts.SetText(text)
+	s.ResizeItem(s.counts, tview.TaggedStringWidth(text)+1, 0)
+}
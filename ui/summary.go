@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cainlara/gozip/core"
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// archiveSummary renders info for the status bar, e.g.
+// "12 entries • 4.0 MiB, 1.2 MiB compressed (30.0%) • Zip64". duplicates is
+// the number of names stored more than once. Data around the archive, which
+// was skipped to open it, is pointed out first.
+func archiveSummary(info core.ArchiveInfo, duplicates int) string {
+	warning := ""
+	if info.HasJunk() {
+		warning = palette.warning + fmt.Sprintf(tr("Ignored data around the archive: see %s"), keyOf(actionInfo)) + "[-] "
+	}
+
+	entries := fmt.Sprintf(tr("%d entries"), info.Entries)
+	if info.Entries == 1 {
+		entries = tr("1 entry")
+	}
+	parts := []string{
+		entries,
+		fmt.Sprintf(tr("%s, %s compressed (%.1f%%)"), util.FormatSize(info.Size), util.FormatSize(info.CompressedSize), 100*info.Ratio()),
+	}
+	if info.Zip64 {
+		parts = append(parts, "Zip64")
+	}
+	if info.Encrypted {
+		parts = append(parts, tr("encrypted entries"))
+	}
+	switch {
+	case duplicates == 1:
+		parts = append(parts, tr("1 duplicate name"))
+	case duplicates > 1:
+		parts = append(parts, fmt.Sprintf(tr("%d duplicate names"), duplicates))
+	}
+	if info.Comment != "" {
+		comment, _, _ := strings.Cut(strings.TrimSpace(info.Comment), "\n")
+		parts = append(parts, fmt.Sprintf(tr("comment: %s"), comment))
+	}
+
+	return warning + palette.muted + tview.Escape(strings.Join(parts, " • ")) + "[-]"
+}
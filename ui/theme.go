@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// noColorEnv names the environment variable that, when set to anything,
+// turns colors off whatever the theme (see https://no-color.org).
+const noColorEnv = "NO_COLOR"
+
+// theme holds every color of the UI: tview's colors for the primitives, and
+// the colors of the messages written with dynamic color tags.
+type theme struct {
+	name string
+	tview.Theme
+
+	success   tcell.Color
+	warning   tcell.Color
+	failure   tcell.Color
+	muted     tcell.Color
+	highlight tcell.Color
+	// field is the background of input fields.
+	field tcell.Color
+	// header is the background of the header line.
+	header tcell.Color
+	// selection is the style of the selected row of tables.
+	selection tcell.Style
+}
+
+// themes are the built-in themes; the first one is the default and the last
+// one is used when NO_COLOR is set.
+var themes = []theme{
+	{
+		name:      "dark",
+		Theme:     tview.Styles,
+		success:   tcell.ColorGreen,
+		warning:   tcell.ColorYellow,
+		failure:   tcell.ColorRed,
+		muted:     tcell.ColorGray,
+		highlight: tcell.ColorYellow,
+		field:     tcell.ColorBlack,
+		header:    tcell.ColorReset,
+		selection: tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite),
+	},
+	{
+		name: "light",
+		Theme: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorWhite,
+			ContrastBackgroundColor:     tcell.ColorLightGray,
+			MoreContrastBackgroundColor: tcell.ColorSilver,
+			BorderColor:                 tcell.ColorBlack,
+			TitleColor:                  tcell.ColorBlack,
+			GraphicsColor:               tcell.ColorBlack,
+			PrimaryTextColor:            tcell.ColorBlack,
+			SecondaryTextColor:          tcell.ColorNavy,
+			TertiaryTextColor:           tcell.ColorDarkGreen,
+			InverseTextColor:            tcell.ColorWhite,
+			ContrastSecondaryTextColor:  tcell.ColorNavy,
+		},
+		success:   tcell.ColorDarkGreen,
+		warning:   tcell.ColorDarkOrange,
+		failure:   tcell.ColorDarkRed,
+		muted:     tcell.ColorDimGray,
+		highlight: tcell.ColorNavy,
+		field:     tcell.ColorLightGray,
+		header:    tcell.ColorWhite,
+		selection: tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorNavy),
+	},
+	{
+		name: "solarized",
+		Theme: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.NewHexColor(0x002b36),
+			ContrastBackgroundColor:     tcell.NewHexColor(0x073642),
+			MoreContrastBackgroundColor: tcell.NewHexColor(0x586e75),
+			BorderColor:                 tcell.NewHexColor(0x268bd2),
+			TitleColor:                  tcell.NewHexColor(0x93a1a1),
+			GraphicsColor:               tcell.NewHexColor(0x268bd2),
+			PrimaryTextColor:            tcell.NewHexColor(0x839496),
+			SecondaryTextColor:          tcell.NewHexColor(0xb58900),
+			TertiaryTextColor:           tcell.NewHexColor(0x859900),
+			InverseTextColor:            tcell.NewHexColor(0x002b36),
+			ContrastSecondaryTextColor:  tcell.NewHexColor(0x2aa198),
+		},
+		success:   tcell.NewHexColor(0x859900),
+		warning:   tcell.NewHexColor(0xcb4b16),
+		failure:   tcell.NewHexColor(0xdc322f),
+		muted:     tcell.NewHexColor(0x586e75),
+		highlight: tcell.NewHexColor(0xb58900),
+		field:     tcell.NewHexColor(0x073642),
+		header:    tcell.NewHexColor(0x002b36),
+		selection: tcell.StyleDefault.Foreground(tcell.NewHexColor(0x002b36)).Background(tcell.NewHexColor(0x268bd2)),
+	},
+	{
+		// monochrome leaves every color to the terminal.
+		name: "monochrome",
+		Theme: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorDefault,
+			ContrastBackgroundColor:     tcell.ColorDefault,
+			MoreContrastBackgroundColor: tcell.ColorDefault,
+			BorderColor:                 tcell.ColorDefault,
+			TitleColor:                  tcell.ColorDefault,
+			GraphicsColor:               tcell.ColorDefault,
+			PrimaryTextColor:            tcell.ColorDefault,
+			SecondaryTextColor:          tcell.ColorDefault,
+			TertiaryTextColor:           tcell.ColorDefault,
+			InverseTextColor:            tcell.ColorDefault,
+			ContrastSecondaryTextColor:  tcell.ColorDefault,
+		},
+		success:   tcell.ColorDefault,
+		warning:   tcell.ColorDefault,
+		failure:   tcell.ColorDefault,
+		muted:     tcell.ColorDefault,
+		highlight: tcell.ColorDefault,
+		field:     tcell.ColorDefault,
+		header:    tcell.ColorDefault,
+		selection: tcell.StyleDefault.Reverse(true),
+	},
+}
+
+// colorTags are the dynamic color tags of the current theme's message
+// colors, such as "[green]"; "[-]" ends them. They are empty when the
+// theme leaves the color to the terminal.
+type colorTags struct {
+	success   string
+	warning   string
+	failure   string
+	muted     string
+	highlight string
+}
+
+// currentTheme and palette are the theme in use and its tags, set by
+// applyTheme.
+var (
+	currentTheme = themes[0]
+	palette      = currentTheme.tags()
+)
+
+// findTheme returns the built-in theme called name; "" is the default.
+// NO_COLOR picks monochrome whatever the name.
+func findTheme(name string) (theme, error) {
+	i := 0
+	if name != "" {
+		i = slices.IndexFunc(themes, func(t theme) bool { return strings.EqualFold(t.name, name) })
+	}
+	if i < 0 {
+		names := make([]string, len(themes))
+		for j, t := range themes {
+			names[j] = t.name
+		}
+		return theme{}, fmt.Errorf("unknown theme %q (want %s)", name, strings.Join(names, ", "))
+	}
+
+	if os.Getenv(noColorEnv) != "" {
+		return themes[len(themes)-1], nil
+	}
+	return themes[i], nil
+}
+
+// applyTheme makes t the theme of the primitives created from now on and of
+// the messages.
+func applyTheme(t theme) {
+	currentTheme = t
+	palette = t.tags()
+	tview.Styles = t.Theme
+}
+
+// tags returns the color tags of the theme's message colors.
+func (t theme) tags() colorTags {
+	return colorTags{
+		success:   colorTag(t.success),
+		warning:   colorTag(t.warning),
+		failure:   colorTag(t.failure),
+		muted:     colorTag(t.muted),
+		highlight: colorTag(t.highlight),
+	}
+}
+
+// colorTag returns the dynamic color tag setting the text color to c, or ""
+// for the terminal's default color.
+func colorTag(c tcell.Color) string {
+	if c == tcell.ColorDefault {
+		return ""
+	}
+	return "[" + c.String() + "]"
+}
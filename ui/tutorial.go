@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cainlara/gozip/config"
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// tutorialMarker is the file, inside the config directory, whose presence
+// means the first-run tutorial was already offered.
+const tutorialMarker = "tutorial-done"
+
+// tourDir is the scratch folder of the running tour, "" when there is
+// none. The tour removes it when it ends, and Run when goZip quits during
+// the tour.
+var tourDir string
+
+// tourEvent identifies a user action the tutorial waits for.
+type tourEvent int
+
+const (
+	tourSelected tourEvent = iota
+	tourFiltered
+	tourExtractedFile
+	tourExtractedFolder
+)
+
+// tourStep is one instruction of the tutorial and the action that completes it.
+type tourStep struct {
+	text  string
+	until tourEvent
+}
+
+var tourSteps = []tourStep{
+	{text: "Use the [::b]Up/Down[::-] arrows to move the selection between entries.", until: tourSelected},
+	{text: "Press [::b]f[::-], type [::b]src[::-] to filter the listing, then press [::b]Enter[::-] to keep the filter.", until: tourFiltered},
+	{text: "Select a file and press [::b]Enter[::-] to extract it. Tour extractions go to a temporary folder.", until: tourExtractedFile},
+	{text: "Select a folder and press [::b]Enter[::-] to extract it with everything inside.", until: tourExtractedFolder},
+}
+
+// tutorial drives the first-run walkthrough over a generated demo archive.
+// A nil *tutorial is valid and ignores every call, which is how the regular
+// browser runs.
+type tutorial struct {
+	view    *tview.TextView
+	step    int
+	workDir string
+	onDone  func()
+}
+
+// tutorialSeen reports whether the first-run tutorial should be skipped:
+// it was offered already, or a settings file exists, so this is not the
+// first launch. When the config directory cannot be determined the tutorial
+// is not offered.
+func tutorialSeen() bool {
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return true
+	}
+	if _, err := os.Stat(filepath.Join(dir, tutorialMarker)); err == nil {
+		return true
+	}
+
+	p, err := config.Path()
+	if err != nil {
+		return true
+	}
+	_, err = os.Stat(p)
+	return err == nil
+}
+
+// markTutorialSeen records that the tutorial must not be offered again.
+func markTutorialSeen() {
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	os.WriteFile(filepath.Join(dir, tutorialMarker), nil, 0644)
+}
+
+// offerTutorial asks first-time users whether they want a guided tour.
+// The question is remembered as soon as it is asked, so it is asked only
+// once, even when goZip quits before an answer or in the middle of the tour.
+func offerTutorial(app *tview.Application, layout *tview.Flex) {
+	markTutorialSeen()
+
+	modal := tview.NewModal().
+		SetText("Welcome to goZip!\n\nTake a quick tour of selecting, filtering and extracting using a demo archive?").
+		AddButtons([]string{"Take the tour", "Skip"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Take the tour" {
+				if err := startTutorial(app, layout); err == nil {
+					return
+				}
+			}
+			app.SetRoot(layout, true)
+		})
+
+	app.SetRoot(modal, true)
+}
+
+// startTutorial generates the demo archive and shows it with the tour bar.
+// When the tour ends the user is taken back to layout.
+func startTutorial(app *tview.Application, layout *tview.Flex) error {
+	workDir, err := os.MkdirTemp("", "gozip-tour-")
+	if err != nil {
+		return err
+	}
+	tourDir = workDir
+
+	demoPath, err := util.CreateDemoArchive(workDir)
+	if err != nil {
+		removeTourDir()
+		return err
+	}
+
+	content, err := util.ListArchive(demoPath)
+	if err != nil {
+		removeTourDir()
+		return err
+	}
+
+	tour := &tutorial{
+		view:    tview.NewTextView().SetDynamicColors(true).SetWordWrap(true),
+		workDir: workDir,
+	}
+	tour.view.SetBorder(true).SetTitle("Tour").SetBorderColor(currentTheme.highlight)
+	tour.onDone = func() {
+		removeTourDir()
+		app.SetRoot(layout, true)
+	}
+	tour.render()
+
+	demoLayout, _, _ := buildBrowser(app, filepath.Base(demoPath), demoPath, newEntryTable(content), tour)
+	app.SetRoot(demoLayout, true)
+
+	return nil
+}
+
+// removeTourDir removes the scratch folder of the running tour, if any.
+func removeTourDir() {
+	if tourDir != "" {
+		os.RemoveAll(tourDir)
+		tourDir = ""
+	}
+}
+
+// notify advances the tour when the user performs the awaited action.
+func (t *tutorial) notify(ev tourEvent) {
+	if t == nil || t.step >= len(tourSteps) || tourSteps[t.step].until != ev {
+		return
+	}
+
+	t.step++
+	t.render()
+}
+
+// destDir returns where extractions should go: the tour's scratch directory,
+// or the configured destination when no tour is running, "" meaning the
+// current directory.
+func (t *tutorial) destDir() string {
+	if t == nil {
+		return settings.DestDir
+	}
+	return filepath.Join(t.workDir, "extracted")
+}
+
+// finish ends the tour and returns to the archive the user opened.
+func (t *tutorial) finish() {
+	if t == nil || t.onDone == nil {
+		return
+	}
+
+	done := t.onDone
+	t.onDone = nil
+	done()
+}
+
+func (t *tutorial) render() {
+	if t.step >= len(tourSteps) {
+		t.view.SetText(palette.success + "That's it! You can now browse your own archive.[-] Press [::b]Esc[::-] to leave the tour.")
+		return
+	}
+
+	t.view.SetText(fmt.Sprintf(palette.highlight+"Step %d/%d[-] %s "+palette.muted+"(Esc ends the tour)[-]", t.step+1, len(tourSteps), tourSteps[t.step].text))
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// typeAheadTimeout is how long after the last letter typed the next one
+// still extends the name being jumped to, instead of starting a new one.
+const typeAheadTimeout = time.Second
+
+// tableNavigationRunes are the letters tview's table moves the selection
+// with; they never start a jump.
+const tableNavigationRunes = "jkgGhl"
+
+// typeAhead collects the letters typed in the browser to jump to the entry
+// whose name starts with them, as file managers do.
+type typeAhead struct {
+	prefix string
+	last   time.Time
+}
+
+// typed handles a key press at now. It returns the prefix to jump to and
+// true when the key is part of a jump: any character while a jump is under
+// way, a character bound to no action nor table movement, or a character
+// typed with Alt held, which forces a jump. Other keys end the jump.
+func (ta *typeAhead) typed(ev *tcell.EventKey, bound bool, now time.Time) (string, bool) {
+	ongoing := ta.prefix != "" && now.Sub(ta.last) < typeAheadTimeout
+	r := ev.Rune()
+
+	switch {
+	case ev.Key() != tcell.KeyRune || ev.Modifiers()&(tcell.ModCtrl|tcell.ModMeta) != 0 || !unicode.IsPrint(r):
+	case ev.Modifiers()&tcell.ModAlt != 0, ongoing:
+		if !ongoing {
+			ta.prefix = ""
+		}
+		ta.prefix += string(r)
+		ta.last = now
+		return ta.prefix, true
+	case !bound && !strings.ContainsRune(tableNavigationRunes, r):
+		ta.prefix = string(r)
+		ta.last = now
+		return ta.prefix, true
+	}
+
+	ta.prefix = ""
+	return "", false
+}
+
+// rowWithPrefix returns the first table row, from row from on and wrapping
+// around, whose entry path or base name starts with prefix, ignoring case.
+func (t *entryTable) rowWithPrefix(prefix string, from int) (int, bool) {
+	prefix = strings.ToLower(prefix)
+	n := len(t.visible)
+	from = max(from, 1)
+	for k := range n {
+		row := (from-1+k)%n + 1
+		name, _, _ := strings.Cut(t.index[t.visible[row-1]], indexSeparator)
+		if strings.HasPrefix(name, prefix) || strings.HasPrefix(path.Base(name), prefix) {
+			return row, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+// The browser measures text in terminal cells, not bytes or runes: CJK
+// characters and most emoji take two cells, combining marks none. These
+// helpers work on plain text, without color tags, grapheme cluster by
+// grapheme cluster, as tview draws it.
+
+// displayWidth returns the number of cells s takes on screen.
+func displayWidth(s string) int {
+	return uniseg.StringWidth(s)
+}
+
+// cutLeft drops the first cells of s, and the whole of a wide character
+// straddling the cut.
+func cutLeft(s string, cells int) string {
+	state := -1
+	rest := s
+	for cells > 0 && rest != "" {
+		var width int
+		_, rest, width, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		cells -= width
+	}
+	return rest
+}
+
+// printableName replaces the control characters of an entry name, such as
+// tabs or terminal escapes, which would shift the columns or garble the
+// screen, with U+FFFD.
+func printableName(name string) string {
+	if !strings.ContainsFunc(name, unicode.IsControl) {
+		return name
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return unicode.ReplacementChar
+		}
+		return r
+	}, name)
+}
+
+// truncateWidth cuts s to width cells, ending it with an ellipsis when
+// anything was cut.
+func truncateWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+
+	var b strings.Builder
+	state, used := -1, 0
+	for rest := s; rest != ""; {
+		var cluster string
+		var w int
+		cluster, rest, w, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteString(cluster)
+		used += w
+	}
+	return b.String() + "…"
+}
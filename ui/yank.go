@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cainlara/gozip/util"
+	"github.com/rivo/tview"
+)
+
+// yankEntryPath copies the name of the selected entry, its full path
+// inside the archive, to the clipboard.
+func yankEntryPath(app *tview.Application, table *tview.Table, status *statusBar) {
+	row, _ := table.GetSelection()
+	entry, ok := cellEntry(table.GetCell(row, 0))
+	if !ok {
+		return
+	}
+
+	util.RecordUsage("action:yank-path")
+	copyToClipboard(app, entry.GetName())
+	status.setMessage(fmt.Sprintf(palette.success+"Copied %s[-]", entry.GetName()))
+}
+
+// yankDestPath copies the path Enter would extract the selected entry to,
+// in the configured destination or the current directory, to the
+// clipboard. Nothing needs to exist there yet.
+func yankDestPath(app *tview.Application, table *tview.Table, status *statusBar, destDir string) {
+	row, _ := table.GetSelection()
+	entry, ok := cellEntry(table.GetCell(row, 0))
+	if !ok {
+		return
+	}
+
+	if destDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			status.showError(err)
+			return
+		}
+		destDir = wd
+	}
+	dest, err := filepath.Abs(filepath.Join(destDir, filepath.FromSlash(entry.GetName())))
+	if err != nil {
+		status.showError(err)
+		return
+	}
+
+	util.RecordUsage("action:yank-destination")
+	copyToClipboard(app, dest)
+	status.setMessage(fmt.Sprintf(palette.success+"Copied %s[-]", dest))
+}
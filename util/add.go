@@ -0,0 +1,81 @@
+package util
+
+import (
+	"archive/zip"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// AddToArchive adds a file, or a folder with everything inside it, to the
+// existing archive at zipPath, under its base name inside folder.
+//
+// Files already in the archive under the same name are replaced; folder
+// entries already there are kept. New files are deflated, and every other
+// entry is copied without recompression.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - srcPath: file or folder on disk to add
+//   - folder: folder inside the archive to add it to, "" for the root
+//
+// Returns:
+//   - int: number of entries added, new folders included
+//   - error: any error encountered; the archive is unchanged on failure
+func AddToArchive(zipPath, srcPath, folder string) (int, error) {
+	absSrc, err := filepath.Abs(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	sources, err := collectSources(zipPath, []string{absSrc})
+	if err != nil {
+		return 0, err
+	}
+	if len(sources) == 0 {
+		return 0, fmt.Errorf("nothing to add from '%s'", srcPath)
+	}
+
+	prefix := normalizeEntryName(folder)
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, "/") + "/"
+	}
+	for i := range sources {
+		sources[i].name = prefix + sources[i].name
+	}
+
+	var added int
+	err = rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		adding := make(map[string]bool, len(sources))
+		for _, e := range sources {
+			adding[e.name] = true
+		}
+
+		existing := make(map[string]bool, len(r.File))
+		for _, f := range r.File {
+			existing[f.Name] = true
+			if adding[f.Name] && !f.FileInfo().IsDir() {
+				continue
+			}
+			if err := copyEntry(w, f, f.Name); err != nil {
+				return err
+			}
+		}
+
+		for _, e := range sources {
+			if e.info.IsDir() && existing[e.name] {
+				continue
+			}
+			if err := addSource(w, e, DeflateAll, false); err != nil {
+				return fmt.Errorf("failed to add %s: %w", e.diskPath, err)
+			}
+			added++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return added, nil
+}
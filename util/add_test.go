@@ -0,0 +1,83 @@
+package util
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestAddToArchive checks adding files and folders, replacing files with the same name
+func TestAddToArchive(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"a.txt":     "old a",
+		"dir/":      "",
+		"dir/b.txt": "b",
+	})
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "a.txt"), []byte("new a"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(src, "pkg", "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create source folder: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "pkg", "sub", "c.go"), []byte("c"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	count, err := AddToArchive(zipPath, filepath.Join(src, "a.txt"), "")
+	if err != nil {
+		t.Fatalf("AddToArchive() unexpected error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("AddToArchive() = %d, want 1", count)
+	}
+
+	count, err = AddToArchive(zipPath, filepath.Join(src, "pkg"), "dir/")
+	if err != nil {
+		t.Fatalf("AddToArchive() unexpected error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("AddToArchive() = %d, want 3", count)
+	}
+
+	want := []string{"a.txt", "dir/", "dir/b.txt", "dir/pkg/", "dir/pkg/sub/", "dir/pkg/sub/c.go"}
+	if got := entryNames(t, zipPath); !slices.Equal(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != "a.txt" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) unexpected error = %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(data) != "new a" {
+			t.Errorf("a.txt = %q, want the added content", data)
+		}
+	}
+}
+
+// TestAddToArchiveMissingSource checks that a missing source leaves the archive alone
+func TestAddToArchiveMissingSource(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "a"})
+
+	if _, err := AddToArchive(zipPath, filepath.Join(t.TempDir(), "nope"), ""); err == nil {
+		t.Error("AddToArchive() expected error, got nil")
+	}
+	if got := entryNames(t, zipPath); !slices.Equal(got, []string{"a.txt"}) {
+		t.Errorf("entries = %v, want unchanged", got)
+	}
+}
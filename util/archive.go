@@ -0,0 +1,146 @@
+package util
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// Archive is an opened ZIP archive. It is the entry point for programs that
+// embed goZip's archive handling: it lists and extracts entries without
+// involving the command line, the working directory or the terminal UI.
+//
+//	a, err := util.OpenFile("release.zip")
+//	if err != nil {
+//		return err
+//	}
+//	defer a.Close()
+//	n, err := a.Extract(ctx, "docs/", "/tmp/out", util.ExtractOptions{})
+//
+// Every compression method goZip supports can be read.
+type Archive struct {
+	reader *zip.Reader
+	ra     io.ReaderAt
+	size   int64
+	closer io.Closer
+	// path is the archive's file, or "" when it was opened from a reader.
+	path string
+}
+
+// Open reads the archive held by r, which is size bytes long. r must stay
+// readable while the Archive is used; Close does not close it.
+//
+// Parameters:
+//   - r: the archive's content, such as an *os.File or a *bytes.Reader
+//   - size: the size of the archive in bytes
+//
+// Returns:
+//   - *Archive: the opened archive
+//   - error: any error encountered while reading the central directory
+func Open(r io.ReaderAt, size int64) (*Archive, error) {
+	// Insecure names are refused when extracting, with ErrPathTraversal.
+	reader, err := newZipReader(r, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return nil, openError(err)
+	}
+
+	return &Archive{reader: reader, ra: r, size: size}, nil
+}
+
+// newZipReader is zip.NewReader ignoring whatever follows the archive in r,
+// which archive/zip only does when it fits in the last 64 KiB. Data before
+// the archive is skipped by archive/zip itself.
+func newZipReader(r io.ReaderAt, size int64) (*zip.Reader, error) {
+	if cd, _, err := locateCentralDirectory(r, size); err == nil && cd.trailing > 0 {
+		size -= cd.trailing
+		r = io.NewSectionReader(r, 0, size)
+	}
+	return zip.NewReader(r, size)
+}
+
+// zipReadCloser is the zip.ReadCloser of openZipReader.
+type zipReadCloser struct {
+	*zip.Reader
+	file *os.File
+}
+
+// Close closes the archive's file.
+func (z *zipReadCloser) Close() error {
+	return z.file.Close()
+}
+
+// openZipReader is zip.OpenReader with the tolerance of newZipReader to
+// data appended to the archive. Its errors are those of zip.OpenReader.
+func openZipReader(zipPath string) (*zipReadCloser, error) {
+	f, err := os.Open(zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	reader, err := newZipReader(f, info.Size())
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		f.Close()
+		return nil, err
+	}
+
+	return &zipReadCloser{Reader: reader, file: f}, err
+}
+
+// OpenFile opens the archive at zipPath. The file stays open until Close.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - *Archive: the opened archive
+//   - error: any error encountered while opening the archive
+func OpenFile(zipPath string) (*Archive, error) {
+	f, err := os.Open(zipPath)
+	if err != nil {
+		return nil, openError(err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, openError(err)
+	}
+
+	a, err := Open(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	a.closer = f
+	a.path = zipPath
+	return a, nil
+}
+
+// Entries returns the entries of the archive, in the order they appear in
+// the central directory, as ListArchive does.
+func (a *Archive) Entries() ([]core.ZippedFile, error) {
+	return listEntries(a.ra, a.size)
+}
+
+// Info returns the summary of the archive, as ReadArchiveInfo does.
+func (a *Archive) Info() (core.ArchiveInfo, error) {
+	info, _, err := readListing(a.ra, a.size)
+	return info, err
+}
+
+// Close releases the archive's file, if OpenFile opened one.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOpenFromReader(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "alpha", "dir/b.txt": "beta"})
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer a.Close()
+
+	entries, err := a.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.GetName())
+	}
+	if want := []string{"a.txt", "dir/b.txt"}; !slices.Equal(names, want) {
+		t.Errorf("Entries() = %v, want %v", names, want)
+	}
+
+	dest := t.TempDir()
+	n, err := a.Extract(context.Background(), "dir", dest, ExtractOptions{})
+	if err != nil || n != 1 {
+		t.Fatalf("Extract = %d, %v; want 1, nil", n, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dest, "dir", "b.txt")); string(got) != "beta" {
+		t.Errorf("dir/b.txt = %q, want %q", got, "beta")
+	}
+
+	if _, err := a.Extract(context.Background(), "", t.TempDir(), ExtractOptions{Resume: true}); err == nil {
+		t.Error("Resume should need an archive opened from a file")
+	}
+}
+
+func TestOpenRejectsNonZip(t *testing.T) {
+	data := []byte("definitely not a zip archive")
+	if _, err := Open(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("Open should fail on data that is not a ZIP archive")
+	}
+}
+
+func TestExtractCancelled(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "alpha", "b.txt": "beta"})
+	a, err := OpenFile(zipPath)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer a.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dest := t.TempDir()
+	n, err := a.Extract(ctx, "", dest, ExtractOptions{})
+	if !errors.Is(err, context.Canceled) || n != 0 {
+		t.Fatalf("Extract = %d, %v; want 0, context.Canceled", n, err)
+	}
+	if files, _ := os.ReadDir(dest); len(files) != 0 {
+		t.Errorf("cancelled extraction wrote %d files", len(files))
+	}
+}
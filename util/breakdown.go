@@ -0,0 +1,102 @@
+package util
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// SizeBucket adds up the files of an archive sharing an extension, a
+// compression method or a top-level folder.
+type SizeBucket struct {
+	Name           string
+	Files          int
+	Size           uint64
+	CompressedSize uint64
+}
+
+// SizeBreakdown tells where the size of an archive goes.
+type SizeBreakdown struct {
+	// Largest holds the largest files, by uncompressed size.
+	Largest []core.ZippedFile
+	// Extensions, Methods and Folders group the files by extension
+	// (lowercased, "(none)" without one), compression method and top-level
+	// folder ("(root)" for files outside any folder), largest first.
+	Extensions []SizeBucket
+	Methods    []SizeBucket
+	Folders    []SizeBucket
+}
+
+// BreakDownSizes groups the files among entries to answer "what makes this
+// archive so large": its top largest files, and the total sizes per
+// extension, compression method and top-level folder. Folders are left out.
+//
+// Parameters:
+//   - entries: the archive's entries, as returned by ListArchive
+//   - top: the number of largest files to keep
+//
+// Returns:
+//   - SizeBreakdown: the largest files and the size of each group
+func BreakDownSizes(entries []core.ZippedFile, top int) SizeBreakdown {
+	extensions := make(map[string]*SizeBucket)
+	methods := make(map[string]*SizeBucket)
+	folders := make(map[string]*SizeBucket)
+
+	add := func(buckets map[string]*SizeBucket, name string, zf core.ZippedFile) {
+		b, ok := buckets[name]
+		if !ok {
+			b = &SizeBucket{Name: name}
+			buckets[name] = b
+		}
+		b.Files++
+		b.Size += zf.GetSize()
+		b.CompressedSize += zf.GetCompressedSize()
+	}
+
+	var files []core.ZippedFile
+	for _, zf := range entries {
+		if zf.IsDir() {
+			continue
+		}
+		files = append(files, zf)
+
+		ext := strings.ToLower(path.Ext(zf.GetName()))
+		if ext == "" {
+			ext = "(none)"
+		}
+		add(extensions, ext, zf)
+		add(methods, zf.GetMethod(), zf)
+
+		folder := "(root)"
+		if name := strings.TrimPrefix(zf.GetName(), "/"); strings.Contains(name, "/") {
+			folder = name[:strings.Index(name, "/")+1]
+		}
+		add(folders, folder, zf)
+	}
+
+	slices.SortStableFunc(files, func(a, b core.ZippedFile) int {
+		return cmp.Compare(b.GetSize(), a.GetSize())
+	})
+
+	return SizeBreakdown{
+		Largest:    files[:min(max(top, 0), len(files))],
+		Extensions: sortedBuckets(extensions),
+		Methods:    sortedBuckets(methods),
+		Folders:    sortedBuckets(folders),
+	}
+}
+
+// sortedBuckets returns the buckets largest first, then by name.
+func sortedBuckets(buckets map[string]*SizeBucket) []SizeBucket {
+	sorted := make([]SizeBucket, 0, len(buckets))
+	for _, b := range buckets {
+		sorted = append(sorted, *b)
+	}
+	slices.SortFunc(sorted, func(a, b SizeBucket) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Name, b.Name))
+	})
+	return sorted
+}
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// TestBreakDownSizes checks the largest files and the totals per extension,
+// method and top-level folder
+func TestBreakDownSizes(t *testing.T) {
+	entries := []core.ZippedFile{
+		core.NewZippedFile("assets/", true, 0, 0, "STORE", "-", 0),
+		core.NewZippedFile("assets/video.MP4", false, 900, 890, "STORE", "-", 1),
+		core.NewZippedFile("assets/logo.png", false, 100, 100, "STORE", "-", 2),
+		core.NewZippedFile("src/main.go", false, 300, 90, "DEFLATE", "-", 3),
+		core.NewZippedFile("src/util.go", false, 200, 60, "DEFLATE", "-", 4),
+		core.NewZippedFile("LICENSE", false, 50, 30, "DEFLATE", "-", 5),
+	}
+
+	b := BreakDownSizes(entries, 3)
+
+	var largest []string
+	for _, zf := range b.Largest {
+		largest = append(largest, zf.GetName())
+	}
+	if want := []string{"assets/video.MP4", "src/main.go", "src/util.go"}; len(largest) != 3 || largest[0] != want[0] || largest[1] != want[1] || largest[2] != want[2] {
+		t.Errorf("Largest = %v, want %v", largest, want)
+	}
+
+	tests := []struct {
+		name    string
+		buckets []SizeBucket
+		want    []SizeBucket
+	}{
+		{"extensions", b.Extensions, []SizeBucket{
+			{".mp4", 1, 900, 890}, {".go", 2, 500, 150}, {".png", 1, 100, 100}, {"(none)", 1, 50, 30},
+		}},
+		{"methods", b.Methods, []SizeBucket{
+			{"STORE", 2, 1000, 990}, {"DEFLATE", 3, 550, 180},
+		}},
+		{"folders", b.Folders, []SizeBucket{
+			{"assets/", 2, 1000, 990}, {"src/", 2, 500, 150}, {"(root)", 1, 50, 30},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.buckets) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", tt.buckets, tt.want)
+			}
+			for i := range tt.want {
+				if tt.buckets[i] != tt.want[i] {
+					t.Errorf("bucket %d = %+v, want %+v", i, tt.buckets[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if b := BreakDownSizes(entries, 100); len(b.Largest) != 5 {
+		t.Errorf("BreakDownSizes(top 100) kept %d files, want the 5 there are", len(b.Largest))
+	}
+}
@@ -0,0 +1,164 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// CaseCollisionPolicy decides what extraction does with files whose paths
+// differ only in case, as README and readme, when the destination's
+// filesystem cannot tell them apart and would silently keep only one.
+type CaseCollisionPolicy string
+
+const (
+	// CaseCollisionAbort fails with a *CaseCollisionError before writing
+	// anything.
+	CaseCollisionAbort CaseCollisionPolicy = "abort"
+	// CaseCollisionRename writes the second file of a collision as
+	// "readme.1", the third as "readme.2" and so on, in archive order.
+	CaseCollisionRename CaseCollisionPolicy = "rename"
+	// CaseCollisionIgnore writes every file, the later ones replacing the
+	// earlier ones.
+	CaseCollisionIgnore CaseCollisionPolicy = "ignore"
+)
+
+// ParseCaseCollisionPolicy validates a policy name; "" is
+// CaseCollisionAbort.
+func ParseCaseCollisionPolicy(s string) (CaseCollisionPolicy, error) {
+	switch policy := CaseCollisionPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case "":
+		return CaseCollisionAbort, nil
+	case CaseCollisionAbort, CaseCollisionRename, CaseCollisionIgnore:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid case collision policy %q (want abort, rename or ignore)", s)
+	}
+}
+
+// CaseCollisionError is the error of an extraction stopped by
+// CaseCollisionAbort.
+type CaseCollisionError struct {
+	// Collisions lists the groups of entry names that would be written to
+	// the same file, each in archive order.
+	Collisions [][]string
+}
+
+func (e *CaseCollisionError) Error() string {
+	groups := make([]string, len(e.Collisions))
+	for i, names := range e.Collisions {
+		groups[i] = strings.Join(names, " and ")
+	}
+	return fmt.Sprintf("names differing only in case would overwrite each other on this filesystem: %s", strings.Join(groups, "; "))
+}
+
+// caseInsensitiveDir is defaultCaseInsensitiveDir; tests replace it.
+var caseInsensitiveDir = defaultCaseInsensitiveDir
+
+// defaultCaseInsensitiveDir reports whether files created in dir, which may
+// not exist yet, are looked up ignoring case.
+func defaultCaseInsensitiveDir(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return defaultCaseInsensitive()
+	}
+	// Look for the nearest existing folder with a letter in its name, and
+	// see whether it is found under that name in another case.
+	for {
+		base := filepath.Base(dir)
+		if swapped := swapCase(base); swapped != base {
+			if info, err := os.Stat(dir); err == nil {
+				other, err := os.Stat(filepath.Join(filepath.Dir(dir), swapped))
+				return err == nil && os.SameFile(info, other)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return defaultCaseInsensitive()
+		}
+		dir = parent
+	}
+}
+
+// defaultCaseInsensitive tells whether the usual filesystems of this system
+// ignore case.
+func defaultCaseInsensitive() bool {
+	return runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+}
+
+// swapCase turns upper case letters into lower case ones and back.
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// resolveCaseCollisions applies policy to the targets whose paths differ
+// only in case, when destDir ignores case: it fails, renames them, recording
+// why in their renamed field, or leaves them be.
+func resolveCaseCollisions(targets []extractTarget, destDir string, policy CaseCollisionPolicy) error {
+	if policy == CaseCollisionIgnore {
+		return nil
+	}
+
+	// groups maps each folded path to the targets sharing it, in order.
+	groups := make(map[string][]int)
+	var folded []string
+	for i, t := range targets {
+		key := strings.ToLower(t.relPath)
+		if _, ok := groups[key]; !ok {
+			folded = append(folded, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	var collisions [][]int
+	for _, key := range folded {
+		if g := groups[key]; len(g) > 1 {
+			collisions = append(collisions, g)
+		}
+	}
+	if len(collisions) == 0 || !caseInsensitiveDir(destDir) {
+		return nil
+	}
+
+	if policy != CaseCollisionRename {
+		err := &CaseCollisionError{}
+		for _, g := range collisions {
+			names := make([]string, len(g))
+			for j, i := range g {
+				names[j] = targets[i].name
+			}
+			err.Collisions = append(err.Collisions, names)
+		}
+		return err
+	}
+
+	taken := make(map[string]bool, len(groups))
+	for key := range groups {
+		taken[key] = true
+	}
+	for _, g := range collisions {
+		first := targets[g[0]].name
+		for n, i := range g[1:] {
+			rel := targets[i].relPath
+			for suffix := n + 1; ; suffix++ {
+				candidate := fmt.Sprintf("%s.%d", rel, suffix)
+				if !taken[strings.ToLower(candidate)] {
+					rel = candidate
+					break
+				}
+			}
+			taken[strings.ToLower(rel)] = true
+			targets[i].relPath = rel
+			targets[i].renamed = fmt.Sprintf("differs only in case from %s", first)
+		}
+	}
+	return nil
+}
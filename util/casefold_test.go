@@ -0,0 +1,63 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// TestCaseCollisions checks that names differing only in case abort the
+// extraction, or are renamed, when the destination ignores case
+func TestCaseCollisions(t *testing.T) {
+	caseInsensitiveDir = func(string) bool { return true }
+	t.Cleanup(func() { caseInsensitiveDir = defaultCaseInsensitiveDir })
+
+	zipPath := writeTestZip(t, 0, map[string]string{"README": "upper", "docs/a.txt": "a", "readme": "lower"})
+
+	destDir := t.TempDir()
+	_, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{})
+	var collision *CaseCollisionError
+	if !errors.As(err, &collision) || len(collision.Collisions) != 1 || !slices.Equal(collision.Collisions[0], []string{"README", "readme"}) {
+		t.Fatalf("ExtractWithOptions() error = %v, want a collision of README and readme", err)
+	}
+	if files, _ := os.ReadDir(destDir); len(files) != 0 {
+		t.Errorf("aborted extraction wrote %d files", len(files))
+	}
+
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{CaseCollisions: CaseCollisionRename})
+	if err != nil || count != 3 {
+		t.Fatalf("ExtractWithOptions(rename) = %d, %v, want 3 files", count, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(destDir, "readme.1")); err != nil || string(data) != "lower" {
+		t.Errorf("readme.1 = %q, %v, want the content of readme", data, err)
+	}
+
+	if _, err := PlanExtraction(zipPath, "", destDir, ExtractOptions{CaseCollisions: CaseCollisionIgnore}); err != nil {
+		t.Errorf("PlanExtraction(ignore) unexpected error = %v", err)
+	}
+
+	if runtime.GOOS == "linux" && defaultCaseInsensitiveDir(filepath.Join(destDir, "New")) {
+		t.Error("defaultCaseInsensitiveDir() = true for a Linux temporary folder")
+	}
+	caseInsensitiveDir = func(string) bool { return false }
+	if _, err := PlanExtraction(zipPath, "", destDir, ExtractOptions{}); err != nil {
+		t.Errorf("PlanExtraction() on a case-sensitive destination unexpected error = %v", err)
+	}
+}
+
+// TestParseCaseCollisionPolicy checks the policy names
+func TestParseCaseCollisionPolicy(t *testing.T) {
+	if p, err := ParseCaseCollisionPolicy(""); err != nil || p != CaseCollisionAbort {
+		t.Errorf("ParseCaseCollisionPolicy(\"\") = %q, %v, want abort", p, err)
+	}
+	if p, err := ParseCaseCollisionPolicy("Rename"); err != nil || p != CaseCollisionRename {
+		t.Errorf("ParseCaseCollisionPolicy(Rename) = %q, %v, want rename", p, err)
+	}
+	if _, err := ParseCaseCollisionPolicy("merge"); err == nil {
+		t.Error("ParseCaseCollisionPolicy(merge) expected an error")
+	}
+}
@@ -0,0 +1,122 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// CatEntry writes the uncompressed content of a file inside the archive to
+// w. When the name is stored several times, the last copy is written, the
+// one extraction picks by default.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - entryName: name of the file to write (as it appears in the ZIP)
+//   - w: where the content goes, e.g. os.Stdout
+//
+// Returns:
+//   - int64: number of bytes written
+//   - error: a missing or encrypted entry, or any error reading the archive
+//     or writing to w
+func CatEntry(zipPath, entryName string, w io.Writer) (int64, error) {
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return 0, openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	entry, err := lastFile(reader.File, entryName)
+	if err != nil {
+		return 0, err
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.Copy(w, rc)
+}
+
+// ReadTextEntry returns the content of a small text file inside the
+// archive, for copying it to the clipboard.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - entryName: name of the file to read (as it appears in the ZIP)
+//
+// Returns:
+//   - string: the content of the file
+//   - error: a file larger than PreviewLimit or that is not UTF-8 text,
+//     a missing or encrypted entry, or any error reading the archive
+func ReadTextEntry(zipPath, entryName string) (string, error) {
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return "", openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	entry, err := lastFile(reader.File, entryName)
+	if err != nil {
+		return "", err
+	}
+	limit := PreviewLimit()
+	if entry.UncompressedSize64 > uint64(limit) {
+		return "", fmt.Errorf("'%s' is larger than %s", entryName, FormatSize(uint64(limit)))
+	}
+
+	rc, err := entry.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	// The recorded size may lie; read one byte past the limit to tell.
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(rc, limit+1)); err != nil {
+		return "", err
+	}
+	if int64(buf.Len()) > limit {
+		return "", fmt.Errorf("'%s' is larger than %s", entryName, FormatSize(uint64(limit)))
+	}
+	if !isText(buf.Bytes()) {
+		return "", fmt.Errorf("'%s' is not a text file", entryName)
+	}
+	return buf.String(), nil
+}
+
+// lastFile returns the last readable file named name among files, whose
+// names were decoded.
+func lastFile(files []*zip.File, name string) (*zip.File, error) {
+	return fileVersion(files, name, 0)
+}
+
+// fileVersion returns the version-th readable file named name among files,
+// whose names were decoded, counting from 1; 0 is the last one.
+func fileVersion(files []*zip.File, name string, version int) (*zip.File, error) {
+	var entry *zip.File
+	seen := 0
+	for _, f := range files {
+		if f.Name != name || f.FileInfo().IsDir() {
+			continue
+		}
+		seen++
+		if version == 0 || seen == version {
+			entry = f
+		}
+	}
+	if entry == nil && seen > 0 {
+		return nil, fmt.Errorf("'%s' has only %d versions", name, seen)
+	}
+	if entry == nil {
+		return nil, entryNotFound("file", name)
+	}
+	if err := checkReadable(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCatEntry checks that the content of a file is written whole
+func TestCatEntry(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"dir/": "", "dir/a.json": `{"a": 1}`})
+
+	var out bytes.Buffer
+	n, err := CatEntry(zipPath, "dir/a.json", &out)
+	if err != nil {
+		t.Fatalf("CatEntry() unexpected error = %v", err)
+	}
+	if out.String() != `{"a": 1}` || n != int64(out.Len()) {
+		t.Errorf("CatEntry() = %d, %q", n, out.String())
+	}
+
+	if _, err := CatEntry(zipPath, "dir/", &out); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("CatEntry(dir/) error = %v, want ErrEntryNotFound", err)
+	}
+	if _, err := CatEntry(zipPath, "missing.txt", &out); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("CatEntry(missing.txt) error = %v, want ErrEntryNotFound", err)
+	}
+}
+
+// TestReadTextEntry checks that only small text files are read
+func TestReadTextEntry(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"notes.txt": "héllo\n",
+		"image.bin": "\x89PNG\x00\x01",
+		"big.txt":   strings.Repeat("a", DefaultPreviewLimit+1),
+	})
+
+	if got, err := ReadTextEntry(zipPath, "notes.txt"); err != nil || got != "héllo\n" {
+		t.Errorf("ReadTextEntry(notes.txt) = %q, %v", got, err)
+	}
+	for _, name := range []string{"image.bin", "big.txt"} {
+		if _, err := ReadTextEntry(zipPath, name); err == nil {
+			t.Errorf("ReadTextEntry(%s) expected error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// CheckEntry decompresses a file of the archive without writing it
+// anywhere and compares the CRC-32 of its content with the one recorded,
+// the way extraction would find it damaged.
+//
+// Parameters:
+//   - ctx: interrupts the check once done
+//   - zipPath: full path to the ZIP file
+//   - entryName: name of the file to check (as it appears in the ZIP)
+//   - version: which entry to check when several share the name, as
+//     ExtractOptions.Version: 0 for the last one, N for the N-th
+//
+// Returns:
+//   - int64: number of bytes decompressed
+//   - error: zip.ErrChecksum, wrapped with the entry's name, when the
+//     content does not match its CRC-32; a missing or encrypted entry, or
+//     any error reading the archive
+func CheckEntry(ctx context.Context, zipPath, entryName string, version int) (int64, error) {
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return 0, openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	entry, err := fileVersion(reader.File, entryName, version)
+	if err != nil {
+		return 0, err
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	// archive/zip skips the check when the recorded CRC-32 is 0; compute it
+	// anyway so such entries are checked too.
+	h := crc32.NewIEEE()
+	n, err := io.Copy(h, &extractReader{ctx: ctx, r: rc})
+	if errors.Is(err, zip.ErrChecksum) {
+		return n, fmt.Errorf("'%s' is damaged: %w", entryName, err)
+	}
+	if err != nil {
+		return n, fmt.Errorf("'%s' could not be decompressed: %w", entryName, err)
+	}
+	if sum := h.Sum32(); sum != entry.CRC32 {
+		return n, fmt.Errorf("'%s' is damaged, its CRC-32 is %08x instead of %08x: %w", entryName, sum, entry.CRC32, zip.ErrChecksum)
+	}
+	return n, nil
+}
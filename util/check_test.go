@@ -0,0 +1,60 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheckEntry checks that intact files pass and damaged ones fail,
+// picking the requested version of a name stored twice
+func TestCheckEntry(t *testing.T) {
+	ctx := context.Background()
+	zipPath := writeCorruptZip(t, map[string]string{"a.txt": "good"}, "a.txt")
+
+	if n, err := CheckEntry(ctx, zipPath, "a.txt", 1); err != nil || n != 4 {
+		t.Errorf("CheckEntry(a.txt, 1) = %d, %v, want 4 and no error", n, err)
+	}
+	if _, err := CheckEntry(ctx, zipPath, "a.txt", 0); !errors.Is(err, zip.ErrChecksum) {
+		t.Errorf("CheckEntry(a.txt, 0) error = %v, want zip.ErrChecksum", err)
+	}
+	if _, err := CheckEntry(ctx, zipPath, "a.txt", 3); err == nil {
+		t.Error("CheckEntry(a.txt, 3) expected error, got nil")
+	}
+	if _, err := CheckEntry(ctx, zipPath, "missing.txt", 0); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("CheckEntry(missing.txt) error = %v, want ErrEntryNotFound", err)
+	}
+}
+
+// TestCheckEntryZeroCRC checks that a file recorded with a CRC-32 of 0,
+// which archive/zip does not verify, is checked too
+func TestCheckEntryZeroCRC(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "zero.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(out)
+	content := []byte("not empty")
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "zero.txt",
+		Method:             zip.Store,
+		CompressedSize64:   uint64(len(content)),
+		UncompressedSize64: uint64(len(content)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(content)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+
+	if _, err := CheckEntry(context.Background(), zipPath, "zero.txt", 0); !errors.Is(err, zip.ErrChecksum) {
+		t.Errorf("CheckEntry() error = %v, want zip.ErrChecksum", err)
+	}
+}
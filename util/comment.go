@@ -0,0 +1,34 @@
+package util
+
+import (
+	"archive/zip"
+	"fmt"
+)
+
+// maxCommentLen is the longest comment the end of central directory record
+// can hold.
+const maxCommentLen = 0xffff
+
+// SetArchiveComment replaces the comment of the archive at zipPath; an
+// empty comment removes it. The entries are copied unchanged.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - comment: the new comment, at most 65535 bytes
+//
+// Returns:
+//   - error: any error encountered; the archive is unchanged on failure
+func SetArchiveComment(zipPath, comment string) error {
+	if len(comment) > maxCommentLen {
+		return fmt.Errorf("comment is %d bytes long, the limit is %d", len(comment), maxCommentLen)
+	}
+
+	return rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		for _, f := range r.File {
+			if err := copyEntry(w, f, f.Name); err != nil {
+				return err
+			}
+		}
+		return w.SetComment(comment)
+	})
+}
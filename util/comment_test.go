@@ -0,0 +1,34 @@
+package util
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestSetArchiveComment checks that the comment is replaced, then removed,
+// without touching the entries
+func TestSetArchiveComment(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "alpha", "dir/b.txt": "beta"})
+
+	for _, comment := range []string{"Release 1.2\nsigned-off-by: release bot", ""} {
+		if err := SetArchiveComment(zipPath, comment); err != nil {
+			t.Fatalf("SetArchiveComment(%q) unexpected error = %v", comment, err)
+		}
+
+		info, err := ReadArchiveInfo(zipPath)
+		if err != nil {
+			t.Fatalf("ReadArchiveInfo() unexpected error = %v", err)
+		}
+		if info.Comment != comment {
+			t.Errorf("comment = %q, want %q", info.Comment, comment)
+		}
+		if got, want := entryNames(t, zipPath), []string{"a.txt", "dir/b.txt"}; !slices.Equal(got, want) {
+			t.Errorf("entries = %v, want %v", got, want)
+		}
+	}
+
+	if err := SetArchiveComment(zipPath, strings.Repeat("x", maxCommentLen+1)); err == nil {
+		t.Error("SetArchiveComment() expected an error for an oversized comment")
+	}
+}
@@ -0,0 +1,178 @@
+package util
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// EntryComparison is the result of CompareEntry.
+type EntryComparison struct {
+	// Identical is true when both contents are byte for byte the same.
+	Identical bool
+	// EntrySize and FileSize are the sizes of both sides, in bytes.
+	EntrySize uint64
+	FileSize  int64
+	// Text is true when both sides were compared as text; Diff then holds
+	// a unified diff of their lines.
+	Text bool
+	Diff string
+	// FirstDifference is the offset of the first differing byte, or -1
+	// when the contents are identical.
+	FirstDifference int64
+	// Note explains why a text comparison fell back to bytes, if it did.
+	Note string
+}
+
+// CompareEntry compares a file inside the archive with a file on disk, for
+// instance the copy extracted earlier.
+//
+// Text files up to PreviewLimit get a unified diff; binary and larger files
+// are compared byte by byte and only the first difference is reported.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - entryName: name of the file to compare (as it appears in the ZIP)
+//   - diskPath: file on disk to compare it with
+//
+// Returns:
+//   - EntryComparison: the outcome of the comparison
+//   - error: any error encountered while reading either side
+func CompareEntry(zipPath, entryName, diskPath string) (EntryComparison, error) {
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return EntryComparison{}, openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	var entry *zip.File
+	for _, f := range reader.File {
+		if f.Name == entryName && !f.FileInfo().IsDir() {
+			entry = f
+			break
+		}
+	}
+	if entry == nil {
+		return EntryComparison{}, entryNotFound("file", entryName)
+	}
+	if err := checkReadable(entry); err != nil {
+		return EntryComparison{}, err
+	}
+
+	info, err := os.Stat(diskPath)
+	if err != nil {
+		return EntryComparison{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return EntryComparison{}, fmt.Errorf("'%s' is not a regular file", diskPath)
+	}
+
+	result := EntryComparison{EntrySize: entry.UncompressedSize64, FileSize: info.Size(), FirstDifference: -1}
+
+	if limit := PreviewLimit(); entry.UncompressedSize64 <= uint64(limit) && info.Size() <= limit {
+		entryData, err := readEntry(entry)
+		if err != nil {
+			return EntryComparison{}, err
+		}
+		fileData, err := os.ReadFile(diskPath)
+		if err != nil {
+			return EntryComparison{}, err
+		}
+
+		result.FirstDifference = firstDifference(entryData, fileData)
+		result.Identical = result.FirstDifference < 0
+		if result.Identical || !isText(entryData) || !isText(fileData) {
+			return result, nil
+		}
+
+		ops, err := diffLines(splitLines(string(entryData)), splitLines(string(fileData)))
+		if err != nil {
+			result.Note = err.Error()
+			return result, nil
+		}
+		result.Text = true
+		result.Diff = unifiedDiff(entryName+" (archive)", diskPath, ops)
+		return result, nil
+	}
+
+	rc, err := entry.Open()
+	if err != nil {
+		return EntryComparison{}, err
+	}
+	defer rc.Close()
+
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return EntryComparison{}, err
+	}
+	defer file.Close()
+
+	result.FirstDifference, err = firstStreamDifference(rc, file)
+	if err != nil {
+		return EntryComparison{}, err
+	}
+	result.Identical = result.FirstDifference < 0
+	result.Note = "too large for a line diff"
+
+	return result, nil
+}
+
+// readEntry returns the uncompressed content of f.
+func readEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// isText reports whether data looks like text: valid UTF-8 without NUL bytes.
+func isText(data []byte) bool {
+	return bytes.IndexByte(data, 0) < 0 && utf8.Valid(data)
+}
+
+// firstDifference returns the offset of the first byte where a and b
+// differ, counting the end of the shorter one, or -1 when they are equal.
+func firstDifference(a, b []byte) int64 {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return int64(i)
+		}
+	}
+	if len(a) != len(b) {
+		return int64(n)
+	}
+	return -1
+}
+
+// firstStreamDifference is firstDifference for readers.
+func firstStreamDifference(a, b io.Reader) (int64, error) {
+	ra, rb := bufio.NewReader(a), bufio.NewReader(b)
+	var offset int64
+	for {
+		ca, errA := ra.ReadByte()
+		cb, errB := rb.ReadByte()
+		if errA != nil && errA != io.EOF {
+			return 0, errA
+		}
+		if errB != nil && errB != io.EOF {
+			return 0, errB
+		}
+
+		switch {
+		case errA == io.EOF && errB == io.EOF:
+			return -1, nil
+		case errA == io.EOF || errB == io.EOF || ca != cb:
+			return offset, nil
+		}
+		offset++
+	}
+}
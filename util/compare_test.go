@@ -0,0 +1,98 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestUnifiedDiff checks hunk headers, context and merging of nearby changes
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "identical",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "one change with context",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "a\n1\n2\n3\n4\n5\n6\n7\n8\nz\n",
+			b:    "A\n1\n2\n3\n4\n5\n6\n7\n8\nZ\n",
+			want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-z\n+Z\n",
+		},
+		{
+			name: "insert into empty",
+			a:    "",
+			b:    "x\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := diffLines(splitLines(tt.a), splitLines(tt.b))
+			if err != nil {
+				t.Fatalf("diffLines() unexpected error = %v", err)
+			}
+			if got := unifiedDiff("old", "new", ops); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompareEntry checks text, binary and identical comparisons
+func TestCompareEntry(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"notes.txt": "one\ntwo\nthree\n",
+		"blob.bin":  "\x00\x01\x02\x03",
+	})
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		return p
+	}
+
+	same, err := CompareEntry(zipPath, "notes.txt", write("same.txt", "one\ntwo\nthree\n"))
+	if err != nil || !same.Identical || same.FirstDifference != -1 {
+		t.Errorf("CompareEntry(identical) = %+v, %v", same, err)
+	}
+
+	text, err := CompareEntry(zipPath, "notes.txt", write("edited.txt", "one\n2\nthree\n"))
+	if err != nil {
+		t.Fatalf("CompareEntry() unexpected error = %v", err)
+	}
+	if text.Identical || !text.Text || !strings.Contains(text.Diff, "-two\n+2\n") || text.FirstDifference != 4 {
+		t.Errorf("CompareEntry(text) = %+v", text)
+	}
+
+	bin, err := CompareEntry(zipPath, "blob.bin", write("blob.bin", "\x00\x01\xff\x03\x04"))
+	if err != nil {
+		t.Fatalf("CompareEntry() unexpected error = %v", err)
+	}
+	if bin.Identical || bin.Text || bin.FirstDifference != 2 || bin.FileSize != 5 || bin.EntrySize != 4 {
+		t.Errorf("CompareEntry(binary) = %+v", bin)
+	}
+
+	if _, err := CompareEntry(zipPath, "missing.txt", filepath.Join(dir, "same.txt")); err == nil {
+		t.Error("CompareEntry() expected error for a missing entry, got nil")
+	}
+	if _, err := CompareEntry(zipPath, "notes.txt", filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("CompareEntry() expected error for a missing file, got nil")
+	}
+}
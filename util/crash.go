@@ -0,0 +1,56 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// WriteCrashReport saves what is known about a panic to a new file in
+// StateDir, named after the time, for the user to attach to a bug report,
+// and logs it.
+//
+// Parameters:
+//   - value: the value the program panicked with
+//   - stack: the stack of the panicking goroutine, as debug.Stack returns it
+//
+// Returns:
+//   - string: path of the report
+//   - error: the report cannot be written
+func WriteCrashReport(value any, stack []byte) (string, error) {
+	Logger().Error("crash", "panic", fmt.Sprint(value), "stack", string(stack))
+
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "goZip crashed: %v\n\n", value)
+	fmt.Fprintf(&b, "Time:      %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Go:        %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Arguments: %q\n\n", os.Args[1:])
+	b.Write(stack)
+
+	p := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".txt")
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		// Two crashes in the same second keep both reports.
+		f, err = os.CreateTemp(dir, "crash-"+now.Format("20060102-150405")+"-*.txt")
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"os"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// TestWriteCrashReport checks that each crash gets a report with the panic
+// and the stack
+func TestWriteCrashReport(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	first, err := WriteCrashReport("boom", debug.Stack())
+	if err != nil {
+		t.Fatalf("WriteCrashReport() unexpected error = %v", err)
+	}
+	second, err := WriteCrashReport("boom again", debug.Stack())
+	if err != nil {
+		t.Fatalf("WriteCrashReport() unexpected error = %v", err)
+	}
+	if first == second {
+		t.Errorf("WriteCrashReport() wrote both crashes to %s", first)
+	}
+
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, want := range []string{"goZip crashed: boom", "TestWriteCrashReport", "Go:"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+}
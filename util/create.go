@@ -0,0 +1,371 @@
+package util
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PrecompressedExtensions lists file extensions whose content is already
+// compressed, so deflating them again only costs time.
+var PrecompressedExtensions = []string{
+	".7z", ".avif", ".br", ".bz2", ".docx", ".flac", ".gif", ".gz", ".heic",
+	".jar", ".jpeg", ".jpg", ".m4a", ".mkv", ".mov", ".mp3", ".mp4", ".ogg",
+	".png", ".rar", ".webm", ".webp", ".xlsx", ".xz", ".zip", ".zst",
+}
+
+// MethodSelector chooses the compression method (zip.Store or zip.Deflate)
+// for a file about to be added, given its name inside the archive and its size.
+type MethodSelector func(name string, size int64) uint16
+
+// DeflateAll is the default MethodSelector: every file is deflated.
+func DeflateAll(name string, size int64) uint16 {
+	return zip.Deflate
+}
+
+// StoreAll is a MethodSelector that adds every file without compression.
+func StoreAll(name string, size int64) uint16 {
+	return zip.Store
+}
+
+// StoreExtensions returns a MethodSelector that stores files whose extension
+// is in exts (compared case-insensitively, with or without the leading dot)
+// and deflates everything else.
+func StoreExtensions(exts []string) MethodSelector {
+	stored := make(map[string]bool, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		stored[ext] = true
+	}
+
+	return func(name string, size int64) uint16 {
+		if stored[strings.ToLower(path.Ext(name))] {
+			return zip.Store
+		}
+		return zip.Deflate
+	}
+}
+
+// CreateOptions controls how CreateArchive builds a new archive.
+type CreateOptions struct {
+	// Level is the DEFLATE compression level, from 1 (fastest) to 9 (best).
+	// Zero selects the default level.
+	Level int
+
+	// SelectMethod picks the method of each file. Nil means DeflateAll.
+	SelectMethod MethodSelector
+
+	// Password, when not empty, encrypts every file with WinZip AES-256.
+	// Directory entries are never encrypted.
+	Password string
+
+	// Reproducible makes the archive depend only on the names and content of
+	// the inputs: entries are sorted by name, timestamps are set to the DOS
+	// epoch, permissions are reduced to 0644/0755 and no extra fields are
+	// written. It cannot be combined with Password, whose salt is random.
+	Reproducible bool
+
+	// SplitSize, when positive, splits the archive into volumes of at most
+	// that many bytes (see SplitVolumeName). Archives that fit in a single
+	// volume are written as regular archives.
+	SplitSize int64
+
+	// Volume, when set, is called with the path of each completed volume
+	// of a split archive.
+	Volume func(path string)
+
+	// Progress, when set, is called before each entry is written with the
+	// entry name, its 1-based position and the total number of entries.
+	Progress func(name string, current, total int)
+}
+
+// dosEpochDate is 1980-01-01, the earliest date a ZIP header can hold.
+const dosEpochDate = 1<<5 | 1
+
+// sourceEntry pairs a file on disk with the name it gets inside the archive.
+type sourceEntry struct {
+	diskPath string
+	name     string
+	info     fs.FileInfo
+}
+
+// CreateArchive writes a new ZIP archive containing the given files and directories.
+//
+// Directories are added recursively. Relative inputs keep their path inside
+// the archive (so "src/pkg" is stored as "src/pkg/..."), while absolute inputs
+// and inputs that climb out of the working directory are stored under their
+// base name. If the archive cannot be completed the partial file is removed.
+//
+// Parameters:
+//   - zipPath: path of the archive to create; an existing file is overwritten
+//   - inputs: files and directories to add
+//   - opts: creation options
+//
+// Returns:
+//   - int: number of entries written, directories included
+//   - error: any error encountered while collecting or writing entries
+func CreateArchive(zipPath string, inputs []string, opts CreateOptions) (int, error) {
+	if len(inputs) == 0 {
+		return 0, errors.New("no files to add")
+	}
+	if opts.Level < 0 || opts.Level > flate.BestCompression {
+		return 0, fmt.Errorf("invalid compression level %d", opts.Level)
+	}
+
+	if opts.SplitSize != 0 && (opts.SplitSize < MinSplitSize || opts.SplitSize > 0xffffffff) {
+		return 0, fmt.Errorf("split size must be between %d bytes and 4 GiB", MinSplitSize)
+	}
+	if opts.Reproducible && opts.Password != "" {
+		return 0, errors.New("encrypted archives cannot be reproducible")
+	}
+
+	entries, err := collectSources(zipPath, inputs)
+	if err != nil {
+		return 0, err
+	}
+	if opts.Reproducible {
+		slices.SortFunc(entries, func(a, b sourceEntry) int {
+			return strings.Compare(a.name, b.name)
+		})
+	}
+
+	if opts.SplitSize > 0 {
+		return createSplitArchive(zipPath, entries, opts)
+	}
+
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create ZIP file: %w", err)
+	}
+
+	count, err := writeSources(out, entries, opts)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(zipPath)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// createSplitArchive builds the archive in a temporary file and then cuts it
+// into volumes, or simply moves it into place when it fits in one.
+func createSplitArchive(zipPath string, entries []sourceEntry, opts CreateOptions) (int, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(zipPath), "."+filepath.Base(zipPath)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create ZIP file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	count, err := writeSources(tmp, entries, opts)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() <= opts.SplitSize {
+		if err := os.Chmod(tmp.Name(), 0644); err != nil {
+			return 0, err
+		}
+		return count, os.Rename(tmp.Name(), zipPath)
+	}
+
+	if err := splitArchive(tmp.Name(), zipPath, opts.SplitSize, opts.Volume); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// collectSources walks the inputs and returns the entries to archive, in the
+// order they will be written. The archive being created is never included.
+func collectSources(zipPath string, inputs []string) ([]sourceEntry, error) {
+	outAbs, err := filepath.Abs(zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []sourceEntry
+	seen := make(map[string]bool)
+
+	for _, input := range inputs {
+		root := filepath.Clean(input)
+		base := archiveBaseName(root)
+
+		if abs, err := filepath.Abs(root); err == nil && abs == outAbs {
+			continue
+		}
+
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if abs, err := filepath.Abs(p); err == nil && abs == outAbs {
+				return nil
+			}
+
+			info, err := os.Stat(p)
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				return nil
+			}
+			if d.Type()&fs.ModeSymlink != 0 && info.IsDir() {
+				// Symlinked directories are not followed, to avoid cycles.
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+
+			name := path.Join(base, filepath.ToSlash(rel))
+			if name == "." || name == "" {
+				return nil
+			}
+			if info.IsDir() {
+				name += "/"
+			}
+
+			if seen[name] {
+				return nil
+			}
+			seen[name] = true
+
+			entries = append(entries, sourceEntry{diskPath: p, name: name, info: info})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// archiveBaseName returns the name under which an input is stored.
+func archiveBaseName(root string) string {
+	slashed := filepath.ToSlash(root)
+	if filepath.IsAbs(root) || slashed == ".." || strings.HasPrefix(slashed, "../") {
+		return filepath.Base(root)
+	}
+	return slashed
+}
+
+// writeSources writes every entry to w as a ZIP archive.
+func writeSources(w io.Writer, entries []sourceEntry, opts CreateOptions) (int, error) {
+	zw := zip.NewWriter(w)
+
+	level := opts.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, level)
+	})
+
+	selectMethod := opts.SelectMethod
+	if selectMethod == nil {
+		selectMethod = DeflateAll
+	}
+
+	for i, e := range entries {
+		if opts.Progress != nil {
+			opts.Progress(e.name, i+1, len(entries))
+		}
+
+		var err error
+		if opts.Password != "" && !e.info.IsDir() {
+			err = addEncryptedSource(zw, e, selectMethod(e.name, e.info.Size()), level, opts.Password)
+		} else {
+			err = addSource(zw, e, selectMethod, opts.Reproducible)
+		}
+		if err != nil {
+			zw.Close()
+			return i, fmt.Errorf("failed to add %s: %w", e.diskPath, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
+
+// addSource writes a single file or directory entry. When reproducible is
+// set, everything that depends on the machine or the time is normalized.
+func addSource(zw *zip.Writer, e sourceEntry, selectMethod MethodSelector, reproducible bool) error {
+	header, err := zip.FileInfoHeader(e.info)
+	if err != nil {
+		return err
+	}
+	header.Name = e.name
+	if reproducible {
+		normalizeHeader(header)
+	}
+
+	if e.info.IsDir() {
+		header.Method = methodStore
+		_, err := zw.CreateHeader(header)
+		return err
+	}
+
+	header.Method = selectMethod(e.name, e.info.Size())
+	fw, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(e.diskPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	_, err = io.Copy(fw, in)
+	return err
+}
+
+// normalizeHeader strips the timestamp, ownership-related permission bits
+// and extra fields from header. A zero Modified keeps archive/zip from adding
+// its extended timestamp field, so the DOS fields are set directly.
+func normalizeHeader(header *zip.FileHeader) {
+	mode := fs.FileMode(0644)
+	if header.Mode().IsDir() {
+		mode = fs.ModeDir | 0755
+	} else if header.Mode()&0111 != 0 {
+		mode = 0755
+	}
+	header.SetMode(mode)
+
+	header.Modified = time.Time{}
+	header.ModifiedDate = dosEpochDate
+	header.ModifiedTime = 0
+	header.Extra = nil
+}
@@ -0,0 +1,219 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCreateArchive checks that directories are added recursively with relative names
+func TestCreateArchive(t *testing.T) {
+	oldDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(oldDir)
+
+	workDir := t.TempDir()
+	os.Chdir(workDir)
+
+	os.MkdirAll(filepath.Join("src", "nested"), 0755)
+	os.WriteFile(filepath.Join("src", "nested", "a.txt"), []byte("nested file"), 0644)
+	os.WriteFile("top.txt", []byte("top level file"), 0644)
+
+	var progress []string
+	opts := CreateOptions{
+		Progress: func(name string, current, total int) {
+			progress = append(progress, name)
+		},
+	}
+
+	count, err := CreateArchive("out.zip", []string{"./src", "top.txt", "out.zip"}, opts)
+	if err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+	if count != 4 {
+		t.Errorf("CreateArchive() count = %d, want 4", count)
+	}
+
+	content, err := openZipFile(context.Background(), "out.zip")
+	if err != nil {
+		t.Fatalf("openZipFile() unexpected error = %v", err)
+	}
+
+	var names []string
+	for _, zf := range content {
+		names = append(names, zf.GetName())
+	}
+
+	want := []string{"src/", "src/nested/", "src/nested/a.txt", "top.txt"}
+	if !slices.Equal(names, want) {
+		t.Errorf("archive entries = %v, want %v", names, want)
+	}
+	if !slices.Equal(progress, want) {
+		t.Errorf("progress names = %v, want %v", progress, want)
+	}
+}
+
+// TestCreateArchiveAbsoluteInput checks that absolute inputs are stored under their base name
+func TestCreateArchiveAbsoluteInput(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "data")
+	os.MkdirAll(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("content"), 0644)
+
+	zipPath := filepath.Join(t.TempDir(), "out.zip")
+	if _, err := CreateArchive(zipPath, []string{srcDir}, CreateOptions{}); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+
+	content, err := openZipFile(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("openZipFile() unexpected error = %v", err)
+	}
+	if len(content) != 2 || content[1].GetName() != "data/file.txt" {
+		t.Errorf("archive entries = %v, want data/ and data/file.txt", content)
+	}
+}
+
+// TestCreateArchiveErrors checks the error handling when creating archives
+func TestCreateArchiveErrors(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "out.zip")
+
+	t.Run("no inputs", func(t *testing.T) {
+		if _, err := CreateArchive(zipPath, nil, CreateOptions{}); err == nil {
+			t.Error("CreateArchive() expected error for no inputs, got nil")
+		}
+	})
+
+	t.Run("missing input", func(t *testing.T) {
+		if _, err := CreateArchive(zipPath, []string{"/path/to/nonexistent"}, CreateOptions{}); err == nil {
+			t.Error("CreateArchive() expected error for missing input, got nil")
+		}
+		if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+			t.Error("CreateArchive() left a partial archive behind")
+		}
+	})
+}
+
+// TestStoreExtensions checks that already-compressed extensions are stored
+func TestStoreExtensions(t *testing.T) {
+	selectMethod := StoreExtensions([]string{".PNG", "jpg", " "})
+
+	tests := []struct {
+		name     string
+		expected uint16
+	}{
+		{name: "images/logo.png", expected: zip.Store},
+		{name: "photo.JPG", expected: zip.Store},
+		{name: "notes.txt", expected: zip.Deflate},
+		{name: "Makefile", expected: zip.Deflate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectMethod(tt.name, 100); got != tt.expected {
+				t.Errorf("selectMethod(%q) = %d, want %d", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateArchiveMethodSelection checks that the selector and level are applied per file
+func TestCreateArchiveMethodSelection(t *testing.T) {
+	srcDir := t.TempDir()
+	text := []byte(strings.Repeat("compressible text ", 200))
+	os.WriteFile(filepath.Join(srcDir, "a.txt"), text, 0644)
+	os.WriteFile(filepath.Join(srcDir, "b.png"), text, 0644)
+
+	zipPath := filepath.Join(t.TempDir(), "out.zip")
+	opts := CreateOptions{Level: 9, SelectMethod: StoreExtensions([]string{"png"})}
+	if _, err := CreateArchive(zipPath, []string{filepath.Join(srcDir, "a.txt"), filepath.Join(srcDir, "b.png")}, opts); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+
+	content, err := openZipFile(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("openZipFile() unexpected error = %v", err)
+	}
+
+	if got := content[0].GetMethod(); got != "DEFLATE" {
+		t.Errorf("a.txt method = %v, want DEFLATE", got)
+	}
+	if content[0].GetCompressedSize() >= content[0].GetSize() {
+		t.Errorf("a.txt was not compressed: %d >= %d", content[0].GetCompressedSize(), content[0].GetSize())
+	}
+	if got := content[1].GetMethod(); got != "STORE" {
+		t.Errorf("b.png method = %v, want STORE", got)
+	}
+
+	if _, err := CreateArchive(zipPath, []string{srcDir}, CreateOptions{Level: 10}); err == nil {
+		t.Error("CreateArchive() expected error for level 10, got nil")
+	}
+}
+
+// TestCreateArchiveReproducible checks that the output ignores mtimes, modes and input order
+func TestCreateArchiveReproducible(t *testing.T) {
+	build := func(mtime time.Time, mode os.FileMode, inputs []string) []byte {
+		t.Helper()
+		dir := t.TempDir()
+		for name, content := range map[string]string{"b.txt": "bee", "a/c.txt": "sea"} {
+			p := filepath.Join(dir, filepath.FromSlash(name))
+			os.MkdirAll(filepath.Dir(p), 0755)
+			if err := os.WriteFile(p, []byte(content), mode); err != nil {
+				t.Fatalf("Failed to write input: %v", err)
+			}
+			os.Chtimes(p, mtime, mtime)
+		}
+
+		var paths []string
+		for _, in := range inputs {
+			paths = append(paths, filepath.Join(dir, in))
+		}
+
+		zipPath := filepath.Join(dir, "out.zip")
+		if _, err := CreateArchive(zipPath, paths, CreateOptions{Reproducible: true}); err != nil {
+			t.Fatalf("CreateArchive() unexpected error = %v", err)
+		}
+
+		data, err := os.ReadFile(zipPath)
+		if err != nil {
+			t.Fatalf("Failed to read zip: %v", err)
+		}
+		return data
+	}
+
+	first := build(time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC), 0600, []string{"b.txt", "a"})
+	second := build(time.Date(2024, 9, 9, 9, 9, 9, 0, time.UTC), 0640, []string{"a", "b.txt"})
+	if !bytes.Equal(first, second) {
+		t.Fatal("reproducible archives of the same content differ")
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+		if len(f.Extra) != 0 {
+			t.Errorf("%s: extra fields = %x, want none", f.Name, f.Extra)
+		}
+		if want := 1980; f.Modified.Year() != want {
+			t.Errorf("%s: modified = %v, want year %d", f.Name, f.Modified, want)
+		}
+	}
+	if want := []string{"a/", "a/c.txt", "b.txt"}; !slices.Equal(names, want) {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+
+	if _, err := CreateArchive(filepath.Join(t.TempDir(), "x.zip"), []string{"."}, CreateOptions{Reproducible: true, Password: "pw"}); err == nil {
+		t.Error("CreateArchive() expected error for reproducible + password, got nil")
+	}
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateFormat is how listings show timestamps: one of the named formats
+// below, or a Go time layout such as "2006-01-02 15:04".
+type DateFormat string
+
+const (
+	// DateRFC3339 shows timestamps as "2024-03-05T14:07:00Z".
+	DateRFC3339 DateFormat = "rfc3339"
+	// DateRelative shows timestamps as "3 days ago".
+	DateRelative DateFormat = "relative"
+)
+
+// layoutReference is the time a layout is tried on to tell it from text
+// that holds no date at all.
+var layoutReference = time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+
+// ParseDateFormat validates a date format: "rfc3339", "relative", or a Go
+// layout, which must show at least part of the date; "" is DateRFC3339.
+func ParseDateFormat(s string) (DateFormat, error) {
+	switch format := DateFormat(strings.ToLower(strings.TrimSpace(s))); format {
+	case "":
+		return DateRFC3339, nil
+	case DateRFC3339, DateRelative:
+		return format, nil
+	}
+	if layoutReference.Format(s) == s {
+		return "", fmt.Errorf("invalid date format %q (want rfc3339, relative or a layout such as \"2006-01-02 15:04\")", s)
+	}
+	return DateFormat(s), nil
+}
+
+// Layout returns the Go layout of the format; relative dates have none.
+func (f DateFormat) Layout() string {
+	switch f {
+	case "", DateRFC3339:
+		return time.RFC3339
+	case DateRelative:
+		return ""
+	}
+	return string(f)
+}
+
+// TimeZone is the zone timestamps are shown in.
+type TimeZone string
+
+const (
+	// TimeZoneUTC shows timestamps in UTC.
+	TimeZoneUTC TimeZone = "utc"
+	// TimeZoneLocal shows timestamps in the system's time zone.
+	TimeZoneLocal TimeZone = "local"
+)
+
+// ParseTimeZone validates a time zone name; "" is TimeZoneUTC.
+func ParseTimeZone(s string) (TimeZone, error) {
+	switch zone := TimeZone(strings.ToLower(strings.TrimSpace(s))); zone {
+	case "":
+		return TimeZoneUTC, nil
+	case TimeZoneUTC, TimeZoneLocal:
+		return zone, nil
+	default:
+		return "", fmt.Errorf("invalid time zone %q (want utc or local)", s)
+	}
+}
+
+// Location returns the zone as a time.Location.
+func (z TimeZone) Location() *time.Location {
+	if z == TimeZoneLocal {
+		return time.Local
+	}
+	return time.UTC
+}
+
+// RelativeTime splits the time from t to now into a count of its largest
+// whole unit, one of "second", "minute", "hour", "day", "month" and "year",
+// for a text such as "3 days ago". The count is negative when t is after
+// now.
+func RelativeTime(t, now time.Time) (int, string) {
+	d := now.Sub(t)
+	sign := 1
+	if d < 0 {
+		sign, d = -1, -d
+	}
+
+	const day = 24 * time.Hour
+	switch {
+	case d < time.Minute:
+		return sign * int(d/time.Second), "second"
+	case d < time.Hour:
+		return sign * int(d/time.Minute), "minute"
+	case d < day:
+		return sign * int(d/time.Hour), "hour"
+	case d < 30*day:
+		return sign * int(d/day), "day"
+	case d < 365*day:
+		return sign * int(d/(30*day)), "month"
+	default:
+		return sign * int(d/(365*day)), "year"
+	}
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseDateFormat checks the named formats and that layouts must show
+// part of a date
+func TestParseDateFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    DateFormat
+		layout  string
+		wantErr bool
+	}{
+		{in: "", want: DateRFC3339, layout: time.RFC3339},
+		{in: " RFC3339 ", want: DateRFC3339, layout: time.RFC3339},
+		{in: "relative", want: DateRelative, layout: ""},
+		{in: "2006-01-02 15:04", want: "2006-01-02 15:04", layout: "2006-01-02 15:04"},
+		{in: "Jan _2", want: "Jan _2", layout: "Jan _2"},
+		{in: "short", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDateFormat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDateFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want || (!tt.wantErr && got.Layout() != tt.layout) {
+			t.Errorf("ParseDateFormat(%q) = %q with layout %q, want %q with layout %q", tt.in, got, got.Layout(), tt.want, tt.layout)
+		}
+	}
+}
+
+// TestParseTimeZone checks the zone names and their locations
+func TestParseTimeZone(t *testing.T) {
+	if z, err := ParseTimeZone(""); err != nil || z != TimeZoneUTC || z.Location() != time.UTC {
+		t.Errorf("ParseTimeZone(\"\") = %q, %v, want UTC", z, err)
+	}
+	if z, err := ParseTimeZone("Local"); err != nil || z != TimeZoneLocal || z.Location() != time.Local {
+		t.Errorf("ParseTimeZone(\"Local\") = %q, %v, want local", z, err)
+	}
+	if _, err := ParseTimeZone("Europe/Madrid"); err == nil {
+		t.Error("ParseTimeZone(\"Europe/Madrid\") expected an error")
+	}
+}
+
+// TestRelativeTime checks the unit chosen for each span, past and future
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago   time.Duration
+		count int
+		unit  string
+	}{
+		{ago: 30 * time.Second, count: 30, unit: "second"},
+		{ago: 5 * time.Minute, count: 5, unit: "minute"},
+		{ago: 90 * time.Minute, count: 1, unit: "hour"},
+		{ago: 3 * 24 * time.Hour, count: 3, unit: "day"},
+		{ago: 65 * 24 * time.Hour, count: 2, unit: "month"},
+		{ago: 800 * 24 * time.Hour, count: 2, unit: "year"},
+		{ago: -2 * time.Hour, count: -2, unit: "hour"},
+	}
+
+	for _, tt := range tests {
+		count, unit := RelativeTime(now.Add(-tt.ago), now)
+		if count != tt.count || unit != tt.unit {
+			t.Errorf("RelativeTime(now - %v) = %d %s, want %d %s", tt.ago, count, unit, tt.count, tt.unit)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"archive/zip"
+	"strings"
+)
+
+// DeleteEntry removes a file or folder from the archive at zipPath.
+//
+// Matching follows ExtractFile: an exact name removes that entry, and a
+// folder name removes the folder and everything inside it. The archive is
+// rewritten without those entries; the remaining ones are copied as is.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - targetName: name of the file or folder to delete (as it appears in the ZIP)
+//
+// Returns:
+//   - int: number of entries removed, directory entries included
+//   - error: any error encountered; the archive is unchanged on failure
+func DeleteEntry(zipPath, targetName string) (int, error) {
+	targetPrefix := targetName
+	if !strings.HasSuffix(targetPrefix, "/") {
+		targetPrefix = targetName + "/"
+	}
+
+	var removed int
+
+	err := rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		for _, f := range r.File {
+			if f.Name == targetName || strings.HasPrefix(f.Name, targetPrefix) {
+				removed++
+				continue
+			}
+
+			if err := copyEntry(w, f, f.Name); err != nil {
+				return err
+			}
+		}
+
+		if removed == 0 {
+			return entryNotFound("file or folder", targetName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestDeleteEntry checks that files and whole folders can be removed
+func TestDeleteEntry(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"keep.txt":        "keep",
+		"remove.txt":      "remove",
+		"folder/":         "",
+		"folder/a.txt":    "a",
+		"folder/sub/b.go": "b",
+		"folderish.txt":   "not inside folder/",
+	})
+
+	count, err := DeleteEntry(zipPath, "folder/")
+	if err != nil {
+		t.Fatalf("DeleteEntry() unexpected error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("DeleteEntry() count = %d, want 3", count)
+	}
+
+	if _, err := DeleteEntry(zipPath, "remove.txt"); err != nil {
+		t.Fatalf("DeleteEntry() unexpected error = %v", err)
+	}
+
+	content, err := ListArchive(zipPath)
+	if err != nil {
+		t.Fatalf("ListArchive() unexpected error = %v", err)
+	}
+
+	var names []string
+	for _, zf := range content {
+		names = append(names, zf.GetName())
+	}
+	want := []string{"folderish.txt", "keep.txt"}
+	if !slices.Equal(names, want) {
+		t.Errorf("entries after delete = %v, want %v", names, want)
+	}
+}
+
+// TestDeleteEntryNotFound checks that a failed delete leaves the archive untouched
+func TestDeleteEntryNotFound(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "a"})
+
+	before, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+
+	if _, err := DeleteEntry(zipPath, "missing.txt"); err == nil {
+		t.Error("DeleteEntry() expected error for missing entry, got nil")
+	}
+
+	after, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("DeleteEntry() modified the archive despite failing")
+	}
+
+	leftovers, _ := os.ReadDir(filepath.Dir(zipPath))
+	if len(leftovers) != 1 {
+		t.Errorf("DeleteEntry() left temporary files behind: %v", leftovers)
+	}
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// demoEntries is the content of the archive used by the first-run tutorial.
+// Folders come first so they can be extracted as a whole during the tour.
+var demoEntries = []struct {
+	name    string
+	content string
+}{
+	{name: "docs/"},
+	{name: "docs/README.md", content: "# Demo\n\nThis archive was generated for the goZip tutorial.\n"},
+	{name: "docs/guide.txt", content: "Filter with f, extract with Enter, quit with q.\n"},
+	{name: "src/"},
+	{name: "src/main.go", content: "package main\n\nfunc main() {}\n"},
+	{name: "src/util/"},
+	{name: "src/util/helper.go", content: "package util\n"},
+	{name: "notes.txt", content: "Extracted files from the tour land in a temporary folder.\n"},
+}
+
+// CreateDemoArchive writes a small sample archive named demo.zip into dir
+// and returns its path. It is used by the first-run tutorial.
+func CreateDemoArchive(dir string) (string, error) {
+	zipPath := filepath.Join(dir, "demo.zip")
+
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	modified := time.Now()
+
+	for _, e := range demoEntries {
+		header := &zip.FileHeader{Name: e.name, Method: methodDeflate, Modified: modified}
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return "", err
+		}
+		if _, err := fw.Write([]byte(e.content)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	return zipPath, out.Close()
+}
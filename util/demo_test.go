@@ -0,0 +1,32 @@
+package util
+
+import "testing"
+
+// TestCreateDemoArchive checks that the tutorial archive has both files and folders
+func TestCreateDemoArchive(t *testing.T) {
+	zipPath, err := CreateDemoArchive(t.TempDir())
+	if err != nil {
+		t.Fatalf("CreateDemoArchive() unexpected error = %v", err)
+	}
+
+	content, err := ListArchive(zipPath)
+	if err != nil {
+		t.Fatalf("ListArchive() unexpected error = %v", err)
+	}
+
+	if len(content) != len(demoEntries) {
+		t.Fatalf("ListArchive() returned %d entries, want %d", len(content), len(demoEntries))
+	}
+
+	var dirs, files int
+	for _, zf := range content {
+		if zf.IsDir() {
+			dirs++
+		} else {
+			files++
+		}
+	}
+	if dirs == 0 || files == 0 {
+		t.Errorf("demo archive has %d folders and %d files, want both", dirs, files)
+	}
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ArchiveExtensions lists the extensions of ZIP archives, including the
+// formats that are ZIP archives under another name.
+var ArchiveExtensions = []string{
+	".aar", ".apk", ".docx", ".ear", ".epub", ".jar", ".nupkg", ".odp",
+	".ods", ".odt", ".pptx", ".war", ".whl", ".xlsx", ".xpi", ".zip",
+}
+
+// HasArchiveExtension reports whether name ends with one of
+// ArchiveExtensions, ignoring case.
+func HasArchiveExtension(name string) bool {
+	return slices.Contains(ArchiveExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// IsZipFile reports whether the file at path starts like a ZIP archive:
+// with a local file header, or with the end of central directory record of
+// an empty archive. The extension is not looked at.
+//
+// Parameters:
+//   - path: the file to check
+//
+// Returns:
+//   - bool: true if the file has a ZIP signature
+//   - error: the file cannot be opened or read; a file too short to hold a
+//     signature is simply not a ZIP archive
+func IsZipFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	var sig [4]byte
+	if _, err := io.ReadFull(f, sig[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	switch binary.LittleEndian.Uint32(sig[:]) {
+	case sigLocalHeader, sigDirectoryEnd:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestIsZipFile checks archives by signature, whatever their extension
+func TestIsZipFile(t *testing.T) {
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.bin")
+	out, err := os.Create(empty)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	if err := zip.NewWriter(out).Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+	out.Close()
+
+	text := filepath.Join(dir, "fake.zip")
+	os.WriteFile(text, []byte("not an archive"), 0644)
+	short := filepath.Join(dir, "short")
+	os.WriteFile(short, []byte("PK"), 0644)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{writeTestZip(t, 0, map[string]string{"a.txt": "a"}), true},
+		{empty, true},
+		{text, false},
+		{short, false},
+	}
+	for _, tt := range tests {
+		got, err := IsZipFile(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("IsZipFile(%s) = %v, %v, want %v", filepath.Base(tt.path), got, err, tt.want)
+		}
+	}
+
+	if _, err := IsZipFile(filepath.Join(dir, "missing.zip")); err == nil {
+		t.Error("IsZipFile(missing) expected an error")
+	}
+}
+
+// TestHasArchiveExtension checks the extension, ignoring case
+func TestHasArchiveExtension(t *testing.T) {
+	for name, want := range map[string]bool{"a.zip": true, "B.JAR": true, "doc.docx": true, "a.tar.gz": false, "zip": false} {
+		if got := HasArchiveExtension(name); got != want {
+			t.Errorf("HasArchiveExtension(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
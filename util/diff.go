@@ -0,0 +1,78 @@
+package util
+
+import (
+	"sort"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// DiffStatus tells how an entry differs between two archives.
+type DiffStatus string
+
+const (
+	// DiffSame means the entry has the same size and CRC in both archives.
+	DiffSame DiffStatus = "same"
+	// DiffAdded means the entry only exists in the new archive.
+	DiffAdded DiffStatus = "added"
+	// DiffRemoved means the entry only exists in the old archive.
+	DiffRemoved DiffStatus = "removed"
+	// DiffChanged means the entry exists in both with a different size or CRC.
+	DiffChanged DiffStatus = "changed"
+)
+
+// EntryDiff is the comparison of one entry name across two archives.
+// Old is the zero ZippedFile for added entries and New for removed ones.
+type EntryDiff struct {
+	Name   string
+	Status DiffStatus
+	Old    core.ZippedFile
+	New    core.ZippedFile
+}
+
+// DiffArchives compares the entries of two archives by name, size and CRC.
+// Timestamps and compression methods are ignored, so an archive rebuilt
+// from the same files compares equal.
+//
+// Parameters:
+//   - oldPath: archive taken as the reference
+//   - newPath: archive compared against it
+//
+// Returns:
+//   - []EntryDiff: one element per entry name found in either archive, sorted by name
+//   - error: any error encountered while reading the archives
+func DiffArchives(oldPath, newPath string) ([]EntryDiff, error) {
+	oldContent, err := ListArchive(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newContent, err := ListArchive(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*EntryDiff)
+	for _, zf := range oldContent {
+		byName[zf.GetName()] = &EntryDiff{Name: zf.GetName(), Status: DiffRemoved, Old: zf}
+	}
+	for _, zf := range newContent {
+		d, ok := byName[zf.GetName()]
+		if !ok {
+			byName[zf.GetName()] = &EntryDiff{Name: zf.GetName(), Status: DiffAdded, New: zf}
+			continue
+		}
+
+		d.New = zf
+		d.Status = DiffSame
+		if d.Old.GetSize() != zf.GetSize() || d.Old.GetCrc() != zf.GetCrc() {
+			d.Status = DiffChanged
+		}
+	}
+
+	diffs := make([]EntryDiff, 0, len(byName))
+	for _, d := range byName {
+		diffs = append(diffs, *d)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+
+	return diffs, nil
+}
@@ -0,0 +1,51 @@
+package util
+
+import "testing"
+
+// TestDiffArchives checks added, removed, changed and unchanged entries
+func TestDiffArchives(t *testing.T) {
+	oldZip := writeTestZip(t, 0, map[string]string{
+		"same.txt":    "same",
+		"changed.txt": "before",
+		"removed.txt": "gone",
+		"dir/":        "",
+	})
+	newZip := writeTestZip(t, 0, map[string]string{
+		"same.txt":    "same",
+		"changed.txt": "after!",
+		"added.txt":   "new",
+		"dir/":        "",
+	}, "same.txt")
+
+	diffs, err := DiffArchives(oldZip, newZip)
+	if err != nil {
+		t.Fatalf("DiffArchives() unexpected error = %v", err)
+	}
+
+	want := []struct {
+		name   string
+		status DiffStatus
+	}{
+		{"added.txt", DiffAdded},
+		{"changed.txt", DiffChanged},
+		{"dir/", DiffSame},
+		{"removed.txt", DiffRemoved},
+		{"same.txt", DiffSame},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("DiffArchives() returned %d entries, want %d: %+v", len(diffs), len(want), diffs)
+	}
+	for i, w := range want {
+		if diffs[i].Name != w.name || diffs[i].Status != w.status {
+			t.Errorf("diffs[%d] = %s %s, want %s %s", i, diffs[i].Name, diffs[i].Status, w.name, w.status)
+		}
+	}
+
+	if diffs[0].Old.GetName() != "" || diffs[3].New.GetName() != "" {
+		t.Error("missing side of added/removed entries should be the zero ZippedFile")
+	}
+
+	if _, err := DiffArchives(oldZip, "missing.zip"); err == nil {
+		t.Error("DiffArchives() expected error for a missing archive, got nil")
+	}
+}
@@ -0,0 +1,100 @@
+package util
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// DuplicateGroup is a set of files of an archive with the same content, as
+// far as their size and CRC-32 tell.
+type DuplicateGroup struct {
+	Size  uint64
+	CRC32 uint32
+	// Entries are the copies, in archive order.
+	Entries []core.ZippedFile
+}
+
+// Savings returns the compressed bytes the archive would lose by keeping
+// only the smallest stored copy.
+func (g DuplicateGroup) Savings() uint64 {
+	var total, smallest uint64
+	for i, zf := range g.Entries {
+		total += zf.GetCompressedSize()
+		if i == 0 || zf.GetCompressedSize() < smallest {
+			smallest = zf.GetCompressedSize()
+		}
+	}
+	return total - smallest
+}
+
+// DuplicateReport lists the files of an archive stored more than once.
+type DuplicateReport struct {
+	// Groups holds the duplicated contents, the largest savings first.
+	Groups []DuplicateGroup
+	// Redundant counts the copies beyond the first of every group.
+	Redundant int
+	// Savings and WastedSize add up the compressed and uncompressed size
+	// of the redundant copies.
+	Savings    uint64
+	WastedSize uint64
+}
+
+// FindDuplicateContent groups the files of the archive at zipPath that have
+// the same size and CRC-32, which in practice means the same content, to find
+// the space wasted by storing them several times. Folders and empty files are
+// left out.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - DuplicateReport: the groups of identical files and what they waste
+//   - error: any error encountered while reading the archive
+func FindDuplicateContent(zipPath string) (DuplicateReport, error) {
+	files, err := ListArchive(zipPath)
+	if err != nil {
+		return DuplicateReport{}, err
+	}
+
+	type key struct {
+		size uint64
+		crc  uint32
+	}
+	groups := make(map[key]int)
+
+	var all []DuplicateGroup
+	for _, zf := range files {
+		if zf.IsDir() || zf.GetSize() == 0 {
+			continue
+		}
+
+		k := key{zf.GetSize(), zf.GetCrc()}
+		i, ok := groups[k]
+		if !ok {
+			i = len(all)
+			groups[k] = i
+			all = append(all, DuplicateGroup{Size: k.size, CRC32: k.crc})
+		}
+		all[i].Entries = append(all[i].Entries, zf)
+	}
+
+	var report DuplicateReport
+	for _, g := range all {
+		if len(g.Entries) < 2 {
+			continue
+		}
+		report.Groups = append(report.Groups, g)
+		report.Redundant += len(g.Entries) - 1
+		report.Savings += g.Savings()
+		report.WastedSize += g.Size * uint64(len(g.Entries)-1)
+	}
+
+	// Stable, so groups saving as much keep the order of their first copy.
+	slices.SortStableFunc(report.Groups, func(a, b DuplicateGroup) int {
+		return cmp.Compare(b.Savings(), a.Savings())
+	})
+
+	return report, nil
+}
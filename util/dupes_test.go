@@ -0,0 +1,41 @@
+package util
+
+import "testing"
+
+// TestFindDuplicateContent checks the grouping by size and CRC, the order of
+// the groups and the totals
+func TestFindDuplicateContent(t *testing.T) {
+	big := "a larger file that is stored three times over"
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"a/big.txt":      big,
+		"b/big copy.txt": big,
+		"c/big.txt":      big,
+		"x/small.txt":    "small",
+		"y/small.txt":    "small",
+		"unique.txt":     "only once",
+		"empty1.txt":     "",
+		"empty2.txt":     "",
+		"dir/":           "",
+	}, "a/big.txt", "b/big copy.txt", "c/big.txt", "x/small.txt", "y/small.txt")
+
+	report, err := FindDuplicateContent(zipPath)
+	if err != nil {
+		t.Fatalf("FindDuplicateContent() unexpected error = %v", err)
+	}
+
+	if len(report.Groups) != 2 {
+		t.Fatalf("FindDuplicateContent() found %d groups, want 2: %+v", len(report.Groups), report.Groups)
+	}
+	first := report.Groups[0]
+	if first.Size != uint64(len(big)) || len(first.Entries) != 3 || first.Entries[0].GetName() != "a/big.txt" {
+		t.Errorf("largest group = %d bytes, %d copies starting with %q, want the three big.txt copies",
+			first.Size, len(first.Entries), first.Entries[0].GetName())
+	}
+	if first.Savings() != 2*uint64(len(big)) {
+		t.Errorf("Savings() = %d, want two stored copies (%d)", first.Savings(), 2*len(big))
+	}
+
+	if report.Redundant != 3 || report.WastedSize != uint64(2*len(big)+len("small")) || report.Savings != report.WastedSize {
+		t.Errorf("totals = %d redundant, %d wasted, %d savings", report.Redundant, report.WastedSize, report.Savings)
+	}
+}
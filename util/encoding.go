@@ -0,0 +1,276 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// NameEncoding is the code page used to read entry names that are not
+// marked as UTF-8, as written by old Windows tools and East-Asian archivers.
+// Names carrying the UTF-8 flag (EFS) are always read as UTF-8.
+type NameEncoding string
+
+const (
+	// NameEncodingAuto keeps names that are valid UTF-8 and guesses the
+	// code page of the others among Shift-JIS, GBK and CP437.
+	NameEncodingAuto NameEncoding = "auto"
+	// NameEncodingUTF8 never converts names.
+	NameEncodingUTF8 NameEncoding = "utf8"
+	// NameEncodingCP437 reads names as IBM PC code page 437, the historical
+	// default of DOS and Windows archivers.
+	NameEncodingCP437 NameEncoding = "cp437"
+	// NameEncodingShiftJIS reads names as Shift-JIS (Japanese).
+	NameEncodingShiftJIS NameEncoding = "sjis"
+	// NameEncodingGBK reads names as GBK (simplified Chinese).
+	NameEncodingGBK NameEncoding = "gbk"
+)
+
+// NameEncodingEnv names the environment variable holding the default
+// NameEncoding, e.g. GOZIP_ENCODING=sjis.
+const NameEncodingEnv = "GOZIP_ENCODING"
+
+// unicodePathExtraID identifies the Info-ZIP Unicode Path extra field, which
+// carries the UTF-8 form of a name stored in a legacy code page.
+const unicodePathExtraID = 0x7075
+
+// ParseNameEncoding validates an encoding name; "" is NameEncodingAuto.
+func ParseNameEncoding(s string) (NameEncoding, error) {
+	switch enc := NameEncoding(strings.ToLower(strings.TrimSpace(s))); enc {
+	case "":
+		return NameEncodingAuto, nil
+	case "utf-8":
+		return NameEncodingUTF8, nil
+	case "ibm437":
+		return NameEncodingCP437, nil
+	case "shift-jis", "shift_jis":
+		return NameEncodingShiftJIS, nil
+	case NameEncodingAuto, NameEncodingUTF8, NameEncodingCP437, NameEncodingShiftJIS, NameEncodingGBK:
+		return enc, nil
+	default:
+		return "", fmt.Errorf("invalid name encoding %q (want auto, utf8, cp437, sjis or gbk)", s)
+	}
+}
+
+// configuredNameEncoding is the default set by SetDefaultNameEncoding.
+var configuredNameEncoding NameEncoding
+
+// SetDefaultNameEncoding sets the encoding used when NameEncodingEnv is
+// unset, as read from the settings file; "" restores NameEncodingAuto.
+func SetDefaultNameEncoding(enc NameEncoding) {
+	configuredNameEncoding = enc
+}
+
+// DefaultNameEncoding returns the encoding configured through
+// NameEncodingEnv, else the one set by SetDefaultNameEncoding, else
+// NameEncodingAuto.
+func DefaultNameEncoding() (NameEncoding, error) {
+	value, ok := os.LookupEnv(NameEncodingEnv)
+	if !ok || value == "" {
+		return ParseNameEncoding(string(configuredNameEncoding))
+	}
+	enc, err := ParseNameEncoding(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", NameEncodingEnv, err)
+	}
+	return enc, nil
+}
+
+// orDefault returns e, or the configured default when e is empty. An invalid
+// default falls back to NameEncodingAuto; callers that want to report it use
+// DefaultNameEncoding.
+func (e NameEncoding) orDefault() NameEncoding {
+	if e != "" {
+		return e
+	}
+	if enc, err := DefaultNameEncoding(); err == nil {
+		return enc
+	}
+	return NameEncodingAuto
+}
+
+// decoder returns the x/text encoding behind a code page, or nil for auto
+// and UTF-8.
+func (e NameEncoding) decoder() encoding.Encoding {
+	switch e {
+	case NameEncodingCP437:
+		return charmap.CodePage437
+	case NameEncodingShiftJIS:
+		return japanese.ShiftJIS
+	case NameEncodingGBK:
+		return simplifiedchinese.GBK
+	}
+	return nil
+}
+
+// decodeName converts an entry name, as stored in the archive, to UTF-8.
+//
+// Names flagged as UTF-8 and plain ASCII names are returned unchanged. Then
+// an Info-ZIP Unicode Path field in extra wins when it matches the name.
+// Otherwise the name is decoded with enc; auto keeps names that are valid
+// UTF-8, since many tools write UTF-8 without setting the flag, and guesses
+// the code page of the others.
+func decodeName(name string, flags uint16, extra []byte, enc NameEncoding) string {
+	if flags&0x800 != 0 || isASCII(name) {
+		return name
+	}
+
+	enc = enc.orDefault()
+	if enc == NameEncodingUTF8 {
+		return name
+	}
+	if unicodeName, ok := unicodePathName(name, extra); ok {
+		return unicodeName
+	}
+
+	if enc == NameEncodingAuto {
+		if utf8.ValidString(name) {
+			return name
+		}
+		enc = guessNameEncoding(name)
+	}
+
+	if decoded, ok := decodeStrict(enc.decoder(), name); ok {
+		return decoded
+	}
+	return name
+}
+
+// entryName is the name of an entry as goZip lists and extracts it:
+// decoded to UTF-8 as decodeName does and, for entries made on MS-DOS or
+// Windows, with backslashes turned into slashes, since some old tools
+// separated folders with them.
+func entryName(name string, versionMadeBy, flags uint16, extra []byte, enc NameEncoding) string {
+	name = decodeName(name, flags, extra, enc)
+	if madeOnWindows(versionMadeBy) {
+		name = strings.ReplaceAll(name, `\`, "/")
+	}
+	return name
+}
+
+// madeOnWindows reports whether the host of versionMadeBy is MS-DOS, OS/2,
+// Windows NTFS or VFAT, whose tools may separate folders with backslashes.
+func madeOnWindows(versionMadeBy uint16) bool {
+	switch versionMadeBy >> 8 {
+	case 0, 6, 11, 14:
+		return true
+	}
+	return false
+}
+
+// decodeNames rewrites the names of files to UTF-8 as decodeName does, so the
+// rest of the package can compare and write them as they are displayed.
+// Rewriting an archive read this way stores the names as UTF-8.
+func decodeNames(files []*zip.File, enc NameEncoding) {
+	for _, f := range files {
+		if name := entryName(f.Name, f.CreatorVersion, f.Flags, f.Extra, enc); name != f.Name {
+			f.Name = name
+			f.NonUTF8 = false
+		}
+	}
+}
+
+// decodeZipName is entryName for an entry read by archive/zip.
+func decodeZipName(f *zip.File, enc NameEncoding) string {
+	return entryName(f.Name, f.CreatorVersion, f.Flags, f.Extra, enc)
+}
+
+// unicodePathName returns the UTF-8 name from an Info-ZIP Unicode Path extra
+// field, when there is one written for this very name.
+func unicodePathName(name string, extra []byte) (string, bool) {
+	data, ok := findExtraField(extra, unicodePathExtraID)
+	if !ok || len(data) < 5 || data[0] != 1 {
+		return "", false
+	}
+	// The field is stale, and ignored, once the name was changed by a tool
+	// that did not update it.
+	if binary.LittleEndian.Uint32(data[1:]) != crc32.ChecksumIEEE([]byte(name)) {
+		return "", false
+	}
+	unicodeName := string(data[5:])
+	return unicodeName, utf8.ValidString(unicodeName)
+}
+
+// guessNameEncoding picks the code page a name that is not UTF-8 most
+// likely uses. A name is taken as Shift-JIS or GBK only when it decodes
+// cleanly into the characters those languages write file names with;
+// Shift-JIS wins when there is kana. Anything else is read as CP437, which
+// accepts every byte.
+func guessNameEncoding(name string) NameEncoding {
+	sjis, sjisOK := decodeStrict(japanese.ShiftJIS, name)
+	sjisOK = sjisOK && plausibleCJK(sjis)
+	gbk, gbkOK := decodeStrict(simplifiedchinese.GBK, name)
+	gbkOK = gbkOK && plausibleCJK(gbk)
+
+	switch {
+	case sjisOK && (hasKana(sjis) || !gbkOK):
+		return NameEncodingShiftJIS
+	case gbkOK:
+		return NameEncodingGBK
+	default:
+		return NameEncodingCP437
+	}
+}
+
+// decodeStrict decodes s, failing on any byte sequence the encoding does not
+// define instead of replacing it.
+func decodeStrict(enc encoding.Encoding, s string) (string, bool) {
+	if enc == nil {
+		return s, false
+	}
+	decoded, err := enc.NewDecoder().String(s)
+	if err != nil || strings.ContainsRune(decoded, utf8.RuneError) {
+		return "", false
+	}
+	return decoded, true
+}
+
+// plausibleCJK reports whether s holds only ASCII, CJK ideographs, kana, and
+// CJK or full-width punctuation. Half-width katakana is left out: it is what
+// GBK bytes usually turn into when misread as Shift-JIS.
+func plausibleCJK(s string) bool {
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+		case r >= 0xff61 && r <= 0xff9f: // half-width katakana
+			return false
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		case r >= 0x3000 && r <= 0x303f: // CJK symbols and punctuation
+		case r >= 0xff01 && r <= 0xff5e: // full-width ASCII
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasKana reports whether s holds any hiragana or katakana.
+func hasKana(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+			return true
+		}
+	}
+	return false
+}
+
+// isASCII reports whether s holds only 7-bit characters, which read the same
+// in every supported encoding.
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
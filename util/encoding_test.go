@@ -0,0 +1,196 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// encodeName returns name in a legacy code page, as an old archiver stores it
+func encodeName(t *testing.T, enc encoding.Encoding, name string) string {
+	t.Helper()
+	encoded, err := enc.NewEncoder().String(name)
+	if err != nil {
+		t.Fatalf("Failed to encode %q: %v", name, err)
+	}
+	return encoded
+}
+
+// writeLegacyZip writes an archive whose names are stored without the UTF-8
+// flag, each holding its own name as content
+func writeLegacyZip(t *testing.T, names ...string) string {
+	t.Helper()
+
+	zipPath := filepath.Join(t.TempDir(), "legacy.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, NonUTF8: true})
+		if err != nil {
+			t.Fatalf("Failed to add %q: %v", name, err)
+		}
+		w.Write([]byte(name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+	return zipPath
+}
+
+// TestDecodeName checks the UTF-8 flag, explicit code pages, the Unicode Path
+// field and the guesses made in auto mode
+func TestDecodeName(t *testing.T) {
+	sjis := encodeName(t, japanese.ShiftJIS, "テスト資料.txt")
+	gbk := encodeName(t, simplifiedchinese.GBK, "中文文档.txt")
+	cp437 := encodeName(t, charmap.CodePage437, "café.txt")
+
+	unicodePath := make([]byte, 4, 64)
+	binary.LittleEndian.PutUint16(unicodePath, unicodePathExtraID)
+	unicodePath = append(unicodePath, 1, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(unicodePath[5:], crc32.ChecksumIEEE([]byte(cp437)))
+	unicodePath = append(unicodePath, "cafè.txt"...)
+	binary.LittleEndian.PutUint16(unicodePath[2:], uint16(len(unicodePath)-4))
+
+	tests := []struct {
+		name  string
+		raw   string
+		flags uint16
+		extra []byte
+		enc   NameEncoding
+		want  string
+	}{
+		{"ascii", "docs/readme.md", 0, nil, NameEncodingShiftJIS, "docs/readme.md"},
+		{"utf-8 flag", "データ.txt", 0x800, nil, NameEncodingCP437, "データ.txt"},
+		{"valid utf-8 in auto", "データ.txt", 0, nil, NameEncodingAuto, "データ.txt"},
+		{"guess shift-jis", sjis, 0, nil, NameEncodingAuto, "テスト資料.txt"},
+		{"guess gbk", gbk, 0, nil, NameEncodingAuto, "中文文档.txt"},
+		{"guess cp437", cp437, 0, nil, NameEncodingAuto, "café.txt"},
+		{"explicit shift-jis", sjis, 0, nil, NameEncodingShiftJIS, "テスト資料.txt"},
+		{"explicit utf8 keeps bytes", sjis, 0, nil, NameEncodingUTF8, sjis},
+		{"unicode path field", cp437, 0, unicodePath, NameEncodingAuto, "cafè.txt"},
+		{"stale unicode path field", cp437 + "x", 0, unicodePath, NameEncodingCP437, "café.txtx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeName(tt.raw, tt.flags, tt.extra, tt.enc); got != tt.want {
+				t.Errorf("decodeName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseNameEncoding checks names, aliases and the environment default
+func TestParseNameEncoding(t *testing.T) {
+	for in, want := range map[string]NameEncoding{"": NameEncodingAuto, "SJIS": NameEncodingShiftJIS, "Shift_JIS": NameEncodingShiftJIS, "utf-8": NameEncodingUTF8, "gbk": NameEncodingGBK} {
+		if got, err := ParseNameEncoding(in); err != nil || got != want {
+			t.Errorf("ParseNameEncoding(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseNameEncoding("latin1"); err == nil {
+		t.Error("ParseNameEncoding(latin1) expected an error")
+	}
+
+	t.Setenv(NameEncodingEnv, "bogus")
+	if _, err := DefaultNameEncoding(); err == nil {
+		t.Errorf("DefaultNameEncoding() with %s=bogus expected an error", NameEncodingEnv)
+	}
+}
+
+// TestLegacyNamesListExtractRename checks that listing, extraction and
+// rewriting all use the decoded names
+func TestLegacyNamesListExtractRename(t *testing.T) {
+	raw := encodeName(t, japanese.ShiftJIS, "フォルダ/テスト.txt")
+	zipPath := writeLegacyZip(t, raw)
+
+	files, err := ListArchive(zipPath)
+	if err != nil || len(files) != 1 || files[0].GetName() != "フォルダ/テスト.txt" {
+		t.Fatalf("ListArchive() = %v, %v, want the decoded name", files, err)
+	}
+
+	destDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "フォルダ/", destDir, ExtractOptions{}); err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(destDir, "フォルダ", "テスト.txt")); err != nil || string(data) != raw {
+		t.Errorf("extracted file = %q, %v, want the entry content", data, err)
+	}
+
+	t.Setenv(NameEncodingEnv, "utf8")
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "フォルダ/", t.TempDir(), ExtractOptions{}); err == nil {
+		t.Errorf("ExtractWithOptions() with %s=utf8 expected the decoded name not to match", NameEncodingEnv)
+	}
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "フォルダ/", t.TempDir(), ExtractOptions{NameEncoding: NameEncodingShiftJIS}); err != nil {
+		t.Errorf("ExtractWithOptions(NameEncoding: sjis) unexpected error = %v", err)
+	}
+	t.Setenv(NameEncodingEnv, "")
+
+	if _, err := RenameEntry(zipPath, "フォルダ/テスト.txt", "フォルダ/試験.txt"); err != nil {
+		t.Fatalf("RenameEntry() unexpected error = %v", err)
+	}
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen ZIP: %v", err)
+	}
+	defer r.Close()
+	if f := r.File[0]; f.Name != "フォルダ/試験.txt" || f.Flags&0x800 == 0 {
+		t.Errorf("renamed entry = %q (flags %#x), want it stored as UTF-8", f.Name, f.Flags)
+	}
+}
+
+// TestBackslashNames checks that names made on Windows with backslashes are
+// listed and extracted with slashes, keeping the stored name, while names
+// made on Unix keep their backslashes
+func TestBackslashNames(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "test.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	w := zip.NewWriter(out)
+	for _, h := range []*zip.FileHeader{
+		{Name: `dir\file.txt`, CreatorVersion: 0 << 8},
+		{Name: `unix\name.txt`, CreatorVersion: 3 << 8},
+	} {
+		fw, err := w.CreateHeader(h)
+		if err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+		fw.Write([]byte("data"))
+	}
+	w.Close()
+	out.Close()
+
+	files, err := ListArchive(zipPath)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("ListArchive() = %v, %v, want 2 entries", files, err)
+	}
+	if files[0].GetName() != "dir/file.txt" || files[0].GetStoredName() != `dir\file.txt` {
+		t.Errorf("ListArchive() entry = %q stored as %q, want dir/file.txt stored as dir\\file.txt", files[0].GetName(), files[0].GetStoredName())
+	}
+	if files[1].GetName() != `unix\name.txt` {
+		t.Errorf("ListArchive() Unix entry = %q, want its backslash kept", files[1].GetName())
+	}
+
+	destDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "dir", destDir, ExtractOptions{}); err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "dir", "file.txt")); err != nil {
+		t.Errorf("dir/file.txt not extracted into its folder: %v", err)
+	}
+}
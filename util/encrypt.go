@@ -0,0 +1,207 @@
+package util
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// WinZip AES (AE-2) parameters. Entries are marked with methodAES and carry
+// their real compression method in the aesExtraID extra field.
+const (
+	aesExtraID     = 0x9901
+	aesVersionAE2  = 2
+	aesStrength256 = 3
+	aesKeyLen      = 32
+	aesSaltLen     = 16
+	aesVerifierLen = 2
+	aesAuthLen     = 10
+	aesIterations  = 1000
+)
+
+// aesKeys derives the encryption key, authentication key and password
+// verification value for salt, as specified by WinZip.
+func aesKeys(password string, salt []byte) (encKey, authKey, verifier []byte) {
+	keys := pbkdf2.Key([]byte(password), salt, aesIterations, 2*aesKeyLen+aesVerifierLen, sha1.New)
+	return keys[:aesKeyLen], keys[aesKeyLen : 2*aesKeyLen], keys[2*aesKeyLen:]
+}
+
+// aesExtraField returns the 0x9901 extra field block for an AES-256 entry
+// whose data is compressed with method.
+func aesExtraField(method uint16) []byte {
+	b := make([]byte, 11)
+	binary.LittleEndian.PutUint16(b, aesExtraID)
+	binary.LittleEndian.PutUint16(b[2:], 7)
+	binary.LittleEndian.PutUint16(b[4:], aesVersionAE2)
+	copy(b[6:], "AE")
+	b[8] = aesStrength256
+	binary.LittleEndian.PutUint16(b[9:], method)
+	return b
+}
+
+// aesCTR is AES in counter mode with the little-endian counter, starting
+// at 1, that WinZip uses instead of the usual big-endian one.
+type aesCTR struct {
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+}
+
+func newAESCTR(key []byte) (*aesCTR, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesCTR{block: block, used: aes.BlockSize}, nil
+}
+
+// XORKeyStream implements cipher.Stream.
+func (c *aesCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.stream[c.used]
+		c.used++
+	}
+}
+
+// aesWriter encrypts what it is given into w and, on Close, appends the
+// authentication code computed over the encrypted data.
+type aesWriter struct {
+	w    io.Writer
+	ctr  *aesCTR
+	mac  hash.Hash
+	buf  []byte
+	size uint64
+}
+
+func newAESWriter(w io.Writer, encKey, authKey []byte) (*aesWriter, error) {
+	ctr, err := newAESCTR(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &aesWriter{w: w, ctr: ctr, mac: hmac.New(sha1.New, authKey)}, nil
+}
+
+func (a *aesWriter) Write(p []byte) (int, error) {
+	if cap(a.buf) < len(p) {
+		a.buf = make([]byte, len(p))
+	}
+	out := a.buf[:len(p)]
+	a.ctr.XORKeyStream(out, p)
+	a.mac.Write(out)
+	a.size += uint64(len(p))
+
+	if _, err := a.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the authentication code. It does not close the underlying writer.
+func (a *aesWriter) Close() error {
+	_, err := a.w.Write(a.mac.Sum(nil)[:aesAuthLen])
+	return err
+}
+
+// addEncryptedSource writes a file entry encrypted with WinZip AES-256.
+//
+// archive/zip needs the sizes of raw entries up front, so the file is read
+// twice: once to measure its compressed size and once to write it.
+func addEncryptedSource(zw *zip.Writer, e sourceEntry, method uint16, level int, password string) error {
+	header, err := zip.FileInfoHeader(e.info)
+	if err != nil {
+		return err
+	}
+	header.Name = e.name
+
+	counter := &countingWriter{}
+	if err := compressFile(counter, e.diskPath, method, level); err != nil {
+		return err
+	}
+
+	salt := make([]byte, aesSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	encKey, authKey, verifier := aesKeys(password, salt)
+
+	// AE-2 leaves the CRC at zero; the authentication code protects the data.
+	header.Method = methodAES
+	header.Flags |= 0x1
+	header.CRC32 = 0
+	header.CompressedSize64 = aesSaltLen + aesVerifierLen + counter.n + aesAuthLen
+	header.Extra = append(header.Extra, aesExtraField(method)...)
+
+	fw, err := zw.CreateRaw(header)
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(salt); err != nil {
+		return err
+	}
+	if _, err := fw.Write(verifier); err != nil {
+		return err
+	}
+
+	aw, err := newAESWriter(fw, encKey, authKey)
+	if err != nil {
+		return err
+	}
+	if err := compressFile(aw, e.diskPath, method, level); err != nil {
+		return err
+	}
+	if aw.size != counter.n {
+		return errors.New("file changed while it was being added")
+	}
+
+	return aw.Close()
+}
+
+// compressFile writes the content of diskPath to w, deflated at level when
+// method is zip.Deflate and as is otherwise.
+func compressFile(w io.Writer, diskPath string, method uint16, level int) error {
+	in, err := os.Open(diskPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	switch method {
+	case zip.Store:
+		_, err = io.Copy(w, in)
+		return err
+	case zip.Deflate:
+		fw, err := flate.NewWriter(w, level)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(fw, in); err != nil {
+			return err
+		}
+		return fw.Close()
+	default:
+		return fmt.Errorf("cannot encrypt entries using method %d", method)
+	}
+}
@@ -0,0 +1,117 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// decryptAESEntry decrypts a WinZip AES-256 entry following the published
+// format step by step, independently of the writer under test.
+func decryptAESEntry(t *testing.T, f *zip.File, password string) []byte {
+	t.Helper()
+
+	extra, ok := findExtraField(f.Extra, aesExtraID)
+	if !ok || len(extra) != 7 || string(extra[2:4]) != "AE" || extra[4] != 3 {
+		t.Fatalf("%s: missing or invalid AES extra field %x", f.Name, extra)
+	}
+	method := binary.LittleEndian.Uint16(extra[5:])
+
+	raw, err := f.OpenRaw()
+	if err != nil {
+		t.Fatalf("OpenRaw(%s) unexpected error = %v", f.Name, err)
+	}
+	data, err := io.ReadAll(raw)
+	if err != nil {
+		t.Fatalf("reading %s: %v", f.Name, err)
+	}
+
+	salt, verifier := data[:16], data[16:18]
+	body, auth := data[18:len(data)-10], data[len(data)-10:]
+
+	keys := pbkdf2.Key([]byte(password), salt, 1000, 66, sha1.New)
+	if !bytes.Equal(keys[64:], verifier) {
+		t.Fatalf("%s: password verification value mismatch", f.Name)
+	}
+
+	mac := hmac.New(sha1.New, keys[32:64])
+	mac.Write(body)
+	if !bytes.Equal(mac.Sum(nil)[:10], auth) {
+		t.Fatalf("%s: authentication code mismatch", f.Name)
+	}
+
+	block, err := aes.NewCipher(keys[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := make([]byte, len(body))
+	var counter, stream [16]byte
+	for i := 0; i < len(body); i += 16 {
+		binary.LittleEndian.PutUint64(counter[:], uint64(i/16+1))
+		block.Encrypt(stream[:], counter[:])
+		for j := i; j < len(body) && j < i+16; j++ {
+			plain[j] = body[j] ^ stream[j-i]
+		}
+	}
+
+	if method == zip.Deflate {
+		plain, err = io.ReadAll(flate.NewReader(bytes.NewReader(plain)))
+		if err != nil {
+			t.Fatalf("%s: inflating: %v", f.Name, err)
+		}
+	}
+
+	return plain
+}
+
+// TestCreateArchiveEncrypted checks that files are written as WinZip AES-256 entries
+func TestCreateArchiveEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"small.txt": "hi",
+		"big.txt":   strings.Repeat("compress me please ", 500),
+		"photo.jpg": "already compressed bytes",
+	}
+	var inputs []string
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+		inputs = append(inputs, p)
+	}
+
+	zipPath := filepath.Join(dir, "secret.zip")
+	opts := CreateOptions{Password: "s3cret", SelectMethod: StoreExtensions([]string{"jpg"})}
+	if _, err := CreateArchive(zipPath, inputs, opts); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Method != methodAES || f.Flags&0x1 == 0 {
+			t.Errorf("%s: method = %d, flags = %#x, want an encrypted AES entry", f.Name, f.Method, f.Flags)
+			continue
+		}
+
+		if got := string(decryptAESEntry(t, f, "s3cret")); got != files[f.Name] {
+			t.Errorf("%s: decrypted content = %q, want %q", f.Name, got, files[f.Name])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestEntropy checks the entropy of uniform, constant and random data
+func TestEntropy(t *testing.T) {
+	if e := entropy(bytes.Repeat([]byte("a"), 100)); e != 0 {
+		t.Errorf("entropy(constant) = %v, want 0", e)
+	}
+	if e := entropy([]byte("abab")); e != 1 {
+		t.Errorf("entropy(abab) = %v, want 1", e)
+	}
+
+	random := make([]byte, entropySample)
+	rand.New(rand.NewSource(1)).Read(random)
+	if e := entropy(random); e < randomEntropy {
+		t.Errorf("entropy(random) = %v, want at least %v", e, randomEntropy)
+	}
+}
+
+// TestFindRandomContent checks which files are reported as random
+func TestFindRandomContent(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func(n int) string {
+		b := make([]byte, n)
+		rng.Read(b)
+		return string(b)
+	}
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(random(32 << 10)))
+	w.Close()
+
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"docs/":         "",
+		"payload.txt":   random(32 << 10),
+		"blob.dat":      random(16 << 10),
+		"notes.txt":     strings.Repeat("nothing to see here\n", 1000),
+		"backup.tar.gz": gz.String(),
+		"tiny.bin":      random(100),
+	}, "payload.txt", "backup.tar.gz")
+
+	report, err := FindRandomContent(zipPath)
+	if err != nil {
+		t.Fatalf("FindRandomContent() unexpected error = %v", err)
+	}
+
+	if report.Sampled != 4 || report.Skipped != 1 {
+		t.Errorf("FindRandomContent() sampled %d and skipped %d files, want 4 and 1", report.Sampled, report.Skipped)
+	}
+	found := map[string]RandomEntry{}
+	for _, e := range report.Entries {
+		found[e.Name] = e
+	}
+	if len(found) != 2 {
+		t.Fatalf("FindRandomContent() = %+v, want payload.txt and blob.dat", report.Entries)
+	}
+	if e, ok := found["payload.txt"]; !ok || !e.Stored || e.Size != 32<<10 {
+		t.Errorf("payload.txt = %+v, want a stored file of 32 KiB", e)
+	}
+	if e, ok := found["blob.dat"]; !ok || e.Stored {
+		t.Errorf("blob.dat = %+v, want a compressed file", e)
+	}
+}
+
+// TestFindRandomContentMissing checks the error for a missing archive
+func TestFindRandomContentMissing(t *testing.T) {
+	if _, err := FindRandomContent("missing.zip"); err == nil {
+		t.Error("FindRandomContent() expected an error for a missing archive")
+	}
+}
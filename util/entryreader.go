@@ -0,0 +1,74 @@
+package util
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// entryReader reads a file of an archive's fs.FS, see core.ArchiveFS, as
+// an io.ReadSeeker. Compressed data can only be read forward, so it keeps
+// the file open where the last read stopped, which serves sequential
+// reads, and opens it again to go back.
+type entryReader struct {
+	fsys fs.FS
+	name string
+	size int64
+
+	r fs.File
+	// pos is where r stands; offset is where the next Read starts.
+	pos, offset int64
+}
+
+func newEntryReader(fsys fs.FS, name string, size int64) *entryReader {
+	return &entryReader{fsys: fsys, name: name, size: size}
+}
+
+func (e *entryReader) Read(p []byte) (int, error) {
+	if e.r == nil || e.offset < e.pos {
+		if e.r != nil {
+			e.r.Close()
+			e.r = nil
+		}
+		r, err := e.fsys.Open(e.name)
+		if err != nil {
+			return 0, err
+		}
+		e.r, e.pos = r, 0
+	}
+	if e.offset > e.pos {
+		n, err := io.CopyN(io.Discard, e.r, e.offset-e.pos)
+		e.pos += n
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := e.r.Read(p)
+	e.pos += int64(n)
+	e.offset = e.pos
+	return n, err
+}
+
+func (e *entryReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += e.offset
+	case io.SeekEnd:
+		offset += e.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek before the start of the file")
+	}
+	e.offset = offset
+	return offset, nil
+}
+
+func (e *entryReader) Close() error {
+	if e.r == nil {
+		return nil
+	}
+	err := e.r.Close()
+	e.r = nil
+	return err
+}
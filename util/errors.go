@@ -0,0 +1,61 @@
+package util
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+)
+
+// Errors returned, wrapped with more context, by the functions of this
+// package. Callers tell them apart with errors.Is:
+//
+//	if errors.Is(err, util.ErrEncrypted) {
+//		// ask for a password, or explain why the entry was skipped
+//	}
+var (
+	// ErrCannotOpen reports an archive that cannot be opened: missing,
+	// unreadable or not a ZIP archive. The cause is wrapped as well.
+	ErrCannotOpen = errors.New("failed to open ZIP file")
+
+	// ErrNotZip reports a file that is not a ZIP archive, or whose central
+	// directory is too damaged to be found.
+	ErrNotZip = errors.New("not a valid ZIP file")
+
+	// ErrEntryNotFound reports a file or folder name missing from the archive.
+	ErrEntryNotFound = errors.New("not found in ZIP archive")
+
+	// ErrEncrypted reports an encrypted entry, which cannot be read without
+	// its password.
+	ErrEncrypted = errors.New("is encrypted")
+
+	// ErrPathTraversal reports an entry whose name would place it outside
+	// the destination directory, such as "../evil" or "/etc/passwd".
+	// Nothing is extracted from such archives; "gozip health --fix
+	// normalize" rewrites the names.
+	ErrPathTraversal = errors.New("would be written outside the destination")
+)
+
+// openError wraps an error from opening an archive with ErrCannotOpen,
+// marking the failures caused by the file not being a ZIP archive with
+// ErrNotZip.
+func openError(err error) error {
+	if errors.Is(err, zip.ErrFormat) {
+		return fmt.Errorf("%w: %w", ErrCannotOpen, ErrNotZip)
+	}
+	return fmt.Errorf("%w: %w", ErrCannotOpen, err)
+}
+
+// entryNotFound returns the error for a target missing from the archive;
+// what names the kind of target, such as "file" or "file or folder".
+func entryNotFound(what, name string) error {
+	return fmt.Errorf("%s '%s' %w", what, name, ErrEntryNotFound)
+}
+
+// checkReadable returns ErrEncrypted, wrapped with the entry's name, for
+// entries that cannot be read without a password.
+func checkReadable(f *zip.File) error {
+	if f.Flags&0x1 != 0 || f.Method == methodAES {
+		return fmt.Errorf("'%s' %w", f.Name, ErrEncrypted)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestErrNotZip checks that every way of opening a file that is not an
+// archive reports ErrNotZip
+func TestErrNotZip(t *testing.T) {
+	notZip := filepath.Join(t.TempDir(), "notes.zip")
+	if err := os.WriteFile(notZip, []byte("plain text, not an archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ListArchive(notZip); !errors.Is(err, ErrNotZip) {
+		t.Errorf("ListArchive() error = %v, want ErrNotZip", err)
+	}
+	if _, err := OpenArchiveStream(notZip); !errors.Is(err, ErrNotZip) {
+		t.Errorf("OpenArchiveStream() error = %v, want ErrNotZip", err)
+	}
+	if _, err := ExtractFile(context.Background(), notZip, "", t.TempDir()); !errors.Is(err, ErrNotZip) {
+		t.Errorf("ExtractFile() error = %v, want ErrNotZip", err)
+	}
+
+	// A missing file is not mistaken for a malformed one.
+	if _, err := ListArchive(filepath.Join(t.TempDir(), "missing.zip")); errors.Is(err, ErrNotZip) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ListArchive(missing) error = %v, want os.ErrNotExist", err)
+	}
+}
+
+// TestErrEntryNotFound checks the error for targets missing from the archive
+func TestErrEntryNotFound(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "alpha"})
+
+	_, err := ExtractFile(context.Background(), zipPath, "missing.txt", t.TempDir())
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Fatalf("ExtractFile() error = %v, want ErrEntryNotFound", err)
+	}
+	if want := "file or folder 'missing.txt' not found in ZIP archive"; err.Error() != want {
+		t.Errorf("ExtractFile() error = %q, want %q", err, want)
+	}
+
+	if _, err := DeleteEntry(zipPath, "missing.txt"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("DeleteEntry() error = %v, want ErrEntryNotFound", err)
+	}
+}
+
+// TestErrEncrypted checks that encrypted entries are refused before anything
+// is written
+func TestErrEncrypted(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "secret.txt"), []byte("classified"), 0644)
+	zipPath := filepath.Join(t.TempDir(), "secret.zip")
+	if _, err := CreateArchive(zipPath, []string{src}, CreateOptions{Password: "pw"}); err != nil {
+		t.Fatalf("CreateArchive() error = %v", err)
+	}
+
+	dest := t.TempDir()
+	if _, err := ExtractFile(context.Background(), zipPath, "", dest); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("ExtractFile() error = %v, want ErrEncrypted", err)
+	}
+	if files, _ := os.ReadDir(dest); len(files) != 0 {
+		t.Errorf("refused extraction wrote %d files", len(files))
+	}
+}
+
+// TestErrPathTraversal checks that an entry escaping the destination stops
+// the whole extraction, and that PlanExtraction predicts it
+func TestErrPathTraversal(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "evil.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(out)
+	for _, name := range []string{"good.txt", "../evil.txt"} {
+		f, _ := w.Create(name)
+		f.Write([]byte(name))
+	}
+	w.Close()
+	out.Close()
+
+	dest := filepath.Join(t.TempDir(), "dest")
+	if _, err := ExtractFile(context.Background(), zipPath, "", dest); !errors.Is(err, ErrPathTraversal) {
+		t.Errorf("ExtractFile() error = %v, want ErrPathTraversal", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "good.txt")); !os.IsNotExist(err) {
+		t.Error("refused extraction wrote good.txt")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "evil.txt")); !os.IsNotExist(err) {
+		t.Error("refused extraction wrote evil.txt outside the destination")
+	}
+
+	if _, err := PlanExtraction(zipPath, "", dest, ExtractOptions{}); !errors.Is(err, ErrPathTraversal) {
+		t.Errorf("PlanExtraction() error = %v, want ErrPathTraversal", err)
+	}
+
+	// Flattening drops the folders, and with them the traversal.
+	if n, err := ExtractWithOptions(context.Background(), zipPath, "", dest, ExtractOptions{Flatten: true}); err != nil || n != 2 {
+		t.Errorf("ExtractWithOptions(Flatten) = %d, %v; want 2, nil", n, err)
+	}
+}
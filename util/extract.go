@@ -0,0 +1,543 @@
+package util
+
+import (
+	"archive/zip"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ExtractOptions refines what ExtractWithOptions writes.
+type ExtractOptions struct {
+	// Include, when not empty, limits extraction to the files matching at
+	// least one of these glob patterns.
+	Include []string
+
+	// Exclude skips the files matching any of these glob patterns, even
+	// when they are included.
+	Exclude []string
+
+	// Flatten writes every file directly into the destination directory,
+	// without its folders (like "unzip -j"). Files sharing a base name are
+	// renamed "name (2).ext" and so on, in archive order.
+	Flatten bool
+
+	// SkipSpaceCheck extracts even when the destination does not seem to
+	// have room for the uncompressed files.
+	SkipSpaceCheck bool
+
+	// RemoveOnFailure deletes the files and folders the extraction created
+	// when it fails partway, leaving the destination as it was except for
+	// files that were already overwritten. Without it the files completed
+	// before the failure are kept. Either way no file is ever left
+	// half-written: each one is written to a temporary name first.
+	RemoveOnFailure bool
+
+	// Resume records each completed file in a manifest inside the
+	// destination directory, and skips the files an earlier, interrupted
+	// run with Resume already extracted, as long as the entry's size and CRC
+	// and the file on disk are unchanged. The manifest is deleted once the
+	// extraction succeeds. It cannot be combined with RemoveOnFailure.
+	Resume bool
+
+	// Jobs is the number of files extracted concurrently, fewer when they
+	// would not fit in the memory limit, see SetMemoryLimit. Values below 2
+	// extract one file at a time, in archive order.
+	Jobs int
+
+	// Observer, when not nil, is told about each file as it is extracted.
+	Observer ExtractObserver
+
+	// ContinueOnError keeps extracting the other files after one fails
+	// instead of stopping at the first failure. The error returned then
+	// joins the failure of every file; the Observer learns which ones.
+	ContinueOnError bool
+
+	// Names, when not empty, limits extraction to the files with these
+	// exact names, for instance those an earlier extraction failed on.
+	Names []string
+
+	// Version picks which entry is extracted when several share a name, as
+	// in archives updated by appending: 0 takes the last one, which is what
+	// most tools show, and N > 0 the N-th one in archive order, so 1 is the
+	// first. Names stored once are not affected; the extraction fails when
+	// a name stored several times has fewer than N versions.
+	Version int
+
+	// Overwrite decides what happens to files that already exist in the
+	// destination; empty means OverwriteAlways. Files it keeps are skipped
+	// and not counted as extracted.
+	Overwrite OverwritePolicy
+
+	// CaseCollisions decides what happens to files whose paths differ only
+	// in case when the destination ignores case, as it usually does on
+	// macOS and Windows; empty means CaseCollisionAbort.
+	CaseCollisions CaseCollisionPolicy
+
+	// RateLimit, when positive, caps how many bytes per second the
+	// extraction reads, all jobs together, so that it does not saturate
+	// slow or shared storage.
+	RateLimit int64
+
+	// NameEncoding is the code page of the names not marked as UTF-8;
+	// targetName, the patterns and the written paths all use the names
+	// converted to UTF-8. Empty means the configured default, see
+	// DefaultNameEncoding.
+	NameEncoding NameEncoding
+
+	// Scanner, when set, pipes every file through a command before it is
+	// written, and blocks or only reports those it flags as its Policy says.
+	Scanner ContentScanner
+}
+
+// validate checks that every pattern is well formed and that the options
+// can be used together.
+func (o ExtractOptions) validate() error {
+	if o.Resume && o.RemoveOnFailure {
+		return errors.New("resuming an extraction cannot be combined with removing it on failure")
+	}
+	if o.Jobs < 0 {
+		return fmt.Errorf("invalid number of jobs %d", o.Jobs)
+	}
+	if o.RateLimit < 0 {
+		return fmt.Errorf("invalid rate limit %d", o.RateLimit)
+	}
+	if o.Version < 0 {
+		return fmt.Errorf("invalid version %d", o.Version)
+	}
+	if o.NameEncoding != "" {
+		if _, err := ParseNameEncoding(string(o.NameEncoding)); err != nil {
+			return err
+		}
+	}
+	if _, err := ParseOverwritePolicy(string(o.Overwrite)); err != nil {
+		return err
+	}
+	if _, err := ParseCaseCollisionPolicy(string(o.CaseCollisions)); err != nil {
+		return err
+	}
+	if _, err := ParseScanPolicy(string(o.Scanner.Policy)); err != nil {
+		return err
+	}
+	for _, p := range append(append([]string(nil), o.Include...), o.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// selects reports whether the entry name passes the include and exclude patterns.
+func (o ExtractOptions) selects(name string) bool {
+	if len(o.Include) > 0 && !matchAnyGlob(o.Include, name) {
+		return false
+	}
+	return !matchAnyGlob(o.Exclude, name)
+}
+
+// ExtractWithOptions is ExtractFile with options: it extracts the target
+// file or folder (or the whole archive when targetName is "") into destDir,
+// writing only the files selected by opts. Nothing is written when the
+// destination lacks the space for the uncompressed files, unless
+// opts.SkipSpaceCheck is set.
+//
+// Parameters:
+//   - ctx: stops the extraction once done, see Archive.Extract
+//   - zipPath: full path to the ZIP file
+//   - targetName: name of the file or folder to extract (as it appears in the ZIP), or ""
+//   - destDir: destination directory where files will be extracted
+//   - opts: which files to write
+//
+// Returns:
+//   - int: number of files extracted, not counting those skipped by opts.Resume or opts.Overwrite
+//   - error: any error encountered during extraction
+func ExtractWithOptions(ctx context.Context, zipPath, targetName, destDir string, opts ExtractOptions) (int, error) {
+	archive, err := OpenFile(zipPath)
+	if err != nil {
+		return 0, err
+	}
+	defer archive.Close()
+
+	return archive.Extract(ctx, targetName, destDir, opts)
+}
+
+// Extract extracts the target file or folder of the archive (or all of it
+// when targetName is "") into destDir, writing only the files selected by
+// opts, exactly as ExtractWithOptions does.
+//
+// Once ctx is done the extraction stops like a failed one, returning ctx's
+// error: the file being written is abandoned without leaving anything under
+// its name, and opts.RemoveOnFailure or opts.Resume apply as usual.
+//
+// Parameters:
+//   - ctx: stops the extraction once done
+//   - targetName: name of the file or folder to extract (as it appears in the ZIP), or ""
+//   - destDir: destination directory where files will be extracted
+//   - opts: which files to write; Resume needs an archive opened with OpenFile
+//
+// Returns:
+//   - int: number of files extracted, not counting those skipped by opts.Resume or opts.Overwrite
+//   - error: any error encountered during extraction
+func (a *Archive) Extract(ctx context.Context, targetName, destDir string, opts ExtractOptions) (int, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	targets, err := selectTargets(a.reader.File, targetName, opts)
+	if err != nil {
+		return 0, err
+	}
+	renameForWindows(targets)
+	if err := resolveCaseCollisions(targets, destDir, opts.CaseCollisions); err != nil {
+		return 0, err
+	}
+	if err := checkTargets(targets); err != nil {
+		return 0, err
+	}
+
+	var manifest *resumeManifest
+	succeeded := false
+	if opts.Resume {
+		if a.path == "" {
+			return 0, errors.New("only archives opened from a file can resume an extraction")
+		}
+		manifest, err = openResumeManifest(a.path, destDir)
+		if err != nil {
+			return 0, err
+		}
+		defer func() { manifest.close(succeeded) }()
+
+		pending := targets[:0]
+		for _, t := range targets {
+			if !manifest.completed(t, filepath.Join(destDir, t.relPath)) {
+				pending = append(pending, t)
+			}
+		}
+		targets = pending
+	}
+
+	kept := targets[:0]
+	for _, t := range targets {
+		if !opts.Overwrite.keeps(t.file, filepath.Join(destDir, t.relPath)) {
+			kept = append(kept, t)
+		}
+	}
+	targets = kept
+
+	if !opts.SkipSpaceCheck {
+		var total uint64
+		for _, t := range targets {
+			total += t.file.UncompressedSize64
+		}
+		if err := CheckFreeSpace(destDir, total); err != nil {
+			return 0, err
+		}
+	}
+
+	log := Logger().With("archive", a.path, "target", targetName, "dest", destDir)
+	jobs := limitJobs(opts.Jobs)
+	log.Info("extracting", "files", len(targets), "jobs", jobs, "overwrite", opts.Overwrite, "resume", opts.Resume)
+
+	limit := newRateLimiter(opts.RateLimit)
+	var observer ExtractObserver = NopExtractObserver{}
+	var renames RenameObserver
+	var scans ScanObserver
+	if opts.Observer != nil {
+		synced := &syncObserver{o: opts.Observer}
+		observer, renames, scans = synced, synced, synced
+	}
+
+	// created lists the files and folders this extraction added, for
+	// RemoveOnFailure. It is guarded by mu, as are
+	// the manifest, extractedCount, firstErr and failures, which collects
+	// the errors of the files that failed under ContinueOnError.
+	var (
+		mu             sync.Mutex
+		created        []string
+		extractedCount int
+		firstErr       error
+		failures       []error
+	)
+
+	extractOne := func(t extractTarget) error {
+		// Construct destination path
+		destPath := filepath.Join(destDir, t.relPath)
+		size := t.file.UncompressedSize64
+		if t.renamed != "" {
+			log.Warn("renamed file", "name", t.name, "path", destPath, "reason", t.renamed)
+			if renames != nil {
+				renames.OnRename(t.name, destPath, t.renamed)
+			}
+		}
+		if err := opts.Scanner.check(ctx, t, scans); err != nil {
+			return err
+		}
+		observer.OnEntryStart(t.name, size)
+
+		// Create parent directories, through the long form of the path
+		// where Windows needs it.
+		diskPath := longPath(destPath)
+		missing := missingDirs(filepath.Dir(destPath))
+		if err := os.MkdirAll(filepath.Dir(diskPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		_, statErr := os.Lstat(diskPath)
+
+		// Extract the file
+		err := extractSingleFile(ctx, t.file, diskPath, limit, func(written uint64) {
+			observer.OnProgress(t.name, written, size)
+		})
+
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, missing...)
+		if err != nil {
+			log.Warn("file failed", "name", t.name, "err", err)
+			return fmt.Errorf("failed to extract %s: %w", t.name, err)
+		}
+		log.Debug("extracted file", "name", t.name, "path", destPath, "size", size)
+		if statErr != nil {
+			created = append(created, destPath)
+		}
+		if manifest != nil {
+			if err := manifest.record(t, destPath); err != nil {
+				return fmt.Errorf("failed to record progress: %w", err)
+			}
+		}
+
+		extractedCount++
+		return nil
+	}
+
+	// Workers stop taking files after the first failure, unless
+	// ContinueOnError is set, or once ctx is done; the files already being
+	// written by other workers are completed.
+	work := make(chan extractTarget)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range work {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = ctx.Err()
+				}
+				stop := firstErr != nil
+				mu.Unlock()
+				if stop {
+					continue
+				}
+
+				if err := extractOne(t); err != nil {
+					observer.OnError(t.name, err)
+					mu.Lock()
+					if opts.ContinueOnError {
+						failures = append(failures, err)
+					} else if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					continue
+				}
+				observer.OnEntryDone(t.name, filepath.Join(destDir, t.relPath))
+			}
+		}()
+	}
+	for _, t := range targets {
+		work <- t
+	}
+	close(work)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		firstErr = errors.Join(append([]error{firstErr}, failures...)...)
+	}
+
+	if firstErr != nil {
+		log.Warn("extraction failed", "extracted", extractedCount, "err", firstErr)
+		if !opts.RemoveOnFailure {
+			return extractedCount, firstErr
+		}
+		// Deepest paths first, so folders are empty by the time they are
+		// removed whatever order the workers created them in.
+		slices.SortFunc(created, func(a, b string) int { return len(b) - len(a) })
+		for _, p := range created {
+			os.Remove(p)
+		}
+		return 0, firstErr
+	}
+
+	log.Info("extracted", "files", extractedCount)
+	succeeded = true
+	return extractedCount, nil
+}
+
+// missingDirs returns dir and those of its parents that do not exist yet,
+// outermost first.
+func missingDirs(dir string) []string {
+	var missing []string
+	for {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		missing = append([]string{dir}, missing...)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return missing
+}
+
+// extractTarget pairs an entry with the path, relative to the destination
+// directory, where it is written.
+type extractTarget struct {
+	file *zip.File
+	// name is the entry name converted to UTF-8, see ExtractOptions.NameEncoding.
+	name    string
+	relPath string
+	// renamed tells why relPath differs from name, see renameForWindows
+	// and resolveCaseCollisions.
+	renamed string
+}
+
+// selectTargets returns the files of an extraction in archive order. Directory
+// entries, files filtered out by opts, including those not in opts.Names, and
+// the versions of duplicated names not picked by opts.Version are left out. It fails when no entry matches
+// targetName at all.
+func selectTargets(files []*zip.File, targetName string, opts ExtractOptions) ([]extractTarget, error) {
+	type candidate struct {
+		file    *zip.File
+		name    string
+		version int
+	}
+
+	var candidates []candidate
+	versions := make(map[string]int)
+	for _, f := range files {
+		name := decodeZipName(f, opts.NameEncoding)
+		if !matchesTarget(name, targetName) {
+			continue
+		}
+		versions[name]++
+		candidates = append(candidates, candidate{file: f, name: name, version: versions[name]})
+	}
+	if len(candidates) == 0 {
+		return nil, entryNotFound("file or folder", targetName)
+	}
+
+	var targets []extractTarget
+	taken := make(map[string]int)
+
+	for _, c := range candidates {
+		f, name := c.file, c.name
+
+		// Skip directory entries and files filtered out by the patterns
+		if f.FileInfo().IsDir() || strings.HasSuffix(name, "/") || !opts.selects(name) {
+			continue
+		}
+
+		if count := versions[name]; count > 1 {
+			if opts.Version > count {
+				return nil, fmt.Errorf("'%s' has only %d versions", name, count)
+			}
+			if want := cmp.Or(opts.Version, count); c.version != want {
+				continue
+			}
+		}
+
+		rel := name
+		if opts.Flatten {
+			rel = path.Base(name)
+			if _, ok := taken[rel]; ok {
+				rel = uniqueEntryName(rel, taken)
+			}
+			taken[rel] = len(targets)
+		}
+
+		// Names is checked last, so flattened files get the names they
+		// would get in an extraction without it.
+		if len(opts.Names) > 0 && !slices.Contains(opts.Names, name) {
+			continue
+		}
+
+		targets = append(targets, extractTarget{file: f, name: name, relPath: rel})
+	}
+
+	return targets, nil
+}
+
+// checkTargets refuses an extraction, before anything is written, when one
+// of its files cannot be extracted safely: its path leaves the destination
+// directory, or it is encrypted.
+func checkTargets(targets []extractTarget) error {
+	for _, t := range targets {
+		if !filepath.IsLocal(filepath.FromSlash(t.relPath)) {
+			return fmt.Errorf("'%s' %w", t.name, ErrPathTraversal)
+		}
+		if err := checkReadable(t.file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchAnyGlob reports whether name matches at least one of the patterns.
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches an entry name against a glob pattern.
+//
+// Patterns use path.Match syntax per path segment, plus "**" for any number
+// of segments, so "vendor/**" matches everything below vendor/. A pattern
+// without a slash, such as "*.go", is matched against the base name and so
+// applies at any depth.
+func matchGlob(pattern, name string) bool {
+	name = strings.TrimSuffix(name, "/")
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(name); i >= 0; i-- {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
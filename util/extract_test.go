@@ -0,0 +1,389 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestMatchGlob checks base-name patterns, anchored patterns and "**"
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/util/helper.go", true},
+		{"*.go", "main.goo", false},
+		{"vendor/**", "vendor/a/b.go", true},
+		{"vendor/**", "src/vendor/a.go", false},
+		{"**/vendor/**", "src/vendor/a.go", true},
+		{"src/*.go", "src/a.go", true},
+		{"src/*.go", "src/sub/a.go", false},
+		{"src/**/*.go", "src/a.go", true},
+		{"src/**/*.go", "src/x/y/a.go", true},
+		{"./docs/*", "docs/readme.md", true},
+		{"docs/", "docs/", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestExtractWithOptions checks that include and exclude patterns select files
+func TestExtractWithOptions(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"main.go":          "package main",
+		"README.md":        "readme",
+		"vendor/":          "",
+		"vendor/lib/x.go":  "package lib",
+		"internal/util.go": "package internal",
+	})
+
+	destDir := t.TempDir()
+	opts := ExtractOptions{Include: []string{"*.go"}, Exclude: []string{"vendor/**"}}
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, opts)
+	if err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("ExtractWithOptions() count = %d, want 2", count)
+	}
+
+	var written []string
+	filepath.WalkDir(destDir, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(destDir, p)
+			written = append(written, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	slices.Sort(written)
+	if want := []string{"internal/util.go", "main.go"}; !slices.Equal(written, want) {
+		t.Errorf("written files = %v, want %v", written, want)
+	}
+
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Include: []string{"[bad"}}); err == nil {
+		t.Error("ExtractWithOptions() expected error for a malformed pattern, got nil")
+	}
+}
+
+// TestExtractFlatten checks that folders are dropped and clashing names are
+// renamed in archive order (writeTestZip sorts entries by name)
+func TestExtractFlatten(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"a/readme.txt":   "a",
+		"b/readme.txt":   "b",
+		"b/c/readme.txt": "c",
+		"b/other.md":     "o",
+	})
+
+	destDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Flatten: true}); err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+
+	want := map[string]string{"readme.txt": "a", "readme (2).txt": "c", "readme (3).txt": "b", "other.md": "o"}
+	entries, _ := os.ReadDir(destDir)
+	if len(entries) != len(want) {
+		t.Errorf("destination holds %d entries, want %d", len(entries), len(want))
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(destDir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+}
+
+// writeCorruptZip writes an archive whose last entry fails its CRC check
+// once fully read, after the given good entries
+func writeCorruptZip(t *testing.T, good map[string]string, bad string) string {
+	t.Helper()
+
+	zipPath := filepath.Join(t.TempDir(), "corrupt.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	for name, content := range good {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+
+	content := []byte("this content does not match its checksum")
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               bad,
+		Method:             zip.Store,
+		CRC32:              1,
+		CompressedSize64:   uint64(len(content)),
+		UncompressedSize64: uint64(len(content)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to add corrupt entry: %v", err)
+	}
+	w.Write(content)
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+	return zipPath
+}
+
+// TestExtractFailureLeavesNoPartialFiles checks that a failing entry is never
+// left half-written and that RemoveOnFailure undoes what was created
+func TestExtractFailureLeavesNoPartialFiles(t *testing.T) {
+	zipPath := writeCorruptZip(t, map[string]string{"good.txt": "good"}, "new/dir/bad.txt")
+
+	t.Run("keep completed files", func(t *testing.T) {
+		destDir := t.TempDir()
+		count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{})
+		if err == nil || count != 1 {
+			t.Fatalf("ExtractWithOptions() = %d, %v, want 1 and an error", count, err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(destDir, "good.txt")); string(data) != "good" {
+			t.Errorf("good.txt = %q, want it kept", data)
+		}
+		leftovers, _ := os.ReadDir(filepath.Join(destDir, "new", "dir"))
+		if len(leftovers) != 0 {
+			t.Errorf("failed entry left %d files behind", len(leftovers))
+		}
+	})
+
+	t.Run("remove on failure", func(t *testing.T) {
+		destDir := t.TempDir()
+		existing := filepath.Join(destDir, "keep.me")
+		os.WriteFile(existing, []byte("mine"), 0644)
+
+		count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{RemoveOnFailure: true})
+		if err == nil || count != 0 {
+			t.Fatalf("ExtractWithOptions() = %d, %v, want 0 and an error", count, err)
+		}
+		entries, _ := os.ReadDir(destDir)
+		if len(entries) != 1 || entries[0].Name() != "keep.me" {
+			t.Errorf("destination holds %v, want only keep.me", entries)
+		}
+	})
+}
+
+// TestExtractContinueOnError checks that ContinueOnError extracts the files
+// after a failing one and reports every failure, and that Names retries
+// only the files asked for
+func TestExtractContinueOnError(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "mixed.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	bad := []byte("this content does not match its checksum")
+	for _, name := range []string{"a.txt", "bad1.txt", "b.txt", "bad2.txt"} {
+		if strings.HasPrefix(name, "bad") {
+			w, _ := zw.CreateRaw(&zip.FileHeader{
+				Name:               name,
+				Method:             zip.Store,
+				CRC32:              1,
+				CompressedSize64:   uint64(len(bad)),
+				UncompressedSize64: uint64(len(bad)),
+			})
+			w.Write(bad)
+			continue
+		}
+		w, _ := zw.Create(name)
+		w.Write([]byte(name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+	out.Close()
+
+	stopDir := t.TempDir()
+	if count, err := ExtractWithOptions(context.Background(), zipPath, "", stopDir, ExtractOptions{}); err == nil || count != 1 {
+		t.Errorf("ExtractWithOptions() = %d, %v, want 1 file and an error", count, err)
+	}
+
+	destDir := t.TempDir()
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{ContinueOnError: true})
+	if err == nil || count != 2 {
+		t.Fatalf("ExtractWithOptions(ContinueOnError) = %d, %v, want 2 files and an error", count, err)
+	}
+	for _, name := range []string{"bad1.txt", "bad2.txt"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not mention %s", err, name)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(destDir, "b.txt")); string(data) != "b.txt" {
+		t.Errorf("b.txt = %q, want it extracted after the failure", data)
+	}
+
+	retryDir := t.TempDir()
+	count, err = ExtractWithOptions(context.Background(), zipPath, "", retryDir, ExtractOptions{Names: []string{"b.txt"}})
+	if err != nil || count != 1 {
+		t.Fatalf("ExtractWithOptions(Names) = %d, %v, want 1 file", count, err)
+	}
+	if entries, _ := os.ReadDir(retryDir); len(entries) != 1 {
+		t.Errorf("Names extracted %d files, want only b.txt", len(entries))
+	}
+}
+
+// TestExtractParallel checks that concurrent extraction writes every file
+// and still stops at, and cleans up after, a failing entry
+func TestExtractParallel(t *testing.T) {
+	files := make(map[string]string)
+	for i := range 200 {
+		files[fmt.Sprintf("dir%d/file%03d.txt", i%7, i)] = strings.Repeat(fmt.Sprint(i), i)
+	}
+	zipPath := writeTestZip(t, 0, files)
+
+	destDir := t.TempDir()
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Jobs: 8})
+	if err != nil || count != len(files) {
+		t.Fatalf("ExtractWithOptions(Jobs: 8) = %d, %v, want %d files", count, err, len(files))
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(destDir, name))
+		if err != nil || string(data) != content {
+			t.Fatalf("%s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+
+	corrupt := writeCorruptZip(t, files, "dir3/zz/bad.txt")
+	cleanDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), corrupt, "", cleanDir, ExtractOptions{Jobs: 8, RemoveOnFailure: true}); err == nil {
+		t.Fatal("ExtractWithOptions(corrupt) expected an error")
+	}
+	if entries, _ := os.ReadDir(cleanDir); len(entries) != 0 {
+		t.Errorf("RemoveOnFailure left %d entries behind", len(entries))
+	}
+
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Jobs: -1}); err == nil {
+		t.Error("ExtractWithOptions(Jobs: -1) expected an error")
+	}
+}
+
+// TestExtractSingleFileCancelled checks that a cancelled context interrupts
+// the copy and leaves neither the file nor its temporary sibling behind
+func TestExtractSingleFileCancelled(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"big.txt": strings.Repeat("data", 1<<16)})
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open ZIP: %v", err)
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	destDir := t.TempDir()
+	err = extractSingleFile(ctx, r.File[0], filepath.Join(destDir, "big.txt"), nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("extractSingleFile() error = %v, want context.Canceled", err)
+	}
+	if files, _ := os.ReadDir(destDir); len(files) != 0 {
+		t.Errorf("cancelled extraction left %d files", len(files))
+	}
+}
+
+// TestExtractRestoresPermissions checks that files archived on Unix get their
+// mode back, while entries from other systems keep the default one
+func TestExtractRestoresPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions do not apply on Windows")
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "modes.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	for name, mode := range map[string]os.FileMode{"run.sh": 0750, "secret.txt": 0600} {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(mode)
+		w, _ := zw.CreateHeader(header)
+		w.Write([]byte(name))
+	}
+	w, _ := zw.Create("dos.txt")
+	w.Write([]byte("dos"))
+	zw.Close()
+	out.Close()
+
+	destDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{}); err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+
+	for name, want := range map[string]os.FileMode{"run.sh": 0750, "secret.txt": 0600} {
+		info, err := os.Stat(filepath.Join(destDir, name))
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", name, err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %v, want %v", name, got, want)
+		}
+	}
+	info, err := os.Stat(filepath.Join(destDir, "dos.txt"))
+	if err != nil {
+		t.Fatalf("Failed to stat dos.txt: %v", err)
+	}
+	if got := info.Mode().Perm(); got&0600 != 0600 {
+		t.Errorf("dos.txt mode = %v, want the default read-write mode", got)
+	}
+}
+
+// TestExtractDuplicateVersions checks that only one version of a name stored
+// several times is written, the last one unless Version picks another
+func TestExtractDuplicateVersions(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "appended.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	for _, e := range [][2]string{{"notes.txt", "v1"}, {"other.txt", "o"}, {"notes.txt", "v2"}, {"notes.txt", "v3"}} {
+		w, _ := zw.Create(e[0])
+		w.Write([]byte(e[1]))
+	}
+	zw.Close()
+	out.Close()
+
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{0, "v3"},
+		{1, "v1"},
+		{2, "v2"},
+	}
+	for _, tt := range tests {
+		destDir := t.TempDir()
+		count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Version: tt.version, Jobs: 4})
+		if err != nil || count != 2 {
+			t.Fatalf("ExtractWithOptions(Version: %d) = %d, %v, want 2 files", tt.version, count, err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(destDir, "notes.txt")); string(data) != tt.want {
+			t.Errorf("Version %d: notes.txt = %q, want %q", tt.version, data, tt.want)
+		}
+		if data, _ := os.ReadFile(filepath.Join(destDir, "other.txt")); string(data) != "o" {
+			t.Errorf("Version %d: other.txt = %q, want it extracted", tt.version, data)
+		}
+	}
+
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "notes.txt", t.TempDir(), ExtractOptions{Version: 4}); err == nil {
+		t.Error("ExtractWithOptions(Version: 4) expected an error for a name stored 3 times")
+	}
+}
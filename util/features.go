@@ -0,0 +1,51 @@
+package util
+
+// Names of the optional features that depend on build tags. Sixel
+// previews and 7z archives are not in any build yet; they are reported
+// disabled so scripts probing for them get an answer.
+const (
+	FeatureZstd  = "zstd"
+	FeatureFUSE  = "fuse"
+	FeatureSixel = "sixel"
+	Feature7z    = "7z"
+)
+
+// Feature describes an optional capability and whether this build includes it.
+type Feature struct {
+	Name        string
+	Description string
+	Enabled     bool
+}
+
+// knownFeatures lists every optional feature, in the order they are reported.
+var knownFeatures = []Feature{
+	{Name: FeatureZstd, Description: "Zstandard (method 93) entries, disabled with -tags nozstd"},
+	{Name: FeatureFUSE, Description: "mounting archives as filesystems (Linux and macOS), disabled with -tags nofuse"},
+	{Name: FeatureSixel, Description: "inline image previews in sixel-capable terminals, not in this build"},
+	{Name: Feature7z, Description: "7z archives, not in this build"},
+}
+
+var enabledFeatures = map[string]bool{}
+
+// enableFeature marks a feature as compiled in. It is called from the init
+// functions of files guarded by the feature's build tag.
+func enableFeature(name string) {
+	enabledFeatures[name] = true
+}
+
+// Features returns the optional features known to goZip, flagging the ones
+// compiled into this binary.
+func Features() []Feature {
+	features := make([]Feature, len(knownFeatures))
+	for i, f := range knownFeatures {
+		f.Enabled = enabledFeatures[f.Name]
+		features[i] = f
+	}
+
+	return features
+}
+
+// SupportedFormats returns the archive formats this build can open.
+func SupportedFormats() []string {
+	return []string{"zip"}
+}
@@ -0,0 +1,20 @@
+package util
+
+import "testing"
+
+// TestFeatures checks that the features no build has yet are reported, as
+// disabled
+func TestFeatures(t *testing.T) {
+	reported := map[string]bool{}
+	for _, f := range Features() {
+		reported[f.Name] = true
+		if (f.Name == FeatureSixel || f.Name == Feature7z) && f.Enabled {
+			t.Errorf("Features() reports %s as enabled", f.Name)
+		}
+	}
+	for _, name := range []string{FeatureZstd, FeatureFUSE, FeatureSixel, Feature7z} {
+		if !reported[name] {
+			t.Errorf("Features() does not report %s", name)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+)
+
+// FindArchives walks the directory tree under root, in lexical order, and
+// calls found for every ZIP archive in it, recognized by its signature
+// whatever its extension, see IsZipFile. Folders and files that cannot be
+// read are skipped; symbolic links are not followed.
+//
+// Parameters:
+//   - ctx: stops the walk once done
+//   - root: the folder to search
+//   - found: called with the path of each archive, root joined with its
+//     path inside, and its information
+//
+// Returns:
+//   - error: root cannot be read, or ctx's error if it was done first
+func FindArchives(ctx context.Context, root string, found func(path string, info fs.FileInfo)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if path == root {
+				return err
+			}
+			Logger().Debug("cannot search", "path", path, "err", err)
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		isZip, err := IsZipFile(path)
+		if err != nil || !isZip {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		found(path, info)
+		return nil
+	})
+}
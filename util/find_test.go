@@ -0,0 +1,60 @@
+package util
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestFindArchives checks that archives are found by their signature in
+// every folder, whatever their extension
+func TestFindArchives(t *testing.T) {
+	root := t.TempDir()
+	zipData, err := os.ReadFile("testdata/test.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"a.zip":             zipData,
+		"deep/down/b.bin":   zipData,
+		"deep/notes.zip":    []byte("not an archive"),
+		"deep/down/c.txt":   []byte("text"),
+		"other/renamed.jar": zipData,
+		"other/empty.zip":   nil,
+	}
+	for name, data := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	err = FindArchives(context.Background(), root, func(path string, info fs.FileInfo) {
+		rel, _ := filepath.Rel(root, path)
+		got = append(got, filepath.ToSlash(rel))
+	})
+	if err != nil {
+		t.Fatalf("FindArchives() unexpected error = %v", err)
+	}
+	want := []string{"a.zip", "deep/down/b.bin", "other/renamed.jar"}
+	if !slices.Equal(got, want) {
+		t.Errorf("FindArchives() found %v, want %v", got, want)
+	}
+
+	if err := FindArchives(context.Background(), filepath.Join(root, "missing"), func(string, fs.FileInfo) {}); err == nil {
+		t.Error("FindArchives() of a missing folder returned no error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := FindArchives(ctx, root, func(string, fs.FileInfo) {}); err != context.Canceled {
+		t.Errorf("FindArchives() after cancel error = %v, want %v", err, context.Canceled)
+	}
+}
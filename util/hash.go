@@ -0,0 +1,149 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// HashAlgorithm names the checksum HashEntries computes.
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "sha256"
+	HashSHA1   HashAlgorithm = "sha1"
+	HashMD5    HashAlgorithm = "md5"
+)
+
+// ParseHashAlgorithm validates an algorithm name, ignoring case and a dash
+// as in "SHA-256"; "" is HashSHA256.
+func ParseHashAlgorithm(s string) (HashAlgorithm, error) {
+	switch alg := HashAlgorithm(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "")); alg {
+	case "":
+		return HashSHA256, nil
+	case HashSHA256, HashSHA1, HashMD5:
+		return alg, nil
+	default:
+		return "", fmt.Errorf("invalid hash algorithm %q (want sha256, sha1 or md5)", s)
+	}
+}
+
+// SumsFileName returns the usual name of a checksum file of the algorithm,
+// such as SHA256SUMS.
+func (a HashAlgorithm) SumsFileName() string {
+	return strings.ToUpper(string(a)) + "SUMS"
+}
+
+func (a HashAlgorithm) new() hash.Hash {
+	switch a {
+	case HashSHA1:
+		return sha1.New()
+	case HashMD5:
+		return md5.New()
+	default:
+		return sha256.New()
+	}
+}
+
+// EntryHash is the checksum of a file inside an archive.
+type EntryHash struct {
+	Name string
+	// Sum is the checksum in lowercase hexadecimal.
+	Sum string
+}
+
+// HashEntries decompresses files of the archive and computes their
+// checksums, without writing anything to disk.
+//
+// Parameters:
+//   - ctx: stops the computation between two buffers
+//   - zipPath: full path to the ZIP file
+//   - names: the files to hash, a folder standing for every file inside
+//     it; none means the whole archive
+//   - alg: the checksum to compute
+//
+// Returns:
+//   - []EntryHash: the checksums, in archive order; a name stored several
+//     times appears once per copy
+//   - error: a name that is not in the archive, an encrypted file, or any
+//     error reading the archive
+func HashEntries(ctx context.Context, zipPath string, names []string, alg HashAlgorithm) ([]EntryHash, error) {
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return nil, openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	found := make([]bool, len(names))
+	var hashes []EntryHash
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() || !matchesAny(f.Name, names, found) {
+			continue
+		}
+		if err := checkReadable(f); err != nil {
+			return nil, err
+		}
+
+		sum, err := hashFile(ctx, f, alg)
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %w", f.Name, err)
+		}
+		hashes = append(hashes, EntryHash{Name: f.Name, Sum: sum})
+	}
+
+	for i, ok := range found {
+		if !ok {
+			return nil, entryNotFound("file or folder", names[i])
+		}
+	}
+	return hashes, nil
+}
+
+// matchesAny reports whether name is one of targets or inside one of them,
+// all of them matching when there are none, and flags the targets matched.
+func matchesAny(name string, targets []string, found []bool) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	matched := false
+	for i, target := range targets {
+		if matchesTarget(name, target) {
+			found[i], matched = true, true
+		}
+	}
+	return matched
+}
+
+// hashFile returns the checksum of the uncompressed content of f.
+func hashFile(ctx context.Context, f *zip.File, alg HashAlgorithm) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	h := alg.new()
+	if _, err := io.Copy(h, &extractReader{ctx: ctx, r: rc}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteSums writes hashes in the format of sha256sum and the like, one
+// "<checksum>  <name>" line per file, which their --check option reads.
+func WriteSums(w io.Writer, hashes []EntryHash) error {
+	for _, h := range hashes {
+		if _, err := fmt.Fprintf(w, "%s  %s\n", h.Sum, h.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestParseHashAlgorithm checks the accepted spellings
+func TestParseHashAlgorithm(t *testing.T) {
+	for s, want := range map[string]HashAlgorithm{"": HashSHA256, "SHA-256": HashSHA256, "sha1": HashSHA1, " MD5 ": HashMD5} {
+		if got, err := ParseHashAlgorithm(s); err != nil || got != want {
+			t.Errorf("ParseHashAlgorithm(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+	if _, err := ParseHashAlgorithm("crc32"); err == nil {
+		t.Error("ParseHashAlgorithm(crc32) expected error, got nil")
+	}
+	if got := HashSHA1.SumsFileName(); got != "SHA1SUMS" {
+		t.Errorf("SumsFileName() = %q, want SHA1SUMS", got)
+	}
+}
+
+// TestHashEntries checks the checksums of files, folders and whole archives
+func TestHashEntries(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"dir/": "", "dir/a.txt": "abc", "b.txt": ""})
+	const (
+		sha256ABC   = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+		sha256Empty = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	)
+
+	hashes, err := HashEntries(context.Background(), zipPath, nil, HashSHA256)
+	if err != nil {
+		t.Fatalf("HashEntries() unexpected error = %v", err)
+	}
+	sums := make(map[string]string)
+	for _, h := range hashes {
+		sums[h.Name] = h.Sum
+	}
+	if len(sums) != 2 || sums["dir/a.txt"] != sha256ABC || sums["b.txt"] != sha256Empty {
+		t.Errorf("HashEntries() = %+v", hashes)
+	}
+
+	hashes, err = HashEntries(context.Background(), zipPath, []string{"dir/"}, HashMD5)
+	if err != nil || len(hashes) != 1 || hashes[0].Sum != "900150983cd24fb0d6963f7d28e17f72" {
+		t.Errorf("HashEntries(dir/, md5) = %+v, %v", hashes, err)
+	}
+
+	var out bytes.Buffer
+	if err := WriteSums(&out, hashes); err != nil || out.String() != "900150983cd24fb0d6963f7d28e17f72  dir/a.txt\n" {
+		t.Errorf("WriteSums() = %q, %v", out.String(), err)
+	}
+
+	if _, err := HashEntries(context.Background(), zipPath, []string{"c.txt"}, HashSHA256); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("HashEntries(c.txt) error = %v, want ErrEntryNotFound", err)
+	}
+}
@@ -0,0 +1,150 @@
+package util
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+)
+
+// HeaderMismatch is an entry whose local header, in front of its data,
+// disagrees with its central directory record. Readers trust one or the
+// other, so such an entry may extract differently from what the listing
+// shows: a common sign of tampering or corruption.
+type HeaderMismatch struct {
+	Name string
+	// HeaderOffset is the position of the local header in the file, as
+	// core.ZippedFile.GetHeaderOffset returns it.
+	HeaderOffset int64
+	// Fields names what differs, such as "CRC-32" or "method".
+	Fields []string
+}
+
+// Detail describes the mismatch in a sentence.
+func (m HeaderMismatch) Detail() string {
+	return "local header disagrees with the central directory on the " + strings.Join(m.Fields, ", ")
+}
+
+// CheckHeaders compares the local header of every entry of the archive with
+// its central directory record: name, method, encryption and UTF-8 flags,
+// CRC-32 and sizes, the last three from the data descriptor when the
+// entry has one. Entries whose local header cannot be read at all are
+// reported with the field "header".
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - []HeaderMismatch: the inconsistent entries, in central directory order
+//   - error: any error opening the archive or reading its central directory
+func CheckHeaders(zipPath string) ([]HeaderMismatch, error) {
+	file, err := os.Open(zipPath)
+	if err != nil {
+		return nil, openError(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, openError(err)
+	}
+	cd, err := readCentralDirectory(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []HeaderMismatch
+	for _, rec := range cd.records {
+		start := cd.baseOffset + rec.headerOffset
+		fields := []string{"header"}
+		if lh, err := readLocalHeader(file, start); err == nil {
+			fields = compareHeaders(file, rec, lh, start+lh.size())
+		}
+		if len(fields) > 0 {
+			name := entryName(rec.name, rec.versionMadeBy, rec.flags, rec.extra, "")
+			mismatches = append(mismatches, HeaderMismatch{Name: name, HeaderOffset: start, Fields: fields})
+		}
+	}
+	return mismatches, nil
+}
+
+// compareHeaders returns the fields on which the local header lh of rec,
+// whose data starts at dataStart, disagrees with rec.
+func compareHeaders(r io.ReaderAt, rec centralRecord, lh localHeader, dataStart int64) []string {
+	var fields []string
+	if lh.name != rec.name {
+		fields = append(fields, "name")
+	}
+	if lh.method != rec.method {
+		fields = append(fields, "method")
+	}
+	const checkedFlags = 0x0001 | 0x0800
+	if lh.flags&checkedFlags != rec.flags&checkedFlags {
+		fields = append(fields, "flags")
+	}
+
+	crc, compressed, uncompressed := lh.crc, uint64(lh.compressed), uint64(lh.uncompressed)
+	if data, ok := findExtraField(lh.extra, zip64ExtraID); ok {
+		if uncompressed == 0xffffffff && len(data) >= 8 {
+			uncompressed = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		}
+		if compressed == 0xffffffff && len(data) >= 8 {
+			compressed = binary.LittleEndian.Uint64(data)
+		}
+	}
+
+	// With a data descriptor the local header may leave these at zero.
+	if lh.flags&0x8 != 0 {
+		dd, ok := readDataDescriptor(r, rec, dataStart+int64(rec.compressed))
+		if !ok {
+			return append(fields, "data descriptor")
+		}
+		if crc == 0 && compressed == 0 && uncompressed == 0 {
+			crc, compressed, uncompressed = dd.crc, dd.compressed, dd.uncompressed
+		} else if dd != (dataDescriptor{crc, compressed, uncompressed}) {
+			fields = append(fields, "data descriptor")
+		}
+	}
+
+	if crc != rec.crc {
+		fields = append(fields, "CRC-32")
+	}
+	if compressed != rec.compressed {
+		fields = append(fields, "compressed size")
+	}
+	if uncompressed != rec.uncompressed {
+		fields = append(fields, "size")
+	}
+	return fields
+}
+
+// dataDescriptor holds the values of a data descriptor.
+type dataDescriptor struct {
+	crc          uint32
+	compressed   uint64
+	uncompressed uint64
+}
+
+// readDataDescriptor reads the data descriptor of rec found at off, right
+// after its compressed data.
+func readDataDescriptor(r io.ReaderAt, rec centralRecord, off int64) (dataDescriptor, bool) {
+	n := dataDescriptorLen(r, rec, off)
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, off); err != nil {
+		return dataDescriptor{}, false
+	}
+
+	// dataDescriptorLen counted the optional signature, if present.
+	le := binary.LittleEndian
+	if n == 16 || n == 24 {
+		buf = buf[4:]
+	}
+	dd := dataDescriptor{crc: le.Uint32(buf)}
+	if len(buf) >= 20 {
+		dd.compressed, dd.uncompressed = le.Uint64(buf[4:]), le.Uint64(buf[12:])
+	} else {
+		dd.compressed, dd.uncompressed = uint64(le.Uint32(buf[4:])), uint64(le.Uint32(buf[8:]))
+	}
+	return dd, true
+}
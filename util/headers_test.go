@@ -0,0 +1,61 @@
+package util
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+// TestCheckHeaders checks that consistent archives pass, with data
+// descriptors and prepended data, and that tampered headers are reported
+func TestCheckHeaders(t *testing.T) {
+	zipPath := writeTestZip(t, 64, map[string]string{"a.txt": "alpha", "b.txt": "bravo", "c.txt": "charlie"})
+
+	mismatches, err := CheckHeaders(zipPath)
+	if err != nil || len(mismatches) != 0 {
+		t.Fatalf("CheckHeaders() = %+v, %v, want no mismatch", mismatches, err)
+	}
+
+	files, err := ListArchive(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Store instead of deflate in the local header of a.txt, and another
+	// CRC-32 in the data descriptor of b.txt.
+	data[files[0].GetHeaderOffset()+8] = 0
+	b := files[1]
+	descriptor := b.GetHeaderOffset() + localHeaderLen + int64(len(b.GetName())) + int64(b.GetCompressedSize())
+	data[descriptor+4] ^= 0xff
+	if err := os.WriteFile(zipPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mismatches, err = CheckHeaders(zipPath)
+	if err != nil {
+		t.Fatalf("CheckHeaders() unexpected error = %v", err)
+	}
+	if len(mismatches) != 2 {
+		t.Fatalf("CheckHeaders() = %+v, want 2 mismatches", mismatches)
+	}
+	if m := mismatches[0]; m.Name != "a.txt" || m.HeaderOffset != files[0].GetHeaderOffset() || !slices.Equal(m.Fields, []string{"method"}) {
+		t.Errorf("mismatch of a.txt = %+v", m)
+	}
+	if m := mismatches[1]; m.Name != "b.txt" || !slices.Equal(m.Fields, []string{"CRC-32"}) {
+		t.Errorf("mismatch of b.txt = %+v", m)
+	}
+	if got := mismatches[0].Detail(); got != "local header disagrees with the central directory on the method" {
+		t.Errorf("Detail() = %q", got)
+	}
+
+	report, err := CheckHealth(zipPath)
+	if err != nil {
+		t.Fatalf("CheckHealth() unexpected error = %v", err)
+	}
+	if n := issueKinds(report)[IssueHeaderMismatch]; n != 2 {
+		t.Errorf("%s issues = %d, want 2 (all: %v)", IssueHeaderMismatch, n, report.Issues)
+	}
+}
@@ -0,0 +1,470 @@
+package util
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Kinds of problems reported by CheckHealth.
+const (
+	IssueStructure       = "structure"
+	IssueWastedSpace     = "wasted-space"
+	IssueWeakCompression = "weak-compression"
+	IssueJunk            = "junk"
+	IssueRisky           = "risky"
+	// IssueHeaderMismatch is an entry whose local header disagrees with
+	// the central directory; see CheckHeaders.
+	IssueHeaderMismatch = "header-mismatch"
+)
+
+// HealthFix names an archive rewrite that resolves a class of issues.
+type HealthFix string
+
+// Available fixes, in the order they should be applied.
+const (
+	FixNone       HealthFix = ""
+	FixNormalize  HealthFix = "normalize"
+	FixRecompress HealthFix = "recompress"
+	FixVacuum     HealthFix = "vacuum"
+)
+
+// weakCompressionMinSize is the smallest entry worth flagging for poor
+// compression; below it the overhead dominates anyway.
+const weakCompressionMinSize = 4096
+
+// HealthIssue is a single finding of CheckHealth.
+type HealthIssue struct {
+	Kind   string
+	Entry  string
+	Detail string
+	Fix    HealthFix
+}
+
+// HealthReport summarises the state of an archive.
+type HealthReport struct {
+	// Score goes from 0 (broken) to 100 (nothing to improve).
+	Score       int
+	Entries     int
+	ArchiveSize int64
+	WastedBytes int64
+	Issues      []HealthIssue
+}
+
+// Fixes returns the distinct fixes recommended by the report, in the order
+// they should be applied.
+func (r HealthReport) Fixes() []HealthFix {
+	wanted := make(map[HealthFix]bool)
+	for _, issue := range r.Issues {
+		wanted[issue.Fix] = true
+	}
+
+	var fixes []HealthFix
+	for _, fix := range []HealthFix{FixNormalize, FixRecompress, FixVacuum} {
+		if wanted[fix] {
+			fixes = append(fixes, fix)
+		}
+	}
+
+	return fixes
+}
+
+// CheckHealth analyses the archive at zipPath for structural problems,
+// wasted space, weak compression, junk files and risky entries.
+//
+// Returns:
+//   - HealthReport: the findings and an overall score
+//   - error: the archive could not be opened at all
+func CheckHealth(zipPath string) (HealthReport, error) {
+	file, err := os.Open(zipPath)
+	if err != nil {
+		return HealthReport{}, openError(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return HealthReport{}, openError(err)
+	}
+
+	// Insecure names are exactly what the risky-entry check reports.
+	reader, err := newZipReader(file, info.Size())
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return HealthReport{}, openError(err)
+	}
+	decodeNames(reader.File, "")
+
+	report := HealthReport{Entries: len(reader.File), ArchiveSize: info.Size()}
+
+	for _, f := range reader.File {
+		if issue, ok := riskyEntry(f); ok {
+			report.Issues = append(report.Issues, issue)
+		} else if isJunkEntry(f.Name) {
+			report.Issues = append(report.Issues, HealthIssue{Kind: IssueJunk, Entry: f.Name, Detail: "operating system metadata file", Fix: FixNormalize})
+		}
+
+		if isWeaklyCompressed(f) {
+			detail := fmt.Sprintf("%s saves only %d of %d bytes", methodToString(f.Method), int64(f.UncompressedSize64)-int64(f.CompressedSize64), f.UncompressedSize64)
+			if f.Method == methodStore {
+				detail = fmt.Sprintf("%d bytes of compressible data stored uncompressed", f.UncompressedSize64)
+			}
+			report.Issues = append(report.Issues, HealthIssue{Kind: IssueWeakCompression, Entry: f.Name, Detail: detail, Fix: FixRecompress})
+		}
+	}
+
+	report.Issues = append(report.Issues, checkLayout(file, info.Size(), &report)...)
+	report.Score = healthScore(report)
+
+	return report, nil
+}
+
+// checkLayout verifies that every entry's data lies inside the file without
+// overlapping others and that its local header agrees with the central
+// directory, and measures the bytes not used by any entry.
+func checkLayout(r io.ReaderAt, size int64, report *HealthReport) []HealthIssue {
+	cd, err := readCentralDirectory(r, size)
+	if err != nil {
+		return []HealthIssue{{Kind: IssueStructure, Detail: err.Error()}}
+	}
+
+	type span struct {
+		name       string
+		start, end int64
+	}
+
+	var issues []HealthIssue
+	spans := make([]span, 0, len(cd.records))
+
+	for _, rec := range cd.records {
+		start := cd.baseOffset + rec.headerOffset
+		lh, err := readLocalHeader(r, start)
+		if err != nil {
+			issues = append(issues, HealthIssue{Kind: IssueStructure, Entry: rec.name, Detail: err.Error()})
+			continue
+		}
+		if fields := compareHeaders(r, rec, lh, start+lh.size()); len(fields) > 0 {
+			m := HeaderMismatch{Fields: fields}
+			issues = append(issues, HealthIssue{Kind: IssueHeaderMismatch, Entry: rec.name, Detail: m.Detail()})
+		}
+
+		dataEnd := start + lh.size() + int64(rec.compressed)
+		end := dataEnd + dataDescriptorLen(r, rec, dataEnd)
+		if end > cd.start {
+			issues = append(issues, HealthIssue{Kind: IssueStructure, Entry: rec.name, Detail: "entry data runs into the central directory"})
+			continue
+		}
+
+		spans = append(spans, span{name: rec.name, start: start, end: end})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var used, last int64
+	for _, s := range spans {
+		if s.start < last {
+			issues = append(issues, HealthIssue{Kind: IssueStructure, Entry: s.name, Detail: "entry data overlaps another entry"})
+		}
+		used += s.end - s.start
+		last = s.end
+	}
+
+	report.WastedBytes = cd.start - cd.baseOffset - used
+	if report.WastedBytes < 0 {
+		report.WastedBytes = 0
+	}
+	if report.WastedBytes > 0 {
+		issues = append(issues, HealthIssue{
+			Kind:   IssueWastedSpace,
+			Detail: fmt.Sprintf("%d bytes are not used by any entry", report.WastedBytes),
+			Fix:    FixVacuum,
+		})
+	}
+
+	return issues
+}
+
+// riskyEntry reports entries that could write outside the destination when
+// extracted by a careless tool: absolute paths, parent traversal and symlinks.
+func riskyEntry(f *zip.File) (HealthIssue, bool) {
+	name := strings.ReplaceAll(f.Name, "\\", "/")
+
+	switch {
+	case strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':'):
+		return HealthIssue{Kind: IssueRisky, Entry: f.Name, Detail: "absolute path", Fix: FixNormalize}, true
+	case name == ".." || strings.HasPrefix(name, "../") || strings.Contains(name, "/../") || strings.HasSuffix(name, "/.."):
+		return HealthIssue{Kind: IssueRisky, Entry: f.Name, Detail: "path traversal", Fix: FixNormalize}, true
+	case f.Mode()&fs.ModeSymlink != 0:
+		return HealthIssue{Kind: IssueRisky, Entry: f.Name, Detail: "symbolic link", Fix: FixNormalize}, true
+	}
+
+	return HealthIssue{}, false
+}
+
+// isJunkEntry reports operating system metadata that has no place in an archive.
+func isJunkEntry(name string) bool {
+	if name == "__MACOSX/" || strings.HasPrefix(name, "__MACOSX/") {
+		return true
+	}
+
+	switch base := path.Base(name); {
+	case base == ".DS_Store", base == "Thumbs.db", base == "desktop.ini":
+		return true
+	case strings.HasPrefix(base, "._"):
+		return true
+	}
+
+	return false
+}
+
+// isWeaklyCompressed reports entries that are compressed but barely shrink,
+// or stored uncompressed although their extension suggests compressible data.
+func isWeaklyCompressed(f *zip.File) bool {
+	if f.UncompressedSize64 < weakCompressionMinSize || f.FileInfo().IsDir() || f.Flags&0x1 != 0 {
+		return false
+	}
+
+	if f.Method == methodStore {
+		return compressibleExtensions[strings.ToLower(path.Ext(f.Name))]
+	}
+
+	return f.CompressedSize64*100 >= f.UncompressedSize64*95
+}
+
+// compressibleExtensions lists text-like formats that deflate well.
+var compressibleExtensions = map[string]bool{
+	".c": true, ".css": true, ".csv": true, ".go": true, ".h": true, ".html": true,
+	".java": true, ".js": true, ".json": true, ".log": true, ".md": true, ".py": true,
+	".sql": true, ".svg": true, ".txt": true, ".xml": true, ".yaml": true, ".yml": true,
+}
+
+// healthScore turns the findings into a 0-100 score. Each kind of issue has a
+// per-finding penalty and a cap, so one noisy category cannot hide the others.
+func healthScore(r HealthReport) int {
+	penalties := map[string]struct{ each, max int }{
+		IssueStructure:       {each: 50, max: 100},
+		IssueRisky:           {each: 15, max: 45},
+		IssueHeaderMismatch:  {each: 25, max: 75},
+		IssueJunk:            {each: 2, max: 10},
+		IssueWeakCompression: {each: 2, max: 10},
+	}
+
+	lost := make(map[string]int)
+	for _, issue := range r.Issues {
+		p, ok := penalties[issue.Kind]
+		if ok && lost[issue.Kind] < p.max {
+			lost[issue.Kind] = min(lost[issue.Kind]+p.each, p.max)
+		}
+	}
+
+	if r.WastedBytes > 0 && r.ArchiveSize > 0 {
+		lost[IssueWastedSpace] = min(20, int(1+r.WastedBytes*100/r.ArchiveSize))
+	}
+
+	score := 100
+	for _, n := range lost {
+		score -= n
+	}
+
+	return max(score, 0)
+}
+
+// ApplyHealthFix rewrites the archive at zipPath with the given fix.
+//
+//   - normalize drops junk and symlink entries and makes names relative
+//   - recompress deflates or stores each weakly compressed entry, whichever is smaller
+//   - vacuum copies every entry into a fresh archive, dropping unused bytes
+//
+// Returns:
+//   - int: number of entries changed or removed
+//   - error: any error encountered; the original archive is kept on failure
+func ApplyHealthFix(zipPath string, fix HealthFix) (int, error) {
+	switch fix {
+	case FixNormalize:
+		return normalizeArchive(zipPath)
+	case FixRecompress:
+		return recompressArchive(zipPath)
+	case FixVacuum:
+		return vacuumArchive(zipPath)
+	default:
+		return 0, fmt.Errorf("unknown fix %q", fix)
+	}
+}
+
+func normalizeArchive(zipPath string) (int, error) {
+	var changed int
+
+	err := rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		seen := make(map[string]bool)
+
+		for _, f := range r.File {
+			if isJunkEntry(f.Name) || f.Mode()&fs.ModeSymlink != 0 {
+				changed++
+				continue
+			}
+
+			name := normalizeEntryName(f.Name)
+			if name == "" || seen[name] {
+				changed++
+				continue
+			}
+			seen[name] = true
+
+			if name != f.Name {
+				changed++
+			}
+			if err := copyEntry(w, f, name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return changed, err
+}
+
+// normalizeEntryName turns name into a clean relative path: backslashes become
+// slashes, drive letters and leading slashes are removed and ".." components
+// that would climb above the archive root are dropped. Directory names keep
+// their trailing slash. It returns "" when nothing is left.
+func normalizeEntryName(name string) string {
+	isDir := strings.HasSuffix(name, "/") || strings.HasSuffix(name, "\\")
+
+	name = strings.ReplaceAll(name, "\\", "/")
+	if len(name) > 1 && name[1] == ':' {
+		name = name[2:]
+	}
+
+	name = path.Clean("/" + name)
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || name == "." {
+		return ""
+	}
+
+	if isDir {
+		name += "/"
+	}
+
+	return name
+}
+
+// recompressLevel is the DEFLATE level entries are recompressed with; the
+// choice between STORE and DEFLATE is measured at the same level.
+const recompressLevel = flate.BestCompression
+
+func recompressArchive(zipPath string) (int, error) {
+	var changed int
+
+	err := rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		w.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, recompressLevel)
+		})
+
+		for _, f := range r.File {
+			if !isWeaklyCompressed(f) {
+				if err := copyEntry(w, f, f.Name); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := recompressEntry(w, f); err != nil {
+				return fmt.Errorf("failed to recompress %s: %w", f.Name, err)
+			}
+			changed++
+		}
+
+		return nil
+	})
+
+	return changed, err
+}
+
+// recompressEntry writes f with DEFLATE if that makes it smaller and with
+// STORE otherwise. The entry is decompressed twice, once to measure and once
+// to write, so large entries never have to fit in memory; w must deflate at
+// recompressLevel, as deflatedSize measures.
+func recompressEntry(w *zip.Writer, f *zip.File) error {
+	deflated, err := deflatedSize(f)
+	if err != nil {
+		return err
+	}
+
+	header := f.FileHeader
+	header.Extra = stripExtraField(header.Extra, zip64ExtraID)
+	header.Method = methodStore
+	if deflated < f.UncompressedSize64 {
+		header.Method = methodDeflate
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	fw, err := w.CreateHeader(&header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, rc)
+	return err
+}
+
+// deflatedSize returns how many bytes f takes once deflated at
+// recompressLevel.
+func deflatedSize(f *zip.File) (uint64, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	counter := &countingWriter{}
+	fw, err := flate.NewWriter(counter, recompressLevel)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(fw, rc); err != nil {
+		return 0, err
+	}
+	if err := fw.Close(); err != nil {
+		return 0, err
+	}
+
+	return counter.n, nil
+}
+
+// countingWriter discards what it is given, counting the bytes.
+type countingWriter struct {
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += uint64(len(p))
+	return len(p), nil
+}
+
+func vacuumArchive(zipPath string) (int, error) {
+	var count int
+
+	err := rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		for _, f := range r.File {
+			if err := copyEntry(w, f, f.Name); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+
+	return count, err
+}
@@ -0,0 +1,189 @@
+package util
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTestZip writes an archive with the given entries after prefix junk bytes,
+// which are not part of any entry and count as wasted space
+func writeTestZip(t *testing.T, prefix int, entries map[string]string, stored ...string) string {
+	t.Helper()
+
+	zipPath := filepath.Join(t.TempDir(), "test.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer out.Close()
+
+	out.Write(make([]byte, prefix))
+
+	w := zip.NewWriter(out)
+	w.SetOffset(int64(prefix))
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		method := zip.Deflate
+		if slices.Contains(stored, name) {
+			method = zip.Store
+		}
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+		if err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+		fw.Write([]byte(entries[name]))
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+
+	return zipPath
+}
+
+func issueKinds(r HealthReport) map[string]int {
+	kinds := make(map[string]int)
+	for _, issue := range r.Issues {
+		kinds[issue.Kind]++
+	}
+	return kinds
+}
+
+// TestCheckHealthClean checks that a tidy archive gets a perfect score
+func TestCheckHealthClean(t *testing.T) {
+	report, err := CheckHealth("testdata/test.zip")
+	if err != nil {
+		t.Fatalf("CheckHealth() unexpected error = %v", err)
+	}
+
+	if report.Score != 100 || len(report.Issues) != 0 {
+		t.Errorf("CheckHealth() = score %d, issues %v, want 100 and none", report.Score, report.Issues)
+	}
+}
+
+// TestCheckHealthFindings checks that every kind of issue is detected
+func TestCheckHealthFindings(t *testing.T) {
+	text := strings.Repeat("plain text compresses well ", 400)
+	zipPath := writeTestZip(t, 64, map[string]string{
+		"docs/readme.txt":  text,
+		"docs/.DS_Store":   "junk",
+		"../escape.txt":    "evil",
+		"/etc/passwd":      "evil",
+		"__MACOSX/._x.txt": "junk",
+	}, "docs/readme.txt")
+
+	report, err := CheckHealth(zipPath)
+	if err != nil {
+		t.Fatalf("CheckHealth() unexpected error = %v", err)
+	}
+
+	kinds := issueKinds(report)
+	want := map[string]int{IssueJunk: 2, IssueRisky: 2, IssueWeakCompression: 1, IssueWastedSpace: 1}
+	for kind, n := range want {
+		if kinds[kind] != n {
+			t.Errorf("%s issues = %d, want %d (all: %v)", kind, kinds[kind], n, report.Issues)
+		}
+	}
+
+	if report.WastedBytes != 64 {
+		t.Errorf("WastedBytes = %d, want 64", report.WastedBytes)
+	}
+	if report.Score >= 100 || report.Score < 0 {
+		t.Errorf("Score = %d, want between 0 and 99", report.Score)
+	}
+
+	wantFixes := []HealthFix{FixNormalize, FixRecompress, FixVacuum}
+	if got := report.Fixes(); !slices.Equal(got, wantFixes) {
+		t.Errorf("Fixes() = %v, want %v", got, wantFixes)
+	}
+
+	for _, fix := range wantFixes {
+		if _, err := ApplyHealthFix(zipPath, fix); err != nil {
+			t.Fatalf("ApplyHealthFix(%s) unexpected error = %v", fix, err)
+		}
+	}
+
+	report, err = CheckHealth(zipPath)
+	if err != nil {
+		t.Fatalf("CheckHealth() after fixes unexpected error = %v", err)
+	}
+	if report.Score != 100 {
+		t.Errorf("Score after fixes = %d, want 100 (issues: %v)", report.Score, report.Issues)
+	}
+
+	content, err := ListArchive(zipPath)
+	if err != nil {
+		t.Fatalf("ListArchive() unexpected error = %v", err)
+	}
+	var names []string
+	for _, zf := range content {
+		names = append(names, zf.GetName())
+	}
+	wantNames := []string{"escape.txt", "etc/passwd", "docs/readme.txt"}
+	if !slices.Equal(names, wantNames) {
+		t.Errorf("entries after fixes = %v, want %v", names, wantNames)
+	}
+}
+
+// TestRecompressMeasuredLevel checks recompressed entries are written at
+// the level their size was measured at, so they end as measured
+func TestRecompressMeasuredLevel(t *testing.T) {
+	var text strings.Builder
+	for i := 0; text.Len() < 64<<10; i++ {
+		fmt.Fprintf(&text, "line %d of a log that deflates better at the best level %x\n", i, i*i)
+	}
+	zipPath := writeTestZip(t, 0, map[string]string{"app.log": text.String()}, "app.log")
+
+	if _, err := ApplyHealthFix(zipPath, FixRecompress); err != nil {
+		t.Fatalf("ApplyHealthFix(recompress) unexpected error = %v", err)
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("OpenReader() unexpected error = %v", err)
+	}
+	defer r.Close()
+	f := r.File[0]
+	measured, err := deflatedSize(f)
+	if err != nil {
+		t.Fatalf("deflatedSize() unexpected error = %v", err)
+	}
+	if f.Method != methodDeflate || f.CompressedSize64 != measured {
+		t.Errorf("recompressed app.log: method %d, %d bytes, want deflated to the %d bytes measured", f.Method, f.CompressedSize64, measured)
+	}
+}
+
+// TestNormalizeEntryName checks the cleanup of unsafe entry names
+func TestNormalizeEntryName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "dir/file.txt", expected: "dir/file.txt"},
+		{name: "/abs/file.txt", expected: "abs/file.txt"},
+		{name: "../../up.txt", expected: "up.txt"},
+		{name: "a/../../b.txt", expected: "b.txt"},
+		{name: `C:\win\file.txt`, expected: "win/file.txt"},
+		{name: "folder/", expected: "folder/"},
+		{name: "..", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeEntryName(tt.name); got != tt.expected {
+				t.Errorf("normalizeEntryName(%q) = %q, want %q", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,23 +1,32 @@
 // Package util provides utility functions for working with ZIP files.
 // It includes functionality for parsing command-line arguments,
 // opening ZIP files, and extracting their contents.
+//
+// Programs embedding goZip use Archive, which lists and extracts an archive
+// opened from a file or any io.ReaderAt without touching os.Args or the
+// working directory.
 package util
 
 import (
 	"archive/zip"
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/cainlara/gozip/core"
 )
 
 // GetFileToExtract retrieves the ZIP file specified in command-line arguments
-// and extracts its contents.
+// and lists its contents.
 //
 // This function performs the following operations:
 //  1. Obtains the current execution directory
@@ -25,6 +34,9 @@ import (
 //  3. Constructs the full path to the ZIP file
 //  4. Opens and reads the contents of the ZIP file
 //
+// Parameters:
+//   - ctx: abandons the listing once done
+//
 // Returns:
 //   - string: name of the ZIP file
 //   - string: full path to the ZIP file
@@ -35,25 +47,41 @@ import (
 //   - Error obtaining the execution directory
 //   - Error parsing arguments (no arguments, too many arguments, invalid extension)
 //   - Error opening the ZIP file (file doesn't exist, not a valid ZIP)
-func GetFileToExtract() (string, string, []core.ZippedFile, error) {
-	execFolder, err := getExecutionFolder()
+//   - ctx's error, once ctx is done
+func GetFileToExtract(ctx context.Context) (string, string, []core.ZippedFile, error) {
+	fileName, filePath, err := GetArchiveArgument()
 	if err != nil {
 		return "", "", nil, err
 	}
 
-	fileName, err := getFileArgumentValue()
+	content, err := openZipFile(ctx, filePath)
 	if err != nil {
 		return "", "", nil, err
 	}
 
-	filePath := filepath.Join(execFolder, fileName)
+	return fileName, filePath, content, nil
+}
 
-	content, err := openZipFile(filePath)
+// GetArchiveArgument is GetFileToExtract without the listing: it returns
+// the name of the ZIP file given on the command line and its full path,
+// leaving the archive unopened.
+//
+// Returns:
+//   - string: name of the ZIP file
+//   - string: full path to the ZIP file
+//   - error: any error obtaining the execution directory or parsing arguments
+func GetArchiveArgument() (string, string, error) {
+	execFolder, err := getExecutionFolder()
 	if err != nil {
-		return "", "", nil, err
+		return "", "", err
 	}
 
-	return fileName, filePath, content, nil
+	fileName, err := getFileArgumentValue()
+	if err != nil {
+		return "", "", err
+	}
+
+	return fileName, filepath.Join(execFolder, fileName), nil
 }
 
 func getExecutionFolder() (string, error) {
@@ -86,70 +114,227 @@ func getFileArgumentValue() (string, error) {
 	return fileName, nil
 }
 
-func openZipFile(filePath string) ([]core.ZippedFile, error) {
-	reader, err := zip.OpenReader(filePath)
-	if err != nil {
+// ListArchive opens the ZIP file at zipPath and returns the entries it contains,
+// in the order they appear in the central directory.
+func ListArchive(zipPath string) ([]core.ZippedFile, error) {
+	return openZipFile(context.Background(), zipPath)
+}
+
+// openZipFile lists the archive at filePath. Reading the central directory
+// cannot be interrupted, so ctx is checked before and after it.
+func openZipFile(ctx context.Context, filePath string) ([]core.ZippedFile, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	defer reader.Close()
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, openError(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, openError(err)
+	}
+
+	content, err := listEntries(f, info.Size())
+	if err != nil {
+		Logger().Warn("cannot list archive", "path", filePath, "err", err)
+		return nil, err
+	}
+	Logger().Info("opened archive", "path", filePath, "entries", len(content), "size", info.Size())
 
-	content := make([]core.ZippedFile, 0, len(reader.File))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	for _, f := range reader.File {
-		fi := f.FileInfo()
-		name := f.Name
-		isDir := fi.IsDir()
-		uncompressed := f.UncompressedSize64
-		compressed := f.CompressedSize64
-		method := methodToString(f.Method)
+	return content, nil
+}
 
-		var modStr string
-		if !f.Modified.IsZero() {
-			modStr = f.Modified.UTC().Format(time.RFC3339)
-		} else {
-			modStr = "-"
-		}
+// listEntries lists the archive in r, which is size bytes long, straight
+// from its central directory. The entries are those archive/zip would
+// report, plus what it keeps to itself, such as local header offsets.
+func listEntries(r io.ReaderAt, size int64) ([]core.ZippedFile, error) {
+	_, content, err := readListing(r, size)
+	return content, err
+}
 
-		crc := f.CRC32
+// readListing is listEntries returning the summary of the archive as well.
+func readListing(r io.ReaderAt, size int64) (core.ArchiveInfo, []core.ZippedFile, error) {
+	cd, err := readCentralDirectory(r, size)
+	if err != nil {
+		return core.ArchiveInfo{}, nil, fmt.Errorf("%w: %w: %w", ErrCannotOpen, ErrNotZip, err)
+	}
 
-		zf := core.NewZippedFile(name, isDir, uncompressed, compressed, method, modStr, crc)
+	info := archiveInfo(cd)
+	content := make([]core.ZippedFile, 0, len(cd.records))
+	for _, rec := range cd.records {
+		zf := zippedFileFromRecord(rec, cd.baseOffset)
+		info.Add(zf)
 		content = append(content, zf)
 	}
 
-	return content, nil
+	return info, content, nil
+}
+
+// archiveInfo returns the archive-level part of the summary of an archive,
+// before its entries are added.
+func archiveInfo(cd *centralDirectory) core.ArchiveInfo {
+	return core.ArchiveInfo{
+		Format:    "zip",
+		Comment:   cd.comment,
+		Zip64:     cd.zip64,
+		Prepended: cd.baseOffset,
+		Trailing:  cd.trailing,
+	}
+}
+
+// ReadArchiveInfo returns the summary of the archive at zipPath: its entry
+// count and sizes, comment, and whether it uses Zip64 or encryption.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - core.ArchiveInfo: the summary of the archive
+//   - error: any error encountered while reading the archive
+func ReadArchiveInfo(zipPath string) (core.ArchiveInfo, error) {
+	a, err := OpenFile(zipPath)
+	if err != nil {
+		return core.ArchiveInfo{}, err
+	}
+	defer a.Close()
+
+	return a.Info()
 }
 
 func methodToString(m uint16) string {
 	switch m {
-	case 0:
+	case methodStore:
 		return "STORE"
-	case 8:
+	case methodDeflate:
 		return "DEFLATE"
+	case methodBzip2:
+		return "BZIP2"
+	case methodLZMA:
+		return "LZMA"
+	case methodZstd:
+		return "ZSTD"
+	case methodXZ:
+		return "XZ"
+	case methodAES:
+		return "AES"
 	default:
 		return fmt.Sprintf("0x%X", m)
 	}
 }
 
+// flagNames describes the general purpose bit flags worth knowing about, by
+// bit.
+var flagNames = []struct {
+	bit  uint16
+	name string
+}{
+	{0x0001, "encrypted"},
+	{0x0008, "data descriptor (sizes and CRC after the data)"},
+	{0x0020, "compressed patched data"},
+	{0x0040, "strong encryption"},
+	{0x0800, "UTF-8 name"},
+	{0x2000, "masked local header"},
+}
+
+// DescribeFlags names the general purpose bit flags set in flags, as
+// core.ZippedFile.GetFlags returns them. Bits without a name are given as
+// "bit N".
+func DescribeFlags(flags uint16) []string {
+	var names []string
+	for bit := range 16 {
+		mask := uint16(1) << bit
+		// Bits 1 and 2 only tune the compression level or method.
+		if flags&mask == 0 || mask == 0x0002 || mask == 0x0004 {
+			continue
+		}
+		name := fmt.Sprintf("bit %d", bit)
+		for _, f := range flagNames {
+			if f.bit == mask {
+				name = f.name
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// FormatSize renders a byte count for humans, e.g. "512 B" or "1.5 MiB".
+func FormatSize(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseSize parses a byte count such as "4096", "64k", "100M" or "2GB".
+// Suffixes are binary multiples: k is 1024 bytes.
+func ParseSize(s string) (int64, error) {
+	num := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+
+	multiplier := int64(1)
+	if n := len(num); n > 0 {
+		switch num[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			num = num[:n-1]
+		}
+	}
+
+	value, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || value < 0 || value > (1<<62)/multiplier {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return value * multiplier, nil
+}
+
 // ExtractFile extracts a file or folder from a ZIP archive to the destination directory.
 //
 // If the target is a file, only that file is extracted.
 // If the target is a folder, all contents within that folder are extracted recursively.
+// An empty target extracts the whole archive.
 //
 // Parameters:
+//   - ctx: stops the extraction once done, see Archive.Extract
 //   - zipPath: full path to the ZIP file
-//   - targetName: name of the file or folder to extract (as it appears in the ZIP)
+//   - targetName: name of the file or folder to extract (as it appears in the ZIP), or ""
 //   - destDir: destination directory where files will be extracted
 //
 // Returns:
 //   - int: number of files extracted
 //   - error: any error encountered during extraction
-func ExtractFile(zipPath, targetName, destDir string) (int, error) {
-	reader, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return 0, fmt.Errorf("failed to open ZIP file: %w", err)
+func ExtractFile(ctx context.Context, zipPath, targetName, destDir string) (int, error) {
+	return ExtractWithOptions(ctx, zipPath, targetName, destDir, ExtractOptions{})
+}
+
+// matchesTarget reports whether the entry name is the target itself or lies
+// inside the target folder. An empty target matches every entry.
+func matchesTarget(name, targetName string) bool {
+	if targetName == "" {
+		return true
 	}
-	defer reader.Close()
 
 	// Normalize target name to handle both files and folders
 	targetPrefix := targetName
@@ -157,57 +342,150 @@ func ExtractFile(zipPath, targetName, destDir string) (int, error) {
 		targetPrefix = targetName + "/"
 	}
 
-	var extractedCount int
-	var found bool
-
-	for _, f := range reader.File {
-		// Check if this file matches (exact match) or is within the target folder
-		if f.Name == targetName || strings.HasPrefix(f.Name, targetPrefix) {
-			found = true
-
-			// Skip if it's a directory entry
-			if f.FileInfo().IsDir() {
-				continue
-			}
+	return name == targetName || strings.HasPrefix(name, targetPrefix)
+}
 
-			// Construct destination path
-			destPath := filepath.Join(destDir, f.Name)
+// extractBufferSize is the size of the copy buffers and write buffers used
+// to extract files.
+const extractBufferSize = 128 << 10
+
+// extractBuffers and extractWriters are shared by every extraction, so that
+// extracting thousands of files, possibly from several workers at once,
+// reuses a few large buffers instead of allocating new ones for each file.
+var (
+	extractBuffers = sync.Pool{New: func() any {
+		b := make([]byte, extractBufferSize)
+		return &b
+	}}
+	extractWriters = sync.Pool{New: func() any {
+		return bufio.NewWriterSize(nil, extractBufferSize)
+	}}
+)
 
-			// Create parent directories
-			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-				return extractedCount, fmt.Errorf("failed to create directory: %w", err)
-			}
+// writerOnly hides every method of a writer but Write. io.CopyBuffer would
+// otherwise hand the copy over to bufio.Writer.ReadFrom and then
+// os.File.ReadFrom, which ignore the pooled buffer and allocate their own.
+type writerOnly struct {
+	io.Writer
+}
 
-			// Extract the file
-			if err := extractSingleFile(f, destPath); err != nil {
-				return extractedCount, fmt.Errorf("failed to extract %s: %w", f.Name, err)
-			}
+// extractReader reads an entry being extracted. It fails once its context
+// is done, so that cancelling an extraction also interrupts the file being
+// written, and reports the bytes read so far to progress, if not nil.
+type extractReader struct {
+	ctx      context.Context
+	r        io.Reader
+	limit    *rateLimiter
+	read     uint64
+	progress func(read uint64)
+}
 
-			extractedCount++
-		}
+func (er *extractReader) Read(p []byte) (int, error) {
+	if err := er.ctx.Err(); err != nil {
+		return 0, err
 	}
 
-	if !found {
-		return 0, fmt.Errorf("file or folder '%s' not found in ZIP archive", targetName)
+	n, err := er.r.Read(p)
+	if waitErr := er.limit.wait(er.ctx, n); waitErr != nil {
+		return n, waitErr
 	}
-
-	return extractedCount, nil
+	if n > 0 && er.progress != nil {
+		er.read += uint64(n)
+		er.progress(er.read)
+	}
+	return n, err
 }
 
 // extractSingleFile extracts a single file from the ZIP archive to the destination path.
-func extractSingleFile(f *zip.File, destPath string) error {
+// The content is written to a temporary file next to it and renamed into place
+// only once complete and checked, so a failed or interrupted extraction never
+// leaves a truncated file under the real name. It gives up, removing the
+// temporary file, as soon as ctx is done. limit, if not nil, slows the
+// reads down to its rate. progress, if not nil, is called with the number
+// of bytes written so far each time a chunk is read.
+func extractSingleFile(ctx context.Context, f *zip.File, destPath string, limit *rateLimiter, progress func(written uint64)) error {
 	rc, err := f.Open()
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
 
-	outFile, err := os.Create(destPath)
+	outFile, err := createTempSibling(destPath)
+	if err != nil {
+		return err
+	}
+
+	buf := extractBuffers.Get().(*[]byte)
+	defer extractBuffers.Put(buf)
+	bw := extractWriters.Get().(*bufio.Writer)
+	bw.Reset(outFile)
+	defer func() {
+		bw.Reset(nil)
+		extractWriters.Put(bw)
+	}()
+
+	_, err = io.CopyBuffer(writerOnly{bw}, &extractReader{ctx: ctx, r: rc, limit: limit, progress: progress}, *buf)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = restorePermissions(f, outFile.Name())
+	}
+	if err == nil {
+		err = restoreTimes(f, outFile.Name())
+	}
+	if err == nil {
+		err = os.Rename(outFile.Name(), destPath)
+	}
 	if err != nil {
+		os.Remove(outFile.Name())
 		return err
 	}
-	defer outFile.Close()
 
-	_, err = io.Copy(outFile, rc)
-	return err
+	return nil
+}
+
+// restorePermissions gives the file at path the permissions Unix tools stored
+// with f, and, when running as root, its owner and its setuid, setgid and
+// sticky bits. Entries made on other systems keep the default permissions.
+func restorePermissions(f *zip.File, path string) error {
+	if creator := f.CreatorVersion >> 8; creator != 3 && creator != 19 { // Unix, macOS
+		return nil
+	}
+
+	mode := f.Mode()
+	if mode.Perm() == 0 {
+		// Some tools leave the attributes empty rather than record a mode.
+		return nil
+	}
+
+	perm := mode.Perm()
+	if os.Geteuid() == 0 {
+		if uid, gid := extraOwner(f.Extra); uid >= 0 {
+			if err := os.Chown(path, uid, gid); err != nil {
+				return err
+			}
+		}
+		perm |= mode & (fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+	}
+
+	return os.Chmod(path, perm)
+}
+
+// createTempSibling creates a new, hidden file next to path. Unlike
+// os.CreateTemp it uses the 0666 permission (before the umask) of os.Create,
+// so the file keeps the usual mode once renamed to path.
+func createTempSibling(path string) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	for tries := 0; ; tries++ {
+		name := filepath.Join(dir, fmt.Sprintf(".%s.%08x.tmp", base, rand.Uint32()))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if errors.Is(err, fs.ErrExist) && tries < 100 {
+			continue
+		}
+		return f, err
+	}
 }
@@ -1,7 +1,14 @@
 package util
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -29,9 +36,14 @@ func TestMethodToString(t *testing.T) {
 			expected: "0x1",
 		},
 		{
-			name:     "unknown method 12",
+			name:     "BZIP2 method (12)",
 			method:   12,
-			expected: "0xC",
+			expected: "BZIP2",
+		},
+		{
+			name:     "ZSTD method (93)",
+			method:   93,
+			expected: "ZSTD",
 		},
 		{
 			name:     "unknown method 255",
@@ -39,9 +51,19 @@ func TestMethodToString(t *testing.T) {
 			expected: "0xFF",
 		},
 		{
-			name:     "BZIP2 method (14)",
+			name:     "LZMA method (14)",
 			method:   14,
-			expected: "0xE",
+			expected: "LZMA",
+		},
+		{
+			name:     "XZ method (95)",
+			method:   95,
+			expected: "XZ",
+		},
+		{
+			name:     "WinZip AES (99)",
+			method:   99,
+			expected: "AES",
 		},
 	}
 
@@ -168,7 +190,7 @@ func TestGetExecutionFolder(t *testing.T) {
 // TestOpenZipFileErrors checks the error handling when opening ZIP files
 func TestOpenZipFileErrors(t *testing.T) {
 	t.Run("archivo no existente", func(t *testing.T) {
-		_, err := openZipFile("/path/to/nonexistent/file.zip")
+		_, err := openZipFile(context.Background(), "/path/to/nonexistent/file.zip")
 		if err == nil {
 			t.Error("openZipFile() expected error for non-existent file, got nil")
 		}
@@ -184,7 +206,7 @@ func TestOpenZipFileErrors(t *testing.T) {
 		tmpFile.WriteString("This is not a zip file")
 		tmpFile.Close()
 
-		_, err = openZipFile(tmpFile.Name())
+		_, err = openZipFile(context.Background(), tmpFile.Name())
 		if err == nil {
 			t.Error("openZipFile() expected error for non-zip file, got nil")
 		}
@@ -200,7 +222,7 @@ func TestOpenZipFileSuccess(t *testing.T) {
 		t.Skip("Skipping test: testdata/test.zip not found. Create a test zip file to run this test.")
 	}
 
-	content, err := openZipFile(testZipPath)
+	content, err := openZipFile(context.Background(), testZipPath)
 	if err != nil {
 		t.Fatalf("openZipFile() unexpected error = %v", err)
 	}
@@ -233,7 +255,7 @@ func TestGetFileToExtractIntegration(t *testing.T) {
 	t.Run("archivo no encontrado en directorio actual", func(t *testing.T) {
 		os.Args = []string{"program", "nonexistent.zip"}
 
-		_, _, _, err := GetFileToExtract()
+		_, _, _, err := GetFileToExtract(context.Background())
 		if err == nil {
 			t.Error("GetFileToExtract() expected error for non-existent file, got nil")
 		}
@@ -242,7 +264,7 @@ func TestGetFileToExtractIntegration(t *testing.T) {
 	t.Run("argumentos inválidos", func(t *testing.T) {
 		os.Args = []string{"program"}
 
-		_, _, _, err := GetFileToExtract()
+		_, _, _, err := GetFileToExtract(context.Background())
 		if err == nil {
 			t.Error("GetFileToExtract() expected error for missing arguments, got nil")
 		}
@@ -272,3 +294,151 @@ func BenchmarkGetFileArgumentValue(b *testing.B) {
 		getFileArgumentValue()
 	}
 }
+
+// TestDescribeFlags checks the names of the flag bits, and that the
+// compression option bits are left out
+func TestDescribeFlags(t *testing.T) {
+	got := DescribeFlags(0x0001 | 0x0002 | 0x0008 | 0x0800 | 0x8000)
+	want := []string{"encrypted", "data descriptor (sizes and CRC after the data)", "UTF-8 name", "bit 15"}
+	if !slices.Equal(got, want) {
+		t.Errorf("DescribeFlags() = %q, want %q", got, want)
+	}
+	if got := DescribeFlags(0); len(got) != 0 {
+		t.Errorf("DescribeFlags(0) = %q, want none", got)
+	}
+}
+
+// TestFormatSize checks the unit chosen for several magnitudes
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSize(tt.n); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestParseSize checks byte counts with and without unit suffixes
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "4096", want: 4096},
+		{in: "64k", want: 64 << 10},
+		{in: "100M", want: 100 << 20},
+		{in: "2GB", want: 2 << 30},
+		{in: "", wantErr: true},
+		{in: "M", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "1.5G", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// benchmarkArchive writes an archive of n deflated files of the given size
+func benchmarkArchive(b *testing.B, n, size int) []*zip.File {
+	b.Helper()
+
+	zipPath := filepath.Join(b.TempDir(), "bench.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		b.Fatalf("Failed to create ZIP: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	content := bytes.Repeat([]byte("goZip benchmark line of text\n"), size/29+1)[:size]
+	for i := range n {
+		w, _ := zw.Create(fmt.Sprintf("file%04d.txt", i))
+		w.Write(content)
+	}
+	zw.Close()
+	out.Close()
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		b.Fatalf("Failed to open ZIP: %v", err)
+	}
+	b.Cleanup(func() { r.Close() })
+	return r.File
+}
+
+// extractSingleFileUnpooled is extractSingleFile as it was before pooling,
+// kept as the baseline of BenchmarkExtractSingleFile: a plain io.Copy that
+// allocates a fresh buffer for every file
+func extractSingleFileUnpooled(_ context.Context, f *zip.File, destPath string, _ *rateLimiter, _ func(uint64)) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	outFile, err := createTempSibling(destPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(outFile, rc)
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(outFile.Name(), destPath)
+	}
+	if err != nil {
+		os.Remove(outFile.Name())
+	}
+	return err
+}
+
+// BenchmarkExtractSingleFile compares pooled, buffered extraction with the
+// plain io.Copy baseline, for many small files and for one large file
+func BenchmarkExtractSingleFile(b *testing.B) {
+	cases := []struct {
+		name  string
+		files []*zip.File
+	}{
+		{"small", benchmarkArchive(b, 200, 4<<10)},
+		{"large", benchmarkArchive(b, 1, 16<<20)},
+	}
+	impls := []struct {
+		name    string
+		extract func(context.Context, *zip.File, string, *rateLimiter, func(uint64)) error
+	}{
+		{"pooled", extractSingleFile},
+		{"io.Copy", extractSingleFileUnpooled},
+	}
+
+	for _, c := range cases {
+		for _, impl := range impls {
+			b.Run(c.name+"/"+impl.name, func(b *testing.B) {
+				destDir := b.TempDir()
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					for _, f := range c.files {
+						if err := impl.extract(context.Background(), f, filepath.Join(destDir, f.Name), nil, nil); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
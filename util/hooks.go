@@ -0,0 +1,204 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// ExtractHooks are the commands run once an extraction completes, to chain
+// goZip into other tools. In a command, {} stands for the extracted paths
+// and {dest} for the destination folder, both quoted for the shell.
+type ExtractHooks struct {
+	// Command runs once per extraction, with {} replaced by the paths of
+	// every file written.
+	Command string
+	// ByPattern maps globs, matched against entry names like
+	// ExtractOptions.Include, to a command run once per file written whose
+	// name matches, with {} replaced by its path, e.g. "*.deb": "dpkg -I {}".
+	ByPattern map[string]string
+}
+
+// IsEmpty reports whether no hook is configured.
+func (h ExtractHooks) IsEmpty() bool {
+	return h.Command == "" && len(h.ByPattern) == 0
+}
+
+// ExtractedFile is a file written by an extraction.
+type ExtractedFile struct {
+	// Name is the entry name inside the archive.
+	Name string
+	// Path is where it was written.
+	Path string
+}
+
+// FileRecorder is an ExtractObserver that records the files written, for
+// RunExtractHooks, and passes every event on to Next when it is set.
+type FileRecorder struct {
+	Next  ExtractObserver
+	Files []ExtractedFile
+}
+
+func (r *FileRecorder) OnEntryStart(name string, size uint64) {
+	if r.Next != nil {
+		r.Next.OnEntryStart(name, size)
+	}
+}
+
+func (r *FileRecorder) OnProgress(name string, written, size uint64) {
+	if r.Next != nil {
+		r.Next.OnProgress(name, written, size)
+	}
+}
+
+func (r *FileRecorder) OnEntryDone(name, path string) {
+	r.Files = append(r.Files, ExtractedFile{Name: name, Path: path})
+	if r.Next != nil {
+		r.Next.OnEntryDone(name, path)
+	}
+}
+
+func (r *FileRecorder) OnError(name string, err error) {
+	if r.Next != nil {
+		r.Next.OnError(name, err)
+	}
+}
+
+func (r *FileRecorder) OnRename(name, path, reason string) {
+	if next, ok := r.Next.(RenameObserver); ok {
+		next.OnRename(name, path, reason)
+	}
+}
+
+func (r *FileRecorder) OnFlagged(name, report string) {
+	if next, ok := r.Next.(ScanObserver); ok {
+		next.OnFlagged(name, report)
+	}
+}
+
+// HookResult is the outcome of one hook command.
+type HookResult struct {
+	// Command is the command line run, after substitution.
+	Command string
+	// Output holds what it wrote to standard output and standard error.
+	Output string
+	// Err is set when it could not run or exited with an error.
+	Err error
+}
+
+// RunExtractHooks runs the hooks matching the files an extraction wrote,
+// one after the other, through the shell: sh, or cmd on Windows. Nothing
+// runs when no file was written.
+//
+// Parameters:
+//   - ctx: cancels the command running
+//   - hooks: the configured hooks
+//   - destDir: the destination folder of the extraction
+//   - files: the files written, as a FileRecorder collects them
+//
+// Returns:
+//   - []HookResult: one per command run, in order: the pattern hooks, by
+//     pattern then file, then the global one
+//   - error: the failures of the commands, joined
+func RunExtractHooks(ctx context.Context, hooks ExtractHooks, destDir string, files []ExtractedFile) ([]HookResult, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	var commands []string
+	for _, pattern := range slices.Sorted(maps.Keys(hooks.ByPattern)) {
+		for _, f := range files {
+			if matchGlob(pattern, f.Name) {
+				commands = append(commands, expandHook(hooks.ByPattern[pattern], destDir, []string{f.Path}))
+			}
+		}
+	}
+	if hooks.Command != "" {
+		paths := make([]string, len(files))
+		for i, f := range files {
+			paths[i] = f.Path
+		}
+		commands = append(commands, expandHook(hooks.Command, destDir, paths))
+	}
+
+	results := make([]HookResult, len(commands))
+	var errs []error
+	for i, command := range commands {
+		output, err := shellCommand(ctx, command).CombinedOutput()
+		results[i] = HookResult{Command: command, Output: string(output), Err: err}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %q: %w", command, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// expandHook substitutes the placeholders of a hook command.
+func expandHook(command, destDir string, paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = shellQuote(p)
+	}
+	return strings.NewReplacer("{dest}", shellQuote(destDir), "{}", strings.Join(quoted, " ")).Replace(command)
+}
+
+// shellQuote quotes s as a single argument for the shell shellCommand runs.
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return cmdQuote(s)
+	}
+	return posixQuote(s)
+}
+
+// posixQuote quotes s as a single argument for sh.
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// cmdQuote quotes s as a single argument for cmd /C. Double quotes alone
+// do not keep cmd from expanding %VAR% in them, and a " in s would end
+// them: the characters cmd acts on, % ^ & | < > and ", are written outside
+// the quotes, escaped with a caret, which also keeps the %...% around a
+// name from ever matching a variable. Backslashes before a quote are
+// doubled, as programs split their command line.
+func cmdQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	var b strings.Builder
+	quoted := false
+	// endQuote ends the quoted run, doubling the backslashes it ends with.
+	endQuote := func() {
+		if !quoted {
+			return
+		}
+		text := b.String()
+		trimmed := strings.TrimRight(text, `\`)
+		b.WriteString(text[len(trimmed):])
+		b.WriteByte('"')
+		quoted = false
+	}
+	for _, r := range s {
+		switch r {
+		case '%', '^', '&', '|', '<', '>':
+			endQuote()
+			b.WriteByte('^')
+			b.WriteRune(r)
+		case '"':
+			endQuote()
+			b.WriteString(`\^"`)
+		default:
+			if !quoted {
+				b.WriteByte('"')
+				quoted = true
+			}
+			b.WriteRune(r)
+		}
+	}
+	endQuote()
+	return b.String()
+}
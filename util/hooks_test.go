@@ -0,0 +1,123 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestRunExtractHooks checks which hooks run and how paths are substituted
+func TestRunExtractHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run through sh in this test")
+	}
+	dir := t.TempDir()
+	files := []ExtractedFile{
+		{Name: "pkg/a.deb", Path: filepath.Join(dir, "pkg", "a.deb")},
+		{Name: "it's.txt", Path: filepath.Join(dir, "it's.txt")},
+	}
+	hooks := ExtractHooks{
+		Command:   "echo {dest}; printf '%s\\n' {}",
+		ByPattern: map[string]string{"*.deb": "echo deb {}", "*.rpm": "echo rpm {}"},
+	}
+
+	results, err := RunExtractHooks(context.Background(), hooks, dir, files)
+	if err != nil {
+		t.Fatalf("RunExtractHooks() unexpected error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("RunExtractHooks() ran %d commands, want 2: %+v", len(results), results)
+	}
+	if want := "deb " + files[0].Path + "\n"; results[0].Output != want {
+		t.Errorf("pattern hook output = %q, want %q", results[0].Output, want)
+	}
+	if want := dir + "\n" + files[0].Path + "\n" + files[1].Path + "\n"; results[1].Output != want {
+		t.Errorf("global hook output = %q, want %q", results[1].Output, want)
+	}
+
+	if results, _ := RunExtractHooks(context.Background(), hooks, dir, nil); len(results) != 0 {
+		t.Errorf("RunExtractHooks() without files ran %+v", results)
+	}
+}
+
+// TestRunExtractHooksFailure checks that a failing command is reported
+// with its output, and does not stop the others
+func TestRunExtractHooksFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run through sh in this test")
+	}
+	files := []ExtractedFile{{Name: "a.txt", Path: "a.txt"}}
+	hooks := ExtractHooks{Command: "echo done", ByPattern: map[string]string{"*": "echo broken >&2; exit 3"}}
+
+	results, err := RunExtractHooks(context.Background(), hooks, ".", files)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("RunExtractHooks() error = %v, want the exit status", err)
+	}
+	if len(results) != 2 || results[0].Output != "broken\n" || results[1].Err != nil {
+		t.Errorf("RunExtractHooks() = %+v", results)
+	}
+}
+
+// TestPosixQuote checks that sh reads the quoted names back unchanged,
+// whatever they hold
+func TestPosixQuote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	names := []string{"plain.txt", "it's.txt", "$(touch pwned).txt", "a b;c|d&e", `x" & calc & ".txt`, "%COMSPEC%.txt", "`id`", ""}
+	for _, name := range names {
+		output, err := shellCommand(context.Background(), "printf %s "+posixQuote(name)).Output()
+		if err != nil {
+			t.Fatalf("sh with %q: %v", name, err)
+		}
+		if string(output) != name {
+			t.Errorf("sh read posixQuote(%q) as %q", name, output)
+		}
+	}
+}
+
+// TestCmdQuote checks that the characters cmd acts on are escaped outside
+// the quotes, so no name can start a command or expand a variable
+func TestCmdQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain.txt", `"plain.txt"`},
+		{`C:\Program Files\out`, `"C:\Program Files\out"`},
+		{`C:\out\`, `"C:\out\\"`},
+		{"", `""`},
+		{"%COMSPEC%.txt", `^%"COMSPEC"^%".txt"`},
+		{`x" & calc & ".txt`, `"x"\^"" "^&" calc "^&" "\^"".txt"`},
+		{"a^b|c<d>e", `"a"^^"b"^|"c"^<"d"^>"e"`},
+		{`dir\"q`, `"dir\\"\^""q"`},
+	}
+	for _, tt := range tests {
+		if got := cmdQuote(tt.in); got != tt.want {
+			t.Errorf("cmdQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestFileRecorder checks that written files are recorded and events passed on
+func TestFileRecorder(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"dir/": "", "dir/a.txt": "a", "b.txt": "b"})
+	next := &FileRecorder{}
+	rec := &FileRecorder{Next: next}
+
+	destDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Observer: rec}); err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+	if len(rec.Files) != 2 || len(next.Files) != 2 {
+		t.Fatalf("FileRecorder recorded %+v, passed on %+v", rec.Files, next.Files)
+	}
+	for _, f := range rec.Files {
+		if _, err := os.Stat(f.Path); err != nil {
+			t.Errorf("recorded %s: %v", f.Name, err)
+		}
+	}
+}
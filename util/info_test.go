@@ -0,0 +1,113 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadArchiveInfo checks the summary of an archive, and that a stream
+// reaches the same summary once read
+func TestReadArchiveInfo(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "info.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(out)
+	w.SetComment("nightly build")
+	w.Create("docs/")
+	f, _ := w.Create("docs/readme.txt")
+	f.Write([]byte(strings.Repeat("compressible ", 1000)))
+	f, _ = w.CreateHeader(&zip.FileHeader{Name: "raw.bin", Method: zip.Store})
+	f.Write([]byte("0123456789"))
+	w.Close()
+	out.Close()
+
+	info, err := ReadArchiveInfo(zipPath)
+	if err != nil {
+		t.Fatalf("ReadArchiveInfo() unexpected error = %v", err)
+	}
+
+	if info.Format != "zip" || info.Entries != 3 || info.Comment != "nightly build" || info.Zip64 || info.Encrypted {
+		t.Errorf("ReadArchiveInfo() = %+v", info)
+	}
+	if want := uint64(13000 + 10); info.Size != want {
+		t.Errorf("Size = %d, want %d", info.Size, want)
+	}
+	if info.CompressedSize >= info.Size || info.Ratio() <= 0 || info.Ratio() >= 1 {
+		t.Errorf("CompressedSize = %d, Ratio() = %v for compressible content", info.CompressedSize, info.Ratio())
+	}
+
+	s, err := OpenArchiveStream(zipPath)
+	if err != nil {
+		t.Fatalf("OpenArchiveStream() unexpected error = %v", err)
+	}
+	defer s.Close()
+	if got := s.Info(); got.Comment != info.Comment || got.Entries != 0 {
+		t.Errorf("Info() before Next = %+v, want the comment and no entries", got)
+	}
+	if _, err := s.Next(10); err != nil {
+		t.Fatalf("Next() unexpected error = %v", err)
+	}
+	if got := s.Info(); got != info {
+		t.Errorf("Info() after Next = %+v, want %+v", got, info)
+	}
+}
+
+// TestArchiveWithJunk checks that an archive between other data, including a
+// stray end of central directory signature past the last 64 KiB, opens and
+// extracts, and that its summary tells how much data surrounds it
+func TestArchiveWithJunk(t *testing.T) {
+	var archive bytes.Buffer
+	w := zip.NewWriter(&archive)
+	f, _ := w.Create("docs/readme.txt")
+	f.Write([]byte("hello"))
+	w.Close()
+
+	trailing := append([]byte("PK\x05\x06"), bytes.Repeat([]byte{'y'}, 100000)...)
+	content := append(bytes.Repeat([]byte{'x'}, 100), archive.Bytes()...)
+	content = append(content, trailing...)
+
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "junk.zip")
+	if err := os.WriteFile(zipPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ReadArchiveInfo(zipPath)
+	if err != nil {
+		t.Fatalf("ReadArchiveInfo() unexpected error = %v", err)
+	}
+	if info.Entries != 1 || info.Prepended != 100 || info.Trailing != int64(len(trailing)) || !info.HasJunk() {
+		t.Errorf("ReadArchiveInfo() = %+v, want 1 entry, 100 bytes before and %d after", info, len(trailing))
+	}
+
+	s, err := OpenArchiveStream(zipPath)
+	if err != nil {
+		t.Fatalf("OpenArchiveStream() unexpected error = %v", err)
+	}
+	defer s.Close()
+	if got := s.Info(); got.Prepended != info.Prepended || got.Trailing != info.Trailing {
+		t.Errorf("stream Info() = %+v, want the offsets of %+v", got, info)
+	}
+
+	if n, err := ExtractFile(context.Background(), zipPath, "docs/readme.txt", dir); err != nil || n != 1 {
+		t.Fatalf("ExtractFile() = %d, %v, want 1 file", n, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "docs", "readme.txt")); string(got) != "hello" {
+		t.Errorf("extracted content = %q, want %q", got, "hello")
+	}
+
+	plain := filepath.Join(dir, "plain.zip")
+	if err := os.WriteFile(plain, archive.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := ReadArchiveInfo(plain); err != nil || info.HasJunk() {
+		t.Errorf("ReadArchiveInfo(plain) = %+v, %v, want no junk", info, err)
+	}
+}
@@ -0,0 +1,42 @@
+package util
+
+import "path/filepath"
+
+// keyringService is the service goZip's passwords are saved under in the
+// OS keyring, each under the absolute path of its archive.
+const keyringService = "gozip"
+
+// KeyringPassword returns the password saved for the archive at zipPath
+// with SaveKeyringPassword: in the Secret Service through secret-tool on
+// Linux and the BSDs, in the login keychain on macOS.
+//
+// Parameters:
+//   - zipPath: the archive, relative or absolute
+//
+// Returns:
+//   - string: the password, "" when none is saved
+//   - error: the keyring cannot be reached on this system
+func KeyringPassword(zipPath string) (string, error) {
+	account, err := filepath.Abs(zipPath)
+	if err != nil {
+		return "", err
+	}
+	return keyringGet(account)
+}
+
+// SaveKeyringPassword saves password for the archive at zipPath in the OS
+// keyring, replacing the one saved before, see KeyringPassword.
+//
+// Parameters:
+//   - zipPath: the archive, relative or absolute
+//   - password: the password to save
+//
+// Returns:
+//   - error: the keyring cannot be reached on this system
+func SaveKeyringPassword(zipPath, password string) error {
+	account, err := filepath.Abs(zipPath)
+	if err != nil {
+		return err
+	}
+	return keyringSet(account, password)
+}
@@ -0,0 +1,53 @@
+//go:build darwin
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// keychainNotFound is the exit code of security when no item matches.
+const keychainNotFound = 44
+
+// keyringGet looks the password of account up in the login keychain.
+func keyringGet(account string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == keychainNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", keyringError(err, stderr.String())
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+// keyringSet saves the password of account in the login keychain. The
+// command goes through the standard input of security, not its arguments,
+// which other users could read.
+func keyringSet(account, password string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		strconv.Quote(keyringService), strconv.Quote(account), strconv.Quote(password)))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return keyringError(err, stderr.String())
+	}
+	return nil
+}
+
+func keyringError(err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("keychain: %s", msg)
+	}
+	return fmt.Errorf("keychain: %w", err)
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package util
+
+import "errors"
+
+// errNoKeyring reports that goZip cannot reach the keyring of this platform.
+var errNoKeyring = errors.New("the keyring is not supported on this platform")
+
+func keyringGet(account string) (string, error) {
+	return "", errNoKeyring
+}
+
+func keyringSet(account, password string) error {
+	return errNoKeyring
+}
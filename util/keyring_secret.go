@@ -0,0 +1,52 @@
+//go:build unix && !darwin
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringGet looks the password of account up in the Secret Service with
+// secret-tool, from libsecret.
+func keyringGet(account string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", keyringService, "archive", account)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	// secret-tool fails silently when nothing is saved.
+	if errors.As(err, &exit) && stderr.Len() == 0 {
+		return "", nil
+	}
+	if err != nil {
+		return "", keyringError(err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// keyringSet saves the password of account in the Secret Service with
+// secret-tool, which reads it from its standard input.
+func keyringSet(account, password string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "store", "--label", "goZip: "+account, "service", keyringService, "archive", account)
+	cmd.Stdin = strings.NewReader(password)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return keyringError(err, stderr.String())
+	}
+	return nil
+}
+
+func keyringError(err error, stderr string) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return errors.New("the keyring needs secret-tool, from libsecret")
+	}
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("keyring: %s", msg)
+	}
+	return fmt.Errorf("keyring: %w", err)
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"math"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// DefaultPreviewLimit is the preview limit used until SetPreviewLimit is
+// called.
+const DefaultPreviewLimit = 1 << 20
+
+// extractJobMemory is about what each extraction job holds while it runs:
+// its copy and write buffers and the decompressor's window.
+const extractJobMemory = 4 * extractBufferSize
+
+var (
+	memoryLimit  atomic.Int64
+	previewLimit atomic.Int64
+)
+
+// SetMemoryLimit caps the memory goZip aims to use: it becomes the soft
+// limit of the Go runtime, see debug.SetMemoryLimit, and extractions run
+// fewer concurrent jobs than asked when theirs would take more than half of
+// it. 0 removes the limit.
+func SetMemoryLimit(bytes int64) {
+	if bytes <= 0 {
+		if memoryLimit.Swap(0) > 0 {
+			debug.SetMemoryLimit(math.MaxInt64)
+		}
+		return
+	}
+	memoryLimit.Store(bytes)
+	debug.SetMemoryLimit(bytes)
+}
+
+// MemoryLimit returns the limit set by SetMemoryLimit, 0 for none.
+func MemoryLimit() int64 {
+	return memoryLimit.Load()
+}
+
+// limitJobs returns how many of jobs extraction jobs fit in the memory
+// limit, at least one.
+func limitJobs(jobs int) int {
+	jobs = max(jobs, 1)
+	if limit := MemoryLimit(); limit > 0 {
+		jobs = min(jobs, max(int(limit/2/extractJobMemory), 1))
+	}
+	return jobs
+}
+
+// SetPreviewLimit sets the largest entry, in bytes, read whole into memory
+// to be shown or compared, as when copying a text file to the clipboard or
+// diffing it against a file on disk. Values below 1 restore
+// DefaultPreviewLimit.
+func SetPreviewLimit(bytes int64) {
+	previewLimit.Store(max(bytes, 0))
+}
+
+// PreviewLimit returns the limit set by SetPreviewLimit.
+func PreviewLimit() int64 {
+	if limit := previewLimit.Load(); limit > 0 {
+		return limit
+	}
+	return DefaultPreviewLimit
+}
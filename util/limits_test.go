@@ -0,0 +1,49 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLimitJobs checks that the memory limit lowers the number of
+// extraction jobs, never below one
+func TestLimitJobs(t *testing.T) {
+	t.Cleanup(func() { SetMemoryLimit(0) })
+
+	if got := limitJobs(8); got != 8 {
+		t.Errorf("limitJobs(8) without a limit = %d, want 8", got)
+	}
+	SetMemoryLimit(4 * extractJobMemory)
+	if got := limitJobs(8); got != 2 {
+		t.Errorf("limitJobs(8) = %d, want 2", got)
+	}
+	SetMemoryLimit(1)
+	if got := limitJobs(8); got != 1 {
+		t.Errorf("limitJobs(8) with a tiny limit = %d, want 1", got)
+	}
+	if got := limitJobs(0); got != 1 {
+		t.Errorf("limitJobs(0) = %d, want 1", got)
+	}
+}
+
+// TestPreviewLimit checks that ReadTextEntry follows the preview limit
+func TestPreviewLimit(t *testing.T) {
+	t.Cleanup(func() { SetPreviewLimit(0) })
+	zipPath := writeTestZip(t, 0, map[string]string{"notes.txt": strings.Repeat("line\n", 100)})
+
+	if _, err := ReadTextEntry(zipPath, "notes.txt"); err != nil {
+		t.Fatalf("ReadTextEntry() unexpected error = %v", err)
+	}
+	SetPreviewLimit(100)
+	if PreviewLimit() != 100 {
+		t.Errorf("PreviewLimit() = %d, want 100", PreviewLimit())
+	}
+	if _, err := ReadTextEntry(zipPath, "notes.txt"); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("ReadTextEntry() over the limit error = %v, want larger than", err)
+	}
+
+	SetPreviewLimit(0)
+	if PreviewLimit() != DefaultPreviewLimit {
+		t.Errorf("PreviewLimit() after a reset = %d, want %d", PreviewLimit(), DefaultPreviewLimit)
+	}
+}
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Language is the language goZip talks to the user in.
+type Language string
+
+const (
+	// LanguageAuto follows the locale of the environment, see
+	// SystemLanguage.
+	LanguageAuto Language = "auto"
+	// LanguageEnglish is the language goZip is written in.
+	LanguageEnglish Language = "en"
+	// LanguageSpanish translates the browser into Spanish.
+	LanguageSpanish Language = "es"
+)
+
+// ParseLanguage validates a language name: a code such as "es", a locale
+// such as "es_ES.UTF-8", or "auto"; "" is LanguageAuto.
+func ParseLanguage(s string) (Language, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	switch value {
+	case "", string(LanguageAuto):
+		return LanguageAuto, nil
+	case "english":
+		return LanguageEnglish, nil
+	case "spanish", "español":
+		return LanguageSpanish, nil
+	}
+	if lang, ok := localeLanguage(value); ok {
+		return lang, nil
+	}
+	return "", fmt.Errorf("invalid language %q (want auto, en or es)", s)
+}
+
+// localeLanguage returns the language of a POSIX locale name such as
+// "es_AR.UTF-8@euro"; "C" and "POSIX" are English.
+func localeLanguage(locale string) (Language, bool) {
+	locale = strings.ToLower(locale)
+	if locale == "c" || locale == "posix" || strings.HasPrefix(locale, "c.") {
+		return LanguageEnglish, true
+	}
+	code, _, _ := strings.Cut(locale, "@")
+	code, _, _ = strings.Cut(code, ".")
+	code, _, _ = strings.Cut(code, "_")
+	code, _, _ = strings.Cut(code, "-")
+	switch Language(code) {
+	case LanguageEnglish, LanguageSpanish:
+		return Language(code), true
+	}
+	return "", false
+}
+
+// SystemLanguage returns the language of the environment's locale, read
+// as the C library does from LC_ALL, LC_MESSAGES and LANG, the first one
+// set winning. Locales in languages goZip is not translated into give
+// LanguageEnglish.
+func SystemLanguage() Language {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if lang, ok := localeLanguage(value); ok {
+			return lang
+		}
+		return LanguageEnglish
+	}
+	return LanguageEnglish
+}
+
+// Resolve returns the language l stands for: SystemLanguage for
+// LanguageAuto or "", l itself otherwise.
+func (l Language) Resolve() Language {
+	if l == "" || l == LanguageAuto {
+		return SystemLanguage()
+	}
+	return l
+}
@@ -0,0 +1,57 @@
+package util
+
+import "testing"
+
+// TestParseLanguage checks language codes, locale names and invalid values
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Language
+	}{
+		{"", LanguageAuto},
+		{"auto", LanguageAuto},
+		{"es", LanguageSpanish},
+		{"Spanish", LanguageSpanish},
+		{"es_AR.UTF-8", LanguageSpanish},
+		{"en-GB", LanguageEnglish},
+		{"C", LanguageEnglish},
+	}
+	for _, tt := range tests {
+		got, err := ParseLanguage(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLanguage(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseLanguage("fr"); err == nil {
+		t.Error("ParseLanguage(fr) expected error, got nil")
+	}
+}
+
+// TestSystemLanguage checks the precedence of the locale variables
+func TestSystemLanguage(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "")
+	if got := SystemLanguage(); got != LanguageEnglish {
+		t.Errorf("SystemLanguage() without a locale = %q, want en", got)
+	}
+
+	t.Setenv("LANG", "es_ES.UTF-8")
+	if got := LanguageAuto.Resolve(); got != LanguageSpanish {
+		t.Errorf("Resolve() with LANG=es_ES.UTF-8 = %q, want es", got)
+	}
+
+	t.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+	if got := SystemLanguage(); got != LanguageEnglish {
+		t.Errorf("SystemLanguage() with LC_MESSAGES=fr_FR = %q, want en", got)
+	}
+
+	t.Setenv("LC_ALL", "es_MX")
+	if got := SystemLanguage(); got != LanguageSpanish {
+		t.Errorf("SystemLanguage() with LC_ALL=es_MX = %q, want es", got)
+	}
+	if got := LanguageEnglish.Resolve(); got != LanguageEnglish {
+		t.Errorf("LanguageEnglish.Resolve() = %q, want en", got)
+	}
+}
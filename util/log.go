@@ -0,0 +1,76 @@
+package util
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// logFile is the name of the log file inside StateDir. goZip logs to a
+// file rather than stderr because the TUI owns the terminal.
+const logFile = "gozip.log"
+
+// logger is the logger Logger returns, discarding everything until
+// OpenLog is called.
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1})))
+}
+
+// Logger returns goZip's logger. Archives opened, extractions and commands
+// are logged at the Info level; each file extracted and each action of the
+// UI at the Debug level.
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// LogPath returns the path of the log file, gozip.log in StateDir.
+func LogPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, logFile), nil
+}
+
+// OpenLog starts logging to the file at LogPath, appending to it. At
+// verbosity 1, as set by -v, Logger writes the Info level and above; at 2,
+// as set by -vv, the Debug level too. Below 1 nothing is logged.
+//
+// Parameters:
+//   - verbosity: how much to log
+//
+// Returns:
+//   - func() error: closes the log file; Logger discards everything again
+//   - error: the log file cannot be created
+func OpenLog(verbosity int) (func() error, error) {
+	if verbosity < 1 {
+		return func() error { return nil }, nil
+	}
+
+	p, err := LogPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	level := slog.LevelInfo
+	if verbosity > 1 {
+		level = slog.LevelDebug
+	}
+	previous := logger.Swap(slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: level})).With("pid", os.Getpid()))
+
+	return func() error {
+		logger.Store(previous)
+		return f.Close()
+	}, nil
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestOpenLog checks the levels written at each verbosity
+func TestOpenLog(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	p, err := LogPath()
+	if err != nil {
+		t.Fatalf("LogPath() unexpected error = %v", err)
+	}
+
+	closeLog, err := OpenLog(0)
+	if err != nil {
+		t.Fatalf("OpenLog(0) unexpected error = %v", err)
+	}
+	Logger().Info("silent")
+	closeLog()
+	if _, err := os.Stat(p); err == nil {
+		t.Error("OpenLog(0) created the log file")
+	}
+
+	for _, verbosity := range []int{1, 2} {
+		closeLog, err := OpenLog(verbosity)
+		if err != nil {
+			t.Fatalf("OpenLog(%d) unexpected error = %v", verbosity, err)
+		}
+		Logger().Info("info", "verbosity", verbosity)
+		Logger().Debug("debug", "verbosity", verbosity)
+		if err := closeLog(); err != nil {
+			t.Fatalf("close unexpected error = %v", err)
+		}
+	}
+	Logger().Info("after close")
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{"level=INFO msg=info", "verbosity=2", "level=DEBUG msg=debug"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log lacks %q:\n%s", want, log)
+		}
+	}
+	for _, unwanted := range []string{"silent", "msg=debug pid=" + strconv.Itoa(os.Getpid()) + " verbosity=1", "after close"} {
+		if strings.Contains(log, unwanted) {
+			t.Errorf("log has %q:\n%s", unwanted, log)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package util
+
+// longPath returns p: only Windows limits the length of paths.
+func longPath(p string) string {
+	return p
+}
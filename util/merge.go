@@ -0,0 +1,149 @@
+package util
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MergePolicy decides what happens when several archives contain a file
+// with the same name.
+type MergePolicy string
+
+const (
+	// MergeSkip keeps the first file and skips the later ones.
+	MergeSkip MergePolicy = "skip"
+	// MergeOverwrite keeps the last file, at the position of the first one.
+	MergeOverwrite MergePolicy = "overwrite"
+	// MergeKeepBoth keeps every file, renaming later ones to "name (2).ext".
+	MergeKeepBoth MergePolicy = "keep-both"
+)
+
+// MergeCollision describes a file name found in more than one archive.
+type MergeCollision struct {
+	// Name is the entry name shared by the archives.
+	Name string
+	// Archive is the archive whose entry collided with an earlier one.
+	Archive string
+	// Result is the name the colliding entry was stored under, or "" when
+	// it was skipped or replaced the earlier entry.
+	Result string
+}
+
+// MergeResult summarizes a merge.
+type MergeResult struct {
+	// Entries is the number of entries in the merged archive.
+	Entries int
+	// Collisions lists every name clash and how it was resolved.
+	Collisions []MergeCollision
+}
+
+// mergedEntry is one entry of the archive being built by MergeArchives.
+type mergedEntry struct {
+	file *zip.File
+	name string
+}
+
+// MergeArchives combines the entries of several archives into a new one.
+//
+// Entries are copied without recompression, in input order. Folders present
+// in several archives are merged silently; files with the same name are
+// resolved according to policy. outPath may be one of the inputs: the
+// result is written to a temporary file and renamed into place at the end.
+//
+// Parameters:
+//   - outPath: path of the merged archive; an existing file is replaced
+//   - inputs: archives to combine, in order of precedence for MergeSkip
+//   - policy: how to resolve files present in more than one archive
+//
+// Returns:
+//   - MergeResult: number of entries written and the collisions found
+//   - error: any error encountered; outPath is unchanged on failure
+func MergeArchives(outPath string, inputs []string, policy MergePolicy) (MergeResult, error) {
+	switch policy {
+	case MergeSkip, MergeOverwrite, MergeKeepBoth:
+	default:
+		return MergeResult{}, fmt.Errorf("unknown conflict policy %q", policy)
+	}
+	if len(inputs) < 2 {
+		return MergeResult{}, errors.New("at least two archives are required")
+	}
+
+	var readers []*zipReadCloser
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}()
+
+	var result MergeResult
+	var plan []mergedEntry
+	index := make(map[string]int)
+
+	for _, input := range inputs {
+		r, err := openZipReader(input)
+		if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+			return MergeResult{}, fmt.Errorf("%s: %w", input, openError(err))
+		}
+		readers = append(readers, r)
+		decodeNames(r.File, "")
+
+		for _, f := range r.File {
+			i, seen := index[f.Name]
+			if !seen {
+				index[f.Name] = len(plan)
+				plan = append(plan, mergedEntry{file: f, name: f.Name})
+				continue
+			}
+			if strings.HasSuffix(f.Name, "/") {
+				continue
+			}
+
+			collision := MergeCollision{Name: f.Name, Archive: input}
+			switch policy {
+			case MergeOverwrite:
+				plan[i].file = f
+			case MergeKeepBoth:
+				collision.Result = uniqueEntryName(f.Name, index)
+				index[collision.Result] = len(plan)
+				plan = append(plan, mergedEntry{file: f, name: collision.Result})
+			}
+			result.Collisions = append(result.Collisions, collision)
+		}
+	}
+
+	err := writeArchiveAtomic(outPath, 0644, func(w *zip.Writer) error {
+		for _, e := range plan {
+			if err := copyEntry(w, e.file, e.name); err != nil {
+				return fmt.Errorf("%s: %w", e.name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	result.Entries = len(plan)
+	return result, nil
+}
+
+// uniqueEntryName returns name with " (n)" inserted before its extension,
+// using the lowest n >= 2 that is not already taken.
+func uniqueEntryName(name string, taken map[string]int) string {
+	ext := path.Ext(name)
+	if ext == path.Base(name) {
+		// Dot files such as ".env" have no extension to preserve.
+		ext = ""
+	}
+	stem := strings.TrimSuffix(name, ext)
+
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", stem, n, ext)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
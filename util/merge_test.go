@@ -0,0 +1,110 @@
+package util
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func readEntries(t *testing.T, zipPath string) map[string]string {
+	t.Helper()
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer r.Close()
+
+	got := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) unexpected error = %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(data)
+	}
+
+	return got
+}
+
+// TestMergeArchives checks each conflict policy on overlapping archives
+func TestMergeArchives(t *testing.T) {
+	first := writeTestZip(t, 0, map[string]string{"dir/": "", "dir/a.txt": "a1", "only1.txt": "1", ".env": "e1"})
+	second := writeTestZip(t, 0, map[string]string{"dir/": "", "dir/a.txt": "a2", "only2.txt": "2", ".env": "e2"})
+
+	tests := []struct {
+		policy         MergePolicy
+		want           map[string]string
+		wantCollisions []string
+	}{
+		{
+			policy:         MergeSkip,
+			want:           map[string]string{"dir/": "", "dir/a.txt": "a1", "only1.txt": "1", "only2.txt": "2", ".env": "e1"},
+			wantCollisions: []string{"", ""},
+		},
+		{
+			policy:         MergeOverwrite,
+			want:           map[string]string{"dir/": "", "dir/a.txt": "a2", "only1.txt": "1", "only2.txt": "2", ".env": "e2"},
+			wantCollisions: []string{"", ""},
+		},
+		{
+			policy: MergeKeepBoth,
+			want: map[string]string{
+				"dir/": "", "dir/a.txt": "a1", "dir/a (2).txt": "a2", "only1.txt": "1", "only2.txt": "2",
+				".env": "e1", ".env (2)": "e2",
+			},
+			wantCollisions: []string{".env (2)", "dir/a (2).txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "merged.zip")
+			result, err := MergeArchives(out, []string{first, second}, tt.policy)
+			if err != nil {
+				t.Fatalf("MergeArchives() unexpected error = %v", err)
+			}
+
+			got := readEntries(t, out)
+			if len(got) != len(tt.want) || result.Entries != len(tt.want) {
+				t.Errorf("merged %d entries (reported %d), want %d: %v", len(got), result.Entries, len(tt.want), got)
+			}
+			for name, content := range tt.want {
+				if got[name] != content {
+					t.Errorf("entry %q = %q, want %q", name, got[name], content)
+				}
+			}
+
+			var results []string
+			for _, c := range result.Collisions {
+				if c.Archive != second {
+					t.Errorf("collision %q archive = %s, want %s", c.Name, c.Archive, second)
+				}
+				results = append(results, c.Result)
+			}
+			if !slices.Equal(results, tt.wantCollisions) {
+				t.Errorf("collision results = %q, want %q", results, tt.wantCollisions)
+			}
+		})
+	}
+}
+
+// TestMergeArchivesErrors checks invalid arguments and unreadable inputs
+func TestMergeArchivesErrors(t *testing.T) {
+	good := writeTestZip(t, 0, map[string]string{"a.txt": "a"})
+	out := filepath.Join(t.TempDir(), "merged.zip")
+
+	if _, err := MergeArchives(out, []string{good, good}, "newest"); err == nil {
+		t.Error("MergeArchives() expected error for unknown policy, got nil")
+	}
+	if _, err := MergeArchives(out, []string{good}, MergeSkip); err == nil {
+		t.Error("MergeArchives() expected error for a single input, got nil")
+	}
+	if _, err := MergeArchives(out, []string{good, filepath.Join(t.TempDir(), "missing.zip")}, MergeSkip); err == nil {
+		t.Error("MergeArchives() expected error for a missing input, got nil")
+	}
+}
@@ -0,0 +1,133 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// Compression method identifiers as defined by the PKWARE APPNOTE.
+const (
+	methodStore   uint16 = 0
+	methodDeflate uint16 = 8
+	methodBzip2   uint16 = 12
+	methodLZMA    uint16 = 14
+	methodZstd    uint16 = 93
+	methodXZ      uint16 = 95
+	// methodAES marks WinZip AES encrypted entries; the real method is
+	// stored in their 0x9901 extra field.
+	methodAES uint16 = 99
+)
+
+// supportedMethods records every method this build can decompress.
+// STORE and DEFLATE are built into archive/zip; the rest are added by registerMethod.
+var supportedMethods = map[uint16]bool{
+	methodStore:   true,
+	methodDeflate: true,
+}
+
+// init registers the decompressors that archive/zip lacks out of the box,
+// so entries using those methods can be read like any STORE/DEFLATE entry.
+// Decompressors behind build tags register themselves from their own files.
+func init() {
+	registerMethod(methodBzip2, newBzip2Reader)
+	registerMethod(methodLZMA, newLZMAReader)
+	registerMethod(methodXZ, newXZReader)
+}
+
+// registerMethod installs a decompressor in archive/zip and records it as supported.
+func registerMethod(m uint16, dcomp zip.Decompressor) {
+	zip.RegisterDecompressor(m, dcomp)
+	supportedMethods[m] = true
+}
+
+// SupportedMethods returns the names of the compression methods this build
+// can decompress, ordered by method identifier.
+func SupportedMethods() []string {
+	ids := make([]int, 0, len(supportedMethods))
+	for m := range supportedMethods {
+		ids = append(ids, int(m))
+	}
+	sort.Ints(ids)
+
+	names := make([]string, 0, len(ids))
+	for _, m := range ids {
+		names = append(names, methodToString(uint16(m)))
+	}
+
+	return names
+}
+
+// newBzip2Reader adapts compress/bzip2, which has no Close, to the
+// decompressor signature expected by archive/zip.
+func newBzip2Reader(r io.Reader) io.ReadCloser {
+	return io.NopCloser(bzip2.NewReader(r))
+}
+
+// newLZMAReader decodes the zip flavour of LZMA. Zip entries start with a
+// 2-byte LZMA SDK version and a 2-byte properties length, followed by the
+// properties themselves but no uncompressed size, so the classic .lzma header
+// is rebuilt with an unknown size before handing the stream to the decoder.
+func newLZMAReader(r io.Reader) io.ReadCloser {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return errorReadCloser{err}
+	}
+
+	props := make([]byte, binary.LittleEndian.Uint16(prefix[2:]))
+	if _, err := io.ReadFull(r, props); err != nil {
+		return errorReadCloser{err}
+	}
+	if len(props) != 5 {
+		return errorReadCloser{errors.New("lzma: unsupported properties size")}
+	}
+
+	header := append(props, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+	lr, err := lzma.NewReader(io.MultiReader(bytes.NewReader(header), r))
+	if err != nil {
+		return errorReadCloser{err}
+	}
+
+	return io.NopCloser(lzmaEOFReader{lr})
+}
+
+// lzmaEOFReader treats running out of input as the end of the stream.
+// Entries written without an end-of-stream marker stop exactly at their
+// compressed size; archive/zip still verifies the size and CRC afterwards.
+type lzmaEOFReader struct {
+	r io.Reader
+}
+
+func (l lzmaEOFReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func newXZReader(r io.Reader) io.ReadCloser {
+	xr, err := xz.NewReader(r)
+	if err != nil {
+		return errorReadCloser{err}
+	}
+
+	return io.NopCloser(xr)
+}
+
+// errorReadCloser reports a decompressor setup failure on the first Read,
+// since archive/zip decompressors cannot return an error themselves.
+type errorReadCloser struct {
+	err error
+}
+
+func (e errorReadCloser) Read([]byte) (int, error) { return 0, e.err }
+
+func (e errorReadCloser) Close() error { return nil }
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+// TestExtractBzip2Entry checks that entries compressed with BZIP2 can be extracted
+func TestExtractBzip2Entry(t *testing.T) {
+	destDir := t.TempDir()
+
+	count, err := ExtractFile(context.Background(), "testdata/bzip2.zip", "sample.txt", destDir)
+	if err != nil {
+		t.Fatalf("ExtractFile() unexpected error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("ExtractFile() count = %d, want 1", count)
+	}
+
+	assertSameContent(t, filepath.Join(destDir, "sample.txt"), "testdata/sample.txt")
+}
+
+func assertSameContent(t *testing.T, gotPath, wantPath string) {
+	t.Helper()
+
+	got, err := os.ReadFile(gotPath)
+	if err != nil {
+		t.Fatalf("Failed to read extracted file: %v", err)
+	}
+	want, err := os.ReadFile(wantPath)
+	if err != nil {
+		t.Fatalf("Failed to read expected file: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("extracted content = %q, want %q", got, want)
+	}
+}
+
+// TestExtractLZMAEntry checks that entries compressed with LZMA can be extracted
+func TestExtractLZMAEntry(t *testing.T) {
+	destDir := t.TempDir()
+
+	if _, err := ExtractFile(context.Background(), "testdata/lzma.zip", "sample.txt", destDir); err != nil {
+		t.Fatalf("ExtractFile() unexpected error = %v", err)
+	}
+
+	assertSameContent(t, filepath.Join(destDir, "sample.txt"), "testdata/sample.txt")
+}
+
+// TestExtractXZEntry checks that entries compressed with XZ can be extracted
+func TestExtractXZEntry(t *testing.T) {
+	want, err := os.ReadFile("testdata/sample.txt")
+	if err != nil {
+		t.Fatalf("Failed to read sample: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "xz.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+
+	// xz.NewWriter emits the stream header straight away, before archive/zip
+	// has written the local header, so the entry is compressed up front.
+	var compressed bytes.Buffer
+	xw, err := xz.NewWriter(&compressed)
+	if err != nil {
+		t.Fatalf("Failed to create xz writer: %v", err)
+	}
+	xw.Write(want)
+	xw.Close()
+
+	w := zip.NewWriter(out)
+	fw, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "sample.txt",
+		Method:             methodXZ,
+		CRC32:              crc32.ChecksumIEEE(want),
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: uint64(len(want)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+	fw.Write(compressed.Bytes())
+	w.Close()
+	out.Close()
+
+	destDir := t.TempDir()
+	if _, err := ExtractFile(context.Background(), zipPath, "sample.txt", destDir); err != nil {
+		t.Fatalf("ExtractFile() unexpected error = %v", err)
+	}
+
+	assertSameContent(t, filepath.Join(destDir, "sample.txt"), "testdata/sample.txt")
+}
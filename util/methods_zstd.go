@@ -0,0 +1,11 @@
+//go:build !nozstd
+
+package util
+
+import "github.com/klauspost/compress/zstd"
+
+// Zstandard support pulls in klauspost/compress; build with -tags nozstd to leave it out.
+func init() {
+	registerMethod(methodZstd, zstd.ZipDecompressor())
+	enableFeature(FeatureZstd)
+}
@@ -0,0 +1,66 @@
+//go:build !nozstd
+
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// TestExtractZstdEntry checks that entries compressed with Zstandard can be extracted
+func TestExtractZstdEntry(t *testing.T) {
+	want, err := os.ReadFile("testdata/sample.txt")
+	if err != nil {
+		t.Fatalf("Failed to read sample: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "zstd.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+
+	w := zip.NewWriter(out)
+	w.RegisterCompressor(methodZstd, zstd.ZipCompressor())
+	fw, err := w.CreateHeader(&zip.FileHeader{Name: "sample.txt", Method: methodZstd})
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+	fw.Write(want)
+	w.Close()
+	out.Close()
+
+	content, err := openZipFile(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("openZipFile() unexpected error = %v", err)
+	}
+	if got := content[0].GetMethod(); got != "ZSTD" {
+		t.Errorf("GetMethod() = %v, want ZSTD", got)
+	}
+
+	destDir := t.TempDir()
+	if _, err := ExtractFile(context.Background(), zipPath, "sample.txt", destDir); err != nil {
+		t.Fatalf("ExtractFile() unexpected error = %v", err)
+	}
+
+	assertSameContent(t, filepath.Join(destDir, "sample.txt"), "testdata/sample.txt")
+}
+
+// TestZstdFeatureEnabled checks that the default build reports Zstandard support
+func TestZstdFeatureEnabled(t *testing.T) {
+	if !slices.Contains(SupportedMethods(), "ZSTD") {
+		t.Errorf("SupportedMethods() = %v, want ZSTD included", SupportedMethods())
+	}
+
+	for _, f := range Features() {
+		if f.Name == FeatureZstd && !f.Enabled {
+			t.Error("Features() reports zstd as disabled")
+		}
+	}
+}
@@ -0,0 +1,213 @@
+//go:build (linux || darwin) && !nofuse
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"path"
+	"sync"
+	"syscall"
+
+	"github.com/cainlara/gozip/core"
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// FUSE support pulls in hanwen/go-fuse; build with -tags nofuse to leave it out.
+func init() {
+	enableFeature(FeatureFUSE)
+}
+
+// MountArchive exposes the archive at zipPath read-only as a filesystem at
+// mountpoint through FUSE, built on core.ArchiveFS, so other programs can
+// browse it without extracting it. Files are decompressed as they are
+// read. It returns once ctx is done, after unmounting.
+//
+// Parameters:
+//   - ctx: unmounts the archive once done
+//   - zipPath: the archive to mount
+//   - mountpoint: an existing, empty folder
+//   - mounted: called once the filesystem is ready
+//
+// Returns:
+//   - error: the archive cannot be opened, FUSE is missing, or the
+//     filesystem cannot be mounted or unmounted
+func MountArchive(ctx context.Context, zipPath, mountpoint string, mounted func()) error {
+	fsys, err := core.ArchiveFS(zipPath)
+	if err != nil {
+		return openError(err)
+	}
+	defer fsys.(io.Closer).Close()
+
+	root := &mountDir{fsys: fsys, name: "."}
+	server, err := fs.Mount(mountpoint, root, &fs.Options{
+		MountOptions: fuse.MountOptions{
+			FsName:  zipPath,
+			Name:    "gozip",
+			Options: []string{"ro"},
+			// Root can mount without fusermount; others fall back to it.
+			DirectMount: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot mount %s: %w", mountpoint, err)
+	}
+	Logger().Info("mounted archive", "path", zipPath, "mountpoint", mountpoint)
+	mounted()
+
+	// The filesystem may also be unmounted from outside, with umount.
+	done := make(chan struct{})
+	go func() {
+		server.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	if err := server.Unmount(); err != nil {
+		return fmt.Errorf("cannot unmount %s: %w", mountpoint, err)
+	}
+	<-done
+	Logger().Info("unmounted archive", "path", zipPath, "mountpoint", mountpoint)
+	return nil
+}
+
+// mountDir is a folder of a mounted archive. The root builds the whole
+// tree once mounted, see OnAdd.
+type mountDir struct {
+	fs.Inode
+	fsys iofs.FS
+	name string
+}
+
+var (
+	_ fs.NodeOnAdder   = (*mountDir)(nil)
+	_ fs.NodeGetattrer = (*mountDir)(nil)
+	_ fs.NodeGetattrer = (*mountFile)(nil)
+	_ fs.NodeOpener    = (*mountFile)(nil)
+	_ fs.FileReader    = (*mountHandle)(nil)
+	_ fs.FileReleaser  = (*mountHandle)(nil)
+)
+
+// OnAdd adds every folder and file of the archive below the root. The
+// inodes are persistent: the archive never changes while mounted.
+func (d *mountDir) OnAdd(ctx context.Context) {
+	if d.name != "." {
+		return
+	}
+	iofs.WalkDir(d.fsys, ".", func(name string, entry iofs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return nil
+		}
+		// WalkDir lists each folder before its contents.
+		parent := d.lookup(path.Dir(name))
+		if parent == nil {
+			return nil
+		}
+
+		var child *fs.Inode
+		if entry.IsDir() {
+			child = parent.NewPersistentInode(ctx, &mountDir{fsys: d.fsys, name: name}, fs.StableAttr{Mode: fuse.S_IFDIR})
+		} else {
+			child = parent.NewPersistentInode(ctx, &mountFile{fsys: d.fsys, name: name}, fs.StableAttr{Mode: fuse.S_IFREG})
+		}
+		parent.AddChild(path.Base(name), child, true)
+		return nil
+	})
+}
+
+// lookup finds the inode of the folder at name, a path inside the
+// archive, walking down from the root.
+func (d *mountDir) lookup(name string) *fs.Inode {
+	node := &d.Inode
+	for _, part := range splitPath(name) {
+		if node = node.GetChild(part); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// splitPath splits a slash-separated path inside the archive into its
+// elements.
+func splitPath(name string) []string {
+	var parts []string
+	for name != "." {
+		parts = append([]string{path.Base(name)}, parts...)
+		name = path.Dir(name)
+	}
+	return parts
+}
+
+func (d *mountDir) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	out.Mode = fuse.S_IFDIR | 0555
+	if info, err := iofs.Stat(d.fsys, d.name); err == nil {
+		modified := info.ModTime()
+		out.SetTimes(nil, &modified, nil)
+	}
+	return fs.OK
+}
+
+// mountFile is a file of a mounted archive.
+type mountFile struct {
+	fs.Inode
+	fsys iofs.FS
+	name string
+}
+
+func (f *mountFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	info, err := iofs.Stat(f.fsys, f.name)
+	if err != nil {
+		return syscall.EIO
+	}
+	out.Mode = fuse.S_IFREG | 0444
+	out.Size = uint64(info.Size())
+	modified := info.ModTime()
+	out.SetTimes(nil, &modified, nil)
+	return fs.OK
+}
+
+// Open opens the file for reading; the archive is read-only.
+func (f *mountFile) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR) != 0 {
+		return nil, 0, syscall.EROFS
+	}
+	info, err := iofs.Stat(f.fsys, f.name)
+	if err != nil {
+		return nil, 0, syscall.EIO
+	}
+	// The content never changes: let the kernel cache it.
+	return &mountHandle{r: newEntryReader(f.fsys, f.name, info.Size())}, fuse.FOPEN_KEEP_CACHE, fs.OK
+}
+
+// mountHandle reads a file of a mounted archive.
+type mountHandle struct {
+	mu sync.Mutex
+	r  *entryReader
+}
+
+func (h *mountHandle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.r.Seek(off, io.SeekStart)
+	n, err := io.ReadFull(h.r, dest)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		Logger().Warn("cannot read mounted file", "name", h.r.name, "err", err)
+		return nil, syscall.EIO
+	}
+	return fuse.ReadResultData(dest[:n]), fs.OK
+}
+
+func (h *mountHandle) Release(ctx context.Context) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.r.Close()
+	return fs.OK
+}
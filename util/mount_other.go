@@ -0,0 +1,15 @@
+//go:build !(linux || darwin) || nofuse
+
+package util
+
+import (
+	"context"
+	"errors"
+)
+
+// MountArchive would expose the archive at zipPath as a filesystem, but
+// this build has no FUSE support: the platform lacks it, or goZip was
+// built with -tags nofuse.
+func MountArchive(ctx context.Context, zipPath, mountpoint string, mounted func()) error {
+	return errors.New("mounting archives is not supported by this build")
+}
@@ -0,0 +1,42 @@
+//go:build linux && !nofuse
+
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMountArchive checks that a mounted archive reads like the folder it
+// holds, when FUSE is available
+func TestMountArchive(t *testing.T) {
+	mountpoint := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var data []byte
+	var readErr, writeErr error
+	err := MountArchive(ctx, "testdata/test.zip", mountpoint, func() {
+		defer cancel()
+		data, readErr = os.ReadFile(filepath.Join(mountpoint, "sample.txt"))
+		writeErr = os.WriteFile(filepath.Join(mountpoint, "sample.txt"), nil, 0644)
+	})
+	if err != nil && strings.Contains(err.Error(), "cannot mount") {
+		t.Skipf("FUSE is not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("MountArchive() unexpected error = %v", err)
+	}
+	if readErr != nil {
+		t.Fatalf("reading the mounted archive: %v", readErr)
+	}
+	if !strings.HasPrefix(string(data), "This is a test file") {
+		t.Errorf("sample.txt read %q", data)
+	}
+	if writeErr == nil {
+		t.Error("writing to the mounted archive succeeded")
+	}
+}
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WriteNameList saves entry names to a text file, one per line, for
+// ReadNameList, "gozip extract --list" or the browser to load later. A name
+// listed several times, as versions of an entry are, is written once.
+//
+// Parameters:
+//   - path: the file to write, replaced if it exists
+//   - names: the entry names, as the archive stores them
+//
+// Returns:
+//   - error: a name a line cannot hold, or any error writing the file
+func WriteNameList(path string, names []string) error {
+	var b strings.Builder
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if strings.ContainsAny(name, "\r\n") {
+			return fmt.Errorf("cannot list %q: it has a line break", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		b.WriteString(name)
+		b.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write list %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadNameList reads entry names from a text file with one per line, as
+// WriteNameList writes them. Blank lines and lines starting with # are
+// skipped, so lists written by hand can carry comments, and Windows line
+// endings are accepted.
+//
+// Parameters:
+//   - path: the file to read
+//
+// Returns:
+//   - []string: the names, in the order listed
+//   - error: any error reading the file
+func ReadNameList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open list: %w", err)
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read list %s: %w", path, err)
+	}
+	return names, nil
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestNameListRoundTrip checks that a written list reads back without duplicates
+func TestNameListRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "marks.list")
+	names := []string{"docs/readme.md", "a b.txt", "docs/readme.md", "src/main.go"}
+
+	if err := WriteNameList(path, names); err != nil {
+		t.Fatalf("WriteNameList() error = %v", err)
+	}
+	got, err := ReadNameList(path)
+	if err != nil {
+		t.Fatalf("ReadNameList() error = %v", err)
+	}
+
+	want := []string{"docs/readme.md", "a b.txt", "src/main.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ReadNameList() = %q, want %q", got, want)
+	}
+}
+
+// TestReadNameListHandWritten checks comments, blank lines and Windows line endings
+func TestReadNameListHandWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "marks.list")
+	content := "# release files\r\nbin/gozip\r\n\r\n  \r\nREADME.md\r\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadNameList(path)
+	if err != nil {
+		t.Fatalf("ReadNameList() error = %v", err)
+	}
+	if want := []string{"bin/gozip", "README.md"}; !slices.Equal(got, want) {
+		t.Errorf("ReadNameList() = %q, want %q", got, want)
+	}
+}
+
+// TestWriteNameListLineBreak checks that names with line breaks are refused
+func TestWriteNameListLineBreak(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "marks.list")
+	if err := WriteNameList(path, []string{"bad\nname"}); err == nil {
+		t.Error("WriteNameList() expected an error for a name with a line break")
+	}
+}
+
+// TestReadNameListMissing checks the error for a missing file
+func TestReadNameListMissing(t *testing.T) {
+	if _, err := ReadNameList(filepath.Join(t.TempDir(), "missing.list")); err == nil {
+		t.Error("ReadNameList() expected an error for a missing file")
+	}
+}
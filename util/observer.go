@@ -0,0 +1,94 @@
+package util
+
+import "sync"
+
+// ExtractObserver follows an extraction entry by entry, through
+// ExtractOptions.Observer. The terminal UI, the command line and programs
+// embedding goZip all watch extractions through it.
+//
+// Calls are never concurrent, even with ExtractOptions.Jobs above 1, but the
+// events of entries extracted in parallel interleave. They are made while
+// the extraction waits, so they should return quickly.
+type ExtractObserver interface {
+	// OnEntryStart is called before an entry is written; size is its
+	// uncompressed size.
+	OnEntryStart(name string, size uint64)
+
+	// OnProgress reports how many bytes of the entry were written so far.
+	// It is called each time a chunk of the entry is decompressed.
+	OnProgress(name string, written, size uint64)
+
+	// OnEntryDone is called once the entry was fully written to path.
+	OnEntryDone(name, path string)
+
+	// OnError is called when the entry could not be extracted. The
+	// extraction then stops, unless ExtractOptions.ContinueOnError is set,
+	// and returns an error wrapping err.
+	OnError(name string, err error)
+}
+
+// RenameObserver is implemented by the observers that also want to know
+// about files written under another name than their entry's, because the
+// filesystem cannot store that name, as with "aux.txt" on Windows. The
+// extraction looks for it on ExtractOptions.Observer.
+type RenameObserver interface {
+	// OnRename is called before OnEntryStart; path is where the entry is
+	// written, and reason tells why its name changed.
+	OnRename(name, path, reason string)
+}
+
+// NopExtractObserver ignores every event. Embed it in an observer that only
+// needs some of them.
+type NopExtractObserver struct{}
+
+func (NopExtractObserver) OnEntryStart(string, uint64)       {}
+func (NopExtractObserver) OnProgress(string, uint64, uint64) {}
+func (NopExtractObserver) OnEntryDone(string, string)        {}
+func (NopExtractObserver) OnError(string, error)             {}
+
+// syncObserver serializes the calls made to an observer by parallel
+// extraction workers.
+type syncObserver struct {
+	mu sync.Mutex
+	o  ExtractObserver
+}
+
+func (s *syncObserver) OnEntryStart(name string, size uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.o.OnEntryStart(name, size)
+}
+
+func (s *syncObserver) OnProgress(name string, written, size uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.o.OnProgress(name, written, size)
+}
+
+func (s *syncObserver) OnEntryDone(name, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.o.OnEntryDone(name, path)
+}
+
+func (s *syncObserver) OnError(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.o.OnError(name, err)
+}
+
+func (s *syncObserver) OnFlagged(name, report string) {
+	if o, ok := s.o.(ScanObserver); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		o.OnFlagged(name, report)
+	}
+}
+
+func (s *syncObserver) OnRename(name, path, reason string) {
+	if r, ok := s.o.(RenameObserver); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		r.OnRename(name, path, reason)
+	}
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// recordingObserver keeps every event as a line of text, and the last
+// progress reported for each entry
+type recordingObserver struct {
+	events   []string
+	progress map[string]uint64
+	inside   int
+}
+
+func (r *recordingObserver) enter() {
+	r.inside++
+	if r.inside > 1 {
+		panic("concurrent observer calls")
+	}
+}
+
+func (r *recordingObserver) OnEntryStart(name string, size uint64) {
+	r.enter()
+	defer func() { r.inside-- }()
+	r.events = append(r.events, fmt.Sprintf("start %s %d", name, size))
+}
+
+func (r *recordingObserver) OnProgress(name string, written, size uint64) {
+	r.enter()
+	defer func() { r.inside-- }()
+	if r.progress == nil {
+		r.progress = make(map[string]uint64)
+	}
+	r.progress[name] = written
+}
+
+func (r *recordingObserver) OnEntryDone(name, path string) {
+	r.enter()
+	defer func() { r.inside-- }()
+	r.events = append(r.events, fmt.Sprintf("done %s %s", name, filepath.Base(path)))
+}
+
+func (r *recordingObserver) OnError(name string, err error) {
+	r.enter()
+	defer func() { r.inside-- }()
+	r.events = append(r.events, "error "+name)
+}
+
+// TestExtractObserver checks the events of a sequential extraction
+func TestExtractObserver(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "alpha", "dir/b.txt": strings.Repeat("b", 300<<10)})
+
+	obs := &recordingObserver{}
+	_, err := ExtractWithOptions(context.Background(), zipPath, "", t.TempDir(), ExtractOptions{Observer: obs})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions() error = %v", err)
+	}
+
+	want := []string{
+		"start a.txt 5",
+		"done a.txt a.txt",
+		fmt.Sprintf("start dir/b.txt %d", 300<<10),
+		"done dir/b.txt b.txt",
+	}
+	if !slices.Equal(obs.events, want) {
+		t.Errorf("events = %q, want %q", obs.events, want)
+	}
+	if got := obs.progress["dir/b.txt"]; got != 300<<10 {
+		t.Errorf("last progress of dir/b.txt = %d, want %d", got, 300<<10)
+	}
+}
+
+// TestExtractObserverParallel checks that parallel workers never call the
+// observer concurrently
+func TestExtractObserverParallel(t *testing.T) {
+	files := make(map[string]string)
+	for i := range 50 {
+		files[fmt.Sprintf("f%02d.txt", i)] = strings.Repeat("x", i*1000)
+	}
+	zipPath := writeTestZip(t, 0, files)
+
+	obs := &recordingObserver{}
+	n, err := ExtractWithOptions(context.Background(), zipPath, "", t.TempDir(), ExtractOptions{Observer: obs, Jobs: 8})
+	if err != nil || n != 50 {
+		t.Fatalf("ExtractWithOptions() = %d, %v; want 50, nil", n, err)
+	}
+	if len(obs.events) != 100 {
+		t.Errorf("got %d events, want 100", len(obs.events))
+	}
+}
+
+// TestExtractObserverError checks that a failing entry is reported
+func TestExtractObserverError(t *testing.T) {
+	zipPath := writeCorruptZip(t, map[string]string{"a.txt": "alpha"}, "z.txt")
+
+	obs := &recordingObserver{}
+	_, err := ExtractWithOptions(context.Background(), zipPath, "", t.TempDir(), ExtractOptions{Observer: obs})
+	if err == nil {
+		t.Fatal("ExtractWithOptions() expected an error for a corrupt entry")
+	}
+	if !slices.Contains(obs.events, "error z.txt") || slices.Contains(obs.events, "done z.txt z.txt") {
+		t.Errorf("events = %q, want an error for z.txt", obs.events)
+	}
+}
@@ -0,0 +1,92 @@
+package util
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Viewer names the kind of program OpenCommand opens a file with.
+type Viewer string
+
+const (
+	// ViewerPager is $PAGER, or less.
+	ViewerPager Viewer = "pager"
+	// ViewerEditor is $VISUAL or $EDITOR, or vi.
+	ViewerEditor Viewer = "editor"
+	// ViewerDefault is the desktop's default application for the file:
+	// xdg-open, open on macOS, or start on Windows.
+	ViewerDefault Viewer = "default"
+)
+
+// OpenCommand returns the command line opening path with viewer. Pager and
+// editor variables may hold arguments, as in EDITOR="code --wait".
+//
+// Parameters:
+//   - viewer: the kind of program to use
+//   - path: the file to open
+//
+// Returns:
+//   - []string: the program and its arguments
+//   - error: an unknown viewer
+func OpenCommand(viewer Viewer, path string) ([]string, error) {
+	var program string
+	switch viewer {
+	case ViewerPager:
+		program = cmp.Or(os.Getenv("PAGER"), "less")
+	case ViewerEditor:
+		program = cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi")
+	case ViewerDefault:
+		switch runtime.GOOS {
+		case "darwin":
+			return []string{"open", path}, nil
+		case "windows":
+			return []string{"cmd", "/c", "start", "", path}, nil
+		default:
+			return []string{"xdg-open", path}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown viewer %q", viewer)
+	}
+
+	return append(strings.Fields(program), path), nil
+}
+
+// ExtractToTemp extracts a file of the archive at zipPath into a new
+// temporary folder, under its base name, to open it with another program.
+// Remove the folder, filepath.Dir of the path returned, once done.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - entryName: name of the file to extract (as it appears in the ZIP)
+//
+// Returns:
+//   - string: path of the extracted file
+//   - error: any error encountered; nothing is left behind on failure
+func ExtractToTemp(zipPath, entryName string) (string, error) {
+	if entryName == "" || strings.HasSuffix(entryName, "/") {
+		return "", errors.New("only files can be opened, not folders")
+	}
+
+	dir, err := os.MkdirTemp("", "gozip-open-")
+	if err != nil {
+		return "", err
+	}
+
+	count, err := ExtractWithOptions(context.Background(), zipPath, entryName, dir, ExtractOptions{Flatten: true})
+	if err == nil && count == 0 {
+		err = entryNotFound("file", entryName)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return filepath.Join(dir, path.Base(entryName)), nil
+}
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestOpenCommand checks the programs chosen from the environment
+func TestOpenCommand(t *testing.T) {
+	t.Setenv("PAGER", "")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+
+	tests := []struct {
+		viewer Viewer
+		want   []string
+	}{
+		{ViewerPager, []string{"less", "f.txt"}},
+		{ViewerEditor, []string{"code", "--wait", "f.txt"}},
+	}
+	for _, tt := range tests {
+		got, err := OpenCommand(tt.viewer, "f.txt")
+		if err != nil {
+			t.Fatalf("OpenCommand(%s) unexpected error = %v", tt.viewer, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("OpenCommand(%s) = %v, want %v", tt.viewer, got, tt.want)
+		}
+	}
+
+	t.Setenv("VISUAL", "nano")
+	if got, _ := OpenCommand(ViewerEditor, "f.txt"); got[0] != "nano" {
+		t.Errorf("OpenCommand(editor) = %v, want VISUAL first", got)
+	}
+	if got, err := OpenCommand(ViewerDefault, "f.txt"); err != nil || got[len(got)-1] != "f.txt" {
+		t.Errorf("OpenCommand(default) = %v, %v", got, err)
+	}
+	if _, err := OpenCommand("browser", "f.txt"); err == nil {
+		t.Error("OpenCommand(browser) expected error, got nil")
+	}
+}
+
+// TestExtractToTemp checks that a single file lands in its own temporary folder
+func TestExtractToTemp(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"dir/": "", "dir/a.txt": "hello", "a.txt": "other"})
+
+	p, err := ExtractToTemp(zipPath, "dir/a.txt")
+	if err != nil {
+		t.Fatalf("ExtractToTemp() unexpected error = %v", err)
+	}
+	defer os.RemoveAll(filepath.Dir(p))
+
+	if filepath.Base(p) != "a.txt" {
+		t.Errorf("ExtractToTemp() = %s, want a.txt", p)
+	}
+	if data, err := os.ReadFile(p); err != nil || string(data) != "hello" {
+		t.Errorf("extracted content = %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(p)); len(entries) != 1 {
+		t.Errorf("temporary folder holds %d files, want 1", len(entries))
+	}
+
+	for _, name := range []string{"dir/", "missing.txt"} {
+		if _, err := ExtractToTemp(zipPath, name); err == nil {
+			t.Errorf("ExtractToTemp(%s) expected error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OverwritePolicy decides what extraction does with files that already
+// exist in the destination.
+type OverwritePolicy string
+
+const (
+	// OverwriteAlways replaces existing files.
+	OverwriteAlways OverwritePolicy = "always"
+	// OverwriteNever keeps existing files and skips their entries.
+	OverwriteNever OverwritePolicy = "never"
+	// OverwriteNewer replaces existing files only with entries modified
+	// after them, like "unzip -u".
+	OverwriteNewer OverwritePolicy = "newer"
+)
+
+// ParseOverwritePolicy validates a policy name; "" is OverwriteAlways.
+func ParseOverwritePolicy(s string) (OverwritePolicy, error) {
+	switch policy := OverwritePolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case "":
+		return OverwriteAlways, nil
+	case OverwriteAlways, OverwriteNever, OverwriteNewer:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid overwrite policy %q (want always, never or newer)", s)
+	}
+}
+
+// keeps reports whether the policy leaves the file at path as it is instead
+// of extracting f over it.
+func (p OverwritePolicy) keeps(f *zip.File, path string) bool {
+	if p == "" || p == OverwriteAlways {
+		return false
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	if p == OverwriteNewer {
+		return !entryModTime(f).After(info.ModTime())
+	}
+	return true
+}
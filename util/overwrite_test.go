@@ -0,0 +1,87 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestExtractOverwritePolicy checks which existing files each policy replaces
+func TestExtractOverwritePolicy(t *testing.T) {
+	modified := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	zipPath := filepath.Join(t.TempDir(), "policy.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	for _, name := range []string{"old.txt", "new.txt"} {
+		w, _ := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		w.Write([]byte("from the archive"))
+	}
+	zw.Close()
+	out.Close()
+
+	// prepare leaves old.txt older than its entry and new.txt newer
+	prepare := func(t *testing.T) string {
+		destDir := t.TempDir()
+		for name, mtime := range map[string]time.Time{"old.txt": modified.Add(-time.Hour), "new.txt": modified.Add(time.Hour)} {
+			p := filepath.Join(destDir, name)
+			if err := os.WriteFile(p, []byte("on disk"), 0o644); err != nil {
+				t.Fatalf("Failed to write %s: %v", name, err)
+			}
+			if err := os.Chtimes(p, mtime, mtime); err != nil {
+				t.Fatalf("Failed to date %s: %v", name, err)
+			}
+		}
+		return destDir
+	}
+
+	tests := []struct {
+		policy   OverwritePolicy
+		replaced map[string]bool
+	}{
+		{OverwriteAlways, map[string]bool{"old.txt": true, "new.txt": true}},
+		{OverwriteNever, map[string]bool{"old.txt": false, "new.txt": false}},
+		{OverwriteNewer, map[string]bool{"old.txt": true, "new.txt": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			destDir := prepare(t)
+			opts := ExtractOptions{Overwrite: tt.policy}
+
+			plan, err := PlanExtraction(zipPath, "", destDir, opts)
+			if err != nil {
+				t.Fatalf("PlanExtraction() unexpected error = %v", err)
+			}
+
+			count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, opts)
+			if err != nil {
+				t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+			}
+
+			want := 0
+			for name, replaced := range tt.replaced {
+				data, _ := os.ReadFile(filepath.Join(destDir, name))
+				if got := string(data) == "from the archive"; got != replaced {
+					t.Errorf("%s replaced = %v, want %v", name, got, replaced)
+				}
+				if replaced {
+					want++
+				}
+			}
+			if count != want || len(plan.Files) != want || plan.Kept != 2-want {
+				t.Errorf("extracted %d, planned %d and kept %d, want %d extracted", count, len(plan.Files), plan.Kept, want)
+			}
+		})
+	}
+
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", t.TempDir(), ExtractOptions{Overwrite: "sometimes"}); err == nil {
+		t.Error("ExtractWithOptions() with an unknown policy expected an error")
+	}
+}
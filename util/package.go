@@ -0,0 +1,613 @@
+package util
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// PackageKind tells what kind of package an archive is.
+type PackageKind string
+
+const (
+	// PackageJAR is a Java archive, or a variant of one such as WAR or EAR.
+	PackageJAR PackageKind = "JAR"
+	// PackageAPK is an Android application package.
+	PackageAPK PackageKind = "APK"
+)
+
+const (
+	manifestName        = "META-INF/MANIFEST.MF"
+	androidManifestName = "AndroidManifest.xml"
+	// packageFileLimit is the largest manifest ReadPackageInfo reads; those
+	// of signed JARs list every file, so they can be long.
+	packageFileLimit = 16 << 20
+	// apkSigBlockLimit is the largest APK signing block ReadPackageInfo
+	// reads.
+	apkSigBlockLimit = 16 << 20
+	// apkSigBlockMagic ends the APK signing block, which sits right before
+	// the central directory.
+	apkSigBlockMagic = "APK Sig Block 42"
+)
+
+// javaExtensions are the extensions of Java archives, which may lack a
+// manifest.
+var javaExtensions = []string{".jar", ".war", ".ear"}
+
+// signatureBlockExtensions are those of the JAR signature blocks under
+// META-INF, PKCS #7 files holding the signer's certificates.
+var signatureBlockExtensions = []string{".rsa", ".dsa", ".ec"}
+
+// apkSchemes names the APK signature schemes by their ID in the APK
+// signing block.
+var apkSchemes = map[uint32]string{
+	0x7109871a: "v2",
+	0xf05368c0: "v3",
+	0x1b93ad61: "v3.1",
+}
+
+// ManifestAttribute is a header of the main section of a JAR manifest.
+type ManifestAttribute struct {
+	Name  string
+	Value string
+}
+
+// PackageSigner is a certificate a JAR or APK is signed with.
+type PackageSigner struct {
+	// Schemes tell how it signs the package: "v1" for the JAR signature
+	// blocks under META-INF, "v2", "v3" or "v3.1" for the APK signing
+	// block.
+	Schemes []string
+	// Subject is the common name of the certificate, or its whole subject
+	// when it has none; Subject and the fields below are empty when the
+	// certificate could not be read.
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+	// Fingerprint is the SHA-256 of the certificate, in hexadecimal.
+	Fingerprint string
+}
+
+// PackageInfo summarises a Java archive or an Android package.
+type PackageInfo struct {
+	Kind PackageKind
+	// Manifest is the main section of META-INF/MANIFEST.MF, in order.
+	Manifest []ManifestAttribute
+	// Package and the fields below come from AndroidManifest.xml, for APKs.
+	Package     string
+	VersionName string
+	VersionCode string
+	MinSDK      string
+	TargetSDK   string
+	Signers     []PackageSigner
+}
+
+// Attribute returns the value of the manifest attribute name, matched
+// regardless of case as the JAR specification does; "" when it is absent.
+//
+// Parameters:
+//   - name: the attribute, such as "Main-Class"
+//
+// Returns:
+//   - string: its value
+func (p PackageInfo) Attribute(name string) string {
+	for _, attr := range p.Manifest {
+		if strings.EqualFold(attr.Name, name) {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// MainClass returns the class "java -jar" runs, from the manifest.
+//
+// Returns:
+//   - string: the class, "" when the archive has none
+func (p PackageInfo) MainClass() string {
+	return p.Attribute("Main-Class")
+}
+
+// ReadPackageInfo reads what describes the JAR or APK at zipPath: the main
+// section of META-INF/MANIFEST.MF, the package name, version and SDK levels
+// of an APK's binary AndroidManifest.xml, and the certificates it is signed
+// with, from the signature blocks under META-INF and the APK signing block.
+// Signatures are not verified. An archive is an APK when it has an
+// AndroidManifest.xml, and a JAR when it has a manifest or a .jar, .war or
+// .ear extension.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - PackageInfo: the summary; only its kind on errors reading a package
+//   - bool: false when the archive is neither a JAR nor an APK
+//   - error: any error encountered while reading the archive or its manifests
+func ReadPackageInfo(zipPath string) (PackageInfo, bool, error) {
+	r, err := openZipReader(zipPath)
+	if err != nil {
+		return PackageInfo{}, false, openError(err)
+	}
+	defer r.Close()
+
+	var manifest, android *zip.File
+	var blocks []*zip.File
+	for _, f := range r.File {
+		switch {
+		case f.Name == androidManifestName:
+			android = f
+		case strings.EqualFold(f.Name, manifestName):
+			manifest = f
+		case isSignatureBlock(f.Name):
+			blocks = append(blocks, f)
+		}
+	}
+
+	var info PackageInfo
+	switch {
+	case android != nil:
+		info.Kind = PackageAPK
+	case manifest != nil || slices.Contains(javaExtensions, strings.ToLower(filepath.Ext(zipPath))):
+		info.Kind = PackageJAR
+	default:
+		return PackageInfo{}, false, nil
+	}
+
+	if manifest != nil {
+		data, err := readPackageFile(manifest)
+		if err != nil {
+			return info, true, err
+		}
+		info.Manifest = parseManifest(data)
+	}
+	if android != nil {
+		data, err := readPackageFile(android)
+		if err != nil {
+			return info, true, err
+		}
+		if err := parseAndroidManifest(data, &info); err != nil {
+			return info, true, fmt.Errorf("reading %s: %w", androidManifestName, err)
+		}
+	}
+
+	for _, f := range blocks {
+		var der []byte
+		if data, err := readPackageFile(f); err == nil {
+			der, _ = signedDataCertificate(data)
+		}
+		info.addSigner("v1", der)
+	}
+	if info.Kind == PackageAPK {
+		if stat, err := r.file.Stat(); err == nil {
+			readAPKSigningBlock(r.file, stat.Size(), &info)
+		}
+	}
+
+	return info, true, nil
+}
+
+// isSignatureBlock reports whether name is a JAR signature block, such as
+// META-INF/CERT.RSA.
+func isSignatureBlock(name string) bool {
+	return strings.EqualFold(path.Dir(name), "META-INF") &&
+		slices.Contains(signatureBlockExtensions, strings.ToLower(path.Ext(name)))
+}
+
+// readPackageFile returns the content of f, one of the small files
+// describing a package.
+func readPackageFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > packageFileLimit {
+		return nil, fmt.Errorf("%s is too large: %s", f.Name, FormatSize(f.UncompressedSize64))
+	}
+	data, err := readEntry(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// parseManifest returns the attributes of the main section of a JAR
+// manifest, which ends at the first empty line. Long values continue on
+// lines starting with a space.
+func parseManifest(data []byte) []ManifestAttribute {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var attrs []ManifestAttribute
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			if n := len(attrs); n > 0 {
+				attrs[n-1].Value += line[1:]
+			}
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		attrs = append(attrs, ManifestAttribute{Name: name, Value: strings.TrimPrefix(value, " ")})
+	}
+	return attrs
+}
+
+// addSigner adds the certificate der, found in the given signature scheme,
+// to p.Signers, or the scheme to its signer when another scheme has the
+// same certificate. A nil der adds a signer whose certificate could not be
+// read.
+func (p *PackageInfo) addSigner(scheme string, der []byte) {
+	signer := PackageSigner{Schemes: []string{scheme}}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		sum := sha256.Sum256(cert.Raw)
+		signer.Subject = certificateName(cert.Subject.CommonName, cert.Subject.String())
+		signer.Issuer = certificateName(cert.Issuer.CommonName, cert.Issuer.String())
+		signer.NotAfter = cert.NotAfter
+		signer.Fingerprint = hex.EncodeToString(sum[:])
+	}
+
+	for i, known := range p.Signers {
+		if signer.Fingerprint != "" && known.Fingerprint == signer.Fingerprint {
+			if !slices.Contains(known.Schemes, scheme) {
+				p.Signers[i].Schemes = append(known.Schemes, scheme)
+			}
+			return
+		}
+	}
+	p.Signers = append(p.Signers, signer)
+}
+
+// certificateName is the common name of a certificate's subject or issuer,
+// or the whole name when it has none.
+func certificateName(common, whole string) string {
+	if common != "" {
+		return common
+	}
+	return whole
+}
+
+// pkcs7ContentInfo is the outer structure of a PKCS #7 file.
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// pkcs7SignedData is the start of the PKCS #7 SignedData structure, up to
+// the certificates; the signer infos that follow are not needed.
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// signedDataCertificate returns the first certificate of the PKCS #7
+// SignedData in data, the signer's in the blocks jarsigner and apksigner
+// write. Blocks in BER with indefinite lengths cannot be read.
+func signedDataCertificate(data []byte) ([]byte, error) {
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	var signed pkcs7SignedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &signed); err != nil {
+		return nil, err
+	}
+
+	var cert asn1.RawValue
+	if _, err := asn1.Unmarshal(signed.Certificates.Bytes, &cert); err != nil {
+		return nil, errors.New("signature block without certificates")
+	}
+	return cert.FullBytes, nil
+}
+
+// readAPKSigningBlock adds to info the signers of the APK signing block,
+// which APK Signature Scheme v2 and later put right before the central
+// directory of the archive in r, which is size bytes long. Nothing is added
+// when there is no block or it is malformed.
+func readAPKSigningBlock(r io.ReaderAt, size int64, info *PackageInfo) {
+	cd, _, err := locateCentralDirectory(r, size)
+	if err != nil || cd.start < 32 {
+		return
+	}
+
+	// The block is its size, ID-value pairs, its size again and the magic;
+	// the sizes leave the first one out.
+	footer := make([]byte, 24)
+	if _, err := r.ReadAt(footer, cd.start-24); err != nil || string(footer[8:]) != apkSigBlockMagic {
+		return
+	}
+	blockSize := binary.LittleEndian.Uint64(footer)
+	if blockSize < 24 || blockSize > apkSigBlockLimit || int64(blockSize)+8 > cd.start {
+		return
+	}
+	pairs := make([]byte, blockSize-24)
+	if _, err := r.ReadAt(pairs, cd.start-int64(blockSize)); err != nil {
+		return
+	}
+
+	for len(pairs) >= 12 {
+		n := binary.LittleEndian.Uint64(pairs)
+		if n < 4 || n > uint64(len(pairs)-8) {
+			return
+		}
+		id := binary.LittleEndian.Uint32(pairs[8:])
+		value := pairs[12 : 8+n]
+		pairs = pairs[8+n:]
+
+		if scheme, ok := apkSchemes[id]; ok {
+			for _, der := range apkSignerCertificates(value) {
+				info.addSigner(scheme, der)
+			}
+		}
+	}
+}
+
+// apkSignerCertificates returns the first certificate of each signer in
+// the value of an APK Signature Scheme v2 or v3 block. In both every signer
+// starts with its signed data, made of the digests and then the
+// certificates.
+func apkSignerCertificates(value []byte) [][]byte {
+	signers, _, ok := lengthPrefixed(value)
+	if !ok {
+		return nil
+	}
+
+	var certs [][]byte
+	for len(signers) > 0 {
+		signer, rest, ok := lengthPrefixed(signers)
+		if !ok {
+			break
+		}
+		signers = rest
+
+		signed, _, ok := lengthPrefixed(signer)
+		if !ok {
+			continue
+		}
+		_, signed, ok = lengthPrefixed(signed)
+		if !ok {
+			continue
+		}
+		list, _, ok := lengthPrefixed(signed)
+		if !ok {
+			continue
+		}
+		cert, _, _ := lengthPrefixed(list)
+		certs = append(certs, cert)
+	}
+	return certs
+}
+
+// lengthPrefixed splits b into the item its 32-bit little-endian length
+// prefix delimits and what follows it.
+func lengthPrefixed(b []byte) (item, rest []byte, ok bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.LittleEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
+
+// Chunk types of Android's binary XML, in which APKs store their
+// AndroidManifest.xml.
+const (
+	axmlStringPool   = 0x0001
+	axmlDocument     = 0x0003
+	axmlResourceMap  = 0x0180
+	axmlStartElement = 0x0102
+	// axmlNoIndex marks an attribute without a string value.
+	axmlNoIndex = 0xffffffff
+	// axmlUTF8 is the string pool flag for UTF-8 strings; UTF-16 otherwise.
+	axmlUTF8 = 0x100
+)
+
+// androidAttributes names the attributes of the android namespace that
+// ReadPackageInfo reads by their resource ID, which stays when tools such
+// as obfuscators rename or strip the names.
+var androidAttributes = map[uint32]string{
+	0x0101020c: "minSdkVersion",
+	0x01010270: "targetSdkVersion",
+	0x0101021b: "versionCode",
+	0x0101021c: "versionName",
+}
+
+// parseAndroidManifest fills the APK fields of info from the binary
+// AndroidManifest.xml in data.
+func parseAndroidManifest(data []byte, info *PackageInfo) error {
+	if len(data) < 8 || binary.LittleEndian.Uint16(data) != axmlDocument {
+		return errors.New("not a binary XML document")
+	}
+
+	var strs []string
+	var resources []uint32
+	off := int(binary.LittleEndian.Uint16(data[2:]))
+	for off+8 <= len(data) {
+		chunkType := binary.LittleEndian.Uint16(data[off:])
+		header := int(binary.LittleEndian.Uint16(data[off+2:]))
+		size := int(binary.LittleEndian.Uint32(data[off+4:]))
+		if size < 8 || header > size || size > len(data)-off {
+			return fmt.Errorf("malformed chunk at offset %d", off)
+		}
+		chunk := data[off : off+size]
+		off += size
+
+		switch chunkType {
+		case axmlStringPool:
+			var err error
+			if strs, err = parseStringPool(chunk, header); err != nil {
+				return err
+			}
+		case axmlResourceMap:
+			for i := header; i+4 <= size; i += 4 {
+				resources = append(resources, binary.LittleEndian.Uint32(chunk[i:]))
+			}
+		case axmlStartElement:
+			name, attrs := parseStartElement(chunk, header, strs, resources)
+			switch name {
+			case "manifest":
+				info.Package = attrs["package"]
+				info.VersionCode = attrs["versionCode"]
+				info.VersionName = attrs["versionName"]
+			case "uses-sdk":
+				info.MinSDK = attrs["minSdkVersion"]
+				info.TargetSDK = attrs["targetSdkVersion"]
+			}
+		}
+	}
+	return nil
+}
+
+// parseStringPool returns the strings of a binary XML string pool chunk.
+func parseStringPool(chunk []byte, header int) ([]string, error) {
+	if header < 28 || len(chunk) < header {
+		return nil, errors.New("malformed string pool")
+	}
+	count := int(binary.LittleEndian.Uint32(chunk[8:]))
+	flags := binary.LittleEndian.Uint32(chunk[16:])
+	start := int(binary.LittleEndian.Uint32(chunk[20:]))
+	if count > (len(chunk)-header)/4 {
+		return nil, errors.New("malformed string pool")
+	}
+
+	strs := make([]string, count)
+	for i := range strs {
+		at := start + int(binary.LittleEndian.Uint32(chunk[header+4*i:]))
+		if at < 0 || at >= len(chunk) {
+			continue
+		}
+		if flags&axmlUTF8 != 0 {
+			strs[i] = poolUTF8(chunk[at:])
+		} else {
+			strs[i] = poolUTF16(chunk[at:])
+		}
+	}
+	return strs, nil
+}
+
+// poolUTF8 decodes a UTF-8 string of a string pool: its length in UTF-16
+// units, its length in bytes, one or two bytes each, and the bytes.
+func poolUTF8(b []byte) string {
+	_, b = poolLength8(b)
+	n, b := poolLength8(b)
+	if n > len(b) {
+		return ""
+	}
+	return string(b[:n])
+}
+
+// poolLength8 reads a length of a UTF-8 string pool entry.
+func poolLength8(b []byte) (int, []byte) {
+	switch {
+	case len(b) >= 2 && b[0]&0x80 != 0:
+		return int(b[0]&0x7f)<<8 | int(b[1]), b[2:]
+	case len(b) >= 1:
+		return int(b[0]), b[1:]
+	}
+	return 0, nil
+}
+
+// poolUTF16 decodes a UTF-16 string of a string pool: its length in units,
+// one or two units long, and the units.
+func poolUTF16(b []byte) string {
+	if len(b) < 2 {
+		return ""
+	}
+	n := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+	if n&0x8000 != 0 && len(b) >= 2 {
+		n = (n&0x7fff)<<16 | int(binary.LittleEndian.Uint16(b))
+		b = b[2:]
+	}
+	if n > len(b)/2 {
+		return ""
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// parseStartElement returns the name and the attributes of a binary XML
+// start element chunk. Attributes known by their resource ID are named as
+// in androidAttributes, the rest by their name without namespace.
+func parseStartElement(chunk []byte, header int, strs []string, resources []uint32) (string, map[string]string) {
+	if header+20 > len(chunk) {
+		return "", nil
+	}
+	ext := chunk[header:]
+	name := poolString(strs, binary.LittleEndian.Uint32(ext[4:]))
+	attrStart := int(binary.LittleEndian.Uint16(ext[8:]))
+	attrSize := int(binary.LittleEndian.Uint16(ext[10:]))
+	count := int(binary.LittleEndian.Uint16(ext[12:]))
+	if attrSize < 20 {
+		return name, nil
+	}
+
+	attrs := make(map[string]string, count)
+	for i := 0; i < count; i++ {
+		at := header + attrStart + i*attrSize
+		if at+20 > len(chunk) {
+			break
+		}
+		attr := chunk[at:]
+		index := binary.LittleEndian.Uint32(attr[4:])
+		key := poolString(strs, index)
+		if uint64(index) < uint64(len(resources)) {
+			if known, ok := androidAttributes[resources[index]]; ok {
+				key = known
+			}
+		}
+		attrs[key] = attributeValue(attr, strs)
+	}
+	return name, attrs
+}
+
+// attributeValue renders the value of a binary XML attribute: its string
+// when it has one, or its typed value.
+func attributeValue(attr []byte, strs []string) string {
+	raw := binary.LittleEndian.Uint32(attr[8:])
+	dataType := attr[15]
+	data := binary.LittleEndian.Uint32(attr[16:])
+
+	switch {
+	case raw != axmlNoIndex:
+		return poolString(strs, raw)
+	case dataType == 0x01:
+		return fmt.Sprintf("@0x%08x", data)
+	case dataType == 0x03:
+		return poolString(strs, data)
+	case dataType == 0x10:
+		return strconv.Itoa(int(int32(data)))
+	case dataType == 0x12:
+		return strconv.FormatBool(data != 0)
+	}
+	return "0x" + strconv.FormatUint(uint64(data), 16)
+}
+
+// poolString returns the string at index i of a string pool, "" when
+// there is none.
+func poolString(strs []string, i uint32) string {
+	if uint64(i) >= uint64(len(strs)) {
+		return ""
+	}
+	return strs[i]
+}
@@ -0,0 +1,311 @@
+package util
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// testCertificate returns a self-signed certificate for cn, in DER.
+func testCertificate(t *testing.T, cn string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"Example"}},
+		NotBefore:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	return der
+}
+
+// testSignatureBlock returns a PKCS #7 SignedData holding cert, as in a
+// JAR's META-INF/CERT.RSA, without signer infos.
+func testSignatureBlock(t *testing.T, cert []byte) []byte {
+	t.Helper()
+
+	emptySet := asn1.RawValue{Tag: asn1.TagSet, IsCompound: true}
+	signed, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: emptySet,
+		ContentInfo:      struct{ ContentType asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: cert},
+		SignerInfos:      emptySet,
+	})
+	if err != nil {
+		t.Fatalf("Marshal(SignedData) error = %v", err)
+	}
+
+	block, err := asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signed}})
+	if err != nil {
+		t.Fatalf("Marshal(ContentInfo) error = %v", err)
+	}
+	return block
+}
+
+// littleEndian encodes values one after the other, as the binary formats
+// of Android do.
+func littleEndian(values ...any) []byte {
+	var b bytes.Buffer
+	for _, v := range values {
+		if data, ok := v.([]byte); ok {
+			b.Write(data)
+			continue
+		}
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	return b.Bytes()
+}
+
+// testAndroidManifest returns a binary AndroidManifest.xml for package
+// com.example.app, version 1.2.3 (42), SDK 21 to 34, with its strings in
+// UTF-8 or UTF-16. The name of minSdkVersion is stripped, as obfuscators
+// do, leaving its resource ID.
+func testAndroidManifest(utf8 bool) []byte {
+	strs := []string{"versionCode", "versionName", "", "targetSdkVersion", "package", "manifest", "uses-sdk", "com.example.app", "1.2.3"}
+	ids := []uint32{0x0101021b, 0x0101021c, 0x0101020c, 0x01010270}
+
+	var data []byte
+	offsets := make([]uint32, len(strs))
+	for i, s := range strs {
+		offsets[i] = uint32(len(data))
+		if utf8 {
+			data = append(data, littleEndian(uint8(len(s)), uint8(len(s)), []byte(s), uint8(0))...)
+		} else {
+			units := utf16.Encode([]rune(s))
+			data = append(data, littleEndian(uint16(len(units)), units, uint16(0))...)
+		}
+	}
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+	flags := uint32(0)
+	if utf8 {
+		flags = axmlUTF8
+	}
+	header := 28 + 4*len(strs)
+	pool := littleEndian(uint16(axmlStringPool), uint16(28), uint32(header+len(data)),
+		uint32(len(strs)), uint32(0), flags, uint32(header), uint32(0), offsets, data)
+
+	resourceMap := littleEndian(uint16(axmlResourceMap), uint16(8), uint32(8+4*len(ids)), ids)
+
+	type attr struct {
+		name, raw uint32
+		kind      uint8
+		data      uint32
+	}
+	element := func(name uint32, attrs ...attr) []byte {
+		body := littleEndian(uint32(axmlNoIndex), name, uint16(20), uint16(20), uint16(len(attrs)), uint16(0), uint16(0), uint16(0))
+		for _, a := range attrs {
+			body = append(body, littleEndian(uint32(axmlNoIndex), a.name, a.raw, uint16(8), uint8(0), a.kind, a.data)...)
+		}
+		return littleEndian(uint16(axmlStartElement), uint16(16), uint32(16+len(body)), uint32(1), uint32(axmlNoIndex), body)
+	}
+	manifest := element(5,
+		attr{4, 7, 0x03, 7},
+		attr{0, axmlNoIndex, 0x10, 42},
+		attr{1, 8, 0x03, 8})
+	usesSDK := element(6,
+		attr{2, axmlNoIndex, 0x10, 21},
+		attr{3, axmlNoIndex, 0x10, 34})
+
+	body := slices.Concat(pool, resourceMap, manifest, usesSDK)
+	return littleEndian(uint16(axmlDocument), uint16(8), uint32(8+len(body)), body)
+}
+
+// insertAPKSigningBlock puts an APK signing block with a v2 signer for
+// cert right before the central directory of the archive at zipPath,
+// which has no comment.
+func insertAPKSigningBlock(t *testing.T, zipPath string, cert []byte) {
+	t.Helper()
+
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	prefixed := func(parts ...[]byte) []byte {
+		b := slices.Concat(parts...)
+		return littleEndian(uint32(len(b)), b)
+	}
+	signedData := prefixed(prefixed(), prefixed(prefixed(cert)), prefixed())
+	value := prefixed(prefixed(signedData, prefixed(), prefixed()))
+	pairs := littleEndian(uint64(4+len(value)), uint32(0x7109871a), value)
+	size := uint64(len(pairs) + 24)
+	block := littleEndian(size, pairs, size, []byte(apkSigBlockMagic))
+
+	eocd := len(data) - directoryEndLen
+	dirOffset := binary.LittleEndian.Uint32(data[eocd+16:])
+	patched := slices.Concat(data[:dirOffset], block, data[dirOffset:])
+	binary.LittleEndian.PutUint32(patched[len(block)+eocd+16:], dirOffset+uint32(len(block)))
+
+	if err := os.WriteFile(zipPath, patched, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+// TestParseManifest checks the main section of a manifest is read, with
+// continuation lines and CRLF line breaks
+func TestParseManifest(t *testing.T) {
+	data := "Manifest-Version: 1.0\r\nMain-Class: com.example.app.Ma\r\n in\r\nCreated-By: 21 (Oracle)\r\n\r\nName: com/example/app/Main.class\r\nSHA-256-Digest: abc\r\n"
+
+	got := parseManifest([]byte(data))
+	want := []ManifestAttribute{
+		{"Manifest-Version", "1.0"},
+		{"Main-Class", "com.example.app.Main"},
+		{"Created-By", "21 (Oracle)"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseManifest() = %v, want %v", got, want)
+	}
+
+	info := PackageInfo{Manifest: got}
+	if c := info.MainClass(); c != "com.example.app.Main" {
+		t.Errorf("MainClass() = %q, want com.example.app.Main", c)
+	}
+	if v := info.Attribute("created-by"); v != "21 (Oracle)" {
+		t.Errorf("Attribute(created-by) = %q, want it regardless of case", v)
+	}
+}
+
+// TestParseAndroidManifest checks the package, version and SDK levels are
+// read from binary XML with UTF-8 and UTF-16 strings
+func TestParseAndroidManifest(t *testing.T) {
+	for _, utf8 := range []bool{false, true} {
+		var info PackageInfo
+		if err := parseAndroidManifest(testAndroidManifest(utf8), &info); err != nil {
+			t.Fatalf("parseAndroidManifest(utf8 %v) unexpected error = %v", utf8, err)
+		}
+		want := PackageInfo{Package: "com.example.app", VersionName: "1.2.3", VersionCode: "42", MinSDK: "21", TargetSDK: "34"}
+		if info.Package != want.Package || info.VersionName != want.VersionName || info.VersionCode != want.VersionCode ||
+			info.MinSDK != want.MinSDK || info.TargetSDK != want.TargetSDK {
+			t.Errorf("parseAndroidManifest(utf8 %v) = %+v, want %+v", utf8, info, want)
+		}
+	}
+
+	if err := parseAndroidManifest([]byte("<manifest/>"), &PackageInfo{}); err == nil {
+		t.Error("parseAndroidManifest(text XML) expected error, got nil")
+	}
+	truncated := testAndroidManifest(false)
+	if err := parseAndroidManifest(truncated[:len(truncated)-10], &PackageInfo{}); err == nil {
+		t.Error("parseAndroidManifest(truncated) expected error, got nil")
+	}
+}
+
+// TestReadPackageInfoJAR checks the manifest and the v1 signer of a JAR
+func TestReadPackageInfoJAR(t *testing.T) {
+	cert := testCertificate(t, "Example Release")
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"META-INF/MANIFEST.MF":   "Manifest-Version: 1.0\nMain-Class: com.example.Main\n\nName: a.class\nSHA-256-Digest: x\n",
+		"META-INF/RELEASE.SF":    "Signature-Version: 1.0\n",
+		"META-INF/RELEASE.RSA":   string(testSignatureBlock(t, cert)),
+		"META-INF/BROKEN.DSA":    "not PKCS #7",
+		"com/example/Main.class": "",
+	})
+
+	info, ok, err := ReadPackageInfo(zipPath)
+	if err != nil || !ok {
+		t.Fatalf("ReadPackageInfo() = %v, %v, want a package", ok, err)
+	}
+	if info.Kind != PackageJAR || info.MainClass() != "com.example.Main" || len(info.Manifest) != 2 {
+		t.Errorf("ReadPackageInfo() = %+v, want a JAR running com.example.Main", info)
+	}
+
+	if len(info.Signers) != 2 {
+		t.Fatalf("ReadPackageInfo() signers = %+v, want the readable and the broken one", info.Signers)
+	}
+	var good, broken PackageSigner
+	for _, s := range info.Signers {
+		if s.Subject == "" {
+			broken = s
+		} else {
+			good = s
+		}
+	}
+	if good.Subject != "Example Release" || good.Issuer != "Example Release" || good.NotAfter.Year() != 2050 ||
+		len(good.Fingerprint) != 64 || !slices.Equal(good.Schemes, []string{"v1"}) {
+		t.Errorf("ReadPackageInfo() signer = %+v, want Example Release until 2050 with v1", good)
+	}
+	if !slices.Equal(broken.Schemes, []string{"v1"}) || broken.Fingerprint != "" {
+		t.Errorf("ReadPackageInfo() unreadable signer = %+v, want only its scheme", broken)
+	}
+}
+
+// TestReadPackageInfoAPK checks the Android manifest and the signers of
+// both schemes of an APK, merged when they share the certificate
+func TestReadPackageInfoAPK(t *testing.T) {
+	cert := testCertificate(t, "Android Release")
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"AndroidManifest.xml":  string(testAndroidManifest(false)),
+		"classes.dex":          "dex\n035",
+		"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nCreated-By: apksigner\n",
+		"META-INF/CERT.RSA":    string(testSignatureBlock(t, cert)),
+	})
+	insertAPKSigningBlock(t, zipPath, cert)
+
+	if _, err := ListArchive(zipPath); err != nil {
+		t.Fatalf("ListArchive() of the signed APK error = %v", err)
+	}
+
+	info, ok, err := ReadPackageInfo(zipPath)
+	if err != nil || !ok {
+		t.Fatalf("ReadPackageInfo() = %v, %v, want a package", ok, err)
+	}
+	if info.Kind != PackageAPK || info.Package != "com.example.app" || info.MinSDK != "21" || info.Attribute("Created-By") != "apksigner" {
+		t.Errorf("ReadPackageInfo() = %+v, want the APK of com.example.app", info)
+	}
+	if len(info.Signers) != 1 || info.Signers[0].Subject != "Android Release" || !slices.Equal(info.Signers[0].Schemes, []string{"v1", "v2"}) {
+		t.Errorf("ReadPackageInfo() signers = %+v, want Android Release with v1 and v2", info.Signers)
+	}
+}
+
+// TestReadPackageInfoOther checks plain archives are not packages, while
+// a .jar without manifest is
+func TestReadPackageInfoOther(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"README": "hello"})
+	if _, ok, err := ReadPackageInfo(zipPath); err != nil || ok {
+		t.Errorf("ReadPackageInfo(zip) = %v, %v, want no package", ok, err)
+	}
+
+	jarPath := filepath.Join(t.TempDir(), "lib.jar")
+	if err := os.Rename(zipPath, jarPath); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	info, ok, err := ReadPackageInfo(jarPath)
+	if err != nil || !ok || info.Kind != PackageJAR || len(info.Manifest) != 0 {
+		t.Errorf("ReadPackageInfo(jar) = %+v, %v, %v, want a JAR without manifest", info, ok, err)
+	}
+
+	if _, _, err := ReadPackageInfo(filepath.Join(t.TempDir(), "missing.jar")); err == nil {
+		t.Error("ReadPackageInfo(missing) expected error, got nil")
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigDir returns the directory where goZip keeps its per-user settings,
+// usually ~/.config/gozip. The directory is not created.
+func ConfigDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(base, "gozip"), nil
+}
+
+// StateDir returns the directory where goZip keeps state that should survive
+// restarts but is not configuration, following $XDG_STATE_HOME and defaulting
+// to ~/.local/state/gozip. The directory is not created.
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "gozip"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state", "gozip"), nil
+}
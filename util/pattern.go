@@ -0,0 +1,34 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ParseNamePattern compiles a pattern matching entry names: a glob, as
+// ExtractOptions.Include takes them, or a regular expression between
+// slashes, such as "/\.(sql|csv)$/", searched anywhere in the name.
+//
+// Parameters:
+//   - pattern: the glob or the slash-delimited regular expression
+//
+// Returns:
+//   - func(string) bool: reports whether an entry name matches
+//   - error: an empty pattern or an invalid regular expression
+func ParseNamePattern(pattern string) (func(string) bool, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %s: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+
+	return func(name string) bool { return matchGlob(pattern, name) }, nil
+}
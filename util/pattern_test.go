@@ -0,0 +1,36 @@
+package util
+
+import "testing"
+
+// TestParseNamePattern checks globs, regular expressions and invalid patterns
+func TestParseNamePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.sql", "db/dump.sql", true},
+		{"*.sql", "db/dump.sql.gz", false},
+		{"db/**", "db/2024/dump.sql", true},
+		{"/\\.(sql|csv)$/", "export/users.csv", true},
+		{"/^db//", "dbx/a", false},
+		{"/dump/", "db/dump.sql", true},
+	}
+
+	for _, tt := range tests {
+		match, err := ParseNamePattern(tt.pattern)
+		if err != nil {
+			t.Errorf("ParseNamePattern(%q) unexpected error = %v", tt.pattern, err)
+			continue
+		}
+		if got := match(tt.name); got != tt.want {
+			t.Errorf("ParseNamePattern(%q)(%q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "  ", "/(/"} {
+		if _, err := ParseNamePattern(bad); err == nil {
+			t.Errorf("ParseNamePattern(%q) expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PlannedFile is a file an extraction would write.
+type PlannedFile struct {
+	// Name is the entry name inside the archive.
+	Name string
+	// Path is where the file would be written.
+	Path string
+	// Size is the uncompressed size of the entry.
+	Size uint64
+	// Overwrites is true when a file already exists at Path.
+	Overwrites bool
+	// Renamed tells why Path does not follow Name, as when Windows cannot
+	// store the name; it is empty otherwise.
+	Renamed string
+}
+
+// ExtractionPlan describes what an extraction would do, without doing it.
+type ExtractionPlan struct {
+	Files []PlannedFile
+	// TotalSize is the number of bytes that would be written.
+	TotalSize uint64
+	// Overwrites counts the files that would replace existing ones.
+	Overwrites int
+	// Kept counts the existing files the overwrite policy would leave as
+	// they are; their entries are not in Files.
+	Kept int
+	// FreeSpace is the space available in the destination directory, valid
+	// only when SpaceKnown is true.
+	FreeSpace  uint64
+	SpaceKnown bool
+}
+
+// Fits reports whether the destination has room for the files. It is true
+// when the free space is unknown, as ExtractWithOptions then does not block.
+func (p ExtractionPlan) Fits() bool {
+	return !p.SpaceKnown || p.FreeSpace >= p.TotalSize
+}
+
+// PlanExtraction lists the files ExtractWithOptions would write for the same
+// arguments, where it would write them and which ones already exist. The
+// filesystem is only inspected, never modified.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - targetName: name of the file or folder to extract, or "" for the whole archive
+//   - destDir: destination directory where files would be extracted
+//   - opts: which files would be written
+//
+// Returns:
+//   - ExtractionPlan: the files that would be written
+//   - error: any error encountered while reading the archive
+func PlanExtraction(zipPath, targetName, destDir string, opts ExtractOptions) (ExtractionPlan, error) {
+	if err := opts.validate(); err != nil {
+		return ExtractionPlan{}, err
+	}
+
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return ExtractionPlan{}, openError(err)
+	}
+	defer reader.Close()
+
+	targets, err := selectTargets(reader.File, targetName, opts)
+	if err != nil {
+		return ExtractionPlan{}, err
+	}
+	renameForWindows(targets)
+	if err := resolveCaseCollisions(targets, destDir, opts.CaseCollisions); err != nil {
+		return ExtractionPlan{}, err
+	}
+	if err := checkTargets(targets); err != nil {
+		return ExtractionPlan{}, err
+	}
+
+	var plan ExtractionPlan
+	for _, t := range targets {
+		pf := PlannedFile{Name: t.name, Path: filepath.Join(destDir, t.relPath), Size: t.file.UncompressedSize64, Renamed: t.renamed}
+		if opts.Overwrite.keeps(t.file, pf.Path) {
+			plan.Kept++
+			continue
+		}
+		if _, err := os.Lstat(pf.Path); err == nil {
+			pf.Overwrites = true
+			plan.Overwrites++
+		}
+
+		plan.Files = append(plan.Files, pf)
+		plan.TotalSize += pf.Size
+	}
+
+	if free, err := FreeSpace(destDir); err == nil {
+		plan.FreeSpace, plan.SpaceKnown = free, true
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPlanExtraction checks planned paths, sizes and overwrites without writing anything
+func TestPlanExtraction(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"dir/":      "",
+		"dir/a.txt": "aaa",
+		"dir/b.txt": "bb",
+		"top.txt":   "t",
+	})
+
+	destDir := t.TempDir()
+	os.MkdirAll(filepath.Join(destDir, "dir"), 0755)
+	os.WriteFile(filepath.Join(destDir, "dir", "b.txt"), []byte("old"), 0644)
+
+	plan, err := PlanExtraction(zipPath, "dir", destDir, ExtractOptions{})
+	if err != nil {
+		t.Fatalf("PlanExtraction() unexpected error = %v", err)
+	}
+	if len(plan.Files) != 2 || plan.TotalSize != 5 || plan.Overwrites != 1 {
+		t.Fatalf("PlanExtraction() = %+v, want 2 files, 5 bytes, 1 overwrite", plan)
+	}
+	if plan.Files[0].Path != filepath.Join(destDir, "dir", "a.txt") || plan.Files[0].Overwrites || !plan.Files[1].Overwrites {
+		t.Errorf("PlanExtraction() files = %+v", plan.Files)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "dir", "a.txt")); err == nil {
+		t.Error("PlanExtraction() wrote a file")
+	}
+
+	all, err := PlanExtraction(zipPath, "", destDir, ExtractOptions{})
+	if err != nil || len(all.Files) != 3 {
+		t.Errorf("PlanExtraction(whole archive) = %+v, %v, want 3 files", all, err)
+	}
+
+	if _, err := PlanExtraction(zipPath, "missing", destDir, ExtractOptions{}); err == nil {
+		t.Error("PlanExtraction() expected error for a missing entry, got nil")
+	}
+}
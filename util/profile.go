@@ -0,0 +1,93 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime/trace"
+)
+
+// Profiler serves net/http/pprof and writes a runtime trace while goZip
+// runs, for reporting performance problems, see StartProfiling.
+type Profiler struct {
+	server   *http.Server
+	listener net.Listener
+	trace    *os.File
+}
+
+// StartProfiling starts what --pprof and --trace ask for: serving the
+// net/http/pprof handlers under /debug/pprof/ on pprofAddr, and tracing
+// the runtime into traceFile. Either may be "" to skip it.
+//
+// Parameters:
+//   - pprofAddr: address to listen on, such as ":6060" or "localhost:0"
+//   - traceFile: file to write the trace to, created or truncated
+//
+// Returns:
+//   - *Profiler: stop it before exiting, or the trace is incomplete
+//   - error: the address cannot be listened on or the trace file created
+func StartProfiling(pprofAddr, traceFile string) (*Profiler, error) {
+	p := &Profiler{}
+
+	if pprofAddr != "" {
+		listener, err := net.Listen("tcp", pprofAddr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot serve pprof: %w", err)
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		p.listener = listener
+		p.server = &http.Server{Handler: mux}
+		go p.server.Serve(listener)
+		Logger().Info("serving pprof", "addr", listener.Addr().String())
+	}
+
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err == nil {
+			err = trace.Start(f)
+			if err != nil {
+				f.Close()
+			}
+		}
+		if err != nil {
+			p.Stop()
+			return nil, fmt.Errorf("cannot trace: %w", err)
+		}
+		p.trace = f
+		Logger().Info("tracing", "file", traceFile)
+	}
+
+	return p, nil
+}
+
+// Addr returns the address pprof is served on, with the port chosen when
+// the one asked for was 0; "" without pprof.
+func (p *Profiler) Addr() string {
+	if p.listener == nil {
+		return ""
+	}
+	return p.listener.Addr().String()
+}
+
+// Stop stops serving pprof and finishes the trace.
+func (p *Profiler) Stop() error {
+	var errs []error
+	if p.server != nil {
+		errs = append(errs, p.server.Close())
+		p.server = nil
+	}
+	if p.trace != nil {
+		trace.Stop()
+		errs = append(errs, p.trace.Close())
+		p.trace = nil
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStartProfiling checks that pprof is served and the trace written
+// until Stop
+func TestStartProfiling(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "gozip.trace")
+	p, err := StartProfiling("localhost:0", traceFile)
+	if err != nil {
+		t.Fatalf("StartProfiling() unexpected error = %v", err)
+	}
+
+	resp, err := http.Get("http://" + p.Addr() + "/debug/pprof/")
+	if err != nil {
+		t.Fatalf("pprof not served: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "goroutine") {
+		t.Errorf("pprof index lacks the goroutine profile:\n%s", body)
+	}
+
+	if err := p.Stop(); err != nil {
+		t.Errorf("Stop() unexpected error = %v", err)
+	}
+	if info, err := os.Stat(traceFile); err != nil || info.Size() == 0 {
+		t.Errorf("trace not written: %v", err)
+	}
+	if _, err := http.Get("http://" + p.Addr() + "/debug/pprof/"); err == nil {
+		t.Error("pprof still served after Stop()")
+	}
+}
+
+// TestStartProfilingNothing checks that nothing is started without flags
+func TestStartProfilingNothing(t *testing.T) {
+	p, err := StartProfiling("", "")
+	if err != nil {
+		t.Fatalf("StartProfiling() unexpected error = %v", err)
+	}
+	if p.Addr() != "" {
+		t.Errorf("Addr() = %q, want none", p.Addr())
+	}
+	if err := p.Stop(); err != nil {
+		t.Errorf("Stop() unexpected error = %v", err)
+	}
+}
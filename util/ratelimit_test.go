@@ -0,0 +1,38 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRateLimiter checks that reads beyond the burst wait for the rate, and
+// that a done context stops the wait
+func TestRateLimiter(t *testing.T) {
+	if l := newRateLimiter(0); l != nil || l.wait(context.Background(), 1<<20) != nil {
+		t.Fatal("newRateLimiter(0) should let everything through")
+	}
+
+	// A second's worth passes at once, half a second more waits.
+	l := newRateLimiter(10_000)
+	start := time.Now()
+	for range 15 {
+		if err := l.wait(context.Background(), 1000); err != nil {
+			t.Fatalf("wait() unexpected error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("15000 bytes at 10000 bytes/s took %v, want about 500ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := l.wait(ctx, 100_000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait() with a done context took %v", elapsed)
+	}
+}
@@ -0,0 +1,168 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// RawHeader holds the header fields of an entry as stored, for debugging
+// archives written by unusual tools.
+type RawHeader struct {
+	// HeaderOffset is the position of the local header in the file.
+	HeaderOffset int64
+	// VersionMadeBy and VersionNeeded come from the central directory;
+	// LocalVersionNeeded repeats the latter in the local header.
+	VersionMadeBy      uint16
+	VersionNeeded      uint16
+	LocalVersionNeeded uint16
+	// CentralExtra and LocalExtra are the extra fields of the central
+	// directory record and of the local header, which often differ.
+	CentralExtra []byte
+	LocalExtra   []byte
+	// Local is the fixed part of the local header, before the name.
+	Local []byte
+}
+
+// ReadRawHeader reads the headers of the entry whose local header starts at
+// headerOffset, as core.ZippedFile.GetHeaderOffset returns it.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - headerOffset: position of the entry's local header in the file
+//
+// Returns:
+//   - RawHeader: the header fields of the entry
+//   - error: no entry starting at headerOffset, an unreadable local
+//     header, or any error reading the archive
+func ReadRawHeader(zipPath string, headerOffset int64) (RawHeader, error) {
+	file, err := os.Open(zipPath)
+	if err != nil {
+		return RawHeader{}, openError(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return RawHeader{}, openError(err)
+	}
+	cd, err := readCentralDirectory(file, info.Size())
+	if err != nil {
+		return RawHeader{}, err
+	}
+
+	for _, rec := range cd.records {
+		if cd.baseOffset+rec.headerOffset != headerOffset {
+			continue
+		}
+		lh, err := readLocalHeader(file, headerOffset)
+		if err != nil {
+			return RawHeader{}, fmt.Errorf("local header at %d: %w", headerOffset, err)
+		}
+		local := make([]byte, localHeaderLen)
+		if _, err := file.ReadAt(local, headerOffset); err != nil {
+			return RawHeader{}, err
+		}
+		return RawHeader{
+			HeaderOffset:       headerOffset,
+			VersionMadeBy:      rec.versionMadeBy,
+			VersionNeeded:      rec.versionNeeded,
+			LocalVersionNeeded: lh.versionNeeded,
+			CentralExtra:       rec.extra,
+			LocalExtra:         lh.extra,
+			Local:              local,
+		}, nil
+	}
+	return RawHeader{}, fmt.Errorf("no entry starts at offset %d", headerOffset)
+}
+
+// hostSystems names the systems of the upper byte of "version made by".
+var hostSystems = map[byte]string{
+	0:  "MS-DOS",
+	1:  "Amiga",
+	2:  "OpenVMS",
+	3:  "Unix",
+	4:  "VM/CMS",
+	5:  "Atari ST",
+	6:  "OS/2",
+	7:  "Macintosh",
+	8:  "Z-System",
+	9:  "CP/M",
+	10: "Windows NTFS",
+	11: "MVS",
+	12: "VSE",
+	13: "Acorn RISC OS",
+	14: "VFAT",
+	15: "alternate MVS",
+	16: "BeOS",
+	17: "Tandem",
+	18: "OS/400",
+	19: "macOS",
+}
+
+// DescribeVersion renders a "version needed to extract" value, such as
+// "2.0" for 20.
+func DescribeVersion(v uint16) string {
+	v &= 0xff
+	return fmt.Sprintf("%d.%d", v/10, v%10)
+}
+
+// DescribeVersionMadeBy renders a "version made by" value: the version of
+// the specification the writer followed and the system it ran on, such as
+// "3.0, Unix".
+func DescribeVersionMadeBy(v uint16) string {
+	host, ok := hostSystems[byte(v>>8)]
+	if !ok {
+		host = fmt.Sprintf("system %d", v>>8)
+	}
+	return DescribeVersion(v) + ", " + host
+}
+
+// ExtraField is a block of an extra field.
+type ExtraField struct {
+	ID   uint16
+	Data []byte
+}
+
+// extraFieldNames describes the extra field blocks goZip knows about, by ID.
+var extraFieldNames = map[uint16]string{
+	zip64ExtraID:          "Zip64",
+	ntfsExtraID:           "NTFS times",
+	unixExtraID:           "Unix",
+	extTimeExtraID:        "extended timestamp",
+	infoZipUnixExtraID:    "Info-ZIP Unix",
+	infoZipNewUnixExtraID: "Info-ZIP Unix owner",
+	unicodePathExtraID:    "Info-ZIP Unicode path",
+	aesExtraID:            "WinZip AES",
+	0xcafe:                "JAR marker",
+	0xd935:                "Android alignment",
+}
+
+// Name describes the block, or gives its ID when unknown.
+func (f ExtraField) Name() string {
+	if name, ok := extraFieldNames[f.ID]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown 0x%04x", f.ID)
+}
+
+// SplitExtra cuts an extra field into its blocks.
+//
+// Parameters:
+//   - extra: an extra field, such as RawHeader.LocalExtra
+//
+// Returns:
+//   - []ExtraField: the blocks, in order
+//   - []byte: the bytes after the last whole block, which should be empty
+func SplitExtra(extra []byte) ([]ExtraField, []byte) {
+	var fields []ExtraField
+	for len(extra) >= 4 {
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if 4+size > len(extra) {
+			break
+		}
+		fields = append(fields, ExtraField{ID: binary.LittleEndian.Uint16(extra), Data: extra[4 : 4+size]})
+		extra = extra[4+size:]
+	}
+	return fields, extra
+}
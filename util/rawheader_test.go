@@ -0,0 +1,62 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestReadRawHeader checks that the headers of an entry are found by
+// offset, past data prepended to the archive
+func TestReadRawHeader(t *testing.T) {
+	zipPath := writeTestZip(t, 16, map[string]string{"a.txt": "alpha", "b.txt": "bravo"})
+	files, err := ListArchive(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := ReadRawHeader(zipPath, files[1].GetHeaderOffset())
+	if err != nil {
+		t.Fatalf("ReadRawHeader() unexpected error = %v", err)
+	}
+	if raw.HeaderOffset != files[1].GetHeaderOffset() || !bytes.HasPrefix(raw.Local, []byte("PK\x03\x04")) || len(raw.Local) != localHeaderLen {
+		t.Errorf("ReadRawHeader() = %+v", raw)
+	}
+	if raw.VersionNeeded != 20 || raw.LocalVersionNeeded != 20 {
+		t.Errorf("versions needed = %d and %d, want 20", raw.VersionNeeded, raw.LocalVersionNeeded)
+	}
+
+	if _, err := ReadRawHeader(zipPath, files[1].GetHeaderOffset()+1); err == nil {
+		t.Error("ReadRawHeader() at no entry expected error, got nil")
+	}
+}
+
+// TestDescribeVersionMadeBy checks the rendering of versions and systems
+func TestDescribeVersionMadeBy(t *testing.T) {
+	tests := map[uint16]string{
+		0x031e: "3.0, Unix",
+		0x0014: "2.0, MS-DOS",
+		0x0a3f: "6.3, Windows NTFS",
+		0x633f: "6.3, system 99",
+	}
+	for v, want := range tests {
+		if got := DescribeVersionMadeBy(v); got != want {
+			t.Errorf("DescribeVersionMadeBy(%#04x) = %q, want %q", v, got, want)
+		}
+	}
+}
+
+// TestSplitExtra checks that blocks are cut apart and leftovers returned
+func TestSplitExtra(t *testing.T) {
+	extra := []byte{0x55, 0x54, 0x05, 0x00, 1, 2, 3, 4, 5, 0x01, 0x00, 0x00, 0x00, 0xff, 0xff}
+
+	fields, rest := SplitExtra(extra)
+	if len(fields) != 2 || fields[0].Name() != "extended timestamp" || len(fields[0].Data) != 5 || fields[1].Name() != "Zip64" || len(fields[1].Data) != 0 {
+		t.Errorf("SplitExtra() fields = %+v", fields)
+	}
+	if !bytes.Equal(rest, []byte{0xff, 0xff}) {
+		t.Errorf("SplitExtra() rest = %x, want ffff", rest)
+	}
+	if name := (ExtraField{ID: 0x1234}).Name(); name != "unknown 0x1234" {
+		t.Errorf("Name() = %q", name)
+	}
+}
@@ -0,0 +1,397 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ZIP record signatures and fixed sizes, as defined by the PKWARE APPNOTE.
+const (
+	sigLocalHeader    = 0x04034b50
+	sigCentralHeader  = 0x02014b50
+	sigDirectoryEnd   = 0x06054b50
+	sigDirectory64End = 0x06064b50
+	sigDirectory64Loc = 0x07064b50
+	sigDataDescriptor = 0x08074b50
+	// sigSplitSingle replaces sigDataDescriptor as the spanning marker of
+	// a split archive that ended up in a single volume.
+	sigSplitSingle = 0x30304b50
+
+	localHeaderLen    = 30
+	centralHeaderLen  = 46
+	directoryEndLen   = 22
+	directory64LocLen = 20
+	directory64EndLen = 56
+
+	zip64ExtraID = 0x0001
+)
+
+var errNoDirectoryEnd = errors.New("end of central directory record not found")
+
+// centralRecord holds the raw central directory fields that archive/zip
+// does not expose.
+type centralRecord struct {
+	name          string
+	versionMadeBy uint16
+	versionNeeded uint16
+	flags         uint16
+	method        uint16
+	modTime       uint16
+	modDate       uint16
+	crc           uint32
+	compressed    uint64
+	uncompressed  uint64
+	extra         []byte
+	comment       string
+	externalAttrs uint32
+	// headerOffset is the local header position as stored in the archive,
+	// not corrected for data prepended to the archive.
+	headerOffset int64
+}
+
+// centralDirectory describes where the central directory lives and its records.
+type centralDirectory struct {
+	// baseOffset is the number of bytes found before the archive proper,
+	// such as a self-extractor stub; stored offsets are relative to it.
+	baseOffset int64
+	// start and end delimit the central directory in the file.
+	start int64
+	end   int64
+	// directoryEnd is the position of the end of central directory record.
+	directoryEnd int64
+	// trailing is the number of bytes after the end of central directory
+	// record and its comment, such as another file concatenated to the
+	// archive.
+	trailing int64
+	zip64    bool
+	comment  string
+	records  []centralRecord
+}
+
+// readCentralDirectory locates and parses the central directory of the ZIP
+// archive in r, which is size bytes long.
+func readCentralDirectory(r io.ReaderAt, size int64) (*centralDirectory, error) {
+	cd, entries, err := locateCentralDirectory(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, cd.end-cd.start)
+	if _, err := r.ReadAt(buf, cd.start); err != nil {
+		return nil, err
+	}
+
+	for len(buf) >= centralHeaderLen && binary.LittleEndian.Uint32(buf) == sigCentralHeader {
+		rec, n, err := parseCentralRecord(buf)
+		if err != nil {
+			return nil, err
+		}
+		cd.records = append(cd.records, rec)
+		buf = buf[n:]
+	}
+
+	if uint64(len(cd.records)) != entries {
+		return nil, errors.New("central directory entry count mismatch")
+	}
+
+	return cd, nil
+}
+
+// locateCentralDirectory reads the end of central directory records of the
+// archive in r and returns where the central directory is, without its
+// records, and the number of entries it declares.
+func locateCentralDirectory(r io.ReaderAt, size int64) (*centralDirectory, uint64, error) {
+	eocdOffset, eocd, err := findDirectoryEnd(r, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cd := &centralDirectory{directoryEnd: eocdOffset}
+	commentLen := int(binary.LittleEndian.Uint16(eocd[20:]))
+	cd.comment = string(eocd[directoryEndLen : directoryEndLen+commentLen])
+	cd.trailing = size - eocdOffset - int64(len(eocd))
+
+	entries := uint64(binary.LittleEndian.Uint16(eocd[10:]))
+	dirSize := int64(binary.LittleEndian.Uint32(eocd[12:]))
+	dirOffset := int64(binary.LittleEndian.Uint32(eocd[16:]))
+	dirEnd := eocdOffset
+
+	if entries == 0xffff || dirSize == 0xffffffff || dirOffset == 0xffffffff {
+		if rec64, recOffset, err := readDirectory64End(r, eocdOffset); err == nil {
+			cd.zip64 = true
+			entries = binary.LittleEndian.Uint64(rec64[32:])
+			dirSize = int64(binary.LittleEndian.Uint64(rec64[40:]))
+			dirOffset = int64(binary.LittleEndian.Uint64(rec64[48:]))
+			dirEnd = recOffset
+		}
+	}
+
+	cd.baseOffset = dirEnd - dirSize - dirOffset
+	if cd.baseOffset < 0 {
+		cd.baseOffset = 0
+	}
+	cd.start = cd.baseOffset + dirOffset
+	cd.end = cd.start + dirSize
+	if cd.start < 0 || cd.end > size {
+		return nil, 0, errors.New("central directory is outside the file")
+	}
+
+	return cd, entries, nil
+}
+
+// directoryEndSearch is how far back from the end of file the end of
+// central directory record can be when nothing follows the archive: the
+// record itself and the longest comment.
+const directoryEndSearch = directoryEndLen + 0xffff
+
+// directoryEndChunk is the size of the blocks read while searching the
+// rest of the file for an archive followed by other data.
+const directoryEndChunk = 1 << 20
+
+// findDirectoryEnd returns the offset and bytes (comment included) of the
+// end of central directory record, searching backwards from the end of file.
+// Data appended to the archive is skipped: past the last 64 KiB the whole
+// file is searched, and a record counts only when the central directory it
+// describes is where it says. When none does, the last record found near the
+// end of file is returned, so a damaged archive is still reported as such.
+func findDirectoryEnd(r io.ReaderAt, size int64) (int64, []byte, error) {
+	var fallback []byte
+	fallbackOffset := int64(-1)
+
+	// Records may start in [lo, hi); buf reaches past hi so that one
+	// starting right before it is read whole.
+	hi := size
+	step := int64(directoryEndSearch)
+	for hi > 0 {
+		lo := max(hi-step, 0)
+		buf := make([]byte, min(hi+directoryEndSearch, size)-lo)
+		if _, err := r.ReadAt(buf, lo); err != nil && err != io.EOF {
+			return 0, nil, err
+		}
+
+		for i := int(hi-lo) - 1; i >= 0; i-- {
+			if i+directoryEndLen > len(buf) || binary.LittleEndian.Uint32(buf[i:]) != sigDirectoryEnd {
+				continue
+			}
+			commentLen := int(binary.LittleEndian.Uint16(buf[i+20:]))
+			if i+directoryEndLen+commentLen > len(buf) {
+				continue
+			}
+			rec := buf[i : i+directoryEndLen+commentLen]
+			if locatesDirectory(r, lo+int64(i), rec) {
+				return lo + int64(i), rec, nil
+			}
+			if fallback == nil && hi == size {
+				fallback, fallbackOffset = rec, lo+int64(i)
+			}
+		}
+
+		hi = lo
+		step = directoryEndChunk
+	}
+
+	if fallback != nil {
+		return fallbackOffset, fallback, nil
+	}
+	return 0, nil, errNoDirectoryEnd
+}
+
+// locatesDirectory tells whether the end of central directory record rec,
+// found at offset, is right after the central directory it describes. A
+// record preceded by a Zip64 locator is taken at its word, the sizes that
+// count being in the Zip64 record.
+func locatesDirectory(r io.ReaderAt, offset int64, rec []byte) bool {
+	entries := binary.LittleEndian.Uint16(rec[10:])
+	dirSize := binary.LittleEndian.Uint32(rec[12:])
+	if signatureAt(r, offset-directory64LocLen, sigDirectory64Loc) {
+		return true
+	}
+	if entries == 0 {
+		return dirSize == 0
+	}
+	return signatureAt(r, offset-int64(dirSize), sigCentralHeader)
+}
+
+// signatureAt tells whether the record at offset in r starts with sig.
+func signatureAt(r io.ReaderAt, offset int64, sig uint32) bool {
+	if offset < 0 {
+		return false
+	}
+	buf := make([]byte, 4)
+	if _, err := r.ReadAt(buf, offset); err != nil {
+		return false
+	}
+	return binary.LittleEndian.Uint32(buf) == sig
+}
+
+// readDirectory64End reads the Zip64 end of central directory record that
+// precedes the locator right before eocdOffset.
+func readDirectory64End(r io.ReaderAt, eocdOffset int64) ([]byte, int64, error) {
+	locOffset := eocdOffset - directory64LocLen
+	if locOffset < 0 {
+		return nil, 0, errors.New("missing zip64 locator")
+	}
+
+	loc := make([]byte, directory64LocLen)
+	if _, err := r.ReadAt(loc, locOffset); err != nil {
+		return nil, 0, err
+	}
+	if binary.LittleEndian.Uint32(loc) != sigDirectory64Loc {
+		return nil, 0, errors.New("missing zip64 locator")
+	}
+
+	// The stored offset ignores prepended data, so fall back to the
+	// position right before the locator when it does not point at a record.
+	candidates := []int64{int64(binary.LittleEndian.Uint64(loc[8:])), locOffset - directory64EndLen}
+	rec := make([]byte, directory64EndLen)
+	for _, off := range candidates {
+		if off < 0 {
+			continue
+		}
+		if _, err := r.ReadAt(rec, off); err != nil {
+			continue
+		}
+		if binary.LittleEndian.Uint32(rec) == sigDirectory64End {
+			return rec, off, nil
+		}
+	}
+
+	return nil, 0, errors.New("missing zip64 end of central directory record")
+}
+
+// parseCentralRecord decodes one central directory header from buf and
+// returns it together with the number of bytes it occupies.
+func parseCentralRecord(buf []byte) (centralRecord, int, error) {
+	le := binary.LittleEndian
+	nameLen := int(le.Uint16(buf[28:]))
+	extraLen := int(le.Uint16(buf[30:]))
+	commentLen := int(le.Uint16(buf[32:]))
+	n := centralHeaderLen + nameLen + extraLen + commentLen
+	if n > len(buf) {
+		return centralRecord{}, 0, errors.New("truncated central directory header")
+	}
+
+	rec := centralRecord{
+		versionMadeBy: le.Uint16(buf[4:]),
+		versionNeeded: le.Uint16(buf[6:]),
+		flags:         le.Uint16(buf[8:]),
+		method:        le.Uint16(buf[10:]),
+		modTime:       le.Uint16(buf[12:]),
+		modDate:       le.Uint16(buf[14:]),
+		crc:           le.Uint32(buf[16:]),
+		compressed:    uint64(le.Uint32(buf[20:])),
+		uncompressed:  uint64(le.Uint32(buf[24:])),
+		externalAttrs: le.Uint32(buf[38:]),
+		headerOffset:  int64(le.Uint32(buf[42:])),
+	}
+
+	rest := buf[centralHeaderLen:n]
+	rec.name = string(rest[:nameLen])
+	rec.extra = rest[nameLen : nameLen+extraLen]
+	rec.comment = string(rest[nameLen+extraLen:])
+
+	// Values saturated at 0xffffffff are found, in order, in the Zip64 extra field.
+	if data, ok := findExtraField(rec.extra, zip64ExtraID); ok {
+		if rec.uncompressed == 0xffffffff && len(data) >= 8 {
+			rec.uncompressed = le.Uint64(data)
+			data = data[8:]
+		}
+		if rec.compressed == 0xffffffff && len(data) >= 8 {
+			rec.compressed = le.Uint64(data)
+			data = data[8:]
+		}
+		if rec.headerOffset == 0xffffffff && len(data) >= 8 {
+			rec.headerOffset = int64(le.Uint64(data))
+		}
+	}
+
+	return rec, n, nil
+}
+
+// findExtraField returns the payload of the first extra field block with the given id.
+func findExtraField(extra []byte, id uint16) ([]byte, bool) {
+	for len(extra) >= 4 {
+		tag := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if 4+size > len(extra) {
+			return nil, false
+		}
+		if tag == id {
+			return extra[4 : 4+size], true
+		}
+		extra = extra[4+size:]
+	}
+
+	return nil, false
+}
+
+// localHeader holds the fields of a local file header.
+type localHeader struct {
+	versionNeeded uint16
+	flags         uint16
+	method        uint16
+	crc           uint32
+	compressed    uint32
+	uncompressed  uint32
+	name          string
+	extra         []byte
+}
+
+// readLocalHeader reads the local file header at off.
+func readLocalHeader(r io.ReaderAt, off int64) (localHeader, error) {
+	buf := make([]byte, localHeaderLen)
+	if _, err := r.ReadAt(buf, off); err != nil {
+		return localHeader{}, err
+	}
+
+	le := binary.LittleEndian
+	if le.Uint32(buf) != sigLocalHeader {
+		return localHeader{}, errors.New("bad local header signature")
+	}
+
+	h := localHeader{
+		versionNeeded: le.Uint16(buf[4:]),
+		flags:         le.Uint16(buf[6:]),
+		method:        le.Uint16(buf[8:]),
+		crc:           le.Uint32(buf[14:]),
+		compressed:    le.Uint32(buf[18:]),
+		uncompressed:  le.Uint32(buf[22:]),
+	}
+
+	nameLen := int(le.Uint16(buf[26:]))
+	extraLen := int(le.Uint16(buf[28:]))
+	rest := make([]byte, nameLen+extraLen)
+	if _, err := r.ReadAt(rest, off+localHeaderLen); err != nil {
+		return localHeader{}, err
+	}
+	h.name = string(rest[:nameLen])
+	h.extra = rest[nameLen:]
+
+	return h, nil
+}
+
+// size returns the number of bytes the local header occupies.
+func (h localHeader) size() int64 {
+	return localHeaderLen + int64(len(h.name)) + int64(len(h.extra))
+}
+
+// dataDescriptorLen returns the length of the data descriptor that follows
+// the compressed data of rec at dataEnd, or 0 when there is none.
+func dataDescriptorLen(r io.ReaderAt, rec centralRecord, dataEnd int64) int64 {
+	if rec.flags&0x8 == 0 {
+		return 0
+	}
+
+	n := int64(12)
+	if _, ok := findExtraField(rec.extra, zip64ExtraID); ok {
+		n = 20
+	}
+
+	sig := make([]byte, 4)
+	if _, err := r.ReadAt(sig, dataEnd); err == nil && binary.LittleEndian.Uint32(sig) == sigDataDescriptor {
+		n += 4
+	}
+
+	return n
+}
@@ -0,0 +1,98 @@
+package util
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RenameEntry renames a file, or moves it to another folder, inside the
+// archive at zipPath. Renaming a folder moves everything inside it.
+//
+// The new name is normalized to a relative slash-separated path; a folder
+// keeps its trailing slash. A file given a new name ending in a slash is
+// moved into that folder under its current base name. Renaming fails if
+// any resulting name would collide with an entry that is not being renamed.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - oldName: current name of the file or folder (as it appears in the ZIP)
+//   - newName: new name or path for it
+//
+// Returns:
+//   - int: number of entries renamed
+//   - error: any error encountered; the archive is unchanged on failure
+func RenameEntry(zipPath, oldName, newName string) (int, error) {
+	newName = normalizeEntryName(newName)
+	if newName == "" {
+		return 0, errors.New("invalid new name")
+	}
+
+	oldPrefix := strings.TrimSuffix(oldName, "/") + "/"
+
+	var renamed int
+
+	err := rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		isFolder := strings.HasSuffix(oldName, "/")
+		for _, f := range r.File {
+			if strings.HasPrefix(f.Name, oldPrefix) {
+				isFolder = true
+				break
+			}
+		}
+
+		newPrefix := strings.TrimSuffix(newName, "/") + "/"
+		if isFolder && strings.HasPrefix(newPrefix, oldPrefix) {
+			return errors.New("cannot move a folder inside itself")
+		}
+
+		renameTo := func(name string) (string, bool) {
+			switch {
+			case name == oldName && !isFolder:
+				if strings.HasSuffix(newName, "/") {
+					return newName + path.Base(oldName), true
+				}
+				return newName, true
+			case isFolder && (name == oldPrefix || name == strings.TrimSuffix(oldPrefix, "/")):
+				return newPrefix, true
+			case isFolder && strings.HasPrefix(name, oldPrefix):
+				return newPrefix + strings.TrimPrefix(name, oldPrefix), true
+			}
+			return name, false
+		}
+
+		kept := make(map[string]bool)
+		for _, f := range r.File {
+			if _, ok := renameTo(f.Name); !ok {
+				kept[f.Name] = true
+			}
+		}
+
+		for _, f := range r.File {
+			name, ok := renameTo(f.Name)
+			if ok {
+				if kept[name] {
+					return fmt.Errorf("'%s' already exists in the archive", name)
+				}
+				renamed++
+			}
+
+			if err := copyEntry(w, f, name); err != nil {
+				return err
+			}
+		}
+
+		if renamed == 0 {
+			return entryNotFound("file or folder", oldName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return renamed, nil
+}
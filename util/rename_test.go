@@ -0,0 +1,123 @@
+package util
+
+import (
+	"slices"
+	"testing"
+)
+
+func entryNames(t *testing.T, zipPath string) []string {
+	t.Helper()
+
+	content, err := ListArchive(zipPath)
+	if err != nil {
+		t.Fatalf("ListArchive() unexpected error = %v", err)
+	}
+
+	names := make([]string, 0, len(content))
+	for _, zf := range content {
+		names = append(names, zf.GetName())
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// TestRenameEntry checks renaming files and moving folders with their contents
+func TestRenameEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldName   string
+		newName   string
+		wantCount int
+		want      []string
+	}{
+		{
+			name:      "rename file",
+			oldName:   "a.txt",
+			newName:   "renamed.txt",
+			wantCount: 1,
+			want:      []string{"dir/", "dir/b.txt", "dir/sub/c.txt", "renamed.txt"},
+		},
+		{
+			name:      "move file into folder",
+			oldName:   "a.txt",
+			newName:   "dir/sub/",
+			wantCount: 1,
+			want:      []string{"dir/", "dir/b.txt", "dir/sub/a.txt", "dir/sub/c.txt"},
+		},
+		{
+			name:      "absolute target path is made relative",
+			oldName:   "a.txt",
+			newName:   "/dir/sub/a.txt",
+			wantCount: 1,
+			want:      []string{"dir/", "dir/b.txt", "dir/sub/a.txt", "dir/sub/c.txt"},
+		},
+		{
+			name:      "rename folder",
+			oldName:   "dir/",
+			newName:   "other",
+			wantCount: 3,
+			want:      []string{"a.txt", "other/", "other/b.txt", "other/sub/c.txt"},
+		},
+		{
+			name:      "rename implicit folder",
+			oldName:   "dir/sub/",
+			newName:   "top/",
+			wantCount: 1,
+			want:      []string{"a.txt", "dir/", "dir/b.txt", "top/c.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zipPath := writeTestZip(t, 0, map[string]string{
+				"a.txt":         "a",
+				"dir/":          "",
+				"dir/b.txt":     "b",
+				"dir/sub/c.txt": "c",
+			})
+
+			count, err := RenameEntry(zipPath, tt.oldName, tt.newName)
+			if err != nil {
+				t.Fatalf("RenameEntry() unexpected error = %v", err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("RenameEntry() count = %d, want %d", count, tt.wantCount)
+			}
+			if got := entryNames(t, zipPath); !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenameEntryErrors checks collisions and invalid targets
+func TestRenameEntryErrors(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"a.txt":     "a",
+		"dir/b.txt": "b",
+	})
+
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+	}{
+		{name: "collision", oldName: "a.txt", newName: "dir/b.txt"},
+		{name: "missing entry", oldName: "missing.txt", newName: "x.txt"},
+		{name: "empty name", oldName: "a.txt", newName: "../"},
+		{name: "folder into itself", oldName: "dir/", newName: "dir/inner/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RenameEntry(zipPath, tt.oldName, tt.newName); err == nil {
+				t.Errorf("RenameEntry(%q, %q) expected error, got nil", tt.oldName, tt.newName)
+			}
+		})
+	}
+
+	if got := entryNames(t, zipPath); !slices.Equal(got, []string{"a.txt", "dir/b.txt"}) {
+		t.Errorf("entries changed after failed renames: %v", got)
+	}
+}
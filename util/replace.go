@@ -0,0 +1,66 @@
+package util
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ReplaceEntry replaces the content of a file inside the archive at zipPath
+// with the file at srcPath, for instance to re-add a newer version of it.
+//
+// The entry keeps its name and position and takes the modification time and
+// permissions of srcPath. It is stored when it was stored before and
+// deflated otherwise. Every other entry is copied without recompression.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - entryName: name of the file to replace (as it appears in the ZIP)
+//   - srcPath: file on disk holding the new content
+//
+// Returns:
+//   - error: any error encountered; the archive is unchanged on failure
+func ReplaceEntry(zipPath, entryName, srcPath string) error {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("'%s' is not a regular file", srcPath)
+	}
+
+	return rewriteArchive(zipPath, func(r *zip.Reader, w *zip.Writer) error {
+		var replaced bool
+		for _, f := range r.File {
+			if f.Name != entryName {
+				if err := copyEntry(w, f, f.Name); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if f.FileInfo().IsDir() {
+				return errors.New("only files can be replaced, not folders")
+			}
+
+			method := uint16(zip.Deflate)
+			if f.Method == zip.Store {
+				method = zip.Store
+			}
+
+			e := sourceEntry{diskPath: srcPath, name: f.Name, info: info}
+			err := addSource(w, e, func(string, int64) uint16 { return method }, false)
+			if err != nil {
+				return fmt.Errorf("failed to add %s: %w", srcPath, err)
+			}
+			replaced = true
+		}
+
+		if !replaced {
+			return entryNotFound("file", entryName)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestReplaceEntry checks that only the target entry changes and keeps its place
+func TestReplaceEntry(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"a.txt":     "old a",
+		"b.png":     "old b",
+		"dir/c.txt": "c",
+	}, "b.png")
+
+	src := filepath.Join(t.TempDir(), "new.png")
+	if err := os.WriteFile(src, []byte("brand new b"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	if err := ReplaceEntry(zipPath, "b.png", src); err != nil {
+		t.Fatalf("ReplaceEntry() unexpected error = %v", err)
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer r.Close()
+
+	var names []string
+	got := make(map[string]string)
+	for _, f := range r.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) unexpected error = %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(data)
+
+		if f.Name == "b.png" && f.Method != zip.Store {
+			t.Errorf("replaced entry method = %d, want stored", f.Method)
+		}
+	}
+
+	if want := []string{"a.txt", "b.png", "dir/c.txt"}; !slices.Equal(names, want) {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+	if got["b.png"] != "brand new b" || got["a.txt"] != "old a" {
+		t.Errorf("contents after replace = %v", got)
+	}
+}
+
+// TestReplaceEntryErrors checks the cases that must leave the archive alone
+func TestReplaceEntryErrors(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "a", "dir/": ""})
+	src := filepath.Join(t.TempDir(), "new.txt")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		entry string
+		src   string
+	}{
+		{name: "missing entry", entry: "missing.txt", src: src},
+		{name: "folder entry", entry: "dir/", src: src},
+		{name: "missing source", entry: "a.txt", src: filepath.Join(t.TempDir(), "nope")},
+		{name: "directory source", entry: "a.txt", src: t.TempDir()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ReplaceEntry(zipPath, tt.entry, tt.src); err == nil {
+				t.Error("ReplaceEntry() expected error, got nil")
+			}
+			if got := entryNames(t, zipPath); !slices.Equal(got, []string{"a.txt", "dir/"}) {
+				t.Errorf("entries = %v, want unchanged", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package util
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+)
+
+// resumeRecord is one line of a resume manifest: an entry that was fully
+// extracted, and the file it produced as it was right after writing it.
+type resumeRecord struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    uint64 `json:"size"`
+	CRC     uint32 `json:"crc"`
+	ModTime int64  `json:"mtime"`
+}
+
+// resumeManifest tracks the progress of an extraction with
+// ExtractOptions.Resume. It is a JSON-lines file in the destination
+// directory, one line per completed entry, so recording progress costs one
+// small append per file and a manifest cut short by a crash is still usable.
+type resumeManifest struct {
+	path string
+	done map[string]resumeRecord
+	out  *os.File
+}
+
+// resumeManifestPath returns the manifest used when extracting zipPath into
+// destDir. The name depends on the archive's absolute path, so extracting
+// several archives into the same directory keeps their progress apart.
+func resumeManifestPath(zipPath, destDir string) (string, error) {
+	abs, err := filepath.Abs(zipPath)
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(abs))
+	return filepath.Join(destDir, fmt.Sprintf(".gozip-resume-%016x.jsonl", h.Sum64())), nil
+}
+
+// openResumeManifest loads the progress left by an earlier, interrupted
+// extraction of zipPath into destDir, if any, and opens the manifest for
+// appending. A malformed line, such as one cut short by a crash, ends the
+// loading; the entries it described are simply extracted again.
+func openResumeManifest(zipPath, destDir string) (*resumeManifest, error) {
+	p, err := resumeManifestPath(zipPath, destDir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &resumeManifest{path: p, done: make(map[string]resumeRecord)}
+
+	if in, err := os.Open(p); err == nil {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			var rec resumeRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				break
+			}
+			m.done[rec.Name] = rec
+		}
+		in.Close()
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	m.out, err = os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open resume manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// completed reports whether t was extracted to destPath by an earlier run:
+// the entry must have the size and CRC recorded then, and the file on disk
+// must still have the size and modification time it had after writing.
+func (m *resumeManifest) completed(t extractTarget, destPath string) bool {
+	rec, ok := m.done[t.name]
+	if !ok || rec.Path != t.relPath || rec.Size != t.file.UncompressedSize64 || rec.CRC != t.file.CRC32 {
+		return false
+	}
+
+	info, err := os.Stat(destPath)
+	return err == nil && uint64(info.Size()) == rec.Size && info.ModTime().UnixNano() == rec.ModTime
+}
+
+// record appends t, just extracted to destPath, to the manifest.
+func (m *resumeManifest) record(t extractTarget, destPath string) error {
+	info, err := os.Stat(destPath)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(resumeRecord{
+		Name:    t.name,
+		Path:    t.relPath,
+		Size:    t.file.UncompressedSize64,
+		CRC:     t.file.CRC32,
+		ModTime: info.ModTime().UnixNano(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.out.Write(append(line, '\n'))
+	return err
+}
+
+// close closes the manifest. Once the extraction succeeded there is nothing
+// left to resume, so the manifest is deleted.
+func (m *resumeManifest) close(succeeded bool) {
+	m.out.Close()
+	if succeeded {
+		os.Remove(m.path)
+	}
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestExtractResume checks that a resumed extraction skips the files an
+// interrupted run completed, unless they changed on disk since
+func TestExtractResume(t *testing.T) {
+	zipPath := writeCorruptZip(t, map[string]string{"good.txt": "good"}, "bad.txt")
+	destDir := t.TempDir()
+	opts := ExtractOptions{Resume: true}
+
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, opts)
+	if err == nil || count != 1 {
+		t.Fatalf("first run = %d, %v, want 1 file and an error", count, err)
+	}
+	manifest, _ := resumeManifestPath(zipPath, destDir)
+	if _, err := os.Stat(manifest); err != nil {
+		t.Fatalf("manifest missing after an interrupted run: %v", err)
+	}
+
+	count, err = ExtractWithOptions(context.Background(), zipPath, "", destDir, opts)
+	if err == nil || count != 0 {
+		t.Errorf("resumed run = %d, %v, want good.txt skipped", count, err)
+	}
+
+	good := filepath.Join(destDir, "good.txt")
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(good, later, later)
+	count, _ = ExtractWithOptions(context.Background(), zipPath, "", destDir, opts)
+	if count != 1 {
+		t.Errorf("run after touching good.txt = %d files, want it extracted again", count)
+	}
+
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Resume: true, RemoveOnFailure: true}); err == nil {
+		t.Error("Resume with RemoveOnFailure expected an error")
+	}
+}
+
+// TestExtractResumeRemovesManifest checks that a completed extraction leaves no manifest behind
+func TestExtractResumeRemovesManifest(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "a", "dir/b.txt": "b"})
+	destDir := filepath.Join(t.TempDir(), "out")
+
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Resume: true})
+	if err != nil || count != 2 {
+		t.Fatalf("ExtractWithOptions(Resume) = %d, %v, want 2 files", count, err)
+	}
+
+	entries, _ := os.ReadDir(destDir)
+	if len(entries) != 2 {
+		t.Errorf("destination holds %v, want only the extracted files", entries)
+	}
+}
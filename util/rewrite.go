@@ -0,0 +1,137 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// rewriteArchive rebuilds the archive at zipPath through fn, which reads the
+// current entries from r and writes the ones to keep to w.
+//
+// The new archive replaces the original atomically, as described in
+// writeArchiveAtomic; on any failure the original is left exactly as it was.
+// fn sees the entry names converted to UTF-8, as they are listed, so the
+// rewritten archive stores them as UTF-8.
+func rewriteArchive(zipPath string, fn func(r *zip.Reader, w *zip.Writer) error) error {
+	// Insecure names are allowed here: fixing them is one reason to rewrite.
+	reader, err := openZipReader(zipPath)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	info, err := os.Stat(zipPath)
+	if err != nil {
+		return err
+	}
+
+	return writeArchiveAtomic(zipPath, info.Mode().Perm(), func(w *zip.Writer) error {
+		// Close the original as soon as its entries are copied: some
+		// platforms refuse to rename over a file that is still open.
+		defer reader.Close()
+
+		if err := w.SetComment(reader.Comment); err != nil {
+			return err
+		}
+		return fn(reader.Reader, w)
+	})
+}
+
+// writeArchiveAtomic writes a new archive at zipPath through fn.
+//
+// The archive is written to a temporary file next to zipPath, re-opened to
+// check it is readable, and only then renamed over zipPath in a single
+// atomic step. On any failure the temporary file is discarded and whatever
+// was at zipPath is left untouched.
+func writeArchiveAtomic(zipPath string, perm os.FileMode, fn func(w *zip.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(zipPath), "."+filepath.Base(zipPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	w := zip.NewWriter(tmp)
+	err = fn(w)
+	if err == nil {
+		err = w.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = verifyArchive(tmp.Name())
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), zipPath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
+
+// verifyArchive checks that the archive at zipPath can be opened again.
+func verifyArchive(zipPath string) error {
+	reader, err := openZipReader(zipPath)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return fmt.Errorf("rewritten archive is not readable: %w", err)
+	}
+
+	return reader.Close()
+}
+
+// copyEntry copies f to w without recompressing it, storing it under name.
+func copyEntry(w *zip.Writer, f *zip.File, name string) error {
+	header := f.FileHeader
+	header.Name = name
+	// CreateRaw writes the flags as given: mark names that need UTF-8, such
+	// as decoded legacy names, as UTF-8.
+	if !isASCII(name) && utf8.ValidString(name) {
+		header.Flags |= 0x800
+	}
+	// archive/zip adds its own Zip64 field when needed; keeping the old one
+	// would leave two of them in the rewritten headers.
+	header.Extra = stripExtraField(header.Extra, zip64ExtraID)
+
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return err
+	}
+
+	fw, err := w.CreateRaw(&header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, raw)
+	return err
+}
+
+// stripExtraField returns extra without the blocks whose id matches.
+func stripExtraField(extra []byte, id uint16) []byte {
+	var out []byte
+	for len(extra) >= 4 {
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if 4+size > len(extra) {
+			break
+		}
+		if binary.LittleEndian.Uint16(extra) != id {
+			out = append(out, extra[:4+size]...)
+		}
+		extra = extra[4+size:]
+	}
+
+	return out
+}
@@ -0,0 +1,149 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ScanPolicy decides what extraction does with the files a ContentScanner
+// flags.
+type ScanPolicy string
+
+const (
+	// ScanBlock does not write flagged files: their extraction fails with
+	// an error wrapping ErrFlagged.
+	ScanBlock ScanPolicy = "block"
+	// ScanWarn writes flagged files anyway, and only tells the observer,
+	// see ScanObserver.
+	ScanWarn ScanPolicy = "warn"
+)
+
+// ParseScanPolicy validates a policy name; "" is ScanBlock.
+func ParseScanPolicy(s string) (ScanPolicy, error) {
+	switch policy := ScanPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case "":
+		return ScanBlock, nil
+	case ScanBlock, ScanWarn:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid scanner policy %q (want block or warn)", s)
+	}
+}
+
+// ErrFlagged is wrapped by the error of a file not extracted because the
+// content scanner flagged it.
+var ErrFlagged = errors.New("flagged by the content scanner")
+
+// scanEntryEnv is the environment variable holding the name of the entry
+// being scanned.
+const scanEntryEnv = "GOZIP_ENTRY"
+
+// ContentScanner is a command, such as "clamscan --no-summary -", every file
+// is piped through before it is extracted, for archives from untrusted
+// sources. It runs through the shell, like the extraction hooks, with the
+// file's content on its standard input and the entry name in $GOZIP_ENTRY;
+// {} in the command stands for it. The name comes from the archive, so it
+// never goes on the command line as is: {} is replaced by "$GOZIP_ENTRY"
+// for sh, and by the name escaped for cmd on Windows.
+// Exit status 0 means clean and 1 flagged, as with clamscan and most
+// scanners; any other status is a failure of the scanner, and the file is
+// not extracted since it could not be checked.
+type ContentScanner struct {
+	// Command is the command line; "" scans nothing.
+	Command string
+	// Policy is what happens to flagged files; "" means ScanBlock.
+	Policy ScanPolicy
+}
+
+// IsEmpty reports whether no scanner is configured.
+func (s ContentScanner) IsEmpty() bool {
+	return s.Command == ""
+}
+
+// ScanObserver is implemented by the observers that also want to know about
+// the files the content scanner flags. The extraction looks for it on
+// ExtractOptions.Observer.
+type ScanObserver interface {
+	// OnFlagged is called before OnEntryStart, or OnError when the policy
+	// blocks the file; report is the first line the scanner printed, such
+	// as "stdin: Eicar-Signature FOUND".
+	OnFlagged(name, report string)
+}
+
+// check scans the file of t, when a scanner is configured, and tells
+// observer, if not nil, when it is flagged. It returns what stops the file
+// from being extracted: a failure of the scanner, or, under ScanBlock, an
+// error wrapping ErrFlagged.
+func (s ContentScanner) check(ctx context.Context, t extractTarget, observer ScanObserver) error {
+	if s.IsEmpty() {
+		return nil
+	}
+	flagged, report, err := s.scan(ctx, t.file, t.name)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", t.name, err)
+	}
+	if !flagged {
+		return nil
+	}
+
+	Logger().Warn("flagged file", "name", t.name, "report", report, "policy", s.Policy)
+	if observer != nil {
+		observer.OnFlagged(t.name, report)
+	}
+	switch {
+	case s.Policy == ScanWarn:
+		return nil
+	case report == "":
+		return fmt.Errorf("%s %w", t.name, ErrFlagged)
+	default:
+		return fmt.Errorf("%s %w: %s", t.name, ErrFlagged, report)
+	}
+}
+
+// scan pipes the content of f through the scanner.
+//
+// Returns:
+//   - bool: whether the scanner flagged the file
+//   - string: the first line it printed
+//   - error: the entry could not be read or the scanner failed
+func (s ContentScanner) scan(ctx context.Context, f *zip.File, name string) (bool, string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return false, "", err
+	}
+	defer rc.Close()
+
+	var output bytes.Buffer
+	entry := `"$` + scanEntryEnv + `"`
+	if runtime.GOOS == "windows" {
+		// cmd parses the value of %GOZIP_ENTRY% again once expanded.
+		entry = cmdQuote(name)
+	}
+	cmd := shellCommand(ctx, strings.ReplaceAll(s.Command, "{}", entry))
+	cmd.Env = append(os.Environ(), scanEntryEnv+"="+name)
+	cmd.Stdin = rc
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+
+	report, _, _ := strings.Cut(strings.TrimSpace(output.String()), "\n")
+	report = strings.TrimSpace(report)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return false, report, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return true, report, nil
+	case report != "":
+		return false, "", fmt.Errorf("scanner %q: %w: %s", s.Command, err, report)
+	default:
+		return false, "", fmt.Errorf("scanner %q: %w", s.Command, err)
+	}
+}
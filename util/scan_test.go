@@ -0,0 +1,142 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// scanRecorder records the files an extraction reports as flagged.
+type scanRecorder struct {
+	NopExtractObserver
+	flagged map[string]string
+}
+
+func (r *scanRecorder) OnFlagged(name, report string) {
+	r.flagged[name] = report
+}
+
+// testScanner flags the files whose content contains EVIL, printing a
+// report like clamscan's, and fails on those containing BROKEN.
+const testScanner = `content=$(cat); case "$content" in *EVIL*) echo "stdin: Test-Signature FOUND"; exit 1;; *BROKEN*) echo "cannot scan" >&2; exit 2;; esac`
+
+// TestParseScanPolicy checks the accepted policy names
+func TestParseScanPolicy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ScanPolicy
+		wantErr bool
+	}{
+		{in: "", want: ScanBlock},
+		{in: "block", want: ScanBlock},
+		{in: " WARN ", want: ScanWarn},
+		{in: "delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScanPolicy(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseScanPolicy(%q) = %q, %v, want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestExtractScannerBlock checks that flagged files are not written and the
+// others are
+func TestExtractScannerBlock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the scanner runs through sh in this test")
+	}
+	zipPath := writeTestZip(t, 0, map[string]string{"clean.txt": "hello", "bad.exe": "some EVIL bytes"})
+	rec := &scanRecorder{flagged: map[string]string{}}
+	destDir := t.TempDir()
+
+	opts := ExtractOptions{Scanner: ContentScanner{Command: testScanner}, Observer: rec, ContinueOnError: true}
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, opts)
+	if !errors.Is(err, ErrFlagged) || !strings.Contains(err.Error(), "Test-Signature FOUND") {
+		t.Errorf("ExtractWithOptions() error = %v, want ErrFlagged with the report", err)
+	}
+	if count != 1 {
+		t.Errorf("ExtractWithOptions() extracted %d files, want 1", count)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "bad.exe")); err == nil {
+		t.Error("the flagged file was written")
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "clean.txt")); err != nil {
+		t.Errorf("the clean file was not written: %v", err)
+	}
+	if report := rec.flagged["bad.exe"]; report != "stdin: Test-Signature FOUND" || len(rec.flagged) != 1 {
+		t.Errorf("flagged = %q", rec.flagged)
+	}
+}
+
+// TestExtractScannerWarn checks that flagged files are written under the
+// warn policy, and reported
+func TestExtractScannerWarn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the scanner runs through sh in this test")
+	}
+	zipPath := writeTestZip(t, 0, map[string]string{"bad.exe": "EVIL"})
+	rec := &scanRecorder{flagged: map[string]string{}}
+	destDir := t.TempDir()
+
+	opts := ExtractOptions{Scanner: ContentScanner{Command: testScanner, Policy: ScanWarn}, Observer: rec}
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, opts); err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "bad.exe")); err != nil {
+		t.Errorf("the flagged file was not written: %v", err)
+	}
+	if _, ok := rec.flagged["bad.exe"]; !ok {
+		t.Error("the flagged file was not reported")
+	}
+}
+
+// TestExtractScannerFailure checks that a file the scanner fails on is not
+// written
+func TestExtractScannerFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the scanner runs through sh in this test")
+	}
+	zipPath := writeTestZip(t, 0, map[string]string{"odd.bin": "BROKEN"})
+	destDir := t.TempDir()
+
+	opts := ExtractOptions{Scanner: ContentScanner{Command: testScanner, Policy: ScanWarn}}
+	_, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, opts)
+	if err == nil || errors.Is(err, ErrFlagged) || !strings.Contains(err.Error(), "cannot scan") {
+		t.Errorf("ExtractWithOptions() error = %v, want the scanner failure", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "odd.bin")); err == nil {
+		t.Error("a file the scanner failed on was written")
+	}
+}
+
+// TestExtractScannerHostileName checks that an entry name made to break
+// out of the quotes reaches the scanner unchanged and runs nothing
+func TestExtractScannerHostileName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the scanner runs through sh in this test")
+	}
+	names := []string{"x'; touch pwned; '.txt", "a$(touch pwned)b.txt", "`touch pwned`.txt", `x" & touch pwned & ".txt`}
+	for _, name := range names {
+		zipPath := writeTestZip(t, 0, map[string]string{name: "hello"})
+		rec := &scanRecorder{flagged: map[string]string{}}
+		workDir := t.TempDir()
+
+		command := "cd " + posixQuote(workDir) + ` && echo {} "$GOZIP_ENTRY"; exit 1`
+		opts := ExtractOptions{Scanner: ContentScanner{Command: command, Policy: ScanWarn}, Observer: rec}
+		if _, err := ExtractWithOptions(context.Background(), zipPath, "", t.TempDir(), opts); err != nil {
+			t.Fatalf("ExtractWithOptions(%q) unexpected error = %v", name, err)
+		}
+		if report := rec.flagged[name]; report != name+" "+name {
+			t.Errorf("the scanner of %q printed %q", name, report)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, "pwned")); err == nil {
+			t.Errorf("the name %q ran a command", name)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// ArchiveHandler serves the files of an archive, opened with
+// core.ArchiveFS, over HTTP: folders as a browsable index, files with
+// their content type, guessed from the extension or the content, and
+// range requests, so media can be seeked and downloads resumed.
+//
+// Parameters:
+//   - fsys: the archive's contents
+//   - title: the name shown atop the index pages, usually the archive's
+//
+// Returns:
+//   - http.Handler: serves GET and HEAD requests
+func ArchiveHandler(fsys fs.FS, title string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "the archive is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = "."
+		}
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		if !info.IsDir() {
+			Logger().Debug("serving file", "name", name, "range", r.Header.Get("Range"))
+			f := newEntryReader(fsys, name, info.Size())
+			defer f.Close()
+			http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+			return
+		}
+
+		// Relative links in the index need the trailing slash.
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+			return
+		}
+		serveIndex(w, fsys, name, title)
+	})
+}
+
+// indexPage lists a folder of the archive; its rows are the folders
+// first, then the files, each sorted by name as fs.ReadDir returns them.
+var indexPage = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width">
+<title>{{.Title}}: {{.Folder}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td { padding: 0.2em 1.5em 0.2em 0; }
+td.size { text-align: right; }
+.muted { color: #777; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} <span class="muted">/{{.Folder}}</span></h1>
+<table>
+{{if .Parent}}<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{end}}{{range .Rows}}<tr><td><a href="{{.Link}}">{{.Name}}</a></td><td class="size">{{.Size}}</td><td class="muted">{{.Modified}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// indexRow is a row of indexPage.
+type indexRow struct {
+	Name     string
+	Link     string
+	Size     string
+	Modified string
+}
+
+func serveIndex(w http.ResponseWriter, fsys fs.FS, name, title string) {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var folders, files []indexRow
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		// The "./" keeps names such as "a:b" from reading as a scheme.
+		row := indexRow{Name: e.Name(), Link: "./" + url.PathEscape(e.Name()), Modified: info.ModTime().Format(time.DateTime)}
+		if e.IsDir() {
+			row.Name += "/"
+			row.Link += "/"
+			folders = append(folders, row)
+			continue
+		}
+		row.Size = FormatSize(uint64(info.Size()))
+		files = append(files, row)
+	}
+
+	folder := ""
+	if name != "." {
+		folder = name + "/"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	indexPage.Execute(w, map[string]any{
+		"Title":  title,
+		"Folder": folder,
+		"Parent": name != ".",
+		"Rows":   append(folders, files...),
+	})
+}
+
+// ServeArchive serves the archive at zipPath over HTTP on addr, see
+// ArchiveHandler, until ctx is done.
+//
+// Parameters:
+//   - ctx: stops the server once done, letting the requests under way finish
+//   - zipPath: the archive to serve
+//   - addr: the address to listen on, such as ":8080"
+//   - listening: called with the address listened on once the server is up
+//
+// Returns:
+//   - error: the archive cannot be opened or addr listened on
+func ServeArchive(ctx context.Context, zipPath, addr string, listening func(addr string)) error {
+	fsys, err := core.ArchiveFS(zipPath)
+	if err != nil {
+		return openError(err)
+	}
+	defer fsys.(io.Closer).Close()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot serve: %w", err)
+	}
+	server := &http.Server{
+		Handler:           ArchiveHandler(fsys, path.Base(zipPath)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	Logger().Info("serving archive", "path", zipPath, "addr", listener.Addr().String())
+	listening(listener.Addr().String())
+
+	// The archive stays open until the requests under way are done.
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-stopped
+	return nil
+}
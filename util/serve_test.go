@@ -0,0 +1,92 @@
+package util
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// TestArchiveHandler checks the index, the files, their content types and
+// ranges, as served over HTTP
+func TestArchiveHandler(t *testing.T) {
+	dir := t.TempDir()
+	text := strings.Repeat("0123456789", 1000)
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docs", "readme.txt"), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	zipPath := filepath.Join(dir, "site.zip")
+	if _, err := CreateArchive(zipPath, []string{filepath.Join(dir, "docs")}, CreateOptions{}); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+
+	fsys, err := core.ArchiveFS(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.(io.Closer).Close()
+	if _, err := fs.Stat(fsys, "docs/readme.txt"); err != nil {
+		t.Fatalf("test archive lacks docs/readme.txt: %v", err)
+	}
+	server := httptest.NewServer(ArchiveHandler(fsys, "site.zip"))
+	defer server.Close()
+
+	get := func(path, rangeHeader string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	if resp, body := get("/", ""); resp.StatusCode != http.StatusOK || !strings.Contains(body, `href="./docs/"`) {
+		t.Errorf("GET / = %d:\n%s", resp.StatusCode, body)
+	}
+	if resp, _ := get("/docs", ""); resp.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("GET /docs = %d, want a redirect", resp.StatusCode)
+	}
+	if resp, body := get("/docs/", ""); !strings.Contains(body, `href="../"`) || !strings.Contains(body, "readme.txt") {
+		t.Errorf("GET /docs/ = %d:\n%s", resp.StatusCode, body)
+	}
+
+	resp, body := get("/docs/readme.txt", "")
+	if body != text || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("GET /docs/readme.txt = %q, %d bytes", resp.Header.Get("Content-Type"), len(body))
+	}
+	for _, r := range []struct{ header, want string }{
+		{"bytes=5004-5007", text[5004:5008]},
+		{"bytes=-3", text[len(text)-3:]},
+	} {
+		resp, body := get("/docs/readme.txt", r.header)
+		if resp.StatusCode != http.StatusPartialContent || body != r.want {
+			t.Errorf("GET /docs/readme.txt with Range %s = %d, %q, want %q", r.header, resp.StatusCode, body, r.want)
+		}
+	}
+
+	if resp, _ := get("/missing.txt", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /missing.txt = %d, want 404", resp.StatusCode)
+	}
+	resp, err = http.Post(server.URL+"/docs/readme.txt", "text/plain", strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d, want 405", resp.StatusCode)
+	}
+}
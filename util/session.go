@@ -0,0 +1,119 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// sessionFile is the name of the session state file inside StateDir.
+const sessionFile = "session.json"
+
+// RecentLimit is the number of recently opened archives a session keeps.
+const RecentLimit = 20
+
+// ArchiveState is what a session remembers about an archive it opened.
+type ArchiveState struct {
+	// Path is the absolute path of the archive.
+	Path     string    `json:"path"`
+	OpenedAt time.Time `json:"opened_at"`
+	// Selected is the name of the entry the cursor was on.
+	Selected string `json:"selected,omitempty"`
+	// Filter is the filter active when the archive was left.
+	Filter string `json:"filter,omitempty"`
+}
+
+// Session is the state goZip keeps between runs in StateDir.
+type Session struct {
+	// Recent lists the archives opened lately, the latest first.
+	Recent []ArchiveState `json:"recent"`
+	// LastDestDir is the folder the browser last extracted into.
+	LastDestDir string `json:"last_dest_dir,omitempty"`
+}
+
+func sessionPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionFile), nil
+}
+
+// LoadSession reads the state saved by the previous runs. A missing file
+// is an empty session.
+//
+// Returns:
+//   - Session: the saved state
+//   - error: any error reading or decoding the file
+func LoadSession() (Session, error) {
+	p, err := sessionPath()
+	if err != nil {
+		return Session{}, err
+	}
+
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Session{}, nil
+	}
+	if err != nil {
+		return Session{}, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Session{}, err
+	}
+	return s, nil
+}
+
+// UpdateSession applies fn to the saved state and saves it again. The file
+// is read right before, so the changes of other goZip instances since this
+// one started are kept; an unreadable file is replaced.
+//
+// Parameters:
+//   - fn: changes the session
+//
+// Returns:
+//   - error: any error writing the file
+func UpdateSession(fn func(s *Session)) error {
+	p, err := sessionPath()
+	if err != nil {
+		return err
+	}
+
+	s, _ := LoadSession()
+	fn(&s)
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0644)
+}
+
+// Archive returns what the session remembers about the archive at path.
+func (s *Session) Archive(path string) (ArchiveState, bool) {
+	i := slices.IndexFunc(s.Recent, func(a ArchiveState) bool { return a.Path == path })
+	if i < 0 {
+		return ArchiveState{}, false
+	}
+	return s.Recent[i], true
+}
+
+// Remember puts state first in the recent archives, replacing what was
+// remembered about the same archive, and forgets the oldest ones past
+// RecentLimit.
+func (s *Session) Remember(state ArchiveState) {
+	s.Recent = slices.DeleteFunc(s.Recent, func(a ArchiveState) bool { return a.Path == state.Path })
+	s.Recent = slices.Insert(s.Recent, 0, state)
+	if len(s.Recent) > RecentLimit {
+		s.Recent = s.Recent[:RecentLimit]
+	}
+}
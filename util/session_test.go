@@ -0,0 +1,87 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSessionRoundTrip checks that updates are saved and read back
+func TestSessionRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	s, err := LoadSession()
+	if err != nil {
+		t.Fatalf("LoadSession() unexpected error = %v", err)
+	}
+	if len(s.Recent) != 0 || s.LastDestDir != "" {
+		t.Errorf("LoadSession() = %+v, want an empty session", s)
+	}
+
+	err = UpdateSession(func(s *Session) {
+		s.Remember(ArchiveState{Path: "/a.zip", Selected: "docs/", Filter: "src"})
+		s.LastDestDir = "/tmp/out"
+	})
+	if err != nil {
+		t.Fatalf("UpdateSession() unexpected error = %v", err)
+	}
+
+	s, err = LoadSession()
+	if err != nil {
+		t.Fatalf("LoadSession() unexpected error = %v", err)
+	}
+	state, ok := s.Archive("/a.zip")
+	if !ok || state.Selected != "docs/" || state.Filter != "src" {
+		t.Errorf("Archive(/a.zip) = %+v, %v", state, ok)
+	}
+	if s.LastDestDir != "/tmp/out" {
+		t.Errorf("LastDestDir = %q, want /tmp/out", s.LastDestDir)
+	}
+}
+
+// TestSessionRemember checks the order and the limit of the recent archives
+func TestSessionRemember(t *testing.T) {
+	var s Session
+	for i := range RecentLimit + 5 {
+		s.Remember(ArchiveState{Path: fmt.Sprintf("/%d.zip", i)})
+	}
+	s.Remember(ArchiveState{Path: "/10.zip", Filter: "again"})
+
+	if len(s.Recent) != RecentLimit {
+		t.Fatalf("len(Recent) = %d, want %d", len(s.Recent), RecentLimit)
+	}
+	if s.Recent[0].Path != "/10.zip" || s.Recent[0].Filter != "again" {
+		t.Errorf("Recent[0] = %+v, want /10.zip reopened", s.Recent[0])
+	}
+	if s.Recent[1].Path != fmt.Sprintf("/%d.zip", RecentLimit+4) {
+		t.Errorf("Recent[1] = %+v, want the latest before it", s.Recent[1])
+	}
+	for _, a := range s.Recent[1:] {
+		if a.Path == "/10.zip" {
+			t.Error("Remember() kept the old state of a reopened archive")
+		}
+	}
+}
+
+// TestLoadSessionCorrupt checks that a damaged file is reported and then replaced
+func TestLoadSessionCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "gozip"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gozip", sessionFile), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadSession(); err == nil {
+		t.Error("LoadSession() expected error, got nil")
+	}
+	if err := UpdateSession(func(s *Session) { s.LastDestDir = "/x" }); err != nil {
+		t.Fatalf("UpdateSession() unexpected error = %v", err)
+	}
+	if s, err := LoadSession(); err != nil || s.LastDestDir != "/x" {
+		t.Errorf("LoadSession() = %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package util
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand returns a command running command line through sh.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
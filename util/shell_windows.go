@@ -0,0 +1,18 @@
+//go:build windows
+
+package util
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand returns a command running command line through cmd. The
+// line is passed as is: the quoting Go adds to arguments would hide the
+// quotes and carets of cmdQuote from cmd.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd /S /C "` + command + `"`}
+	return cmd
+}
@@ -0,0 +1,192 @@
+package util
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GPGEnv names the environment variable holding the gpg program
+// VerifySignature runs, e.g. GOZIP_GPG=gpg2; gpg on the PATH by default.
+const GPGEnv = "GOZIP_GPG"
+
+// SignatureExtensions are the extensions of the detached signatures
+// FindSignature looks for next to an archive, in order.
+var SignatureExtensions = []string{".asc", ".sig"}
+
+// signatureLimit is the largest signature VerifySignature downloads.
+const signatureLimit = 1 << 20
+
+// SignatureStatus is the outcome of checking a detached signature.
+type SignatureStatus string
+
+const (
+	// SignatureGood is a valid signature by a key in the keyring.
+	SignatureGood SignatureStatus = "good"
+	// SignatureBad is a signature that does not match the archive: it was
+	// modified after being signed, or the signature is for another file.
+	SignatureBad SignatureStatus = "bad"
+	// SignatureExpired is a valid signature made by a key that expired, or
+	// one that expired itself.
+	SignatureExpired SignatureStatus = "expired"
+	// SignatureRevoked is a valid signature made by a revoked key.
+	SignatureRevoked SignatureStatus = "revoked"
+	// SignatureUnknownKey is a signature that cannot be checked because
+	// the key that made it is not in the keyring.
+	SignatureUnknownKey SignatureStatus = "unknown key"
+)
+
+// SignatureResult is what VerifySignature learned from gpg.
+type SignatureResult struct {
+	// Signature is the file or URL of the signature checked.
+	Signature string
+	Status    SignatureStatus
+	// Signer is the user ID of the key, such as "Alice <alice@example.org>";
+	// empty when the key is unknown.
+	Signer string
+	// KeyID is the long ID of the key, and Fingerprint its fingerprint when
+	// the signature is valid.
+	KeyID       string
+	Fingerprint string
+	// Trust is how far the keyring trusts the key, from gpg's TRUST_
+	// status: undefined, never, marginal, full or ultimate.
+	Trust string
+}
+
+// Valid reports whether the signature is good.
+func (r SignatureResult) Valid() bool {
+	return r.Status == SignatureGood
+}
+
+// FindSignature returns the detached signature next to the archive at
+// zipPath, such as archive.zip.asc, or "" when there is none.
+func FindSignature(zipPath string) string {
+	for _, ext := range SignatureExtensions {
+		if info, err := os.Stat(zipPath + ext); err == nil && info.Mode().IsRegular() {
+			return zipPath + ext
+		}
+	}
+	return ""
+}
+
+// VerifySignature checks a detached OpenPGP signature of the archive at
+// zipPath with gpg, see GPGEnv, against the user's keyring.
+//
+// Parameters:
+//   - ctx: cancels gpg, or the download
+//   - zipPath: full path to the ZIP file
+//   - signature: the signature file, or an http or https URL to download it from
+//
+// Returns:
+//   - SignatureResult: the outcome and who signed
+//   - error: the signature could not be read, or gpg could not run or
+//     reported nothing about it
+func VerifySignature(ctx context.Context, zipPath, signature string) (SignatureResult, error) {
+	sigPath := signature
+	if strings.HasPrefix(signature, "http://") || strings.HasPrefix(signature, "https://") {
+		downloaded, err := downloadSignature(ctx, signature)
+		if err != nil {
+			return SignatureResult{}, err
+		}
+		defer os.Remove(downloaded)
+		sigPath = downloaded
+	}
+
+	var stdout, stderr bytes.Buffer
+	program := cmp.Or(os.Getenv(GPGEnv), "gpg")
+	cmd := exec.CommandContext(ctx, program, "--batch", "--no-tty", "--status-fd", "1", "--verify", sigPath, zipPath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	result, ok := parseGPGStatus(stdout.String())
+	result.Signature = signature
+	if ok {
+		return result, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(runErr, &exitErr) && runErr != nil {
+		return result, fmt.Errorf("failed to run %s: %w", program, runErr)
+	}
+	detail, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+	return result, fmt.Errorf("%s could not check %s: %s", program, signature, cmp.Or(detail, "no signature found"))
+}
+
+// gpgStatuses maps the status keywords of gpg that name the signer to the
+// outcome they tell.
+var gpgStatuses = map[string]SignatureStatus{
+	"GOODSIG":   SignatureGood,
+	"BADSIG":    SignatureBad,
+	"EXPSIG":    SignatureExpired,
+	"EXPKEYSIG": SignatureExpired,
+	"REVKEYSIG": SignatureRevoked,
+}
+
+// parseGPGStatus reads the lines gpg --status-fd writes while verifying,
+// and reports whether they told the outcome.
+func parseGPGStatus(output string) (SignatureResult, bool) {
+	var result SignatureResult
+	for _, line := range strings.Split(output, "\n") {
+		keyword, args, ok := strings.Cut(strings.TrimPrefix(line, "[GNUPG:] "), " ")
+		if !ok || !strings.HasPrefix(line, "[GNUPG:] ") {
+			continue
+		}
+		keyID, userID, _ := strings.Cut(args, " ")
+
+		if status, ok := gpgStatuses[keyword]; ok {
+			result.Status, result.KeyID, result.Signer = status, keyID, userID
+			continue
+		}
+		switch keyword {
+		case "ERRSIG":
+			if result.Status == "" {
+				result.Status, result.KeyID = SignatureUnknownKey, keyID
+			}
+		case "VALIDSIG":
+			result.Fingerprint = keyID
+		default:
+			if trust, ok := strings.CutPrefix(keyword, "TRUST_"); ok {
+				result.Trust = strings.ToLower(trust)
+			}
+		}
+	}
+	return result, result.Status != ""
+}
+
+// downloadSignature saves the signature at url to a temporary file and
+// returns its path.
+func downloadSignature(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download the signature: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download the signature: %s", resp.Status)
+	}
+
+	f, err := os.CreateTemp("", "gozip-*.sig")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, io.LimitReader(resp.Body, signatureLimit))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to download the signature: %w", err)
+	}
+	return f.Name(), nil
+}
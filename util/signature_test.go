@@ -0,0 +1,140 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestParseGPGStatus checks the outcome read from gpg's status lines
+func TestParseGPGStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   SignatureResult
+		ok     bool
+	}{
+		{
+			name: "good",
+			output: "[GNUPG:] NEWSIG\n" +
+				"[GNUPG:] GOODSIG C5499AA75967CFC4 Test Signer <test@example.org>\n" +
+				"[GNUPG:] VALIDSIG F4E3EE494BC7138544B28C33C5499AA75967CFC4 2026-10-16 1792175236 0 4 0 22 8 00 F4E3EE494BC7138544B28C33C5499AA75967CFC4\n" +
+				"[GNUPG:] TRUST_ULTIMATE 0 pgp\n",
+			want: SignatureResult{Status: SignatureGood, Signer: "Test Signer <test@example.org>", KeyID: "C5499AA75967CFC4",
+				Fingerprint: "F4E3EE494BC7138544B28C33C5499AA75967CFC4", Trust: "ultimate"},
+			ok: true,
+		},
+		{
+			name:   "bad",
+			output: "[GNUPG:] BADSIG C5499AA75967CFC4 Test Signer <test@example.org>\n[GNUPG:] FAILURE gpg-exit 33554433\n",
+			want:   SignatureResult{Status: SignatureBad, Signer: "Test Signer <test@example.org>", KeyID: "C5499AA75967CFC4"},
+			ok:     true,
+		},
+		{
+			name:   "unknown key",
+			output: "[GNUPG:] ERRSIG C5499AA75967CFC4 22 8 00 1792175236 9 F4E3EE494BC7138544B28C33C5499AA75967CFC4\n[GNUPG:] NO_PUBKEY C5499AA75967CFC4\n",
+			want:   SignatureResult{Status: SignatureUnknownKey, KeyID: "C5499AA75967CFC4"},
+			ok:     true,
+		},
+		{
+			name:   "revoked",
+			output: "[GNUPG:] REVKEYSIG C5499AA75967CFC4 Old Key <old@example.org>\n",
+			want:   SignatureResult{Status: SignatureRevoked, Signer: "Old Key <old@example.org>", KeyID: "C5499AA75967CFC4"},
+			ok:     true,
+		},
+		{
+			name:   "no signature",
+			output: "[GNUPG:] NODATA 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseGPGStatus(tt.output)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseGPGStatus(%s) = %+v, %v, want %+v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestFindSignature checks that .asc is preferred to .sig
+func TestFindSignature(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "release.zip")
+	if got := FindSignature(zipPath); got != "" {
+		t.Errorf("FindSignature() without a signature = %q", got)
+	}
+
+	for _, ext := range []string{".sig", ".asc"} {
+		if err := os.WriteFile(zipPath+ext, []byte("sig"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := FindSignature(zipPath); got != zipPath+".asc" {
+		t.Errorf("FindSignature() = %q, want the .asc file", got)
+	}
+}
+
+// TestVerifySignature signs an archive with a throwaway key and checks it
+// before and after the archive changes, from a file and from a URL
+func TestVerifySignature(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	// Not t.TempDir: the sockets of gpg-agent need a short path, and the
+	// agent, stopped last, may still write there when the test ends.
+	home, err := os.MkdirTemp("", "gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	t.Setenv("GNUPGHOME", home)
+	defer exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+	t.Setenv(GPGEnv, "")
+	gpg := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("gpg", append([]string{"--batch", "--passphrase", ""}, args...)...).CombinedOutput(); err != nil {
+			t.Skipf("gpg %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "signed"})
+	gpg("--quick-gen-key", "Test Signer <test@example.org>", "ed25519", "sign", "never")
+	gpg("--armor", "--detach-sign", "-o", zipPath+".asc", zipPath)
+
+	ctx := context.Background()
+	result, err := VerifySignature(ctx, zipPath, FindSignature(zipPath))
+	if err != nil {
+		t.Fatalf("VerifySignature() unexpected error = %v", err)
+	}
+	if !result.Valid() || result.Signer != "Test Signer <test@example.org>" || result.Fingerprint == "" {
+		t.Errorf("VerifySignature() = %+v, want a good signature by the test key", result)
+	}
+
+	sig, err := os.ReadFile(zipPath + ".asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write(sig) }))
+	defer server.Close()
+	if result, err := VerifySignature(ctx, zipPath, server.URL+"/release.zip.asc"); err != nil || !result.Valid() {
+		t.Errorf("VerifySignature(URL) = %+v, %v, want a good signature", result, err)
+	}
+
+	f, err := os.OpenFile(zipPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("tampered"))
+	f.Close()
+	if result, err := VerifySignature(ctx, zipPath, zipPath+".asc"); err != nil || result.Status != SignatureBad {
+		t.Errorf("VerifySignature() after tampering = %+v, %v, want a bad signature", result, err)
+	}
+
+	if _, err := VerifySignature(ctx, zipPath, zipPath); err == nil {
+		t.Error("VerifySignature() with a file that is no signature expected an error")
+	}
+}
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// diskFreeFunc measures free space; tests replace it to simulate full disks.
+var diskFreeFunc = diskFree
+
+// FreeSpace returns the number of bytes available for new files in dir.
+// A directory that does not exist yet is measured at its nearest existing
+// parent, where it would be created.
+//
+// Parameters:
+//   - dir: destination directory; "" means the current directory
+//
+// Returns:
+//   - uint64: available bytes
+//   - error: any error encountered, including platforms where free space is unknown
+func FreeSpace(dir string) (uint64, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return diskFreeFunc(dir)
+}
+
+// CheckFreeSpace returns an error when dir has fewer than need bytes
+// available. When the free space cannot be determined the check passes, so
+// it never blocks an extraction that might succeed.
+//
+// Parameters:
+//   - dir: destination directory
+//   - need: number of bytes about to be written
+//
+// Returns:
+//   - error: nil, or an error describing the shortfall
+func CheckFreeSpace(dir string, need uint64) error {
+	free, err := FreeSpace(dir)
+	if err != nil || free >= need {
+		return nil
+	}
+
+	return fmt.Errorf("not enough free space: %s needed, %s available", FormatSize(need), FormatSize(free))
+}
@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package util
+
+import "errors"
+
+// diskFree is not available on this platform; CheckFreeSpace then lets
+// every extraction through.
+func diskFree(dir string) (uint64, error) {
+	return 0, errors.New("free space is unknown on this platform")
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFreeSpace checks that missing directories are measured at an existing parent
+func TestFreeSpace(t *testing.T) {
+	var measured string
+	defer func(orig func(string) (uint64, error)) { diskFreeFunc = orig }(diskFreeFunc)
+	diskFreeFunc = func(dir string) (uint64, error) {
+		measured = dir
+		return 1000, nil
+	}
+
+	dir := t.TempDir()
+	free, err := FreeSpace(filepath.Join(dir, "not", "yet"))
+	if err != nil || free != 1000 {
+		t.Fatalf("FreeSpace() = %d, %v, want 1000", free, err)
+	}
+	if measured != dir {
+		t.Errorf("FreeSpace() measured %s, want %s", measured, dir)
+	}
+
+	if err := CheckFreeSpace(dir, 1000); err != nil {
+		t.Errorf("CheckFreeSpace(1000) unexpected error = %v", err)
+	}
+	if err := CheckFreeSpace(dir, 1001); err == nil {
+		t.Error("CheckFreeSpace(1001) expected an error")
+	}
+
+	diskFreeFunc = func(string) (uint64, error) { return 0, errors.New("unknown") }
+	if err := CheckFreeSpace(dir, 1<<40); err != nil {
+		t.Errorf("CheckFreeSpace() with unknown space = %v, want nil", err)
+	}
+}
+
+// TestExtractChecksFreeSpace checks that extraction is blocked, before writing
+// anything, when the destination is too small
+func TestExtractChecksFreeSpace(t *testing.T) {
+	zipPath := writeTestZip(t, 0, map[string]string{"a.txt": "aaaa", "b.txt": "bbbb"})
+
+	defer func(orig func(string) (uint64, error)) { diskFreeFunc = orig }(diskFreeFunc)
+	diskFreeFunc = func(string) (uint64, error) { return 6, nil }
+
+	destDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{}); err == nil {
+		t.Fatal("ExtractWithOptions() expected a free space error")
+	}
+	if entries, _ := os.ReadDir(destDir); len(entries) != 0 {
+		t.Errorf("ExtractWithOptions() wrote %d files despite the error", len(entries))
+	}
+
+	plan, err := PlanExtraction(zipPath, "", destDir, ExtractOptions{})
+	if err != nil || plan.Fits() || plan.FreeSpace != 6 {
+		t.Errorf("PlanExtraction() = %+v, %v, want a plan that does not fit in 6 bytes", plan, err)
+	}
+
+	if count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{SkipSpaceCheck: true}); err != nil || count != 2 {
+		t.Errorf("ExtractWithOptions(SkipSpaceCheck) = %d, %v, want 2 files", count, err)
+	}
+}
@@ -0,0 +1,266 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MinSplitSize is the smallest volume size accepted for split archives. It
+// guarantees that any local header fits in a single volume.
+const MinSplitSize = 64 * 1024
+
+// SplitVolumeName returns the name of the given 1-based volume of the split
+// archive zipPath: out.z01, out.z02 and so on. The last volume is zipPath itself.
+func SplitVolumeName(zipPath string, volume int) string {
+	return fmt.Sprintf("%s.z%02d", strings.TrimSuffix(zipPath, filepath.Ext(zipPath)), volume)
+}
+
+// splitWriter writes a byte stream across numbered volumes of at most size bytes.
+type splitWriter struct {
+	zipPath string
+	size    int64
+
+	file    *os.File
+	disk    int
+	written int64
+	paths   []string
+}
+
+// offset returns the current disk number and the position within it.
+func (s *splitWriter) offset() (int, int64) {
+	return s.disk, s.written
+}
+
+// reserve starts a new volume unless n bytes still fit in the current one,
+// so records that must not span volumes are kept together.
+func (s *splitWriter) reserve(n int64) error {
+	if s.file != nil && s.written+n <= s.size {
+		return nil
+	}
+	return s.next()
+}
+
+func (s *splitWriter) next() error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+		s.disk++
+	}
+	if s.disk > 0xfffe {
+		return errors.New("too many volumes")
+	}
+
+	f, err := os.Create(SplitVolumeName(s.zipPath, s.disk+1))
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.written = 0
+	s.paths = append(s.paths, f.Name())
+	return nil
+}
+
+func (s *splitWriter) Write(p []byte) (int, error) {
+	var total int
+	for len(p) > 0 {
+		if s.file == nil || s.written == s.size {
+			if err := s.next(); err != nil {
+				return total, err
+			}
+		}
+
+		chunk := p
+		if room := s.size - s.written; int64(len(chunk)) > room {
+			chunk = chunk[:room]
+		}
+		n, err := s.file.Write(chunk)
+		total += n
+		s.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+	}
+
+	return total, nil
+}
+
+// splitArchive copies the single-file archive at srcPath into a split
+// archive made of volumes of at most size bytes, following the APPNOTE
+// layout understood by other tools: a spanning signature at the start of
+// the first volume, local headers never cut across volumes, and per-volume
+// offsets in the central directory.
+//
+// The last volume is renamed to zipPath; the others are named by
+// SplitVolumeName. When everything fits in one volume, its spanning
+// signature becomes the temporary marker APPNOTE 8.5.4 asks for, so it is
+// not taken for a split archive. On failure every volume written so far is
+// removed.
+func splitArchive(srcPath, zipPath string, size int64, onDone func(path string)) error {
+	r, err := openZipReader(srcPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	s := &splitWriter{zipPath: zipPath, size: size}
+	err = writeSplitArchive(s, r.Reader)
+	if err == nil && len(s.paths) == 1 {
+		marker := make([]byte, 4)
+		binary.LittleEndian.PutUint32(marker, sigSplitSingle)
+		_, err = s.file.WriteAt(marker, 0)
+	}
+	if s.file != nil {
+		if closeErr := s.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err == nil {
+		err = os.Rename(s.paths[len(s.paths)-1], zipPath)
+	}
+	if err != nil {
+		for _, p := range s.paths {
+			os.Remove(p)
+		}
+		return err
+	}
+
+	if onDone != nil {
+		for _, p := range s.paths[:len(s.paths)-1] {
+			onDone(p)
+		}
+		onDone(zipPath)
+	}
+	return nil
+}
+
+// writeSplitArchive writes the entries of r to s with disk-relative offsets.
+func writeSplitArchive(s *splitWriter, r *zip.Reader) error {
+	le := binary.LittleEndian
+	if len(r.File) >= 0xffff {
+		return errors.New("too many entries for a split archive")
+	}
+
+	marker := make([]byte, 4)
+	le.PutUint32(marker, sigDataDescriptor)
+	if _, err := s.Write(marker); err != nil {
+		return err
+	}
+
+	var records [][]byte
+	var cdSize int
+	for _, f := range r.File {
+		if f.CompressedSize64 >= 0xffffffff || f.UncompressedSize64 >= 0xffffffff {
+			return fmt.Errorf("%s: entries over 4 GiB cannot be split", f.Name)
+		}
+
+		// Sizes are known, so the data descriptor is not needed.
+		h := f.FileHeader
+		h.Flags &^= 0x8
+		extra := stripExtraField(h.Extra, zip64ExtraID)
+
+		local := make([]byte, localHeaderLen, localHeaderLen+len(h.Name)+len(extra))
+		le.PutUint32(local, sigLocalHeader)
+		le.PutUint16(local[4:], h.ReaderVersion)
+		le.PutUint16(local[6:], h.Flags)
+		le.PutUint16(local[8:], h.Method)
+		le.PutUint16(local[10:], h.ModifiedTime)
+		le.PutUint16(local[12:], h.ModifiedDate)
+		le.PutUint32(local[14:], h.CRC32)
+		le.PutUint32(local[18:], uint32(h.CompressedSize64))
+		le.PutUint32(local[22:], uint32(h.UncompressedSize64))
+		le.PutUint16(local[26:], uint16(len(h.Name)))
+		le.PutUint16(local[28:], uint16(len(extra)))
+		local = append(append(local, h.Name...), extra...)
+
+		if err := s.reserve(int64(len(local))); err != nil {
+			return err
+		}
+		disk, offset := s.offset()
+		if _, err := s.Write(local); err != nil {
+			return err
+		}
+
+		raw, err := f.OpenRaw()
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(s, raw); err != nil {
+			return err
+		}
+
+		record := make([]byte, centralHeaderLen, centralHeaderLen+len(h.Name)+len(extra)+len(h.Comment))
+		le.PutUint32(record, sigCentralHeader)
+		le.PutUint16(record[4:], h.CreatorVersion)
+		copy(record[6:30], local[4:30])
+		le.PutUint16(record[30:], uint16(len(extra)))
+		le.PutUint16(record[32:], uint16(len(h.Comment)))
+		le.PutUint16(record[34:], uint16(disk))
+		le.PutUint32(record[38:], h.ExternalAttrs)
+		le.PutUint32(record[42:], uint32(offset))
+		record = append(append(append(record, h.Name...), extra...), h.Comment...)
+
+		records = append(records, record)
+		cdSize += len(record)
+	}
+
+	// Keep the central directory and end record in one volume when they
+	// fit; otherwise only individual records are kept whole.
+	endLen := int64(directoryEndLen + len(r.Comment))
+	if int64(cdSize)+endLen <= s.size {
+		if err := s.reserve(int64(cdSize) + endLen); err != nil {
+			return err
+		}
+	}
+
+	cdDisk, cdOffset := -1, int64(0)
+	recordDisks := make([]int, len(records))
+	for i, record := range records {
+		if err := s.reserve(int64(len(record))); err != nil {
+			return err
+		}
+		recordDisks[i], _ = s.offset()
+		if i == 0 {
+			cdDisk, cdOffset = s.offset()
+		}
+		if _, err := s.Write(record); err != nil {
+			return err
+		}
+	}
+
+	if err := s.reserve(endLen); err != nil {
+		return err
+	}
+	disk, offset := s.offset()
+	if cdDisk < 0 {
+		cdDisk, cdOffset = disk, offset
+	}
+
+	var entriesHere int
+	for _, d := range recordDisks {
+		if d == disk {
+			entriesHere++
+		}
+	}
+
+	end := make([]byte, directoryEndLen, endLen)
+	le.PutUint32(end, sigDirectoryEnd)
+	le.PutUint16(end[4:], uint16(disk))
+	le.PutUint16(end[6:], uint16(cdDisk))
+	le.PutUint16(end[8:], uint16(entriesHere))
+	le.PutUint16(end[10:], uint16(len(records)))
+	le.PutUint32(end[12:], uint32(cdSize))
+	le.PutUint32(end[16:], uint32(cdOffset))
+	le.PutUint16(end[20:], uint16(len(r.Comment)))
+	end = append(end, r.Comment...)
+
+	_, err := s.Write(end)
+	return err
+}
@@ -0,0 +1,147 @@
+package util
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestCreateArchiveSplit checks the volume layout by following every
+// central directory record to its local header across volumes
+func TestCreateArchiveSplit(t *testing.T) {
+	dir := t.TempDir()
+	want := make(map[string][]byte)
+	var inputs []string
+	for _, name := range []string{"one.bin", "two.bin", "three.bin"} {
+		data := make([]byte, 70*1024)
+		rand.Read(data)
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+		want[name] = data
+		inputs = append(inputs, p)
+	}
+
+	zipPath := filepath.Join(dir, "out.zip")
+	var volumes []string
+	opts := CreateOptions{SplitSize: MinSplitSize, Volume: func(p string) { volumes = append(volumes, p) }}
+	if _, err := CreateArchive(zipPath, inputs, opts); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+
+	if len(volumes) < 4 || volumes[0] != SplitVolumeName(zipPath, 1) || volumes[len(volumes)-1] != zipPath {
+		t.Fatalf("volumes = %v, want out.z01... ending with out.zip", volumes)
+	}
+
+	var joined []byte
+	var starts []int64
+	for _, v := range volumes {
+		data, err := os.ReadFile(v)
+		if err != nil {
+			t.Fatalf("Failed to read volume: %v", err)
+		}
+		if len(data) > MinSplitSize {
+			t.Errorf("%s is %d bytes, want at most %d", v, len(data), MinSplitSize)
+		}
+		starts = append(starts, int64(len(joined)))
+		joined = append(joined, data...)
+	}
+	if binary.LittleEndian.Uint32(joined) != sigDataDescriptor {
+		t.Error("first volume does not start with the spanning signature")
+	}
+
+	last := joined[starts[len(starts)-1]:]
+	_, eocd, err := findDirectoryEnd(bytes.NewReader(last), int64(len(last)))
+	if err != nil {
+		t.Fatalf("findDirectoryEnd() unexpected error = %v", err)
+	}
+	le := binary.LittleEndian
+	if got := int(le.Uint16(eocd[4:])); got != len(volumes)-1 {
+		t.Errorf("end record disk = %d, want %d", got, len(volumes)-1)
+	}
+	cd := joined[starts[le.Uint16(eocd[6:])]+int64(le.Uint32(eocd[16:])):]
+	cd = cd[:le.Uint32(eocd[12:])]
+
+	for len(cd) > 0 {
+		rec, n, err := parseCentralRecord(cd)
+		if err != nil {
+			t.Fatalf("parseCentralRecord() unexpected error = %v", err)
+		}
+		disk := le.Uint16(cd[34:])
+		cd = cd[n:]
+
+		offset := starts[disk] + rec.headerOffset
+		local, err := readLocalHeader(bytes.NewReader(joined), offset)
+		if err != nil || local.name != rec.name {
+			t.Fatalf("%s: no matching local header at disk %d (%v)", rec.name, disk, err)
+		}
+
+		start := offset + local.size()
+		data, err := io.ReadAll(flate.NewReader(bytes.NewReader(joined[start : start+int64(rec.compressed)])))
+		if err != nil {
+			t.Fatalf("%s: inflating: %v", rec.name, err)
+		}
+		if !bytes.Equal(data, want[rec.name]) {
+			t.Errorf("%s: content differs after reassembly", rec.name)
+		}
+		delete(want, rec.name)
+	}
+	if len(want) != 0 {
+		t.Errorf("entries missing from the central directory: %d", len(want))
+	}
+}
+
+// TestCreateArchiveSplitSingleVolume checks that small archives are not split
+func TestCreateArchiveSplitSingleVolume(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "a.txt")
+	os.WriteFile(input, []byte("small"), 0644)
+
+	zipPath := filepath.Join(dir, "out.zip")
+	if _, err := CreateArchive(zipPath, []string{input}, CreateOptions{SplitSize: MinSplitSize}); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+	if _, err := os.Stat(SplitVolumeName(zipPath, 1)); err == nil {
+		t.Error("small archive was split")
+	}
+	if names := entryNames(t, zipPath); len(names) != 1 {
+		t.Errorf("entries = %v, want a.txt", names)
+	}
+
+	if _, err := CreateArchive(zipPath, []string{input}, CreateOptions{SplitSize: 1000}); err == nil {
+		t.Error("CreateArchive() expected error for a tiny split size, got nil")
+	}
+}
+
+// TestSplitArchiveSingleVolume checks that a split archive ending up in a
+// single volume carries the temporary marker, not the spanning signature
+func TestSplitArchiveSingleVolume(t *testing.T) {
+	srcPath := writeTestZip(t, 0, map[string]string{"a.txt": "small"})
+	zipPath := filepath.Join(t.TempDir(), "out.zip")
+
+	var volumes []string
+	if err := splitArchive(srcPath, zipPath, MinSplitSize, func(p string) { volumes = append(volumes, p) }); err != nil {
+		t.Fatalf("splitArchive() unexpected error = %v", err)
+	}
+	if !slices.Equal(volumes, []string{zipPath}) {
+		t.Errorf("volumes = %v, want only %s", volumes, zipPath)
+	}
+
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig := binary.LittleEndian.Uint32(data); sig != sigSplitSingle {
+		t.Errorf("marker = %#x, want %#x", sig, sigSplitSingle)
+	}
+	if names := entryNames(t, zipPath); len(names) != 1 {
+		t.Errorf("entries = %v, want a.txt", names)
+	}
+}
@@ -0,0 +1,294 @@
+package util
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// Extra field IDs carrying modification times, read the way archive/zip
+// reads them so both listings agree.
+const (
+	ntfsExtraID        = 0x000a
+	unixExtraID        = 0x000d
+	extTimeExtraID     = 0x5455
+	infoZipUnixExtraID = 0x5855
+
+	// infoZipNewUnixExtraID carries the owner of the file, not its times.
+	infoZipNewUnixExtraID = 0x7875
+)
+
+// ArchiveStream lists an archive progressively: its central directory is
+// read in chunks and handed over a batch of entries at a time, so a browser
+// can show the first entries of a huge archive while the rest is parsed.
+// The entries are the ones ListArchive returns, in the same order.
+type ArchiveStream struct {
+	file   *os.File
+	r      *bufio.Reader
+	total  int
+	loaded int
+	// baseOffset is the size of any data prepended to the archive.
+	baseOffset int64
+	info       core.ArchiveInfo
+}
+
+// OpenArchiveStream opens the archive at zipPath and reads its end of
+// central directory record, so a file that is not a ZIP archive is
+// reported right away. Entries are then read with Next.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - *ArchiveStream: the stream, to be closed by the caller
+//   - error: any error encountered while opening the archive
+func OpenArchiveStream(zipPath string) (*ArchiveStream, error) {
+	f, err := os.Open(zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	cd, entries, err := locateCentralDirectory(f, info.Size())
+	if err != nil {
+		f.Close()
+		Logger().Warn("cannot list archive", "path", zipPath, "err", err)
+		return nil, fmt.Errorf("%w: %w: %w", ErrCannotOpen, ErrNotZip, err)
+	}
+	Logger().Info("opened archive", "path", zipPath, "entries", entries, "size", info.Size(), "streaming", true)
+
+	return &ArchiveStream{
+		file:       f,
+		r:          bufio.NewReaderSize(io.NewSectionReader(f, cd.start, cd.end-cd.start), 1<<20),
+		total:      int(entries),
+		baseOffset: cd.baseOffset,
+		info:       archiveInfo(cd),
+	}, nil
+}
+
+// Total returns the number of entries the archive declares.
+func (s *ArchiveStream) Total() int {
+	return s.total
+}
+
+// Loaded returns the number of entries returned by Next so far.
+func (s *ArchiveStream) Loaded() int {
+	return s.loaded
+}
+
+// Next returns up to max further entries. It returns io.EOF once every
+// entry was returned.
+func (s *ArchiveStream) Next(max int) ([]core.ZippedFile, error) {
+	if s.loaded >= s.total {
+		return nil, io.EOF
+	}
+
+	batch := make([]core.ZippedFile, 0, min(max, s.total-s.loaded))
+	for len(batch) < max && s.loaded < s.total {
+		rec, err := s.readRecord()
+		if err != nil {
+			return batch, err
+		}
+		zf := zippedFileFromRecord(rec, s.baseOffset)
+		s.info.Add(zf)
+		batch = append(batch, zf)
+		s.loaded++
+	}
+
+	return batch, nil
+}
+
+// Info returns the summary of the archive. Its format, comment and Zip64
+// flag are known from the start; the counts and sizes cover the entries
+// returned by Next so far.
+func (s *ArchiveStream) Info() core.ArchiveInfo {
+	return s.info
+}
+
+// Close closes the archive.
+func (s *ArchiveStream) Close() error {
+	return s.file.Close()
+}
+
+// readRecord reads the next central directory header.
+func (s *ArchiveStream) readRecord() (centralRecord, error) {
+	fixed, err := s.r.Peek(centralHeaderLen)
+	if err != nil || binary.LittleEndian.Uint32(fixed) != sigCentralHeader {
+		return centralRecord{}, errors.New("zip: central directory is shorter than declared")
+	}
+
+	le := binary.LittleEndian
+	n := centralHeaderLen + int(le.Uint16(fixed[28:])) + int(le.Uint16(fixed[30:])) + int(le.Uint16(fixed[32:]))
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(s.r, buf); err != nil {
+		return centralRecord{}, errors.New("zip: truncated central directory header")
+	}
+
+	rec, _, err := parseCentralRecord(buf)
+	return rec, err
+}
+
+// zippedFileFromRecord builds the listing entry of a central directory
+// record, with the values archive/zip reports for it and its name converted
+// to UTF-8 with the default NameEncoding. baseOffset is the size of the data
+// prepended to the archive, to which header offsets are relative.
+func zippedFileFromRecord(rec centralRecord, baseOffset int64) core.ZippedFile {
+	modified := recordModified(rec)
+	var modStr string
+	if !modified.IsZero() {
+		modStr = modified.UTC().Format(time.RFC3339)
+	} else {
+		modStr = "-"
+	}
+
+	mode := recordMode(rec)
+	uid, gid := extraOwner(rec.extra)
+	times := extraTimes(rec.extra)
+
+	stored := decodeName(rec.name, rec.flags, rec.extra, "")
+	name := entryName(rec.name, rec.versionMadeBy, rec.flags, rec.extra, "")
+	zf := core.NewZippedFile(name, mode.IsDir() || strings.HasSuffix(name, "/"), rec.uncompressed, rec.compressed, methodToString(rec.method), modStr, rec.crc)
+	return zf.WithMetadata(core.Metadata{
+		Modified:     modified,
+		Accessed:     times.accessed,
+		Created:      times.created,
+		Mode:         mode,
+		UID:          uid,
+		GID:          gid,
+		Comment:      rec.comment,
+		Encrypted:    rec.flags&0x1 != 0,
+		HeaderOffset: baseOffset + rec.headerOffset,
+		Flags:        rec.flags,
+		StoredName:   stored,
+	})
+}
+
+// recordMode mirrors zip.FileHeader.Mode.
+func recordMode(rec centralRecord) fs.FileMode {
+	var mode fs.FileMode
+
+	switch rec.versionMadeBy >> 8 {
+	case 3, 19: // Unix, macOS
+		mode = unixModeToFileMode(rec.externalAttrs >> 16)
+	case 0, 11, 14: // FAT, NTFS, VFAT
+		if rec.externalAttrs&0x10 != 0 {
+			mode = fs.ModeDir | 0777
+		} else {
+			mode = 0666
+		}
+		if rec.externalAttrs&0x01 != 0 { // read-only
+			mode &^= 0222
+		}
+	}
+
+	if len(rec.name) > 0 && rec.name[len(rec.name)-1] == '/' {
+		mode |= fs.ModeDir
+	}
+	return mode
+}
+
+// unixModeToFileMode converts the st_mode bits Unix tools store in the
+// external attributes.
+func unixModeToFileMode(m uint32) fs.FileMode {
+	mode := fs.FileMode(m & 0777)
+	switch m & 0xf000 {
+	case 0x6000:
+		mode |= fs.ModeDevice
+	case 0x2000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0x4000:
+		mode |= fs.ModeDir
+	case 0x1000:
+		mode |= fs.ModeNamedPipe
+	case 0xa000:
+		mode |= fs.ModeSymlink
+	case 0xc000:
+		mode |= fs.ModeSocket
+	}
+	if m&0x800 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if m&0x400 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if m&0x200 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// extraOwner returns the owner stored by Info-ZIP's Unix extra fields, or
+// -1, -1 when there is none.
+func extraOwner(extra []byte) (int, int) {
+	le := binary.LittleEndian
+
+	// Current Info-ZIP Unix field: version, then sized UID and GID.
+	if field, ok := findExtraField(extra, infoZipNewUnixExtraID); ok && len(field) >= 2 && field[0] == 1 {
+		uidSize := int(field[1])
+		if len(field) >= 2+uidSize+1 {
+			gidSize := int(field[2+uidSize])
+			if len(field) >= 3+uidSize+gidSize {
+				uid, okUID := littleEndianID(field[2 : 2+uidSize])
+				gid, okGID := littleEndianID(field[3+uidSize : 3+uidSize+gidSize])
+				if okUID && okGID {
+					return uid, gid
+				}
+			}
+		}
+	}
+
+	// Older field: times, then 16-bit IDs, which central headers often omit.
+	if field, ok := findExtraField(extra, infoZipUnixExtraID); ok && len(field) >= 12 {
+		return int(le.Uint16(field[8:])), int(le.Uint16(field[10:]))
+	}
+
+	return -1, -1
+}
+
+// littleEndianID decodes a little-endian ID of up to 4 bytes.
+func littleEndianID(b []byte) (int, bool) {
+	if len(b) == 0 || len(b) > 4 {
+		return 0, false
+	}
+	var id uint32
+	for i := len(b) - 1; i >= 0; i-- {
+		id = id<<8 | uint32(b[i])
+	}
+	return int(id), true
+}
+
+// recordModified mirrors zip.FileHeader.Modified: the last timestamp extra
+// field wins over the MS-DOS date and time.
+func recordModified(rec centralRecord) time.Time {
+	if modified := extraTimes(rec.extra).modified; !modified.IsZero() {
+		return modified
+	}
+	return msDosTime(rec.modDate, rec.modTime)
+}
+
+// msDosTime converts an MS-DOS date and time into a UTC time.Time.
+func msDosTime(dosDate, dosTime uint16) time.Time {
+	return time.Date(
+		int(dosDate>>9+1980),
+		time.Month(dosDate>>5&0xf),
+		int(dosDate&0x1f),
+		int(dosTime>>11),
+		int(dosTime>>5&0x3f),
+		int(dosTime&0x1f*2),
+		0,
+		time.UTC,
+	)
+}
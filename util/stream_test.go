@@ -0,0 +1,171 @@
+package util
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cainlara/gozip/core"
+)
+
+// streamAll reads every entry of zipPath through an ArchiveStream, in
+// batches of the given size
+func streamAll(t *testing.T, zipPath string, batch int) []core.ZippedFile {
+	t.Helper()
+
+	s, err := OpenArchiveStream(zipPath)
+	if err != nil {
+		t.Fatalf("OpenArchiveStream() unexpected error = %v", err)
+	}
+	defer s.Close()
+
+	var all []core.ZippedFile
+	for {
+		entries, err := s.Next(batch)
+		all = append(all, entries...)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() unexpected error = %v", err)
+		}
+		if len(entries) > batch {
+			t.Fatalf("Next(%d) returned %d entries", batch, len(entries))
+		}
+	}
+
+	if s.Loaded() != s.Total() || s.Total() != len(all) {
+		t.Errorf("Loaded() = %d, Total() = %d, read %d entries", s.Loaded(), s.Total(), len(all))
+	}
+	return all
+}
+
+// TestArchiveStreamMatchesListArchive checks that streaming lists exactly
+// what ListArchive lists, timestamps and folders included
+func TestArchiveStreamMatchesListArchive(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src", "empty"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "a.txt"), []byte("a"), 0644)
+	created := filepath.Join(t.TempDir(), "created.zip")
+	if _, err := CreateArchive(created, []string{filepath.Join(dir, "src")}, CreateOptions{}); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+
+	archives := []string{
+		"testdata/test.zip",
+		"testdata/bzip2.zip",
+		"testdata/lzma.zip",
+		created,
+		writeTestZip(t, 100, map[string]string{"a.txt": "a", "dir/": "", "dir/b.txt": "b"}),
+	}
+
+	for _, zipPath := range archives {
+		want, err := ListArchive(zipPath)
+		if err != nil {
+			t.Fatalf("ListArchive(%s) unexpected error = %v", zipPath, err)
+		}
+
+		for _, batch := range []int{1, 2, 1000} {
+			if got := streamAll(t, zipPath, batch); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s streamed in batches of %d = %v, want %v", filepath.Base(zipPath), batch, got, want)
+			}
+		}
+	}
+}
+
+// TestOpenArchiveStreamInvalid checks that non-archives are rejected up front
+func TestOpenArchiveStreamInvalid(t *testing.T) {
+	if _, err := OpenArchiveStream("testdata/sample.txt"); err == nil {
+		t.Error("OpenArchiveStream(sample.txt) expected an error")
+	}
+}
+
+// TestListArchiveMatchesArchiveZip checks the listing, read straight from
+// the central directory, against what archive/zip reports
+func TestListArchiveMatchesArchiveZip(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "secret.txt"), []byte("classified"), 0600)
+	encrypted := filepath.Join(t.TempDir(), "secret.zip")
+	if _, err := CreateArchive(encrypted, []string{src}, CreateOptions{Password: "pw"}); err != nil {
+		t.Fatalf("CreateArchive() unexpected error = %v", err)
+	}
+
+	archives := []string{
+		"testdata/test.zip",
+		"testdata/lzma.zip",
+		encrypted,
+		writeTestZip(t, 100, map[string]string{"a.txt": "a", "dir/": "", "dir/b.txt": "b"}),
+	}
+
+	for _, zipPath := range archives {
+		got, err := ListArchive(zipPath)
+		if err != nil {
+			t.Fatalf("ListArchive(%s) unexpected error = %v", zipPath, err)
+		}
+
+		r, err := zip.OpenReader(zipPath)
+		if err != nil {
+			t.Fatalf("zip.OpenReader(%s) unexpected error = %v", zipPath, err)
+		}
+		defer r.Close()
+
+		if len(got) != len(r.File) {
+			t.Fatalf("%s: listed %d entries, want %d", zipPath, len(got), len(r.File))
+		}
+		for i, f := range r.File {
+			zf := got[i]
+			if zf.GetName() != f.Name || zf.IsDir() != f.FileInfo().IsDir() || zf.GetMode() != f.Mode() ||
+				zf.GetSize() != f.UncompressedSize64 || zf.GetCrc() != f.CRC32 || zf.GetComment() != f.Comment ||
+				!zf.GetModified().Equal(f.Modified) || zf.IsEncrypted() != (f.Flags&0x1 != 0) {
+				t.Errorf("%s: entry %d = %+v, want %+v", zipPath, i, zf, f.FileHeader)
+			}
+
+			dataOffset, err := f.DataOffset()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if headerLen := int64(localHeaderLen + len(f.Name)); dataOffset-zf.GetHeaderOffset() < headerLen {
+				t.Errorf("%s: %s header offset %d does not precede its data at %d", zipPath, f.Name, zf.GetHeaderOffset(), dataOffset)
+			}
+		}
+	}
+}
+
+// TestListArchiveOwner checks the owner read from Info-ZIP Unix extra fields
+func TestListArchiveOwner(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "owned.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(out)
+	// 0x7875: version 1, 4-byte UID 1000, 4-byte GID 100.
+	owned := []byte{0x75, 0x78, 11, 0, 1, 4, 0xe8, 0x03, 0, 0, 4, 100, 0, 0, 0}
+	for name, extra := range map[string][]byte{"owned.txt": owned, "plain.txt": nil} {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Extra: extra})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(name))
+	}
+	w.Close()
+	out.Close()
+
+	content, err := ListArchive(zipPath)
+	if err != nil {
+		t.Fatalf("ListArchive() unexpected error = %v", err)
+	}
+	for _, zf := range content {
+		wantUID, wantGID := -1, -1
+		if zf.GetName() == "owned.txt" {
+			wantUID, wantGID = 1000, 100
+		}
+		if zf.GetUID() != wantUID || zf.GetGID() != wantGID {
+			t.Errorf("%s owner = %d:%d, want %d:%d", zf.GetName(), zf.GetUID(), zf.GetGID(), wantUID, wantGID)
+		}
+	}
+}
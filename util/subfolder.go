@@ -0,0 +1,120 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SubfolderMode decides whether extracting a whole archive goes into a new
+// folder named after it instead of straight into the destination directory.
+type SubfolderMode string
+
+const (
+	// SubfolderAuto uses a subfolder when the archive has more than one
+	// top-level entry, so it cannot litter the destination directory.
+	SubfolderAuto SubfolderMode = "auto"
+	// SubfolderAlways always extracts into a subfolder.
+	SubfolderAlways SubfolderMode = "always"
+	// SubfolderNever always extracts straight into the destination.
+	SubfolderNever SubfolderMode = "never"
+)
+
+// SubfolderModeEnv names the environment variable holding the default
+// SubfolderMode, e.g. GOZIP_SUBFOLDER=never.
+const SubfolderModeEnv = "GOZIP_SUBFOLDER"
+
+// ParseSubfolderMode validates a mode name; "" is SubfolderAuto.
+func ParseSubfolderMode(s string) (SubfolderMode, error) {
+	switch mode := SubfolderMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return SubfolderAuto, nil
+	case SubfolderAuto, SubfolderAlways, SubfolderNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid subfolder mode %q (want auto, always or never)", s)
+	}
+}
+
+// DefaultSubfolderMode returns the mode configured through SubfolderModeEnv,
+// or SubfolderAuto when the variable is unset.
+func DefaultSubfolderMode() (SubfolderMode, error) {
+	mode, err := ParseSubfolderMode(os.Getenv(SubfolderModeEnv))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", SubfolderModeEnv, err)
+	}
+	return mode, nil
+}
+
+// ArchiveFolderName returns the name of the folder an archive is extracted
+// into: its base name without the extension ("report.zip" becomes "report").
+// Names without an extension get an "-extracted" suffix, so the folder never
+// collides with the archive itself.
+func ArchiveFolderName(zipPath string) string {
+	base := filepath.Base(zipPath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if name == "" || name == base {
+		return base + "-extracted"
+	}
+	return name
+}
+
+// TopLevelEntries returns the distinct first path elements of the archive,
+// in the order they first appear. Folders keep their trailing slash.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//
+// Returns:
+//   - []string: the top-level files and folders
+//   - error: any error encountered while reading the archive
+func TopLevelEntries(zipPath string) ([]string, error) {
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return nil, openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	var top []string
+	seen := make(map[string]bool)
+	for _, f := range reader.File {
+		name := strings.TrimPrefix(f.Name, "/")
+		if i := strings.Index(name, "/"); i >= 0 {
+			name = name[:i+1]
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		top = append(top, name)
+	}
+
+	return top, nil
+}
+
+// WantsSubfolder reports whether extracting the whole archive with mode
+// should go into a folder named after it.
+//
+// Parameters:
+//   - zipPath: full path to the ZIP file
+//   - mode: the configured SubfolderMode
+//
+// Returns:
+//   - bool: true if a subfolder should be used
+//   - error: any error encountered while reading the archive
+func WantsSubfolder(zipPath string, mode SubfolderMode) (bool, error) {
+	switch mode {
+	case SubfolderAlways:
+		return true, nil
+	case SubfolderNever:
+		return false, nil
+	}
+
+	top, err := TopLevelEntries(zipPath)
+	if err != nil {
+		return false, err
+	}
+	return len(top) > 1, nil
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestWantsSubfolder checks the subfolder decision for each mode
+func TestWantsSubfolder(t *testing.T) {
+	single := writeTestZip(t, 0, map[string]string{"app/": "", "app/main.go": "m", "app/go.mod": "g"})
+	several := writeTestZip(t, 0, map[string]string{"main.go": "m", "go.mod": "g", "lib/a.go": "a"})
+
+	tests := []struct {
+		zipPath string
+		mode    SubfolderMode
+		want    bool
+	}{
+		{single, SubfolderAuto, false},
+		{several, SubfolderAuto, true},
+		{single, SubfolderAlways, true},
+		{several, SubfolderNever, false},
+	}
+
+	for _, tt := range tests {
+		got, err := WantsSubfolder(tt.zipPath, tt.mode)
+		if err != nil || got != tt.want {
+			t.Errorf("WantsSubfolder(%s, %s) = %v, %v, want %v", filepath.Base(tt.zipPath), tt.mode, got, err, tt.want)
+		}
+	}
+
+	top, _ := TopLevelEntries(several)
+	if !slices.Equal(top, []string{"go.mod", "lib/", "main.go"}) {
+		t.Errorf("TopLevelEntries() = %v", top)
+	}
+}
+
+// TestArchiveFolderName checks the folder names derived from archive paths
+func TestArchiveFolderName(t *testing.T) {
+	tests := map[string]string{
+		"/tmp/report.zip":          "report",
+		"backup.2024.zip":          "backup.2024",
+		"data":                     "data-extracted",
+		filepath.Join("a", ".zip"): ".zip-extracted",
+	}
+
+	for in, want := range tests {
+		if got := ArchiveFolderName(in); got != want {
+			t.Errorf("ArchiveFolderName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestParseSubfolderMode checks accepted and rejected mode names
+func TestParseSubfolderMode(t *testing.T) {
+	if mode, err := ParseSubfolderMode(""); err != nil || mode != SubfolderAuto {
+		t.Errorf("ParseSubfolderMode(\"\") = %q, %v, want auto", mode, err)
+	}
+	if mode, err := ParseSubfolderMode("Never"); err != nil || mode != SubfolderNever {
+		t.Errorf("ParseSubfolderMode(Never) = %q, %v, want never", mode, err)
+	}
+	if _, err := ParseSubfolderMode("sometimes"); err == nil {
+		t.Error("ParseSubfolderMode(sometimes) expected an error")
+	}
+}
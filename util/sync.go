@@ -0,0 +1,179 @@
+package util
+
+import (
+	"archive/zip"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SyncOptions controls how SyncDirectory decides what changed.
+type SyncOptions struct {
+	// CompareHash compares file contents by CRC-32 instead of by size and
+	// modification time. It is slower but ignores timestamps entirely.
+	CompareHash bool
+
+	// Delete removes entries whose file no longer exists in the directory.
+	Delete bool
+}
+
+// SyncResult lists the changes made by SyncDirectory.
+type SyncResult struct {
+	Added     []string
+	Updated   []string
+	Deleted   []string
+	Unchanged int
+}
+
+// SyncDirectory updates the archive at zipPath to mirror the directory dir.
+//
+// Entry names are relative to dir. Files missing from the archive are added,
+// files that changed are replaced and, with opts.Delete, entries whose file
+// is gone are removed; everything else is copied without recompression.
+// The archive is created when it does not exist yet.
+//
+// Parameters:
+//   - dir: directory to mirror
+//   - zipPath: archive to update
+//   - opts: how changes are detected and whether deletions are applied
+//
+// Returns:
+//   - SyncResult: the entries added, updated and deleted
+//   - error: any error encountered; the archive is unchanged on failure
+func SyncDirectory(dir, zipPath string, opts SyncOptions) (SyncResult, error) {
+	sources, err := collectDirectory(dir, zipPath)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	var result SyncResult
+	sync := func(r *zip.Reader, w *zip.Writer) error {
+		result = SyncResult{}
+		selectMethod := StoreExtensions(PrecompressedExtensions)
+
+		pending := make(map[string]sourceEntry, len(sources))
+		for _, s := range sources {
+			pending[s.name] = s
+		}
+
+		if r != nil {
+			for _, f := range r.File {
+				src, ok := pending[f.Name]
+				if !ok {
+					if opts.Delete {
+						result.Deleted = append(result.Deleted, f.Name)
+						continue
+					}
+					if err := copyEntry(w, f, f.Name); err != nil {
+						return err
+					}
+					continue
+				}
+				delete(pending, f.Name)
+
+				changed, err := fileChanged(f, src, opts.CompareHash)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					result.Unchanged++
+					if err := copyEntry(w, f, f.Name); err != nil {
+						return err
+					}
+					continue
+				}
+
+				result.Updated = append(result.Updated, f.Name)
+				if err := addSource(w, src, selectMethod, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, s := range sources {
+			if _, ok := pending[s.name]; !ok {
+				continue
+			}
+			result.Added = append(result.Added, s.name)
+			if err := addSource(w, s, selectMethod, false); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if _, err = os.Stat(zipPath); errors.Is(err, fs.ErrNotExist) {
+		err = writeArchiveAtomic(zipPath, 0644, func(w *zip.Writer) error { return sync(nil, w) })
+	} else {
+		err = rewriteArchive(zipPath, sync)
+	}
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	return result, nil
+}
+
+// collectDirectory lists the files and folders below dir, named relative to
+// it, skipping the archive at zipPath should it live inside dir.
+func collectDirectory(dir, zipPath string) ([]sourceEntry, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New(dir + " is not a directory")
+	}
+
+	entries, err := collectSources(zipPath, []string{dir})
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := archiveBaseName(filepath.Clean(dir)) + "/"
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.name == prefix {
+			continue
+		}
+		e.name = strings.TrimPrefix(e.name, prefix)
+		kept = append(kept, e)
+	}
+
+	return kept, nil
+}
+
+// fileChanged reports whether the file on disk differs from entry f.
+// Folders never change. Without hashing, modification times within two
+// seconds are considered equal, the resolution of DOS timestamps.
+func fileChanged(f *zip.File, src sourceEntry, compareHash bool) (bool, error) {
+	if src.info.IsDir() {
+		return false, nil
+	}
+	if uint64(src.info.Size()) != f.UncompressedSize64 {
+		return true, nil
+	}
+
+	if !compareHash {
+		delta := f.Modified.Sub(src.info.ModTime())
+		return delta < -2*time.Second || delta > 2*time.Second, nil
+	}
+
+	in, err := os.Open(src.diskPath)
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, in); err != nil {
+		return false, err
+	}
+	return h.Sum32() != f.CRC32, nil
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestSyncDirectory checks adding, updating, keeping and deleting entries
+func TestSyncDirectory(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, mtime time.Time) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		os.Chtimes(p, mtime, mtime)
+	}
+
+	old := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	write("keep.txt", "keep", old)
+	write("edit.txt", "before", old)
+	write("gone.txt", "gone", old)
+	write("sub/same-size.txt", "aaaa", old)
+
+	zipPath := filepath.Join(t.TempDir(), "mirror.zip")
+	first, err := SyncDirectory(dir, zipPath, SyncOptions{})
+	if err != nil {
+		t.Fatalf("SyncDirectory() unexpected error = %v", err)
+	}
+	if len(first.Added) != 5 || len(first.Updated) != 0 {
+		t.Errorf("first sync = %+v, want 5 entries added", first)
+	}
+
+	newer := old.Add(time.Hour)
+	write("edit.txt", "after!", newer)
+	write("sub/same-size.txt", "bbbb", old)
+	write("new.txt", "new", newer)
+	os.Remove(filepath.Join(dir, "gone.txt"))
+
+	t.Run("size and mtime", func(t *testing.T) {
+		result, err := SyncDirectory(dir, zipPath, SyncOptions{})
+		if err != nil {
+			t.Fatalf("SyncDirectory() unexpected error = %v", err)
+		}
+		if !slices.Equal(result.Added, []string{"new.txt"}) || !slices.Equal(result.Updated, []string{"edit.txt"}) || len(result.Deleted) != 0 {
+			t.Errorf("SyncDirectory() = %+v", result)
+		}
+		if got := readEntries(t, zipPath)["gone.txt"]; got != "gone" {
+			t.Errorf("gone.txt = %q, want it kept without Delete", got)
+		}
+	})
+
+	t.Run("hash and delete", func(t *testing.T) {
+		result, err := SyncDirectory(dir, zipPath, SyncOptions{CompareHash: true, Delete: true})
+		if err != nil {
+			t.Fatalf("SyncDirectory() unexpected error = %v", err)
+		}
+		if !slices.Equal(result.Updated, []string{"sub/same-size.txt"}) || !slices.Equal(result.Deleted, []string{"gone.txt"}) {
+			t.Errorf("SyncDirectory() = %+v", result)
+		}
+
+		got := readEntries(t, zipPath)
+		want := map[string]string{"edit.txt": "after!", "keep.txt": "keep", "new.txt": "new", "sub/": "", "sub/same-size.txt": "bbbb"}
+		if len(got) != len(want) {
+			t.Errorf("entries = %v, want %v", got, want)
+		}
+		for name, content := range want {
+			if got[name] != content {
+				t.Errorf("%s = %q, want %q", name, got[name], content)
+			}
+		}
+	})
+
+	if _, err := SyncDirectory(filepath.Join(dir, "keep.txt"), zipPath, SyncOptions{}); err == nil {
+		t.Error("SyncDirectory() expected error for a file instead of a directory, got nil")
+	}
+}
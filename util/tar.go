@@ -0,0 +1,120 @@
+package util
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// maxLinkTarget bounds how much of a symbolic link entry is read as its
+// target.
+const maxLinkTarget = 4096
+
+// WriteTar writes the files ExtractWithOptions would extract as a tar
+// stream to w instead of to disk, so they can be piped into tar, docker cp
+// or ssh without a temporary copy. Each file is named after the path it
+// would be extracted to, relative to the destination, and keeps its
+// modification time and, for entries made on Unix, its mode and owner.
+// Symbolic links stay links. Options about the destination, such as
+// opts.Overwrite and opts.Resume, do not apply; a file opts.Scanner blocks
+// ends the stream.
+//
+// Parameters:
+//   - ctx: stops the stream once done
+//   - zipPath: full path to the ZIP file
+//   - targetName: name of the file or folder to write (as it appears in the ZIP), or "" for the whole archive
+//   - w: destination of the tar stream
+//   - opts: which files to write
+//
+// Returns:
+//   - int: number of files written
+//   - error: any error encountered while reading the archive or writing to w
+func WriteTar(ctx context.Context, zipPath, targetName string, w io.Writer, opts ExtractOptions) (int, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return 0, openError(err)
+	}
+	defer reader.Close()
+
+	targets, err := selectTargets(reader.File, targetName, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkTargets(targets); err != nil {
+		return 0, err
+	}
+
+	limit := newRateLimiter(opts.RateLimit)
+	tw := tar.NewWriter(w)
+	for i, t := range targets {
+		if err := opts.Scanner.check(ctx, t, nil); err != nil {
+			return i, err
+		}
+		if err := writeTarEntry(ctx, tw, t, limit); err != nil {
+			return i, err
+		}
+	}
+	return len(targets), tw.Close()
+}
+
+// writeTarEntry writes the header and the content of one target to tw, at
+// the rate of limit.
+func writeTarEntry(ctx context.Context, tw *tar.Writer, t extractTarget, limit *rateLimiter) error {
+	f := t.file
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     t.relPath,
+		Mode:     0644,
+		Size:     int64(f.UncompressedSize64),
+		ModTime:  entryModTime(f),
+	}
+	if hdr.ModTime.IsZero() {
+		hdr.ModTime = time.Unix(0, 0)
+	}
+	if creator := f.CreatorVersion >> 8; creator == 3 || creator == 19 { // Unix, macOS
+		mode := f.Mode()
+		if perm := mode.Perm(); perm != 0 {
+			hdr.Mode = int64(perm)
+			if mode&fs.ModeSetuid != 0 {
+				hdr.Mode |= 04000
+			}
+			if mode&fs.ModeSetgid != 0 {
+				hdr.Mode |= 02000
+			}
+			if mode&fs.ModeSticky != 0 {
+				hdr.Mode |= 01000
+			}
+		}
+		if uid, gid := extraOwner(f.Extra); uid >= 0 {
+			hdr.Uid, hdr.Gid = uid, gid
+		}
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	r := &extractReader{ctx: ctx, r: rc, limit: limit}
+
+	if f.Mode()&fs.ModeSymlink != 0 {
+		target, err := io.ReadAll(io.LimitReader(r, maxLinkTarget))
+		if err != nil {
+			return err
+		}
+		hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, string(target), 0
+		return tw.WriteHeader(hdr)
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, r)
+	return err
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteTar checks names, contents, modes, links and the filters of the tar stream
+func TestWriteTar(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "test.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	for _, e := range []struct {
+		name, content string
+		mode          os.FileMode
+	}{
+		{"dir/", "", os.ModeDir | 0755},
+		{"dir/run.sh", "#!/bin/sh\n", 0755},
+		{"dir/link", "run.sh", os.ModeSymlink | 0777},
+		{"notes.txt", "notes", 0600},
+	} {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		header.SetMode(e.mode)
+		w, _ := zw.CreateHeader(header)
+		w.Write([]byte(e.content))
+	}
+	zw.Close()
+	out.Close()
+
+	var buf bytes.Buffer
+	count, err := WriteTar(context.Background(), zipPath, "", &buf, ExtractOptions{})
+	if err != nil || count != 3 {
+		t.Fatalf("WriteTar() = %d, %v, want 3 files", count, err)
+	}
+
+	type entry struct {
+		typeflag byte
+		mode     int64
+		link     string
+		content  string
+	}
+	got := make(map[string]entry)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading the tar stream: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		got[hdr.Name] = entry{hdr.Typeflag, hdr.Mode, hdr.Linkname, string(content)}
+	}
+	want := map[string]entry{
+		"dir/run.sh": {tar.TypeReg, 0755, "", "#!/bin/sh\n"},
+		"dir/link":   {tar.TypeSymlink, 0777, "run.sh", ""},
+		"notes.txt":  {tar.TypeReg, 0600, "", "notes"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("WriteTar() wrote %v, want %v", got, want)
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("WriteTar() entry %s = %+v, want %+v", name, got[name], w)
+		}
+	}
+
+	buf.Reset()
+	count, err = WriteTar(context.Background(), zipPath, "dir", &buf, ExtractOptions{Exclude: []string{"link"}, Flatten: true})
+	if err != nil || count != 1 {
+		t.Fatalf("WriteTar(dir) = %d, %v, want 1 file", count, err)
+	}
+	if hdr, err := tar.NewReader(&buf).Next(); err != nil || hdr.Name != "run.sh" {
+		t.Errorf("WriteTar(dir) first entry = %v, %v, want run.sh", hdr, err)
+	}
+
+	if _, err := WriteTar(context.Background(), zipPath, "missing", io.Discard, ExtractOptions{}); err == nil {
+		t.Error("WriteTar() expected error for a missing entry, got nil")
+	}
+}
@@ -0,0 +1,135 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"os"
+	"time"
+)
+
+// entryTimes are the timestamps an entry's extra fields record; a zero time
+// means the archive does not record it.
+type entryTimes struct {
+	modified time.Time
+	accessed time.Time
+	created  time.Time
+}
+
+// ntfsEpoch is where NTFS timestamps, counted in 100 ns ticks, start.
+var ntfsEpoch = time.Date(1601, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// extraTimes reads the timestamps of the NTFS (0x000a), extended timestamp
+// (0x5455) and Unix (0x5855, 0x000d) extra fields. NTFS times are accurate
+// to 100 ns, the others to the second; all of them replace the MS-DOS time,
+// which is only accurate to two seconds. When several fields are present
+// the last one wins, as in archive/zip.
+//
+// Central directory records usually hold only the modification time of the
+// extended timestamp field; the NTFS field keeps all three.
+func extraTimes(extra []byte) entryTimes {
+	var times entryTimes
+
+	le := binary.LittleEndian
+	for len(extra) >= 4 {
+		tag := le.Uint16(extra)
+		size := int(le.Uint16(extra[2:]))
+		if len(extra)-4 < size {
+			break
+		}
+		field := extra[4 : 4+size]
+		extra = extra[4+size:]
+
+		switch tag {
+		case ntfsExtraID:
+			if len(field) < 4 {
+				continue
+			}
+			for attrs := field[4:]; len(attrs) >= 4; {
+				attrTag := le.Uint16(attrs)
+				attrSize := int(le.Uint16(attrs[2:]))
+				if len(attrs)-4 < attrSize {
+					break
+				}
+				attr := attrs[4 : 4+attrSize]
+				attrs = attrs[4+attrSize:]
+				if attrTag != 1 || attrSize != 24 {
+					continue
+				}
+
+				times = entryTimes{
+					modified: ntfsTime(le.Uint64(attr)),
+					accessed: ntfsTime(le.Uint64(attr[8:])),
+					created:  ntfsTime(le.Uint64(attr[16:])),
+				}
+			}
+		case unixExtraID, infoZipUnixExtraID:
+			if len(field) >= 8 {
+				times = entryTimes{
+					accessed: time.Unix(int64(le.Uint32(field)), 0),
+					modified: time.Unix(int64(le.Uint32(field[4:])), 0),
+				}
+			}
+		case extTimeExtraID:
+			if len(field) < 1 {
+				continue
+			}
+			// The flags say which times the local header holds, in this
+			// order; the central header may stop after the first one.
+			flags, data := field[0], field[1:]
+			var found entryTimes
+			for i, t := range []*time.Time{&found.modified, &found.accessed, &found.created} {
+				if flags&(1<<i) == 0 {
+					continue
+				}
+				if len(data) < 4 {
+					break
+				}
+				*t = time.Unix(int64(le.Uint32(data)), 0)
+				data = data[4:]
+			}
+			if !found.modified.IsZero() {
+				times = found
+			}
+		}
+	}
+
+	return times
+}
+
+// ntfsTime converts an NTFS timestamp; 0 means not set.
+func ntfsTime(ticks uint64) time.Time {
+	if ticks == 0 {
+		return time.Time{}
+	}
+	const ticksPerSecond = 1e7
+	ts := int64(ticks)
+	return time.Unix(ntfsEpoch.Unix()+ts/ticksPerSecond, (1e9/ticksPerSecond)*(ts%ticksPerSecond))
+}
+
+// restoreTimes gives the file at path the modification and access times
+// recorded for f. Without an extra field the MS-DOS time is used, read as
+// local time like other unzip tools do; the access time is then left as is.
+func restoreTimes(f *zip.File, path string) error {
+	times := extraTimes(f.Extra)
+	times.modified = entryModTime(f)
+	if times.modified.IsZero() {
+		return nil
+	}
+
+	// A zero access time leaves the current one unchanged.
+	return os.Chtimes(path, times.accessed, times.modified)
+}
+
+// entryModTime returns the modification time extraction gives the file of
+// f: the one of its extra fields, else the MS-DOS time read as local time.
+// It is zero when the archive records none.
+func entryModTime(f *zip.File) time.Time {
+	if modified := extraTimes(f.Extra).modified; !modified.IsZero() {
+		return modified
+	}
+	if f.ModifiedDate == 0 && f.ModifiedTime == 0 {
+		return time.Time{}
+	}
+	dos := msDosTime(f.ModifiedDate, f.ModifiedTime)
+	return time.Date(dos.Year(), dos.Month(), dos.Day(), dos.Hour(), dos.Minute(), dos.Second(), 0, time.Local)
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ntfsExtra builds an NTFS extra field holding the three timestamps
+func ntfsExtra(modified, accessed, created time.Time) []byte {
+	ticks := func(t time.Time) uint64 {
+		return uint64((t.Unix()-ntfsEpoch.Unix())*1e7 + int64(t.Nanosecond()/100))
+	}
+
+	field := make([]byte, 36)
+	binary.LittleEndian.PutUint16(field, ntfsExtraID)
+	binary.LittleEndian.PutUint16(field[2:], 32)
+	binary.LittleEndian.PutUint16(field[8:], 1)
+	binary.LittleEndian.PutUint16(field[10:], 24)
+	binary.LittleEndian.PutUint64(field[12:], ticks(modified))
+	binary.LittleEndian.PutUint64(field[20:], ticks(accessed))
+	binary.LittleEndian.PutUint64(field[28:], ticks(created))
+	return field
+}
+
+// TestExtraTimes checks the NTFS, extended timestamp and Unix fields
+func TestExtraTimes(t *testing.T) {
+	modified := time.Date(2024, time.May, 4, 12, 30, 15, 123456700, time.UTC)
+	accessed := modified.Add(time.Hour)
+	created := modified.Add(-time.Hour)
+
+	got := extraTimes(ntfsExtra(modified, accessed, created))
+	if !got.modified.Equal(modified) || !got.accessed.Equal(accessed) || !got.created.Equal(created) {
+		t.Errorf("extraTimes(NTFS) = %+v, want 100 ns accurate times", got)
+	}
+
+	ut := []byte{0x55, 0x54, 13, 0, 7, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(ut[5:], uint32(modified.Unix()))
+	binary.LittleEndian.PutUint32(ut[9:], uint32(accessed.Unix()))
+	binary.LittleEndian.PutUint32(ut[13:], uint32(created.Unix()))
+	got = extraTimes(ut)
+	if got.modified.Unix() != modified.Unix() || got.accessed.Unix() != accessed.Unix() || got.created.Unix() != created.Unix() {
+		t.Errorf("extraTimes(extended timestamp) = %+v", got)
+	}
+
+	// Central headers keep only the modification time, whatever the flags say.
+	got = extraTimes(append([]byte{0x55, 0x54, 5, 0}, ut[4:9]...))
+	if got.modified.Unix() != modified.Unix() || !got.accessed.IsZero() {
+		t.Errorf("extraTimes(central extended timestamp) = %+v, want only the modification time", got)
+	}
+
+	if got := extraTimes([]byte{0x55, 0x54, 1, 0, 2}); !got.modified.IsZero() {
+		t.Errorf("extraTimes(no modification time) = %+v, want nothing", got)
+	}
+}
+
+// TestListAndExtractTimes checks that listing reports the NTFS times and that
+// extraction restores the modification time
+func TestListAndExtractTimes(t *testing.T) {
+	modified := time.Date(2023, time.February, 3, 4, 5, 6, 700000000, time.UTC)
+	accessed := modified.Add(48 * time.Hour)
+	created := modified.Add(-48 * time.Hour)
+
+	zipPath := filepath.Join(t.TempDir(), "times.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create ZIP: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	w, _ := zw.CreateHeader(&zip.FileHeader{Name: "ntfs.txt", Method: zip.Deflate, Extra: ntfsExtra(modified, accessed, created)})
+	w.Write([]byte("ntfs"))
+	w, _ = zw.CreateHeader(&zip.FileHeader{Name: "unix.txt", Method: zip.Deflate, Modified: modified})
+	w.Write([]byte("unix"))
+	zw.Close()
+	out.Close()
+
+	files, err := ListArchive(zipPath)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("ListArchive() = %v, %v", files, err)
+	}
+	if zf := files[0]; !zf.GetModified().Equal(modified) || !zf.GetAccessed().Equal(accessed) || !zf.GetCreated().Equal(created) {
+		t.Errorf("ntfs.txt times = %v, %v, %v", zf.GetModified(), zf.GetAccessed(), zf.GetCreated())
+	}
+
+	destDir := t.TempDir()
+	if _, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{}); err != nil {
+		t.Fatalf("ExtractWithOptions() unexpected error = %v", err)
+	}
+	for name, want := range map[string]time.Time{"ntfs.txt": modified, "unix.txt": modified.Truncate(time.Second)} {
+		info, err := os.Stat(filepath.Join(destDir, name))
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", name, err)
+		}
+		if !info.ModTime().Equal(want) {
+			t.Errorf("%s modification time = %v, want %v", name, info.ModTime(), want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// usageFile is the name of the local usage statistics file inside StateDir.
+// Statistics are only recorded once the user opts in by creating it through
+// EnableUsageStats; they never leave the machine.
+const usageFile = "usage.json"
+
+// UsageStats holds the locally recorded usage counters.
+type UsageStats struct {
+	Since  time.Time      `json:"since"`
+	Counts map[string]int `json:"counts"`
+}
+
+// UsageCount is a single counter of a usage report.
+type UsageCount struct {
+	Event string
+	Count int
+}
+
+var usage struct {
+	sync.Mutex
+	loaded  bool
+	enabled bool
+	stats   UsageStats
+	dirty   bool
+}
+
+func usagePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, usageFile), nil
+}
+
+// loadUsageStats reads the statistics file. The bool result is false when
+// the user has not opted in.
+func loadUsageStats() (UsageStats, bool, error) {
+	p, err := usagePath()
+	if err != nil {
+		return UsageStats{}, false, err
+	}
+
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return UsageStats{}, false, nil
+	}
+	if err != nil {
+		return UsageStats{}, false, err
+	}
+
+	var stats UsageStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return UsageStats{}, false, err
+	}
+	if stats.Counts == nil {
+		stats.Counts = make(map[string]int)
+	}
+
+	return stats, true, nil
+}
+
+func saveUsageStats(stats UsageStats) error {
+	p, err := usagePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(p, data, 0644)
+}
+
+// RecordUsage counts one occurrence of event, such as "command:create" or
+// "key:f", and logs it at the Debug level. It counts nothing unless usage
+// statistics are enabled. Counters are kept in memory until FlushUsage is
+// called.
+func RecordUsage(event string) {
+	Logger().Debug("event", "name", event)
+
+	usage.Lock()
+	defer usage.Unlock()
+
+	if !usage.loaded {
+		usage.loaded = true
+		_, enabled, err := loadUsageStats()
+		usage.enabled = enabled && err == nil
+		usage.stats.Counts = make(map[string]int)
+	}
+
+	if !usage.enabled {
+		return
+	}
+
+	usage.stats.Counts[event]++
+	usage.dirty = true
+}
+
+// FlushUsage writes the counters recorded since the last flush to disk.
+func FlushUsage() error {
+	usage.Lock()
+	defer usage.Unlock()
+
+	if !usage.enabled || !usage.dirty {
+		return nil
+	}
+
+	// Merge with the file in case another goZip instance wrote meanwhile.
+	stats, enabled, err := loadUsageStats()
+	if err != nil || !enabled {
+		return err
+	}
+	for event, n := range usage.stats.Counts {
+		stats.Counts[event] += n
+	}
+
+	usage.stats.Counts = make(map[string]int)
+	usage.dirty = false
+
+	return saveUsageStats(stats)
+}
+
+// EnableUsageStats opts in to local usage statistics. Existing counters are kept.
+func EnableUsageStats() error {
+	if _, enabled, err := loadUsageStats(); err != nil || enabled {
+		return err
+	}
+
+	return saveUsageStats(UsageStats{Since: time.Now().UTC(), Counts: map[string]int{}})
+}
+
+// DisableUsageStats opts out and deletes every recorded counter.
+func DisableUsageStats() error {
+	p, err := usagePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// UsageReport returns the recorded statistics, most used events first.
+// The bool result is false when statistics are not enabled.
+func UsageReport() (UsageStats, []UsageCount, bool, error) {
+	stats, enabled, err := loadUsageStats()
+	if err != nil || !enabled {
+		return stats, nil, enabled, err
+	}
+
+	counts := make([]UsageCount, 0, len(stats.Counts))
+	for event, n := range stats.Counts {
+		counts = append(counts, UsageCount{Event: event, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Event < counts[j].Event
+	})
+
+	return stats, counts, true, nil
+}
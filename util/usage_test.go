@@ -0,0 +1,73 @@
+package util
+
+import (
+	"testing"
+)
+
+// resetUsage forgets the in-memory usage state so each test starts fresh
+func resetUsage(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	usage.Lock()
+	usage.loaded = false
+	usage.enabled = false
+	usage.dirty = false
+	usage.Unlock()
+}
+
+// TestUsageDisabledByDefault checks that nothing is recorded without opting in
+func TestUsageDisabledByDefault(t *testing.T) {
+	resetUsage(t)
+
+	RecordUsage("command:create")
+	if err := FlushUsage(); err != nil {
+		t.Fatalf("FlushUsage() unexpected error = %v", err)
+	}
+
+	_, counts, enabled, err := UsageReport()
+	if err != nil {
+		t.Fatalf("UsageReport() unexpected error = %v", err)
+	}
+	if enabled || len(counts) != 0 {
+		t.Errorf("UsageReport() = %v, %v, want disabled and empty", counts, enabled)
+	}
+}
+
+// TestUsageRecording checks that counters accumulate across flushes once enabled
+func TestUsageRecording(t *testing.T) {
+	resetUsage(t)
+
+	if err := EnableUsageStats(); err != nil {
+		t.Fatalf("EnableUsageStats() unexpected error = %v", err)
+	}
+
+	RecordUsage("key:f")
+	RecordUsage("key:f")
+	FlushUsage()
+	RecordUsage("key:f")
+	RecordUsage("command:create")
+	FlushUsage()
+
+	_, counts, enabled, err := UsageReport()
+	if err != nil || !enabled {
+		t.Fatalf("UsageReport() = enabled %v, err %v", enabled, err)
+	}
+
+	want := []UsageCount{{Event: "key:f", Count: 3}, {Event: "command:create", Count: 1}}
+	if len(counts) != len(want) {
+		t.Fatalf("UsageReport() counts = %v, want %v", counts, want)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("counts[%d] = %v, want %v", i, counts[i], want[i])
+		}
+	}
+
+	if err := DisableUsageStats(); err != nil {
+		t.Fatalf("DisableUsageStats() unexpected error = %v", err)
+	}
+	if _, _, enabled, _ := UsageReport(); enabled {
+		t.Error("UsageReport() still enabled after DisableUsageStats()")
+	}
+}
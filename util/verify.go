@@ -0,0 +1,194 @@
+package util
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// bsdSumsLine matches the lines of BSD-style sums files, as written by
+// "sha256sum --tag": "SHA256 (name) = checksum".
+var bsdSumsLine = regexp.MustCompile(`^(SHA256|SHA1|MD5) \((.*)\) = ([0-9a-fA-F]+)$`)
+
+// ParseSums reads a checksum file in the formats of sha256sum, sha1sum and
+// md5sum: "<checksum>  <name>" lines, with "*" before binary names, or
+// the BSD "SHA256 (name) = <checksum>" lines. Empty lines and lines
+// starting with "#" are skipped. The algorithm is told from the length of
+// the checksums, which must all use the same one.
+//
+// Parameters:
+//   - r: the content of the sums file
+//
+// Returns:
+//   - []EntryHash: the names and expected checksums, in file order
+//   - HashAlgorithm: the algorithm of the checksums
+//   - error: a malformed line, mixed algorithms, or an empty file
+func ParseSums(r io.Reader) ([]EntryHash, HashAlgorithm, error) {
+	var sums []EntryHash
+	var alg HashAlgorithm
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var h EntryHash
+		if m := bsdSumsLine.FindStringSubmatch(line); m != nil {
+			h = EntryHash{Name: m[2], Sum: m[3]}
+		} else {
+			sum, name, ok := strings.Cut(line, " ")
+			if !ok || len(name) < 2 || (name[0] != ' ' && name[0] != '*') {
+				return nil, "", fmt.Errorf("line %d: want a checksum, two spaces and a name", n)
+			}
+			h = EntryHash{Name: name[1:], Sum: sum}
+		}
+
+		lineAlg, err := sumAlgorithm(h.Sum)
+		if err != nil {
+			return nil, "", fmt.Errorf("line %d: %w", n, err)
+		}
+		if alg != "" && lineAlg != alg {
+			return nil, "", fmt.Errorf("line %d: %s checksum in a %s file", n, lineAlg, alg)
+		}
+		alg = lineAlg
+		h.Sum = strings.ToLower(h.Sum)
+		h.Name = strings.TrimPrefix(h.Name, "./")
+		sums = append(sums, h)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+	if len(sums) == 0 {
+		return nil, "", errors.New("no checksum found")
+	}
+	return sums, alg, nil
+}
+
+// sumAlgorithm tells the algorithm of a hexadecimal checksum from its length.
+func sumAlgorithm(sum string) (HashAlgorithm, error) {
+	for _, c := range sum {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return "", fmt.Errorf("invalid checksum %q", sum)
+		}
+	}
+	switch len(sum) {
+	case 64:
+		return HashSHA256, nil
+	case 40:
+		return HashSHA1, nil
+	case 32:
+		return HashMD5, nil
+	default:
+		return "", fmt.Errorf("checksum %q is neither SHA-256, SHA-1 nor MD5", sum)
+	}
+}
+
+// VerifyReport is the outcome of VerifySums.
+type VerifyReport struct {
+	Algorithm HashAlgorithm
+	// OK, Mismatched and Missing list the names of the sums file whose
+	// file has the expected checksum, another one, or is not in the
+	// archive, in file order.
+	OK         []string
+	Mismatched []string
+	Missing    []string
+}
+
+// Passed reports whether every file listed was found with its checksum.
+func (r VerifyReport) Passed() bool {
+	return len(r.Mismatched) == 0 && len(r.Missing) == 0
+}
+
+// VerifySums checks the files of the archive against a checksum file, such
+// as the SHA256SUMS published next to a release bundle. The sums file is
+// read from disk or, when there is no such file, from the archive itself,
+// its names then being relative to its folder. Files the sums file does
+// not list are not checked; for a name stored several times, the last
+// copy is, the one extraction picks by default.
+//
+// Parameters:
+//   - ctx: stops the verification between two buffers
+//   - zipPath: full path to the ZIP file
+//   - sumsPath: the sums file on disk, or its name inside the archive
+//
+// Returns:
+//   - VerifyReport: which files passed and which did not
+//   - error: an unreadable or malformed sums file, an encrypted file, or
+//     any error reading the archive
+func VerifySums(ctx context.Context, zipPath, sumsPath string) (VerifyReport, error) {
+	reader, err := openZipReader(zipPath)
+	if err != nil {
+		return VerifyReport{}, openError(err)
+	}
+	defer reader.Close()
+	decodeNames(reader.File, "")
+
+	data, err := os.ReadFile(sumsPath)
+	var dir string
+	if errors.Is(err, fs.ErrNotExist) {
+		var entry *zip.File
+		entry, err = lastFile(reader.File, sumsPath)
+		if errors.Is(err, ErrEntryNotFound) {
+			return VerifyReport{}, fmt.Errorf("'%s' is neither a file nor in the archive", sumsPath)
+		}
+		if err != nil {
+			return VerifyReport{}, err
+		}
+		data, err = readEntry(entry)
+		dir = path.Dir(entry.Name)
+	}
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	sums, alg, err := ParseSums(bytes.NewReader(data))
+	if err != nil {
+		return VerifyReport{}, fmt.Errorf("%s: %w", sumsPath, err)
+	}
+
+	// The last copy of each name listed.
+	files := make(map[string]*zip.File)
+	for _, f := range reader.File {
+		if !f.FileInfo().IsDir() {
+			files[f.Name] = f
+		}
+	}
+
+	report := VerifyReport{Algorithm: alg}
+	for _, s := range sums {
+		name := s.Name
+		if dir != "" && dir != "." {
+			name = path.Join(dir, name)
+		}
+		f, ok := files[name]
+		if !ok {
+			report.Missing = append(report.Missing, s.Name)
+			continue
+		}
+		if err := checkReadable(f); err != nil {
+			return VerifyReport{}, err
+		}
+
+		sum, err := hashFile(ctx, f, alg)
+		if err != nil {
+			return VerifyReport{}, fmt.Errorf("'%s': %w", f.Name, err)
+		}
+		if sum == s.Sum {
+			report.OK = append(report.OK, s.Name)
+		} else {
+			report.Mismatched = append(report.Mismatched, s.Name)
+		}
+	}
+	return report, nil
+}
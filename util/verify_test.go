@@ -0,0 +1,76 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestParseSums checks the GNU and BSD formats and the detected algorithm
+func TestParseSums(t *testing.T) {
+	input := "# release 1.0\n" +
+		"900150983cd24fb0d6963f7d28e17f72  ./a.txt\n" +
+		"D41D8CD98F00B204E9800998ECF8427E *bin/b.exe\r\n" +
+		"\n" +
+		"MD5 (c d.txt) = 0cc175b9c0f1b6a831c399e269772661\n"
+
+	sums, alg, err := ParseSums(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseSums() unexpected error = %v", err)
+	}
+	want := []EntryHash{
+		{Name: "a.txt", Sum: "900150983cd24fb0d6963f7d28e17f72"},
+		{Name: "bin/b.exe", Sum: "d41d8cd98f00b204e9800998ecf8427e"},
+		{Name: "c d.txt", Sum: "0cc175b9c0f1b6a831c399e269772661"},
+	}
+	if alg != HashMD5 || !slices.Equal(sums, want) {
+		t.Errorf("ParseSums() = %+v, %s, want %+v, md5", sums, alg, want)
+	}
+
+	for _, bad := range []string{
+		"",
+		"xyz  a.txt\n",
+		"900150983cd24fb0d6963f7d28e17f72 a.txt\n",
+		"900150983cd24fb0d6963f7d28e17f72  a.txt\nba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  b.txt\n",
+	} {
+		if _, _, err := ParseSums(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseSums(%q) expected error, got nil", bad)
+		}
+	}
+}
+
+// TestVerifySums checks a sums file on disk and one inside the archive
+func TestVerifySums(t *testing.T) {
+	const sumABC = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	sums := sumABC + "  a.txt\n" + sumABC + "  b.txt\n" + sumABC + "  gone.txt\n"
+	zipPath := writeTestZip(t, 0, map[string]string{
+		"rel/":           "",
+		"rel/a.txt":      "abc",
+		"rel/b.txt":      "tampered",
+		"rel/SHA256SUMS": sums,
+	})
+
+	report, err := VerifySums(context.Background(), zipPath, "rel/SHA256SUMS")
+	if err != nil {
+		t.Fatalf("VerifySums(inside) unexpected error = %v", err)
+	}
+	if report.Passed() || !slices.Equal(report.OK, []string{"a.txt"}) || !slices.Equal(report.Mismatched, []string{"b.txt"}) || !slices.Equal(report.Missing, []string{"gone.txt"}) {
+		t.Errorf("VerifySums(inside) = %+v", report)
+	}
+
+	sumsPath := filepath.Join(t.TempDir(), "SHA256SUMS")
+	if err := os.WriteFile(sumsPath, []byte(sumABC+"  rel/a.txt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err = VerifySums(context.Background(), zipPath, sumsPath)
+	if err != nil || !report.Passed() || report.Algorithm != HashSHA256 {
+		t.Errorf("VerifySums(disk) = %+v, %v", report, err)
+	}
+
+	if _, err := VerifySums(context.Background(), zipPath, "MD5SUMS"); err == nil {
+		t.Error("VerifySums(MD5SUMS) expected error, got nil")
+	}
+}
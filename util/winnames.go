@@ -0,0 +1,77 @@
+package util
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// windowsNames tells whether extracted files must get names Windows
+// accepts; tests turn it on elsewhere.
+var windowsNames = runtime.GOOS == "windows"
+
+// windowsReserved are the device names Windows refuses as file names, with
+// or without an extension.
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsSafePath returns rel, a slash-separated path, with every
+// component turned into a name Windows can create, and why it had to
+// change, "" when it did not: the characters <>:"|?* and control
+// characters become "_", reserved device names such as CON or aux.txt get
+// a "_" after the base name, and trailing dots and spaces, which Windows
+// drops, are replaced by "_".
+func windowsSafePath(rel string) (string, string) {
+	var reasons []string
+	note := func(reason string) {
+		if !slices.Contains(reasons, reason) {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		safe := strings.Map(func(r rune) rune {
+			if r < 32 || strings.ContainsRune(`<>:"|?*`, r) {
+				return '_'
+			}
+			return r
+		}, part)
+		if safe != part {
+			note("characters Windows does not allow")
+		}
+
+		if trimmed := strings.TrimRight(safe, ". "); trimmed != safe && trimmed != "" {
+			safe = trimmed + strings.Repeat("_", len(safe)-len(trimmed))
+			note("trailing dot or space")
+		}
+
+		if base, ext, found := strings.Cut(safe, "."); windowsReserved[strings.ToUpper(base)] {
+			safe = base + "_"
+			if found {
+				safe += "." + ext
+			}
+			note("reserved name on Windows")
+		}
+		parts[i] = safe
+	}
+
+	if len(reasons) == 0 {
+		return rel, ""
+	}
+	return strings.Join(parts, "/"), strings.Join(reasons, ", ")
+}
+
+// renameForWindows gives the targets names Windows can create when
+// extracting on Windows, recording why in their renamed field.
+func renameForWindows(targets []extractTarget) {
+	if !windowsNames {
+		return
+	}
+	for i, t := range targets {
+		targets[i].relPath, targets[i].renamed = windowsSafePath(t.relPath)
+	}
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWindowsSafePath checks reserved names, forbidden characters and
+// trailing dots
+func TestWindowsSafePath(t *testing.T) {
+	tests := []struct {
+		in, want string
+		renamed  bool
+	}{
+		{"docs/readme.txt", "docs/readme.txt", false},
+		{"CON", "CON_", true},
+		{"dir/aux.txt", "dir/aux_.txt", true},
+		{"nul.tar.gz", "nul_.tar.gz", true},
+		{"console.txt", "console.txt", false},
+		{"a:b/c*d?.txt", "a_b/c_d_.txt", true},
+		{"notes.", "notes_", true},
+		{"com1/lpt9.log", "com1_/lpt9_.log", true},
+	}
+
+	for _, tt := range tests {
+		got, reason := windowsSafePath(tt.in)
+		if got != tt.want || (reason != "") != tt.renamed {
+			t.Errorf("windowsSafePath(%q) = %q, %q, want %q (renamed %v)", tt.in, got, reason, tt.want, tt.renamed)
+		}
+	}
+}
+
+// renameRecorder records the renames of an extraction
+type renameRecorder struct {
+	NopExtractObserver
+	paths map[string]string
+}
+
+func (r *renameRecorder) OnRename(name, path, reason string) {
+	r.paths[name] = path
+}
+
+// TestExtractWindowsNames checks that extraction renames the names Windows
+// refuses and reports them, as it does on Windows
+func TestExtractWindowsNames(t *testing.T) {
+	windowsNames = true
+	t.Cleanup(func() { windowsNames = false })
+
+	zipPath := writeTestZip(t, 0, map[string]string{"aux.txt": "a", "ok.txt": "b", "q?.txt": "c"})
+	destDir := t.TempDir()
+	observer := &renameRecorder{paths: make(map[string]string)}
+	count, err := ExtractWithOptions(context.Background(), zipPath, "", destDir, ExtractOptions{Observer: observer})
+	if err != nil || count != 3 {
+		t.Fatalf("ExtractWithOptions() = %d, %v, want 3 files", count, err)
+	}
+	for _, name := range []string{"aux_.txt", "ok.txt", "q_.txt"} {
+		if _, err := os.Stat(filepath.Join(destDir, name)); err != nil {
+			t.Errorf("%s not extracted: %v", name, err)
+		}
+	}
+	if len(observer.paths) != 2 || observer.paths["aux.txt"] != filepath.Join(destDir, "aux_.txt") {
+		t.Errorf("OnRename() calls = %v, want aux.txt and q?.txt", observer.paths)
+	}
+
+	plan, err := PlanExtraction(zipPath, "aux.txt", destDir, ExtractOptions{})
+	if err != nil || len(plan.Files) != 1 || plan.Files[0].Renamed == "" {
+		t.Errorf("PlanExtraction() = %+v, %v, want a renamed file", plan, err)
+	}
+}